@@ -38,6 +38,7 @@ const targets = [
         postprocess: async (result) => {
             await reportBundleSize(result.outputFiles?.[0]?.path ?? resolve(outDir, 'pondlive.js'), 'esbuild');
             await minifyWithTerser(resolve(outDir, 'pondlive.js'));
+            await compressAsset(resolve(outDir, 'pondlive.js'));
         },
     },
     {
@@ -50,6 +51,7 @@ const targets = [
         },
         postprocess: async (result) => {
             await reportBundleSize(result.outputFiles?.[0]?.path ?? resolve(outDir, 'pondlive-dev.js'), 'esbuild');
+            await compressAsset(resolve(outDir, 'pondlive-dev.js'));
         },
     },
 ];
@@ -121,6 +123,23 @@ async function minifyWithTerser(filePath) {
     }
 }
 
+// The Go server embeds these and picks one by Accept-Encoding.
+async function compressAsset(filePath) {
+    const fs = await import('fs');
+    const zlib = await import('zlib');
+
+    const data = fs.readFileSync(filePath);
+    fs.writeFileSync(`${filePath}.gz`, zlib.gzipSync(data, {level: zlib.constants.Z_BEST_COMPRESSION}));
+    fs.writeFileSync(`${filePath}.br`, zlib.brotliCompressSync(data, {
+        params: {
+            [zlib.constants.BROTLI_PARAM_QUALITY]: zlib.constants.BROTLI_MAX_QUALITY,
+            [zlib.constants.BROTLI_PARAM_SIZE_HINT]: data.length,
+        },
+    }));
+    await reportBundleSize(`${filePath}.gz`, 'gzip');
+    await reportBundleSize(`${filePath}.br`, 'brotli');
+}
+
 function resolveConfig(target) {
     return {
         ...baseConfig,
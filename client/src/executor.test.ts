@@ -221,7 +221,7 @@ describe('Executor', () => {
                     replace: false,
                 });
 
-                expect(pushStateSpy).toHaveBeenCalledWith({}, '', '/new-page');
+                expect(pushStateSpy).toHaveBeenCalledWith({ key: expect.any(String), idx: expect.any(Number) }, '', '/new-page');
                 pushStateSpy.mockRestore();
             });
 
@@ -235,7 +235,7 @@ describe('Executor', () => {
                     replace: false,
                 });
 
-                expect(pushStateSpy).toHaveBeenCalledWith({}, '', '/search?q=test');
+                expect(pushStateSpy).toHaveBeenCalledWith({ key: expect.any(String), idx: expect.any(Number) }, '', '/search?q=test');
                 pushStateSpy.mockRestore();
             });
 
@@ -249,7 +249,7 @@ describe('Executor', () => {
                     replace: false,
                 });
 
-                expect(pushStateSpy).toHaveBeenCalledWith({}, '', '/page#section');
+                expect(pushStateSpy).toHaveBeenCalledWith({ key: expect.any(String), idx: expect.any(Number) }, '', '/page#section');
                 pushStateSpy.mockRestore();
             });
         });
@@ -265,11 +265,88 @@ describe('Executor', () => {
                     replace: true,
                 });
 
-                expect(replaceStateSpy).toHaveBeenCalledWith({}, '', '/replaced');
+                expect(replaceStateSpy).toHaveBeenCalledWith({ key: expect.any(String), idx: expect.any(Number) }, '', '/replaced');
                 replaceStateSpy.mockRestore();
             });
         });
 
+        describe('scroll and focus', () => {
+            it('should scroll to top and focus main after a push settles', () => {
+                const scrollSpy = vi.spyOn(window, 'scrollTo').mockImplementation(() => {});
+                const main = document.createElement('main');
+                document.body.appendChild(main);
+
+                bus.publish('router', 'push', {
+                    path: '/next',
+                    query: '',
+                    hash: '',
+                    replace: false,
+                });
+                executor.afterPatch();
+
+                expect(scrollSpy).toHaveBeenCalledWith(0, 0);
+                expect(main.getAttribute('tabindex')).toBe('-1');
+                expect(document.activeElement).toBe(main);
+
+                main.remove();
+                scrollSpy.mockRestore();
+            });
+
+            it('should scroll to the hash target and honour preserve', () => {
+                const scrollSpy = vi.spyOn(window, 'scrollTo').mockImplementation(() => {});
+                const target = document.createElement('section');
+                target.id = 'details';
+                target.scrollIntoView = vi.fn();
+                document.body.appendChild(target);
+
+                bus.publish('router', 'push', {
+                    path: '/with-hash',
+                    query: '',
+                    hash: 'details',
+                    replace: false,
+                });
+                executor.afterPatch();
+                expect(target.scrollIntoView).toHaveBeenCalled();
+
+                scrollSpy.mockClear();
+                bus.publish('router', 'push', {
+                    path: '/kept',
+                    query: '',
+                    hash: '',
+                    replace: false,
+                    scroll: 'preserve',
+                    focus: 'none',
+                });
+                executor.afterPatch();
+                expect(scrollSpy).not.toHaveBeenCalled();
+
+                target.remove();
+                scrollSpy.mockRestore();
+            });
+
+            it('should restore the saved position on popstate', () => {
+                const scrollSpy = vi.spyOn(window, 'scrollTo').mockImplementation(() => {});
+                const startKey = (window.history.state as { key: string }).key;
+
+                Object.defineProperty(window, 'scrollY', { value: 420, configurable: true });
+                bus.publish('router', 'push', {
+                    path: '/deeper',
+                    query: '',
+                    hash: '',
+                    replace: false,
+                });
+                executor.afterPatch();
+                Object.defineProperty(window, 'scrollY', { value: 0, configurable: true });
+
+                scrollSpy.mockClear();
+                window.dispatchEvent(new PopStateEvent('popstate', { state: { key: startKey } }));
+                executor.afterPatch();
+
+                expect(scrollSpy).toHaveBeenCalledWith(0, 420);
+                scrollSpy.mockRestore();
+            });
+        });
+
         describe('back', () => {
             it('should call history.back', () => {
                 const backSpy = vi.spyOn(window.history, 'back');
@@ -291,6 +368,17 @@ describe('Executor', () => {
                 forwardSpy.mockRestore();
             });
         });
+
+        describe('go', () => {
+            it('should call history.go with the delta', () => {
+                const goSpy = vi.spyOn(window.history, 'go').mockImplementation(() => {});
+
+                bus.publish('router', 'go', { delta: -1 });
+
+                expect(goSpy).toHaveBeenCalledWith(-1);
+                goSpy.mockRestore();
+            });
+        });
     });
 
     describe('popstate listener', () => {
@@ -303,6 +391,55 @@ describe('Executor', () => {
                 hash: expect.any(String),
             });
         });
+
+        it('should report how far a popstate moved through history', () => {
+            bus.publish('router', 'push', { path: '/first', query: '', hash: '', replace: false });
+            const previous = window.history.state;
+            bus.publish('router', 'push', { path: '/second', query: '', hash: '', replace: false });
+            mockTransport.send.mockClear();
+
+            window.dispatchEvent(new PopStateEvent('popstate', { state: previous }));
+
+            expect(mockTransport.send).toHaveBeenCalledWith('router', 'popstate', expect.objectContaining({ delta: -1 }));
+        });
+    });
+
+    describe('base path', () => {
+        let mounted: Executor;
+
+        beforeEach(() => {
+            executor.destroy();
+            mounted = new Executor({
+                bus,
+                transport: mockTransport as unknown as Transport,
+                resolveRef,
+                basePath: '/admin',
+            });
+        });
+
+        it('should prefix pushed paths with the base path', () => {
+            const pushStateSpy = vi.spyOn(window.history, 'pushState');
+
+            bus.publish('router', 'push', { path: '/users', query: 'page=2', hash: '', replace: false });
+
+            expect(pushStateSpy).toHaveBeenCalledWith({ key: expect.any(String), idx: expect.any(Number) }, '', '/admin/users?page=2');
+            pushStateSpy.mockRestore();
+            mounted.destroy();
+        });
+
+        it('should strip the base path from popstate locations', () => {
+            window.history.replaceState(null, '', '/admin/users');
+            mockTransport.send.mockClear();
+
+            window.dispatchEvent(new PopStateEvent('popstate'));
+
+            expect(mockTransport.send).toHaveBeenCalledWith('router', 'popstate', {
+                path: '/users',
+                query: '',
+                hash: '',
+            });
+            mounted.destroy();
+        });
     });
 
     describe('destroy', () => {
@@ -4,31 +4,67 @@ import {
     DOMQueryPayload,
     DOMAsyncPayload,
     DOMResponsePayload,
+    RouterGoPayload,
     RouterNavPayload,
     RouterPopstatePayload,
 } from './protocol';
 import { Bus, Subscription } from './bus';
-import { Transport } from './transport';
+import { LiveTransport } from './transport';
 
 export type RefResolver = (refId: string) => Element | undefined;
 
 export interface ExecutorConfig {
     bus: Bus;
-    transport: Transport;
+    transport: LiveTransport;
     resolveRef: RefResolver;
+    basePath?: string;
 }
 
+interface ScrollPosition {
+    x: number;
+    y: number;
+}
+
+interface HistoryState {
+    key?: string;
+    idx?: number;
+}
+
+interface PendingNavigation {
+    restore?: ScrollPosition;
+    scroll: 'auto' | 'preserve';
+    focus: string;
+    hash: string;
+    pathChanged: boolean;
+}
+
+const DEFAULT_FOCUS_TARGETS = ['main', '[role="main"]', 'h1'];
+const NAVIGATION_SETTLE_MS = 100;
+
 export class Executor {
     private readonly bus: Bus;
-    private readonly transport: Transport;
+    private readonly transport: LiveTransport;
     private readonly resolveRef: RefResolver;
+    private readonly basePath: string;
     private readonly subscriptions: Subscription[] = [];
-    private popstateHandler: (() => void) | null = null;
+    private popstateHandler: ((event: PopStateEvent) => void) | null = null;
+    private readonly scrollPositions = new Map<string, ScrollPosition>();
+    private currentKey: string;
+    private currentIndex: number;
+    private pendingNavigation: PendingNavigation | null = null;
+    private settleTimer: ReturnType<typeof setTimeout> | null = null;
 
     constructor(config: ExecutorConfig) {
         this.bus = config.bus;
         this.transport = config.transport;
         this.resolveRef = config.resolveRef;
+        this.basePath = config.basePath ?? '';
+        this.currentIndex = (window.history.state as HistoryState | null)?.idx ?? 0;
+        this.currentKey = this.ensureHistoryKey();
+
+        if ('scrollRestoration' in window.history) {
+            window.history.scrollRestoration = 'manual';
+        }
 
         this.setupDOMSubscriptions();
         this.setupRouterSubscriptions();
@@ -45,6 +81,16 @@ export class Executor {
             window.removeEventListener('popstate', this.popstateHandler);
             this.popstateHandler = null;
         }
+
+        if (this.settleTimer) {
+            clearTimeout(this.settleTimer);
+            this.settleTimer = null;
+        }
+        this.pendingNavigation = null;
+    }
+
+    afterPatch(): void {
+        this.settleNavigation();
     }
 
     private setupDOMSubscriptions(): void {
@@ -75,15 +121,36 @@ export class Executor {
         this.subscriptions.push(
             this.bus.subscribe('router', 'forward', () => this.handleForward())
         );
+        this.subscriptions.push(
+            this.bus.subscribe('router', 'go', (payload) => this.handleGo(payload))
+        );
     }
 
     private setupPopstateListener(): void {
-        this.popstateHandler = () => {
+        this.popstateHandler = (event: PopStateEvent) => {
+            this.saveScroll();
+            const state = event.state as HistoryState | null;
+            // Entries carry their position in the session history, so the server
+            // can step back over a popstate it blocks instead of pushing a copy.
+            const delta = typeof state?.idx === 'number' ? state.idx - this.currentIndex : 0;
+            this.currentIndex = state?.idx ?? this.currentIndex;
+            this.currentKey = state?.key ?? this.ensureHistoryKey();
+            this.scheduleNavigation({
+                restore: this.scrollPositions.get(this.currentKey) ?? { x: 0, y: 0 },
+                scroll: 'auto',
+                focus: '',
+                hash: '',
+                pathChanged: true,
+            });
+
             const payload: RouterPopstatePayload = {
-                path: window.location.pathname,
+                path: this.appPath(window.location.pathname),
                 query: window.location.search.replace(/^\?/, ''),
                 hash: window.location.hash.replace(/^#/, ''),
             };
+            if (delta !== 0) {
+                payload.delta = delta;
+            }
             this.transport.send('router', 'popstate', payload);
         };
         window.addEventListener('popstate', this.popstateHandler);
@@ -155,12 +222,109 @@ export class Executor {
 
     private handlePush(payload: RouterNavPayload): void {
         const url = this.buildUrl(payload);
-        window.history.pushState({}, '', url);
+        const pathChanged = this.basePath + payload.path !== window.location.pathname;
+
+        this.saveScroll();
+        this.currentKey = this.createKey();
+        this.currentIndex += 1;
+        window.history.pushState({ key: this.currentKey, idx: this.currentIndex }, '', url);
+
+        this.scheduleNavigation({
+            scroll: payload.scroll ?? 'auto',
+            focus: payload.focus ?? '',
+            hash: payload.hash,
+            pathChanged,
+        });
     }
 
     private handleReplace(payload: RouterNavPayload): void {
         const url = this.buildUrl(payload);
-        window.history.replaceState({}, '', url);
+        window.history.replaceState({ key: this.currentKey, idx: this.currentIndex }, '', url);
+
+        if (payload.scroll === 'auto' || payload.focus) {
+            this.scheduleNavigation({
+                scroll: payload.scroll ?? 'preserve',
+                focus: payload.focus ?? '',
+                hash: payload.hash,
+                pathChanged: true,
+            });
+        }
+    }
+
+    private scheduleNavigation(pending: PendingNavigation): void {
+        this.pendingNavigation = pending;
+        if (this.settleTimer) {
+            clearTimeout(this.settleTimer);
+        }
+        this.settleTimer = setTimeout(() => this.settleNavigation(), NAVIGATION_SETTLE_MS);
+    }
+
+    private settleNavigation(): void {
+        const pending = this.pendingNavigation;
+        if (!pending) return;
+
+        this.pendingNavigation = null;
+        if (this.settleTimer) {
+            clearTimeout(this.settleTimer);
+            this.settleTimer = null;
+        }
+
+        if (pending.restore) {
+            window.scrollTo(pending.restore.x, pending.restore.y);
+        } else if (pending.scroll !== 'preserve') {
+            const target = pending.hash ? this.findHashTarget(pending.hash) : null;
+            if (target) {
+                target.scrollIntoView();
+            } else if (pending.pathChanged) {
+                window.scrollTo(0, 0);
+            }
+        }
+
+        if (pending.pathChanged && pending.focus !== 'none') {
+            this.moveFocus(pending.focus);
+        }
+    }
+
+    private findHashTarget(hash: string): Element | null {
+        const id = decodeURIComponent(hash);
+        return document.getElementById(id) ?? document.getElementsByName(id)[0] ?? null;
+    }
+
+    private moveFocus(selector: string): void {
+        const candidates = selector ? [selector] : DEFAULT_FOCUS_TARGETS;
+        for (const candidate of candidates) {
+            let el: HTMLElement | null = null;
+            try {
+                el = document.querySelector<HTMLElement>(candidate);
+            } catch {
+                el = null;
+            }
+            if (!el) continue;
+
+            if (!el.hasAttribute('tabindex') && el.tabIndex < 0) {
+                el.setAttribute('tabindex', '-1');
+            }
+            el.focus({ preventScroll: true });
+            return;
+        }
+    }
+
+    private saveScroll(): void {
+        this.scrollPositions.set(this.currentKey, { x: window.scrollX, y: window.scrollY });
+    }
+
+    private ensureHistoryKey(): string {
+        const state = window.history.state as HistoryState | null;
+        if (state?.key) {
+            return state.key;
+        }
+        const key = this.createKey();
+        window.history.replaceState({ ...(state ?? {}), key, idx: this.currentIndex }, '');
+        return key;
+    }
+
+    private createKey(): string {
+        return Math.random().toString(36).slice(2, 10);
     }
 
     private handleBack(): void {
@@ -171,8 +335,19 @@ export class Executor {
         window.history.forward();
     }
 
+    private handleGo(payload: RouterGoPayload): void {
+        window.history.go(payload.delta);
+    }
+
+    private appPath(pathname: string): string {
+        if (!this.basePath || (pathname !== this.basePath && !pathname.startsWith(this.basePath + '/'))) {
+            return pathname;
+        }
+        return pathname.slice(this.basePath.length) || '/';
+    }
+
     private buildUrl(payload: RouterNavPayload): string {
-        let url = payload.path;
+        let url = this.basePath + payload.path;
         if (payload.query) {
             url += '?' + payload.query;
         }
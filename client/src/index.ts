@@ -1,6 +1,7 @@
 export { Runtime, RuntimeConfig, boot } from './runtime';
 export { Logger, LogLevel, LoggerConfig } from './logger';
-export { Transport, TransportConfig, ConnectionState, JoinPayload } from './transport';
+export { Transport, BaseTransport, LiveTransport, TransportConfig, ConnectionState, JoinPayload, dispatchMessage } from './transport';
+export { SSETransport, FallbackTransport, TransportMode, createTransport } from './sse';
 export { Bus, Subscription } from './bus';
 export { Patcher, PatcherCallbacks } from './patcher';
 export { Executor, ExecutorConfig } from './executor';
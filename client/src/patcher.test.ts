@@ -33,6 +33,41 @@ describe('Patcher', () => {
         });
     });
 
+    describe('appendText', () => {
+        it('should append to existing text', () => {
+            root.innerHTML = '<p>Hello</p>';
+            const patches: Patch[] = [
+                { seq: 0, path: [0, 0], op: 'appendText', value: ', world' }
+            ];
+            patcher.apply(patches);
+            expect(root.querySelector('p')!.textContent).toBe('Hello, world');
+        });
+
+        it('should keep the same text node', () => {
+            root.innerHTML = '<p>Hello</p>';
+            const textNode = root.querySelector('p')!.firstChild;
+            patcher.apply([{ seq: 0, path: [0, 0], op: 'appendText', value: '!' }]);
+            expect(root.querySelector('p')!.firstChild).toBe(textNode);
+        });
+    });
+
+    describe('spliceText', () => {
+        it('should replace a range of text', () => {
+            root.innerHTML = '<p>the draft answer</p>';
+            const patches: Patch[] = [
+                { seq: 0, path: [0, 0], op: 'spliceText', value: { offset: 4, delete: 5, insert: 'final' } }
+            ];
+            patcher.apply(patches);
+            expect(root.querySelector('p')!.textContent).toBe('the final answer');
+        });
+
+        it('should use UTF-16 offsets', () => {
+            root.innerHTML = '<p>😀 old</p>';
+            patcher.apply([{ seq: 0, path: [0, 0], op: 'spliceText', value: { offset: 3, delete: 3, insert: 'new' } }]);
+            expect(root.querySelector('p')!.textContent).toBe('😀 new');
+        });
+    });
+
     describe('setComment', () => {
         it('should set comment content', () => {
             root.innerHTML = '<!--old-->';
@@ -409,6 +444,79 @@ describe('Patcher', () => {
         });
     });
 
+    describe('html payloads', () => {
+        it('should insert pre-rendered html and bind metadata', () => {
+            root.innerHTML = '<main></main>';
+            const patches: Patch[] = [
+                {
+                    seq: 0,
+                    path: [0],
+                    op: 'addChild',
+                    index: 0,
+                    value: {
+                        html: '<table><tbody><tr data-key="r0"><td>row 0</td><td><button>edit</button></td></tr></tbody></table>',
+                        meta: [
+                            { seq: 0, path: [0, 0, 1, 0], op: 'setHandlers', value: [{ event: 'click', handler: 'h0' }] },
+                            { seq: 1, path: [0], op: 'setRef', value: 'rows' },
+                        ],
+                    },
+                },
+            ];
+            patcher.apply(patches);
+
+            expect(root.querySelector('td')!.textContent).toBe('row 0');
+            root.querySelector('button')!.click();
+            expect(callbacks.onEvent).toHaveBeenCalledWith('h0', {});
+            expect(callbacks.onRef).toHaveBeenCalledWith('rows', root.querySelector('tbody'));
+        });
+
+        it('should replace a node with pre-rendered html', () => {
+            root.innerHTML = '<section><p>old</p></section>';
+            patcher.apply([{ seq: 0, path: [0, 0], op: 'replaceNode', value: { html: '<div><span>new</span></div>' } }]);
+            expect(root.innerHTML).toBe('<section><div><span>new</span></div></section>');
+        });
+
+        it('should create svg children in the svg namespace', () => {
+            root.innerHTML = '<svg></svg>';
+            patcher.apply([{ seq: 0, path: [0], op: 'addChild', index: 0, value: { html: '<g><circle r="2"></circle></g>' } }]);
+            expect(root.querySelector('circle')!.namespaceURI).toBe('http://www.w3.org/2000/svg');
+        });
+    });
+
+    describe('templates', () => {
+        const def = {
+            id: 1,
+            statics: ['<li class="', '"><span>', '</span></li>'],
+            slots: [{ path: [], attr: 'class' }, { path: [0, 0] }],
+        };
+
+        it('should instantiate a defined template with escaped slots', () => {
+            root.innerHTML = '<ul></ul>';
+            patcher.apply([
+                { seq: 0, path: null, op: 'defTemplate', value: def },
+                { seq: 1, path: [0], op: 'addChild', index: 0, value: { tpl: 1, slots: ['row', '<b>one</b>'] } },
+                { seq: 2, path: [0], op: 'addChild', index: 1, value: { tpl: 1, slots: ['row', 'two'] } },
+            ]);
+
+            expect(root.innerHTML).toBe('<ul><li class="row"><span>&lt;b&gt;one&lt;/b&gt;</span></li><li class="row"><span>two</span></li></ul>');
+        });
+
+        it('should update only the changed slots', () => {
+            root.innerHTML = '<ul></ul>';
+            patcher.apply([
+                { seq: 0, path: null, op: 'defTemplate', value: def },
+                { seq: 1, path: [0], op: 'addChild', index: 0, value: { tpl: 1, slots: ['row', 'one'] } },
+            ]);
+            const span = root.querySelector('span')!;
+
+            patcher.apply([{ seq: 2, path: [0, 0], op: 'setSlots', value: { tpl: 1, slots: { '0': 'row active', '1': 'uno' } } }]);
+
+            expect(root.querySelector('li')!.className).toBe('row active');
+            expect(root.querySelector('span')).toBe(span);
+            expect(span.textContent).toBe('uno');
+        });
+    });
+
     describe('addChild', () => {
         it('should add child at index', () => {
             root.innerHTML = '<ul><li>1</li><li>3</li></ul>';
@@ -1,4 +1,15 @@
-import { Patch, HandlerMeta, ScriptMeta } from './protocol';
+import {
+    Patch,
+    HandlerMeta,
+    HTMLPayload,
+    ScriptMeta,
+    SlotUpdate,
+    TemplateDef,
+    TemplatePayload,
+    TextSplice,
+    isHTMLPayload,
+    isTemplatePayload,
+} from './protocol';
 
 export interface StructuredNode {
     tag?: string;
@@ -39,6 +50,7 @@ export class Patcher {
     private handlerStore = new WeakMap<Element, Map<string, HandlerState>>();
     private scriptStore = new WeakMap<Element, string>();
     private keyedElements = new Map<string, Element>();
+    private templates = new Map<number, TemplateDef>();
 
     constructor(root: Node, callbacks: PatcherCallbacks) {
         this.root = root;
@@ -55,11 +67,20 @@ export class Patcher {
     private applyPatch(patch: Patch): void {
         const node = this.resolvePath(patch.path);
         if (!node) return;
+        this.applyTo(node, patch);
+    }
 
+    private applyTo(node: Node, patch: Patch): void {
         switch (patch.op) {
             case 'setText':
                 this.setText(node, patch.value as string);
                 break;
+            case 'appendText':
+                this.appendText(node, patch.value as string);
+                break;
+            case 'spliceText':
+                this.spliceText(node, patch.value as TextSplice);
+                break;
             case 'setComment':
                 this.setComment(node, patch.value as string);
                 break;
@@ -97,14 +118,22 @@ export class Patcher {
                 this.callbacks.onRefDelete(patch.value as string);
                 break;
             case 'replaceNode':
-                this.replaceNode(node, patch.value as StructuredNode);
+                this.replaceNode(node, patch.value as StructuredNode | HTMLPayload | TemplatePayload);
                 break;
             case 'addChild':
-                this.addChild(node, patch.index!, patch.value as StructuredNode, patch.path ?? []);
+                this.addChild(node, patch.index!, patch.value as StructuredNode | HTMLPayload | TemplatePayload, patch.path ?? []);
                 break;
             case 'delChild':
                 this.delChild(node, patch.index!);
                 break;
+            case 'defTemplate': {
+                const def = patch.value as TemplateDef;
+                this.templates.set(def.id, def);
+                break;
+            }
+            case 'setSlots':
+                this.setSlots(node, patch.value as SlotUpdate);
+                break;
             case 'moveChild':
                 this.moveChild(node, patch.value as { fromIndex: number; newIdx: number; key?: string }, patch.path ?? []);
                 break;
@@ -112,7 +141,11 @@ export class Patcher {
     }
 
     private resolvePath(path: number[] | null): Node | null {
-        let node: Node | null = this.root;
+        return this.resolveFrom(this.root, path);
+    }
+
+    private resolveFrom(root: Node, path: number[] | null): Node | null {
+        let node: Node | null = root;
         if (path) {
             for (const index of path) {
                 if (!node) return null;
@@ -126,6 +159,23 @@ export class Patcher {
         node.textContent = text;
     }
 
+    private appendText(node: Node, text: string): void {
+        if (node instanceof CharacterData) {
+            node.appendData(text);
+        } else {
+            node.textContent = (node.textContent ?? '') + text;
+        }
+    }
+
+    private spliceText(node: Node, splice: TextSplice): void {
+        if (node instanceof CharacterData) {
+            node.replaceData(splice.offset, splice.delete, splice.insert);
+            return;
+        }
+        const current = node.textContent ?? '';
+        node.textContent = current.slice(0, splice.offset) + splice.insert + current.slice(splice.offset + splice.delete);
+    }
+
     private setComment(node: Node, text: string): void {
         node.textContent = text;
     }
@@ -310,6 +360,10 @@ export class Patcher {
         const root = segments.shift()!;
         let current: unknown;
 
+        if (root === 'form' && segments.length === 0) {
+            return this.resolveFormData(e);
+        }
+
         switch (root) {
             case 'event':
                 current = e;
@@ -336,6 +390,24 @@ export class Patcher {
         return this.serializeValue(current);
     }
 
+    private resolveFormData(e: Event): Record<string, string> | undefined {
+        let form: HTMLFormElement | null = null;
+        if (e.currentTarget instanceof HTMLFormElement) {
+            form = e.currentTarget;
+        } else if (e.target instanceof Element) {
+            form = e.target.closest('form');
+        }
+        if (!form) return undefined;
+
+        const result: Record<string, string> = {};
+        new FormData(form).forEach((value, key) => {
+            if (typeof value === 'string') {
+                result[key] = value;
+            }
+        });
+        return result;
+    }
+
     private serializeValue(value: unknown): unknown {
         if (value === null || value === undefined) return null;
 
@@ -372,16 +444,16 @@ export class Patcher {
         }
     }
 
-    private replaceNode(oldNode: Node, newNodeData: StructuredNode): void {
-        const newNode = this.createNode(newNodeData);
+    private replaceNode(oldNode: Node, newNodeData: StructuredNode | HTMLPayload | TemplatePayload): void {
+        const newNode = this.materialize(newNodeData, oldNode.parentNode);
         if (newNode && oldNode.parentNode) {
             this.cleanupTree(oldNode);
             oldNode.parentNode.replaceChild(newNode, oldNode);
         }
     }
 
-    private addChild(parent: Node, index: number, nodeData: StructuredNode, parentPath: number[]): void {
-        const newNode = this.createNode(nodeData);
+    private addChild(parent: Node, index: number, nodeData: StructuredNode | HTMLPayload | TemplatePayload, parentPath: number[]): void {
+        const newNode = this.materialize(nodeData, parent);
         if (!newNode) return;
 
         if (nodeData.key && newNode instanceof Element) {
@@ -494,6 +566,73 @@ export class Patcher {
         'title', 'tspan', 'use', 'view'
     ]);
 
+    private materialize(data: StructuredNode | HTMLPayload | TemplatePayload, parent: Node | null): Node | null {
+        if (isTemplatePayload(data)) {
+            const html = this.renderTemplate(data);
+            return html === null ? null : this.createFromHTML({ html, key: data.key, meta: data.meta }, parent);
+        }
+        if (isHTMLPayload(data)) {
+            return this.createFromHTML(data, parent);
+        }
+        return this.createNode(data);
+    }
+
+    private renderTemplate(payload: TemplatePayload): string | null {
+        const def = this.templates.get(payload.tpl);
+        if (!def) return null;
+
+        let html = '';
+        def.statics.forEach((part, i) => {
+            html += part;
+            if (i < payload.slots.length) {
+                html += escapeHTML(payload.slots[i]);
+            }
+        });
+        return html;
+    }
+
+    private setSlots(node: Node, update: SlotUpdate): void {
+        const def = this.templates.get(update.tpl);
+        if (!def) return;
+
+        for (const [index, value] of Object.entries(update.slots)) {
+            const slot = def.slots[Number(index)];
+            if (!slot) continue;
+            const target = this.resolveFrom(node, slot.path);
+            if (!target) continue;
+
+            if (!slot.attr) {
+                target.textContent = value;
+            } else if (slot.attr === 'style' || slot.attr === 'data-key') {
+                (target as Element).setAttribute(slot.attr, value);
+            } else {
+                this.setAttr(target as Element, { [slot.attr]: [value] });
+            }
+        }
+    }
+
+    private createFromHTML(payload: HTMLPayload, parent: Node | null): Node | null {
+        const template = document.createElement('template');
+        const inSvg = parent instanceof SVGElement && parent.tagName !== 'foreignObject';
+        template.innerHTML = inSvg ? `<svg>${payload.html}</svg>` : payload.html;
+
+        let node: Node | null = template.content.firstChild;
+        if (inSvg) {
+            node = node?.firstChild ?? null;
+        }
+        if (!node) return null;
+
+        const meta = [...(payload.meta ?? [])].sort((a, b) => a.seq - b.seq);
+        for (const patch of meta) {
+            const target = this.resolveFrom(node, patch.path);
+            if (target) {
+                this.applyTo(target, patch);
+            }
+        }
+
+        return node;
+    }
+
     private createNode(data: StructuredNode, isSvg = false): Node | null {
         if (data.text !== undefined) {
             return document.createTextNode(data.text);
@@ -547,3 +686,12 @@ export class Patcher {
         return el;
     }
 }
+
+function escapeHTML(value: string): string {
+    return value
+        .replace(/&/g, '&amp;')
+        .replace(/</g, '&lt;')
+        .replace(/>/g, '&gt;')
+        .replace(/"/g, '&#34;')
+        .replace(/'/g, '&#39;');
+}
@@ -1,4 +1,4 @@
-export type StaticTopic = 'router' | 'dom' | 'frame' | 'ack';
+export type StaticTopic = 'router' | 'dom' | 'frame' | 'ack' | 'reload';
 export type ScriptTopic = `script:${string}`;
 export type HandlerTopic = `${string}:h${number}`;
 export type Topic = StaticTopic | ScriptTopic | HandlerTopic;
@@ -8,6 +8,7 @@ export const Topics = {
     DOM: 'dom' as const,
     Frame: 'frame' as const,
     Ack: 'ack' as const,
+    Reload: 'reload' as const,
 } as const;
 
 export function isScriptTopic(topic: string): topic is ScriptTopic {
@@ -39,6 +40,7 @@ export interface Event {
 }
 
 export interface ClientEvt extends Event {
+    id?: number;
     a: string;
     p?: unknown;
 }
@@ -55,10 +57,12 @@ export interface ClientAck extends Event {
 
 export interface ServerAck extends Event {
     seq: number;
+    eid?: number;
 }
 
 export interface ClientConfig {
     debug?: boolean;
+    transport?: 'websocket' | 'sse';
 }
 
 export interface Boot {
@@ -68,6 +72,7 @@ export interface Boot {
     seq: number;
     patch: Patch[];
     location: Location;
+    base?: string;
     client?: ClientConfig;
 }
 
@@ -124,6 +129,8 @@ export interface Stylesheet {
 
 export type OpKind =
     | 'setText'
+    | 'appendText'
+    | 'spliceText'
     | 'setComment'
     | 'setAttr'
     | 'delAttr'
@@ -139,10 +146,14 @@ export type OpKind =
     | 'replaceNode'
     | 'addChild'
     | 'delChild'
-    | 'moveChild';
+    | 'moveChild'
+    | 'defTemplate'
+    | 'setSlots';
 
 export const OpKinds = {
     SetText: 'setText' as OpKind,
+    AppendText: 'appendText' as OpKind,
+    SpliceText: 'spliceText' as OpKind,
     SetComment: 'setComment' as OpKind,
     SetAttr: 'setAttr' as OpKind,
     DelAttr: 'delAttr' as OpKind,
@@ -159,8 +170,45 @@ export const OpKinds = {
     AddChild: 'addChild' as OpKind,
     DelChild: 'delChild' as OpKind,
     MoveChild: 'moveChild' as OpKind,
+    DefTemplate: 'defTemplate' as OpKind,
+    SetSlots: 'setSlots' as OpKind,
 } as const;
 
+export interface TextSplice {
+    offset: number;
+    delete: number;
+    insert: string;
+}
+
+export interface HTMLPayload {
+    html: string;
+    key?: string;
+    meta?: Patch[];
+}
+
+export interface TemplateSlot {
+    path: number[];
+    attr?: string;
+}
+
+export interface TemplateDef {
+    id: number;
+    statics: string[];
+    slots: TemplateSlot[];
+}
+
+export interface TemplatePayload {
+    tpl: number;
+    slots: string[];
+    key?: string;
+    meta?: Patch[];
+}
+
+export interface SlotUpdate {
+    tpl: number;
+    slots: Record<string, string>;
+}
+
 export interface Patch {
     seq: number;
     path: number[] | null;
@@ -171,6 +219,19 @@ export interface Patch {
     index?: number;
 }
 
+export function isHTMLPayload(value: unknown): value is HTMLPayload {
+    return typeof value === 'object' && value !== null && typeof (value as HTMLPayload).html === 'string';
+}
+
+export function isTemplatePayload(value: unknown): value is TemplatePayload {
+    return (
+        typeof value === 'object' &&
+        value !== null &&
+        typeof (value as TemplatePayload).tpl === 'number' &&
+        Array.isArray((value as TemplatePayload).slots)
+    );
+}
+
 export function isBoot(msg: unknown): msg is Boot {
     return typeof msg === 'object' && msg !== null && (msg as Boot).t === 'boot';
 }
@@ -213,7 +274,7 @@ export const FrameActions = {
     Patch: 'patch' as FrameServerAction,
 } as const;
 
-export type RouterServerAction = 'push' | 'replace' | 'back' | 'forward';
+export type RouterServerAction = 'push' | 'replace' | 'back' | 'forward' | 'go';
 export type RouterClientAction = 'popstate';
 
 export const RouterActions = {
@@ -221,6 +282,7 @@ export const RouterActions = {
     Replace: 'replace' as RouterServerAction,
     Back: 'back' as RouterServerAction,
     Forward: 'forward' as RouterServerAction,
+    Go: 'go' as RouterServerAction,
     Popstate: 'popstate' as RouterClientAction,
 } as const;
 
@@ -229,6 +291,8 @@ export interface RouterNavPayload {
     query: string;
     hash: string;
     replace: boolean;
+    scroll?: 'auto' | 'preserve';
+    focus?: string;
 }
 
 export type DOMServerAction = 'call' | 'set' | 'query' | 'async';
@@ -305,12 +369,23 @@ export interface FramePatchPayload {
 
 export interface AckPayload {
     seq: number;
+    eid?: number;
+}
+
+export interface ReloadPayload {
+    ver: number;
+    grace: number;
 }
 
 export interface RouterPopstatePayload {
     path: string;
     query: string;
     hash: string;
+    delta?: number;
+}
+
+export interface RouterGoPayload {
+    delta: number;
 }
 
 export interface StaticTopicActionMap {
@@ -322,6 +397,7 @@ export interface StaticTopicActionMap {
         replace: RouterNavPayload;
         back: undefined;
         forward: undefined;
+        go: RouterGoPayload;
         popstate: RouterPopstatePayload;
     };
     dom: {
@@ -334,6 +410,9 @@ export interface StaticTopicActionMap {
     ack: {
         ack: AckPayload;
     };
+    reload: {
+        reload: ReloadPayload;
+    };
 }
 
 export interface ScriptTopicActions {
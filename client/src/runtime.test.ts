@@ -3,6 +3,7 @@ import { Runtime, RuntimeConfig } from './runtime';
 import { Boot } from './protocol';
 import { Transport } from './transport';
 import { Logger } from './logger';
+import { Bus } from './bus';
 
 vi.mock('./transport');
 
@@ -284,6 +285,53 @@ describe('Runtime', () => {
         });
     });
 
+    describe('version reload', () => {
+        const publishReload = (payload: { ver: number; grace: number }) => {
+            (runtime as unknown as { bus: Bus }).bus.publish('reload', 'reload', payload);
+        };
+
+        beforeEach(() => {
+            sessionStorage.clear();
+        });
+
+        it('should schedule a reload after the grace period', () => {
+            const timeout = vi.spyOn(window, 'setTimeout');
+
+            publishReload({ ver: 2, grace: 5000 });
+
+            expect(sessionStorage.getItem('pond_version_reload')).toBe('2');
+            const delay = timeout.mock.calls[timeout.mock.calls.length - 1][1] as number;
+            expect(delay).toBeGreaterThanOrEqual(5000);
+        });
+
+        it('should stop reloading when the same version is requested again', () => {
+            sessionStorage.setItem('pond_version_reload', '2');
+            const timeout = vi.spyOn(window, 'setTimeout');
+
+            publishReload({ ver: 2, grace: 0 });
+
+            expect(timeout).not.toHaveBeenCalled();
+        });
+
+        it('should forget the reload once joined on the requested version', () => {
+            const connect = (runtime as unknown as { handleStateChange: (s: string) => void });
+            sessionStorage.setItem('pond_version_reload', '1');
+
+            connect.handleStateChange('connected');
+
+            expect(sessionStorage.getItem('pond_version_reload')).toBeNull();
+        });
+
+        it('should keep the reload marker when joined on a stale version', () => {
+            const connect = (runtime as unknown as { handleStateChange: (s: string) => void });
+            sessionStorage.setItem('pond_version_reload', '2');
+
+            connect.handleStateChange('connected');
+
+            expect(sessionStorage.getItem('pond_version_reload')).toBe('2');
+        });
+    });
+
     describe('script handling', () => {
         it('should execute scripts from patches', async () => {
             const child = document.createElement('div');
@@ -3,12 +3,14 @@ import {
     Location,
     Patch,
     FramePatchPayload,
+    ReloadPayload,
     ScriptMeta,
     isBoot,
     isServerError,
 } from './protocol';
 import { Bus } from './bus';
-import { Transport, ConnectionState } from './transport';
+import { LiveTransport, ConnectionState } from './transport';
+import { TransportMode, createTransport } from './sse';
 import { Patcher } from './patcher';
 import { Executor } from './executor';
 import { ScriptExecutor } from './scripts';
@@ -21,7 +23,9 @@ export interface RuntimeConfig {
     seq: number;
     endpoint: string;
     location: Location;
+    basePath?: string;
     debug?: boolean;
+    transport?: TransportMode;
 }
 
 const RELOAD_JITTER_MIN = 1000;
@@ -29,6 +33,7 @@ const RELOAD_JITTER_MAX = 10000;
 const MAX_RELOADS = 10;
 const RELOAD_TRACKING_KEY = 'pond_reload_count';
 const RELOAD_TIMESTAMP_KEY = 'pond_reload_timestamp';
+const VERSION_RELOAD_KEY = 'pond_version_reload';
 const RELOAD_WINDOW_MS = 60000;
 
 interface ResumeOK {
@@ -54,11 +59,12 @@ declare global {
 
 export class Runtime {
     private readonly bus: Bus;
-    private readonly transport: Transport;
+    private readonly transport: LiveTransport;
     private readonly patcher: Patcher;
     private readonly executor: Executor;
     private readonly scripts: ScriptExecutor;
     private readonly refs = new Map<string, Element>();
+    private readonly version: number;
 
     private cseq = 0;
     private lastSeq = 0;
@@ -66,20 +72,21 @@ export class Runtime {
 
     constructor(config: RuntimeConfig) {
         this.lastSeq = config.seq;
+        this.version = config.version;
 
         Logger.configure({ enabled: config.debug ?? false, level: 'debug' });
         Logger.info('Runtime', 'Initializing', { sid: config.sessionId, ver: config.version });
 
         this.bus = new Bus();
 
-        this.transport = new Transport({
+        this.transport = createTransport({
             endpoint: config.endpoint,
             sessionId: config.sessionId,
             version: config.version,
             lastAck: config.seq,
             location: config.location,
             bus: this.bus,
-        });
+        }, config.transport);
 
         const resolveRef = (refId: string) => this.refs.get(refId);
 
@@ -101,11 +108,13 @@ export class Runtime {
             bus: this.bus,
             transport: this.transport,
             resolveRef,
+            basePath: config.basePath,
         });
 
         this.scripts = new ScriptExecutor({ bus: this.bus, transport: this.transport });
 
         this.bus.subscribe('frame', 'patch', (payload) => this.handlePatch(payload));
+        this.bus.subscribe('reload', 'reload', (payload) => this.handleReload(payload));
 
         this.transport.onStateChange((state) => this.handleStateChange(state));
 
@@ -164,11 +173,29 @@ export class Runtime {
         if (payload.patches && payload.patches.length > 0) {
             this.applyPatches(payload.patches);
         }
+        this.executor.afterPatch();
 
         this.lastSeq = payload.seq;
         this.transport.sendAck(payload.seq);
     }
 
+    private handleReload(payload: ReloadPayload): void {
+        Logger.warn('Runtime', 'Server version changed', { from: this.version, to: payload.ver });
+
+        // A second request for the same version means the reload served stale markup again.
+        try {
+            if (sessionStorage.getItem(VERSION_RELOAD_KEY) === String(payload.ver)) {
+                Logger.error('Runtime', 'Still on a stale version after reloading - entering failsafe mode');
+                this.enterFailsafeMode();
+                return;
+            }
+            sessionStorage.setItem(VERSION_RELOAD_KEY, String(payload.ver));
+        } catch {
+        }
+
+        this.reloadWithJitter(payload.grace);
+    }
+
     private handleResumeOK(resume: ResumeOK): void {
         Logger.info('Runtime', 'Resume OK', { from: resume.from, to: resume.to });
     }
@@ -206,6 +233,7 @@ export class Runtime {
         if (!wasConnected && this.connectedState) {
             Logger.info('Runtime', 'Connected');
             this.clearReloadTracking();
+            this.clearVersionReload();
         } else if (wasConnected && !this.connectedState) {
             Logger.warn('Runtime', 'Disconnected');
         }
@@ -216,7 +244,7 @@ export class Runtime {
         }
     }
 
-    private reloadWithJitter(): void {
+    private reloadWithJitter(delay = 0): void {
         if (this.shouldEnterFailsafeMode()) {
             Logger.error('Runtime', 'Entering failsafe mode - too many consecutive reloads');
             this.enterFailsafeMode();
@@ -225,7 +253,7 @@ export class Runtime {
 
         this.incrementReloadCount();
 
-        const jitter = Math.floor(Math.random() * (RELOAD_JITTER_MAX - RELOAD_JITTER_MIN)) + RELOAD_JITTER_MIN;
+        const jitter = delay + Math.floor(Math.random() * (RELOAD_JITTER_MAX - RELOAD_JITTER_MIN)) + RELOAD_JITTER_MIN;
         Logger.info('Runtime', `Reloading in ${jitter}ms`);
 
         setTimeout(() => {
@@ -274,6 +302,17 @@ export class Runtime {
         }
     }
 
+    // Joining on the version a reload asked for means the reload worked, so a
+    // later upgrade to that same version must be allowed to reload again.
+    private clearVersionReload(): void {
+        try {
+            if (sessionStorage.getItem(VERSION_RELOAD_KEY) === String(this.version)) {
+                sessionStorage.removeItem(VERSION_RELOAD_KEY);
+            }
+        } catch {
+        }
+    }
+
     private enterFailsafeMode(): void {
         try {
             sessionStorage.removeItem(RELOAD_TRACKING_KEY);
@@ -315,9 +354,11 @@ export function boot(): Runtime | null {
         sessionId: bootData.sid,
         version: bootData.ver,
         seq: bootData.seq,
-        endpoint: '/live',
+        endpoint: `${bootData.base ?? ''}/live`,
         location: bootData.location,
+        basePath: bootData.base,
         debug: bootData.client?.debug,
+        transport: bootData.client?.transport,
     };
 
     const runtime = new Runtime(config);
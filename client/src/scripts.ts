@@ -1,7 +1,7 @@
 import { ScriptMeta, ScriptPayload } from './protocol';
 import { Bus, Subscription } from './bus';
 import { Logger } from './logger';
-import { Transport } from './transport';
+import { LiveTransport } from './transport';
 
 export interface ScriptTransport {
     send(event: string, data: unknown): void;
@@ -16,13 +16,13 @@ export interface ScriptInstance {
 
 export interface ScriptExecutorConfig {
     bus: Bus;
-    transport: Transport;
+    transport: LiveTransport;
 }
 
 
 export class ScriptExecutor {
     private readonly bus: Bus;
-    private readonly transport: Transport;
+    private readonly transport: LiveTransport;
     private readonly scripts = new Map<string, ScriptInstance>();
 
     constructor(config: ScriptExecutorConfig) {
@@ -0,0 +1,334 @@
+import { describe, it, expect, vi, beforeEach, afterEach, Mock } from 'vitest';
+import { SSETransport, FallbackTransport, createTransport } from './sse';
+import { Transport, TransportConfig } from './transport';
+import { Bus } from './bus';
+import { PondClient, ChannelState } from '@eleven-am/pondsocket-client';
+
+vi.mock('@eleven-am/pondsocket-client', () => ({
+    PondClient: vi.fn(),
+    ChannelState: {
+        JOINED: 'JOINED',
+        STALLED: 'STALLED',
+        CLOSED: 'CLOSED',
+        JOINING: 'JOINING',
+        IDLE: 'IDLE',
+    },
+}));
+
+class FakeEventSource {
+    static readonly CONNECTING = 0;
+    static readonly OPEN = 1;
+    static readonly CLOSED = 2;
+    static instances: FakeEventSource[] = [];
+
+    readyState = FakeEventSource.CONNECTING;
+    onmessage: ((e: MessageEvent) => void) | null = null;
+    onerror: (() => void) | null = null;
+    listeners: Record<string, (e: MessageEvent) => void> = {};
+
+    constructor(public readonly url: string) {
+        FakeEventSource.instances.push(this);
+    }
+
+    addEventListener(type: string, listener: (e: MessageEvent) => void): void {
+        this.listeners[type] = listener;
+    }
+
+    close(): void {
+        this.readyState = FakeEventSource.CLOSED;
+    }
+
+    emit(type: string, data: unknown): void {
+        const event = { data: JSON.stringify(data) } as MessageEvent;
+        if (type === 'message') {
+            this.onmessage?.(event);
+        } else {
+            this.listeners[type]?.(event);
+        }
+    }
+
+    static last(): FakeEventSource {
+        return FakeEventSource.instances[FakeEventSource.instances.length - 1];
+    }
+}
+
+const flushPromises = () => new Promise((resolve) => setTimeout(resolve, 0));
+
+describe('SSETransport', () => {
+    let bus: Bus;
+    let config: TransportConfig;
+    let fetchMock: Mock;
+
+    beforeEach(() => {
+        FakeEventSource.instances = [];
+        vi.stubGlobal('EventSource', FakeEventSource);
+        fetchMock = vi.fn().mockResolvedValue({ ok: true, status: 204 });
+        vi.stubGlobal('fetch', fetchMock);
+
+        bus = new Bus();
+        config = {
+            endpoint: '/app/live',
+            sessionId: 'sess-1',
+            version: 7,
+            lastAck: 0,
+            location: { path: '/', query: {}, hash: '' },
+            bus,
+        };
+    });
+
+    afterEach(() => {
+        vi.unstubAllGlobals();
+    });
+
+    it('opens a stream for the session', () => {
+        const transport = new SSETransport(config);
+        transport.connect();
+
+        expect(FakeEventSource.last().url).toBe('/app/live/sse?sid=sess-1&ver=7&enc=compact');
+        expect(transport.connectionState).toBe('connecting');
+    });
+
+    it('becomes connected once the stream announces its connection', () => {
+        const transport = new SSETransport(config);
+        const listener = vi.fn();
+        transport.onStateChange(listener);
+        transport.connect();
+
+        FakeEventSource.last().emit('conn', 'sse-abc');
+
+        expect(transport.connectionState).toBe('connected');
+        expect(listener).toHaveBeenLastCalledWith('connected');
+    });
+
+    it('queues events until connected and posts them in order', async () => {
+        const transport = new SSETransport(config);
+        transport.connect();
+
+        transport.sendHandler('c0:h0', { cseq: 1, value: 'a' });
+        transport.sendAck(3);
+        expect(fetchMock).not.toHaveBeenCalled();
+
+        FakeEventSource.last().emit('conn', 'sse-abc');
+        await flushPromises();
+
+        expect(fetchMock).toHaveBeenCalledTimes(2);
+        const posts = Object.fromEntries(fetchMock.mock.calls.map(([url, init]) => [url, init]));
+        const evt = posts['/app/live/sse/sse-abc/evt'];
+        expect(evt.method).toBe('POST');
+        expect(JSON.parse(evt.body)).toEqual({ t: 'c0:h0', sid: 'sess-1', id: 1, a: 'invoke', p: { cseq: 1, value: 'a' } });
+        expect(JSON.parse(posts['/app/live/sse/sse-abc/ack'].body)).toEqual({ t: 'ack', sid: 'sess-1', seq: 3 });
+    });
+
+    it('replays unacknowledged events when the stream reconnects', async () => {
+        const transport = new SSETransport(config);
+        transport.connect();
+        const source = FakeEventSource.last();
+        source.emit('conn', 'sse-abc');
+
+        transport.sendHandler('c0:h0', { cseq: 1 });
+        transport.sendHandler('c0:h0', { cseq: 2 });
+        source.emit('message', { seq: 2, topic: 'ack', event: 'ack', data: { t: 'ack', sid: 'sess-1', seq: 2, eid: 1 } });
+        await flushPromises();
+        fetchMock.mockClear();
+
+        source.onerror?.();
+        source.emit('conn', 'sse-abc');
+        await flushPromises();
+
+        expect(fetchMock).toHaveBeenCalledTimes(1);
+        expect(JSON.parse(fetchMock.mock.calls[0][1].body).id).toBe(2);
+    });
+
+    it('publishes stream messages to the bus', () => {
+        const transport = new SSETransport(config);
+        const handler = vi.fn();
+        bus.subscribe('router', 'push', handler);
+        transport.connect();
+
+        FakeEventSource.last().emit('message', [4, 'router', 'push', { path: '/next', query: {}, hash: '' }]);
+
+        expect(handler).toHaveBeenCalledWith({ path: '/next', query: {}, hash: '' });
+    });
+
+    it('stops the stream after a reload directive', () => {
+        const transport = new SSETransport(config);
+        const handler = vi.fn();
+        bus.subscribe('reload', 'reload', handler);
+        transport.connect();
+
+        const source = FakeEventSource.last();
+        source.emit('message', { seq: 1, topic: 'reload', event: 'reload', data: { ver: 8, grace: 0 } });
+
+        expect(handler).toHaveBeenCalledWith({ ver: 8, grace: 0 });
+        expect(source.readyState).toBe(FakeEventSource.CLOSED);
+    });
+
+    it('reports a refused stream as declined and a dropped one as stalled', () => {
+        const transport = new SSETransport(config);
+        transport.connect();
+        const source = FakeEventSource.last();
+
+        source.readyState = FakeEventSource.CONNECTING;
+        source.onerror?.();
+        expect(transport.connectionState).toBe('stalled');
+
+        source.readyState = FakeEventSource.CLOSED;
+        source.onerror?.();
+        expect(transport.connectionState).toBe('declined');
+    });
+});
+
+describe('FallbackTransport', () => {
+    let stateHandler: (state: ChannelState) => void;
+    let mockChannel: Record<string, Mock>;
+    let mockClient: Record<string, Mock>;
+    let config: TransportConfig;
+
+    beforeEach(() => {
+        vi.useFakeTimers();
+        FakeEventSource.instances = [];
+        vi.stubGlobal('EventSource', FakeEventSource);
+
+        mockChannel = {
+            join: vi.fn(),
+            leave: vi.fn(),
+            sendMessage: vi.fn(),
+            onMessage: vi.fn(),
+            onChannelStateChange: vi.fn((handler) => {
+                stateHandler = handler;
+            }),
+        };
+        mockClient = {
+            connect: vi.fn(),
+            disconnect: vi.fn(),
+            createChannel: vi.fn().mockReturnValue(mockChannel),
+        };
+        (PondClient as unknown as Mock).mockImplementation(() => mockClient);
+
+        config = {
+            endpoint: '/live',
+            sessionId: 'sess-1',
+            version: 1,
+            lastAck: 0,
+            location: { path: '/', query: {}, hash: '' },
+            bus: new Bus(),
+        };
+    });
+
+    afterEach(() => {
+        vi.useRealTimers();
+        vi.unstubAllGlobals();
+    });
+
+    it('falls back to SSE when the socket does not open in time', () => {
+        const transport = new FallbackTransport(config, 1000);
+        transport.connect();
+        expect(FakeEventSource.instances).toHaveLength(0);
+
+        vi.advanceTimersByTime(1000);
+
+        expect(transport.usingFallback).toBe(true);
+        expect(mockClient.disconnect).toHaveBeenCalled();
+        expect(FakeEventSource.last().url).toBe('/live/sse?sid=sess-1&ver=1&enc=compact');
+    });
+
+    it('falls back when the socket closes before joining', () => {
+        const transport = new FallbackTransport(config, 1000);
+        const listener = vi.fn();
+        transport.onStateChange(listener);
+        transport.connect();
+
+        stateHandler(ChannelState.CLOSED);
+
+        expect(transport.usingFallback).toBe(true);
+        expect(listener).not.toHaveBeenCalledWith('disconnected');
+
+        FakeEventSource.last().emit('conn', 'sse-abc');
+        expect(listener).toHaveBeenLastCalledWith('connected');
+        expect(transport.connectionState).toBe('connected');
+    });
+
+    it('keeps the socket once it has joined', () => {
+        const transport = new FallbackTransport(config, 1000);
+        const listener = vi.fn();
+        transport.onStateChange(listener);
+        transport.connect();
+
+        stateHandler(ChannelState.JOINED);
+        vi.advanceTimersByTime(5000);
+        stateHandler(ChannelState.CLOSED);
+
+        expect(transport.usingFallback).toBe(false);
+        expect(FakeEventSource.instances).toHaveLength(0);
+        expect(listener).toHaveBeenLastCalledWith('disconnected');
+    });
+
+    it('replays events sent over the socket once the stream connects', async () => {
+        const fetchMock = vi.fn().mockResolvedValue({ ok: true, status: 204 });
+        vi.stubGlobal('fetch', fetchMock);
+
+        const transport = new FallbackTransport(config, 1000);
+        transport.connect();
+        transport.sendHandler('c0:h0', { cseq: 1 });
+        expect(mockChannel.sendMessage).toHaveBeenCalledWith('evt', expect.objectContaining({ id: 1 }));
+
+        stateHandler(ChannelState.CLOSED);
+        FakeEventSource.last().emit('conn', 'sse-abc');
+        await vi.runAllTimersAsync();
+
+        expect(fetchMock).toHaveBeenCalledTimes(1);
+        expect(fetchMock.mock.calls[0][0]).toBe('/live/sse/sse-abc/evt');
+        expect(JSON.parse(fetchMock.mock.calls[0][1].body)).toMatchObject({ id: 1, p: { cseq: 1 } });
+    });
+
+    it('routes sends through the active transport', () => {
+        const transport = new FallbackTransport(config, 1000);
+        transport.connect();
+        stateHandler(ChannelState.JOINED);
+
+        transport.sendAck(2);
+
+        expect(mockChannel.sendMessage).toHaveBeenCalledWith('ack', { t: 'ack', sid: 'sess-1', seq: 2 });
+    });
+});
+
+describe('createTransport', () => {
+    const config = (): TransportConfig => ({
+        endpoint: '/live',
+        sessionId: 'sess-1',
+        version: 1,
+        lastAck: 0,
+        location: { path: '/', query: {}, hash: '' },
+        bus: new Bus(),
+    });
+
+    beforeEach(() => {
+        (PondClient as unknown as Mock).mockImplementation(() => ({
+            connect: vi.fn(),
+            disconnect: vi.fn(),
+            createChannel: vi.fn().mockReturnValue({
+                onMessage: vi.fn(),
+                onChannelStateChange: vi.fn(),
+            }),
+        }));
+        vi.stubGlobal('EventSource', FakeEventSource);
+    });
+
+    afterEach(() => {
+        vi.unstubAllGlobals();
+    });
+
+    it('honours a forced mode', () => {
+        expect(createTransport(config(), 'sse')).toBeInstanceOf(SSETransport);
+        expect(createTransport(config(), 'websocket')).toBeInstanceOf(Transport);
+    });
+
+    it('uses the fallback transport by default', () => {
+        expect(createTransport(config())).toBeInstanceOf(FallbackTransport);
+    });
+
+    it('uses plain WebSockets when EventSource is unavailable', () => {
+        vi.stubGlobal('EventSource', undefined);
+        expect(createTransport(config())).toBeInstanceOf(Transport);
+    });
+});
@@ -0,0 +1,247 @@
+import { ActionFor, Event, HandlerEventPayload, PayloadFor, ScriptPayload, Topic } from './protocol';
+import { Logger } from './logger';
+import { BaseTransport, ConnectionState, EventLog, LiveTransport, Transport, TransportConfig, dispatchMessage } from './transport';
+
+export type TransportMode = 'auto' | 'websocket' | 'sse';
+
+export const FALLBACK_TIMEOUT = 5000;
+
+interface Outgoing {
+    type: 'evt' | 'ack';
+    message: Event;
+}
+
+export class SSETransport extends BaseTransport {
+    private readonly streamUrl: string;
+    private readonly postUrl: string;
+    private source: EventSource | null = null;
+    private connId: string | null = null;
+    private queue: Outgoing[] = [];
+    private outbox: Promise<void> = Promise.resolve();
+
+    constructor(config: TransportConfig) {
+        super(config);
+
+        const params = new URLSearchParams({
+            sid: config.sessionId,
+            ver: String(config.version),
+            enc: config.encoding ?? 'compact',
+        });
+        this.streamUrl = `${config.endpoint}/sse?${params.toString()}`;
+        this.postUrl = `${config.endpoint}/sse`;
+    }
+
+    connect(): void {
+        this.setState('connecting');
+
+        const source = new EventSource(this.streamUrl);
+        this.source = source;
+
+        source.addEventListener('conn', (e) => {
+            this.connId = JSON.parse((e as MessageEvent).data);
+            this.flushQueue();
+            this.setState('connected');
+        });
+
+        source.onmessage = (e) => {
+            Logger.info('TRANSPORT', 'SSE received message:', e.data);
+            let payload: unknown;
+            try {
+                payload = JSON.parse(e.data);
+            } catch {
+                return;
+            }
+            // The stream would be reopened and told to reload again, so stop here.
+            if (dispatchMessage(this.bus, payload) === 'reload') {
+                this.close();
+            }
+        };
+
+        source.onerror = () => {
+            if (this.source !== source) {
+                return;
+            }
+            if (source.readyState === EventSource.CLOSED) {
+                this.source = null;
+                this.setState('declined');
+            } else {
+                this.setState('stalled');
+            }
+        };
+    }
+
+    disconnect(): void {
+        this.close();
+        this.setState('disconnected');
+    }
+
+    private close(): void {
+        this.source?.close();
+        this.source = null;
+        this.connId = null;
+    }
+
+    protected sendMessage<T extends Event>(type: 'evt' | 'ack', message: T): void {
+        Logger.info('TRANSPORT', 'SSE sending message:', type, message);
+        // Events are replayed from the event log once the stream connects.
+        if (type === 'evt' && !this.connId) {
+            return;
+        }
+        this.queue.push({ type, message });
+        this.flushQueue();
+    }
+
+    // Posts are chained so the server sees events in the order they were sent.
+    private flushQueue(): void {
+        const connId = this.connId;
+        if (!connId) {
+            return;
+        }
+
+        const pending = this.queue;
+        this.queue = [];
+        for (const { type, message } of pending) {
+            this.outbox = this.outbox.then(() => this.post(connId, type, message));
+        }
+    }
+
+    private async post(connId: string, type: 'evt' | 'ack', message: Event): Promise<void> {
+        try {
+            const res = await fetch(`${this.postUrl}/${encodeURIComponent(connId)}/${type}`, {
+                method: 'POST',
+                headers: { 'Content-Type': 'application/json' },
+                body: JSON.stringify(message),
+                keepalive: true,
+            });
+            if (!res.ok) {
+                Logger.warn('TRANSPORT', 'SSE post rejected', type, res.status);
+            }
+        } catch (err) {
+            Logger.error('TRANSPORT', 'SSE post failed', type, err);
+        }
+    }
+}
+
+/**
+ * Starts on a WebSocket and switches to Server-Sent Events for the rest of the
+ * page's life if the socket never opens. Both share one event log, so events
+ * sent before the switch are replayed over the stream.
+ */
+export class FallbackTransport implements LiveTransport {
+    private current: LiveTransport;
+    private unsubscribe: () => void;
+    private listeners: Array<(state: ConnectionState) => void> = [];
+    private timer: ReturnType<typeof setTimeout> | null = null;
+    private opened = false;
+    private fellBack = false;
+
+    private readonly config: TransportConfig;
+
+    constructor(config: TransportConfig, private readonly timeout = FALLBACK_TIMEOUT) {
+        this.config = { ...config, events: config.events ?? new EventLog() };
+        this.current = new Transport(this.config);
+        this.unsubscribe = this.current.onStateChange((state) => this.handleState(state));
+    }
+
+    get sid(): string {
+        return this.current.sid;
+    }
+
+    get connectionState(): ConnectionState {
+        return this.current.connectionState;
+    }
+
+    get usingFallback(): boolean {
+        return this.fellBack;
+    }
+
+    connect(): void {
+        this.current.connect();
+        if (!this.fellBack && !this.opened) {
+            this.timer = setTimeout(() => this.fallback(), this.timeout);
+        }
+    }
+
+    disconnect(): void {
+        this.clearTimer();
+        this.current.disconnect();
+    }
+
+    onStateChange(listener: (state: ConnectionState) => void): () => void {
+        this.listeners.push(listener);
+        return () => {
+            const idx = this.listeners.indexOf(listener);
+            if (idx !== -1) {
+                this.listeners.splice(idx, 1);
+            }
+        };
+    }
+
+    send<T extends Topic, A extends ActionFor<T>>(topic: T, action: A, payload: PayloadFor<T, A>): void {
+        this.current.send(topic, action, payload);
+    }
+
+    sendAck(seq: number): void {
+        this.current.sendAck(seq);
+    }
+
+    sendHandler(handlerId: string, payload: HandlerEventPayload): void {
+        this.current.sendHandler(handlerId, payload);
+    }
+
+    sendScript(scriptId: string, payload: ScriptPayload): void {
+        this.current.sendScript(scriptId, payload);
+    }
+
+    private handleState(state: ConnectionState): void {
+        if (state === 'connected') {
+            this.opened = true;
+            this.clearTimer();
+        } else if (!this.opened && !this.fellBack && (state === 'disconnected' || state === 'stalled')) {
+            this.fallback();
+            return;
+        }
+
+        for (const listener of this.listeners) {
+            try {
+                listener(state);
+            } catch {
+                // swallow
+            }
+        }
+    }
+
+    private fallback(): void {
+        if (this.fellBack || this.opened) {
+            return;
+        }
+
+        Logger.warn('TRANSPORT', 'WebSocket did not open, falling back to Server-Sent Events');
+        this.clearTimer();
+        this.fellBack = true;
+
+        this.unsubscribe();
+        this.current.disconnect();
+
+        this.current = new SSETransport(this.config);
+        this.unsubscribe = this.current.onStateChange((state) => this.handleState(state));
+        this.current.connect();
+    }
+
+    private clearTimer(): void {
+        if (this.timer !== null) {
+            clearTimeout(this.timer);
+            this.timer = null;
+        }
+    }
+}
+
+export function createTransport(config: TransportConfig, mode: TransportMode = 'auto'): LiveTransport {
+    if (mode === 'sse') {
+        return new SSETransport(config);
+    }
+    if (mode === 'websocket' || typeof EventSource === 'undefined') {
+        return new Transport(config);
+    }
+    return new FallbackTransport(config);
+}
@@ -1,5 +1,5 @@
 import { describe, it, expect, vi, beforeEach, Mock } from 'vitest';
-import { Transport, TransportConfig } from './transport';
+import { Transport, TransportConfig, EventLog } from './transport';
 import { Bus } from './bus';
 import { PondClient, ChannelState } from '@eleven-am/pondsocket-client';
 
@@ -56,16 +56,16 @@ describe('Transport', () => {
         (PondClient as unknown as Mock).mockImplementation(() => mockClient);
 
         bus = new Bus();
-        const config: TransportConfig = {
-            endpoint: '/live',
-            sessionId: 'test-session',
-            version: 1,
-            lastAck: 0,
-            location: { path: '/', query: {}, hash: '' },
-            bus,
-        };
+        transport = new Transport(baseConfig());
+    });
 
-        transport = new Transport(config);
+    const baseConfig = (): TransportConfig => ({
+        endpoint: '/live',
+        sessionId: 'test-session',
+        version: 1,
+        lastAck: 0,
+        location: { path: '/', query: {}, hash: '' },
+        bus,
     });
 
     describe('constructor', () => {
@@ -79,6 +79,7 @@ describe('Transport', () => {
                 ver: 1,
                 ack: 0,
                 loc: { path: '/', query: {}, hash: '' },
+                enc: 'compact',
             });
         });
 
@@ -189,6 +190,7 @@ describe('Transport', () => {
             expect(mockChannel.sendMessage).toHaveBeenCalledWith('evt', {
                 t: 'dom',
                 sid: 'test-session',
+                id: 1,
                 a: 'response',
                 p: { requestId: 'req-1', result: 'ok' },
             });
@@ -214,12 +216,71 @@ describe('Transport', () => {
             expect(mockChannel.sendMessage).toHaveBeenCalledWith('evt', {
                 t: 'c0:h0',
                 sid: 'test-session',
+                id: 1,
                 a: 'invoke',
                 p: { cseq: 1, value: 'test' },
             });
         });
     });
 
+    describe('event replay', () => {
+        const sentEvents = () => mockChannel.sendMessage.mock.calls
+            .filter(([type]) => type === 'evt')
+            .map(([, evt]) => evt.id);
+
+        it('should number events per session', () => {
+            transport.sendHandler('c0:h0', { cseq: 1 });
+            transport.send('dom', 'response', { requestId: 'req-1', result: 'ok' });
+            transport.sendScript('s0', { scriptId: 's0', event: 'ping', data: null });
+
+            expect(sentEvents()).toEqual([1, 2, 3]);
+        });
+
+        it('should resend unacknowledged events after rejoining', () => {
+            stateHandler(ChannelState.JOINED);
+            transport.sendHandler('c0:h0', { cseq: 1 });
+            transport.sendHandler('c0:h0', { cseq: 2 });
+            stateHandler(ChannelState.STALLED);
+            mockChannel.sendMessage.mockClear();
+
+            stateHandler(ChannelState.JOINED);
+
+            expect(sentEvents()).toEqual([1, 2]);
+            expect(mockChannel.sendMessage.mock.calls[0][1].p).toEqual({ cseq: 1 });
+        });
+
+        it('should drop events once the server acknowledges them', () => {
+            stateHandler(ChannelState.JOINED);
+            transport.sendHandler('c0:h0', { cseq: 1 });
+            transport.sendHandler('c0:h0', { cseq: 2 });
+
+            messageHandler('message', {
+                seq: 5,
+                topic: 'ack',
+                event: 'ack',
+                data: { t: 'ack', sid: 'test-session', seq: 5, eid: 1 },
+            });
+
+            stateHandler(ChannelState.STALLED);
+            mockChannel.sendMessage.mockClear();
+            stateHandler(ChannelState.JOINED);
+
+            expect(sentEvents()).toEqual([2]);
+        });
+
+        it('should share numbering through a common event log', () => {
+            const events = new EventLog();
+            const first = new Transport({ ...baseConfig(), events });
+            const second = new Transport({ ...baseConfig(), events });
+
+            first.sendHandler('c0:h0', { cseq: 1 });
+            second.sendHandler('c0:h0', { cseq: 2 });
+
+            expect(sentEvents()).toEqual([1, 2]);
+            expect(events.unacked().map((evt) => evt.id)).toEqual([1, 2]);
+        });
+    });
+
     describe('message handling', () => {
         it('should publish frame patch to bus', () => {
             const callback = vi.fn();
@@ -235,6 +296,18 @@ describe('Transport', () => {
             expect(callback).toHaveBeenCalledWith({ seq: 1, patches: [] });
         });
 
+        it('should decode compact frame patches', () => {
+            const callback = vi.fn();
+            bus.subscribe('frame', 'patch', callback);
+
+            messageHandler('message', [4, 'frame', 'patch', [[0, [0], 'hi']]]);
+
+            expect(callback).toHaveBeenCalledWith({
+                seq: 4,
+                patches: [{ seq: 0, path: [0], op: 'setText', value: 'hi' }],
+            });
+        });
+
         it('should publish router push to bus', () => {
             const callback = vi.fn();
             bus.subscribe('router', 'push', callback);
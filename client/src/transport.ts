@@ -7,7 +7,6 @@ import {
     PayloadFor,
     ClientEvt,
     ClientAck,
-    isMessage,
     HandlerEventPayload,
     handlerTopic,
     FramePatchPayload,
@@ -17,6 +16,7 @@ import {
 } from './protocol';
 import { Bus } from './bus';
 import {Logger} from "./logger";
+import { Encoding, decodeMessage } from './wire';
 
 export interface TransportConfig {
     endpoint: string;
@@ -25,6 +25,8 @@ export interface TransportConfig {
     lastAck: number;
     location: Location;
     bus: Bus;
+    encoding?: Encoding;
+    events?: EventLog;
 }
 
 export type ConnectionState = 'connecting' | 'connected' | 'disconnected' | 'stalled' | 'declined';
@@ -34,37 +36,66 @@ export interface JoinPayload {
     ver: number;
     ack: number;
     loc: Location;
+    enc: Encoding;
 }
 
-export class Transport {
-    private readonly client: PondClient;
-    private readonly channel: ReturnType<PondClient['createChannel']>;
-    private readonly sessionId: string;
-    private readonly bus: Bus;
-    private state: ConnectionState = 'disconnected';
-    private stateListeners: Array<(state: ConnectionState) => void> = [];
+const MAX_UNACKED_EVENTS = 256;
 
-    constructor(config: TransportConfig) {
-        this.sessionId = config.sessionId;
-        this.bus = config.bus;
+/**
+ * Numbers outgoing events for the session and keeps them until the server
+ * acknowledges them, so they can be resent after a reconnect. The server drops
+ * IDs it has already handled.
+ */
+export class EventLog {
+    private nextId = 0;
+    private pending: ClientEvt[] = [];
 
-        this.client = new PondClient(config.endpoint);
+    record(evt: ClientEvt): ClientEvt {
+        evt.id = ++this.nextId;
+        this.pending.push(evt);
+        if (this.pending.length > MAX_UNACKED_EVENTS) {
+            this.pending.shift();
+        }
+        return evt;
+    }
 
-        const joinPayload: JoinPayload = {
-            sid: config.sessionId,
-            ver: config.version,
-            ack: config.lastAck,
-            loc: config.location,
-        };
+    ackThrough(eid: number): void {
+        this.pending = this.pending.filter((evt) => (evt.id ?? 0) > eid);
+    }
 
-        this.channel = this.client.createChannel(`live/${config.sessionId}`, joinPayload);
+    unacked(): ClientEvt[] {
+        return this.pending.slice();
+    }
+}
 
-        this.channel.onMessage((_event: string, payload: unknown) => {
-            this.handleMessage(payload);
-        });
+export interface LiveTransport {
+    readonly sid: string;
+    readonly connectionState: ConnectionState;
+    connect(): void;
+    disconnect(): void;
+    onStateChange(listener: (state: ConnectionState) => void): () => void;
+    send<T extends Topic, A extends ActionFor<T>>(topic: T, action: A, payload: PayloadFor<T, A>): void;
+    sendAck(seq: number): void;
+    sendHandler(handlerId: string, payload: HandlerEventPayload): void;
+    sendScript(scriptId: string, payload: ScriptPayload): void;
+}
 
-        this.channel.onChannelStateChange((channelState: ChannelState) => {
-            this.handleStateChange(channelState);
+export abstract class BaseTransport implements LiveTransport {
+    protected readonly sessionId: string;
+    protected readonly bus: Bus;
+    protected readonly events: EventLog;
+    protected state: ConnectionState = 'disconnected';
+    private stateListeners: Array<(state: ConnectionState) => void> = [];
+
+    protected constructor(config: TransportConfig) {
+        this.sessionId = config.sessionId;
+        this.bus = config.bus;
+        this.events = config.events ?? new EventLog();
+
+        this.bus.subscribe('ack', 'ack', (ack) => {
+            if (ack.eid) {
+                this.events.ackThrough(ack.eid);
+            }
         });
     }
 
@@ -76,19 +107,9 @@ export class Transport {
         return this.state;
     }
 
-    connect(): void {
-        this.state = 'connecting';
-        this.notifyStateChange();
-        this.channel.join();
-        this.client.connect();
-    }
+    abstract connect(): void;
 
-    disconnect(): void {
-        this.channel.leave();
-        this.client.disconnect();
-        this.state = 'disconnected';
-        this.notifyStateChange();
-    }
+    abstract disconnect(): void;
 
     onStateChange(listener: (state: ConnectionState) => void): () => void {
         this.stateListeners.push(listener);
@@ -111,7 +132,7 @@ export class Transport {
             a: String(action),
             p: payload,
         };
-        this.sendMessage('evt', evt);
+        this.sendEvent(evt);
     }
 
     sendAck(seq: number): void {
@@ -130,7 +151,7 @@ export class Transport {
             a: 'invoke',
             p: payload,
         };
-        this.sendMessage('evt', evt);
+        this.sendEvent(evt);
     }
 
     sendScript(scriptId: string, payload: ScriptPayload): void {
@@ -140,109 +161,175 @@ export class Transport {
             a: 'message',
             p: payload,
         };
-        this.sendMessage('evt', evt);
+        this.sendEvent(evt);
     }
 
-    private handleMessage(payload: unknown): void {
-        Logger.info('TRANSPORT','Transport received message:', payload);
-        if (!isMessage(payload)) {
-            return;
-        }
+    protected setState(state: ConnectionState): void {
+        const previous = this.state;
+        this.state = state;
 
-        const { seq, topic, event, data } = payload;
+        // Anything sent while the connection was going down may not have arrived.
+        if (state === 'connected' && previous !== 'connected') {
+            for (const evt of this.events.unacked()) {
+                this.sendMessage('evt', evt);
+            }
+        }
 
-        if (!this.isValidTopic(topic)) {
-            return;
+        for (const listener of this.stateListeners) {
+            try {
+                listener(this.state);
+            } catch {
+                // swallow
+            }
         }
+    }
 
-        this.publishToBus(topic, event, data, seq);
+    private sendEvent(evt: ClientEvt): void {
+        this.sendMessage('evt', this.events.record(evt));
     }
 
-    private isValidTopic(topic: string): topic is Topic {
-        return topic === 'router' || topic === 'dom' || topic === 'frame' || topic === 'ack' || topic.startsWith('script:');
+    protected abstract sendMessage<T extends Event>(type: 'evt' | 'ack', message: T): void;
+}
+
+export class Transport extends BaseTransport {
+    private readonly client: PondClient;
+    private readonly channel: ReturnType<PondClient['createChannel']>;
+
+    constructor(config: TransportConfig) {
+        super(config);
+
+        this.client = new PondClient(config.endpoint);
+
+        const joinPayload: JoinPayload = {
+            sid: config.sessionId,
+            ver: config.version,
+            ack: config.lastAck,
+            loc: config.location,
+            enc: config.encoding ?? 'compact',
+        };
+
+        this.channel = this.client.createChannel(`live/${config.sessionId}`, joinPayload);
+
+        this.channel.onMessage((_event: string, payload: unknown) => {
+            Logger.info('TRANSPORT','Transport received message:', payload);
+            dispatchMessage(this.bus, payload);
+        });
+
+        this.channel.onChannelStateChange((channelState: ChannelState) => {
+            this.handleStateChange(channelState);
+        });
     }
 
-    private publishToBus(topic: Topic, action: string, data: unknown, seq: number): void {
-        switch (topic) {
-            case 'frame':
-                if (action === 'patch') {
-                    const payload: FramePatchPayload = {
-                        seq,
-                        patches: data as Patch[],
-                    };
-                    this.bus.publish('frame', 'patch', payload);
-                }
-                break;
-            case 'router':
-                if (action === 'push') {
-                    this.bus.publish('router', 'push', data as PayloadFor<'router', 'push'>);
-                } else if (action === 'replace') {
-                    this.bus.publish('router', 'replace', data as PayloadFor<'router', 'replace'>);
-                } else if (action === 'back') {
-                    this.bus.publish('router', 'back', undefined);
-                } else if (action === 'forward') {
-                    this.bus.publish('router', 'forward', undefined);
-                }
-                break;
-            case 'dom':
-                if (action === 'call') {
-                    this.bus.publish('dom', 'call', data as PayloadFor<'dom', 'call'>);
-                } else if (action === 'set') {
-                    this.bus.publish('dom', 'set', data as PayloadFor<'dom', 'set'>);
-                } else if (action === 'query') {
-                    this.bus.publish('dom', 'query', data as PayloadFor<'dom', 'query'>);
-                } else if (action === 'async') {
-                    this.bus.publish('dom', 'async', data as PayloadFor<'dom', 'async'>);
-                }
-                break;
-            case 'ack':
-                if (action === 'ack') {
-                    this.bus.publish('ack', 'ack', data as PayloadFor<'ack', 'ack'>);
-                }
-                break;
-            default:
-                if (topic.startsWith('script:') && action === 'send') {
-                    const payload = data as ScriptPayload;
-                    this.bus.publishScript(payload.scriptId, 'send', payload);
-                }
-                break;
-        }
+    connect(): void {
+        this.setState('connecting');
+        this.channel.join();
+        this.client.connect();
+    }
+
+    disconnect(): void {
+        this.channel.leave();
+        this.client.disconnect();
+        this.setState('disconnected');
     }
 
     private handleStateChange(channelState: ChannelState): void {
         switch (channelState) {
             case ChannelState.JOINED:
-                this.state = 'connected';
+                this.setState('connected');
                 break;
             case ChannelState.STALLED:
-                this.state = 'stalled';
+                this.setState('stalled');
                 break;
             case ChannelState.CLOSED:
-                this.state = 'disconnected';
+                this.setState('disconnected');
                 break;
             case ChannelState.DECLINED:
-                this.state = 'declined';
+                this.setState('declined');
                 break;
             case ChannelState.JOINING:
             case ChannelState.IDLE:
-                this.state = 'connecting';
+                this.setState('connecting');
                 break;
         }
-        this.notifyStateChange();
-    }
-
-    private notifyStateChange(): void {
-        for (const listener of this.stateListeners) {
-            try {
-                listener(this.state);
-            } catch {
-                // swallow
-            }
-        }
     }
 
-    private sendMessage<T extends Event>(type: string, message: T): void {
+    protected sendMessage<T extends Event>(type: 'evt' | 'ack', message: T): void {
         Logger.info('TRANSPORT','Transport sending message:', type, message);
         this.channel.sendMessage(type, message);
     }
 }
+
+// Shared by every live transport so frames decode the same way regardless of how they arrived.
+export function dispatchMessage(bus: Bus, payload: unknown): Topic | null {
+    const message = decodeMessage(payload);
+    if (!message) {
+        return null;
+    }
+
+    const { seq, topic, event, data } = message;
+
+    if (!isValidTopic(topic)) {
+        return null;
+    }
+
+    publishToBus(bus, topic, event, data, seq);
+    return topic;
+}
+
+function isValidTopic(topic: string): topic is Topic {
+    return topic === 'router' || topic === 'dom' || topic === 'frame' || topic === 'ack' || topic === 'reload' || topic.startsWith('script:');
+}
+
+function publishToBus(bus: Bus, topic: Topic, action: string, data: unknown, seq: number): void {
+    switch (topic) {
+        case 'frame':
+            if (action === 'patch') {
+                const payload: FramePatchPayload = {
+                    seq,
+                    patches: data as Patch[],
+                };
+                bus.publish('frame', 'patch', payload);
+            }
+            break;
+        case 'router':
+            if (action === 'push') {
+                bus.publish('router', 'push', data as PayloadFor<'router', 'push'>);
+            } else if (action === 'replace') {
+                bus.publish('router', 'replace', data as PayloadFor<'router', 'replace'>);
+            } else if (action === 'back') {
+                bus.publish('router', 'back', undefined);
+            } else if (action === 'forward') {
+                bus.publish('router', 'forward', undefined);
+            } else if (action === 'go') {
+                bus.publish('router', 'go', data as PayloadFor<'router', 'go'>);
+            }
+            break;
+        case 'dom':
+            if (action === 'call') {
+                bus.publish('dom', 'call', data as PayloadFor<'dom', 'call'>);
+            } else if (action === 'set') {
+                bus.publish('dom', 'set', data as PayloadFor<'dom', 'set'>);
+            } else if (action === 'query') {
+                bus.publish('dom', 'query', data as PayloadFor<'dom', 'query'>);
+            } else if (action === 'async') {
+                bus.publish('dom', 'async', data as PayloadFor<'dom', 'async'>);
+            }
+            break;
+        case 'ack':
+            if (action === 'ack') {
+                bus.publish('ack', 'ack', data as PayloadFor<'ack', 'ack'>);
+            }
+            break;
+        case 'reload':
+            if (action === 'reload') {
+                bus.publish('reload', 'reload', data as PayloadFor<'reload', 'reload'>);
+            }
+            break;
+        default:
+            if (topic.startsWith('script:') && action === 'send') {
+                const payload = data as ScriptPayload;
+                bus.publishScript(payload.scriptId, 'send', payload);
+            }
+            break;
+    }
+}
@@ -0,0 +1,39 @@
+import { describe, it, expect } from 'vitest';
+import { readFileSync } from 'fs';
+import { resolve } from 'path';
+import { compactOps, decodeMessage } from './wire';
+import { OpKinds } from './protocol';
+
+interface WireFixture {
+    name: string;
+    message: unknown;
+    compact: unknown;
+}
+
+const fixtures: WireFixture[] = JSON.parse(
+    readFileSync(resolve(process.cwd(), '../internal/session/testdata/wire_fixtures.json'), 'utf8')
+);
+
+describe('decodeMessage', () => {
+    for (const fixture of fixtures) {
+        it(`decodes compact fixture: ${fixture.name}`, () => {
+            expect(decodeMessage(fixture.compact)).toEqual(fixture.message);
+        });
+
+        it(`passes through json fixture: ${fixture.name}`, () => {
+            expect(decodeMessage(fixture.message)).toEqual(fixture.message);
+        });
+    }
+
+    it('rejects malformed payloads', () => {
+        expect(decodeMessage([1, 'frame'])).toBeNull();
+        expect(decodeMessage([1, 'frame', 'patch', [[99, []]]])).toBeNull();
+        expect(decodeMessage('nope')).toBeNull();
+    });
+
+    it('has a compact code for every op', () => {
+        for (const op of Object.values(OpKinds)) {
+            expect(compactOps).toContain(op);
+        }
+    });
+});
@@ -0,0 +1,88 @@
+import { Message, OpKind, Patch, isMessage } from './protocol';
+
+export type Encoding = 'json' | 'compact';
+
+// Must match compactOps in internal/session/encoding.go; entries are only appended.
+export const compactOps: OpKind[] = [
+    'setText',
+    'setComment',
+    'setAttr',
+    'delAttr',
+    'setStyle',
+    'delStyle',
+    'setStyleDecl',
+    'delStyleDecl',
+    'setHandlers',
+    'setScript',
+    'delScript',
+    'setRef',
+    'delRef',
+    'replaceNode',
+    'addChild',
+    'delChild',
+    'moveChild',
+    'appendText',
+    'spliceText',
+    'defTemplate',
+    'setSlots',
+];
+
+export function decodeMessage(payload: unknown): Message | null {
+    if (isMessage(payload)) {
+        return payload;
+    }
+    if (!Array.isArray(payload) || payload.length !== 4) {
+        return null;
+    }
+
+    const [seq, topic, event, data] = payload;
+    if (typeof seq !== 'number' || typeof topic !== 'string' || typeof event !== 'string') {
+        return null;
+    }
+
+    if (topic === 'frame' && event === 'patch') {
+        const patches = expandPatches(data);
+        if (!patches) {
+            return null;
+        }
+        return { seq, topic, event, data: patches };
+    }
+
+    return { seq, topic, event, data };
+}
+
+function expandPatches(data: unknown): Patch[] | null {
+    if (!Array.isArray(data)) {
+        return null;
+    }
+
+    const patches: Patch[] = [];
+    for (let i = 0; i < data.length; i++) {
+        const row = data[i];
+        if (!Array.isArray(row) || row.length < 2) {
+            return null;
+        }
+
+        const op = compactOps[row[0]];
+        if (op === undefined) {
+            return null;
+        }
+
+        const [, path, value, name, selector, index] = row;
+        const patch: Patch = { seq: i, path: path ?? null, op };
+        if (value !== undefined && value !== null) {
+            patch.value = value;
+        }
+        if (name) {
+            patch.name = name;
+        }
+        if (selector) {
+            patch.selector = selector;
+        }
+        if (typeof index === 'number') {
+            patch.index = index;
+        }
+        patches.push(patch);
+    }
+    return patches;
+}
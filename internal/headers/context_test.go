@@ -86,6 +86,28 @@ func TestRequestStateRedirect(t *testing.T) {
 	}
 }
 
+func TestRequestStateStatus(t *testing.T) {
+	state := NewRequestState(nil)
+
+	if code := state.Status(); code != http.StatusOK {
+		t.Errorf("Expected default status 200, got %d", code)
+	}
+
+	state.SetStatus(http.StatusNotFound)
+	if code := state.Status(); code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", code)
+	}
+
+	if code := state.Clone().Status(); code != http.StatusNotFound {
+		t.Errorf("Expected clone to keep status 404, got %d", code)
+	}
+
+	state.ReplaceInfo(&RequestInfo{})
+	if code := state.Status(); code != http.StatusOK {
+		t.Errorf("Expected status reset after ReplaceInfo, got %d", code)
+	}
+}
+
 func TestRequestStateIsLive(t *testing.T) {
 	state := NewRequestState(nil)
 
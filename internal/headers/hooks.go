@@ -78,3 +78,11 @@ func UseIsLive(ctx *runtime.Ctx) bool {
 	}
 	return state.IsLive()
 }
+
+func UseStatus(ctx *runtime.Ctx, code int) {
+	state := UseRequestState(ctx)
+	if state == nil || state.IsLive() {
+		return
+	}
+	state.SetStatus(code)
+}
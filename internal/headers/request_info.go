@@ -1,6 +1,7 @@
 package headers
 
 import (
+	"context"
 	"net/http"
 	"net/url"
 	"sync"
@@ -15,6 +16,8 @@ type RequestInfo struct {
 	Query   url.Values
 	Hash    string
 	Headers http.Header
+
+	ctx context.Context
 }
 
 func NewRequestInfo(r *http.Request) *RequestInfo {
@@ -53,6 +56,7 @@ func NewRequestInfo(r *http.Request) *RequestInfo {
 		Query:      query,
 		Hash:       hash,
 		Headers:    headers,
+		ctx:        r.Context(),
 	}
 }
 
@@ -135,7 +139,17 @@ func (r *RequestInfo) Clone() *RequestInfo {
 		Query:      query,
 		Hash:       r.Hash,
 		Headers:    r.Headers.Clone(),
+		ctx:        r.ctx,
+	}
+}
+
+// Context returns the context of the HTTP request the info was built from, or
+// context.Background when there was none.
+func (r *RequestInfo) Context() context.Context {
+	if r == nil || r.ctx == nil {
+		return context.Background()
 	}
+	return r.ctx
 }
 
 type RequestState struct {
@@ -148,6 +162,7 @@ type RequestState struct {
 	responseHeaders http.Header
 	redirectURL     string
 	redirectCode    int
+	status          int
 
 	cookieMutations map[string]*cookieMutation
 
@@ -208,6 +223,17 @@ func (s *RequestState) GetCookie(name string) (string, bool) {
 	return s.info.GetCookie(name)
 }
 
+// Context returns the originating request's context. It is done once that
+// request has been served, so only SSR work should be tied to it.
+func (s *RequestState) Context() context.Context {
+	if s == nil {
+		return context.Background()
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.info.Context()
+}
+
 func (s *RequestState) Path() string {
 	if s == nil || s.info == nil {
 		return ""
@@ -306,6 +332,27 @@ func (s *RequestState) Redirect() (url string, code int, hasRedirect bool) {
 	return "", 0, false
 }
 
+func (s *RequestState) SetStatus(code int) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status = code
+}
+
+func (s *RequestState) Status() int {
+	if s == nil {
+		return http.StatusOK
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.status == 0 {
+		return http.StatusOK
+	}
+	return s.status
+}
+
 func (s *RequestState) MutateCookie(name, value string) {
 	if s == nil {
 		return
@@ -347,6 +394,7 @@ func (s *RequestState) ReplaceInfo(info *RequestInfo) {
 	s.info = info
 	s.responseHeaders = make(http.Header)
 	s.cookieMutations = make(map[string]*cookieMutation)
+	s.status = 0
 	s.mu.Unlock()
 }
 
@@ -374,6 +422,7 @@ func (s *RequestState) Clone() *RequestState {
 		responseHeaders: responseHeaders,
 		redirectURL:     s.redirectURL,
 		redirectCode:    s.redirectCode,
+		status:          s.status,
 		cookieMutations: cookieMutations,
 		setState:        s.setState,
 	}
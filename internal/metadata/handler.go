@@ -16,4 +16,8 @@ type EventOptions struct {
 	Throttle int      `json:"throttle,omitempty"`
 	Listen   []string `json:"listen,omitempty"`
 	Props    []string `json:"props,omitempty"`
+
+	// Immediate flushes the handler's updates without waiting for the
+	// session's coalescing window or frame budget. Server-side only.
+	Immediate bool `json:"-"`
 }
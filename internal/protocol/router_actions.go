@@ -8,6 +8,7 @@ const (
 	RouterReplaceAction RouterServerAction = "replace"
 	RouterBackAction    RouterServerAction = "back"
 	RouterForwardAction RouterServerAction = "forward"
+	RouterGoAction      RouterServerAction = "go"
 
 	RouterPopstateAction RouterClientAction = "popstate"
 )
@@ -17,6 +18,17 @@ type RouterNavPayload struct {
 	Query   string `json:"query"`
 	Hash    string `json:"hash"`
 	Replace bool   `json:"replace"`
+	Scroll  string `json:"scroll,omitempty"`
+	Focus   string `json:"focus,omitempty"`
+
+	// Delta is how many history entries a popstate moved, when the client
+	// knows it: -1 for one step back, 1 for one step forward.
+	Delta int `json:"delta,omitempty"`
+}
+
+// RouterGoPayload moves the client through its history like history.go.
+type RouterGoPayload struct {
+	Delta int `json:"delta"`
 }
 
 func (b *Bus) PublishRouterPush(payload RouterNavPayload) {
@@ -35,10 +47,14 @@ func (b *Bus) PublishRouterForward() {
 	b.Publish(RouteHandler, string(RouterForwardAction), nil)
 }
 
+func (b *Bus) PublishRouterGo(delta int) {
+	b.Publish(RouteHandler, string(RouterGoAction), RouterGoPayload{Delta: delta})
+}
+
 func (b *Bus) SubscribeToRouterCommands(callback func(action RouterServerAction, data interface{})) *Subscription {
 	return b.Upsert(RouteHandler, func(event string, data interface{}) {
 		switch RouterServerAction(event) {
-		case RouterPushAction, RouterReplaceAction, RouterBackAction, RouterForwardAction:
+		case RouterPushAction, RouterReplaceAction, RouterBackAction, RouterForwardAction, RouterGoAction:
 			callback(RouterServerAction(event), data)
 		}
 	})
@@ -12,6 +12,7 @@ const (
 	DOMHandler      Topic = "dom"
 	TopicFrame      Topic = "frame"
 	TopicDiagnostic Topic = "diagnostic"
+	TopicReload     Topic = "reload"
 
 	AckTopic Topic = "ack"
 )
@@ -23,6 +24,7 @@ type Event struct {
 
 type ClientEvt struct {
 	Event
+	ID      uint64      `json:"id,omitempty"`
 	Action  string      `json:"a"`
 	Payload interface{} `json:"p,omitempty"`
 }
@@ -42,10 +44,12 @@ type ClientAck struct {
 type ServerAck struct {
 	Event
 	Seq uint64 `json:"seq"`
+	EID uint64 `json:"eid,omitempty"`
 }
 
 type ClientConfig struct {
-	Debug *bool `json:"debug,omitempty"`
+	Debug     *bool  `json:"debug,omitempty"`
+	Transport string `json:"transport,omitempty"`
 }
 
 type Boot struct {
@@ -55,9 +59,15 @@ type Boot struct {
 	Seq      int            `json:"seq"`
 	Patch    []diff.Patch   `json:"patch"`
 	Location route.Location `json:"location"`
+	Base     string         `json:"base,omitempty"`
 	Client   *ClientConfig  `json:"client,omitempty"`
 }
 
+type Reload struct {
+	Ver   int `json:"ver"`
+	Grace int `json:"grace"`
+}
+
 type ServerError struct {
 	T          string         `json:"t"`
 	SID        string         `json:"sid"`
@@ -0,0 +1,126 @@
+package route
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+type Segment struct {
+	Name       string
+	Optional   bool
+	Constraint *Constraint
+	Err        error
+}
+
+type Constraint struct {
+	Source string
+	re     *regexp.Regexp
+}
+
+var namedConstraints = map[string]string{
+	"int":   `-?[0-9]+`,
+	"uint":  `[0-9]+`,
+	"uuid":  `[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`,
+	"alpha": `[a-zA-Z]+`,
+	"slug":  `[a-z0-9]+(?:-[a-z0-9]+)*`,
+}
+
+var constraintCache sync.Map
+
+func (c *Constraint) Match(value string) bool {
+	if c == nil {
+		return true
+	}
+	return c.re != nil && c.re.MatchString(value)
+}
+
+func ParseSegment(seg string) (Segment, bool) {
+	if !strings.HasPrefix(seg, ":") {
+		return Segment{}, false
+	}
+
+	name := strings.TrimPrefix(seg, ":")
+	out := Segment{}
+	if strings.HasSuffix(name, "?") {
+		out.Optional = true
+		name = strings.TrimSuffix(name, "?")
+	}
+
+	if open := strings.IndexByte(name, '('); open >= 0 && strings.HasSuffix(name, ")") {
+		out.Constraint, out.Err = compileConstraint(name[open+1 : len(name)-1])
+		name = name[:open]
+	}
+
+	out.Name = name
+	return out, true
+}
+
+// CheckPattern reports the first param constraint in pattern that does not
+// compile. Paths are matched a segment at a time, so a constraint containing
+// "/" could never match and is rejected as well.
+func CheckPattern(pattern string) error {
+	for i := 0; i < len(pattern); i++ {
+		if pattern[i] != ':' || (i > 0 && pattern[i-1] != '/') {
+			continue
+		}
+		start := i
+		for i < len(pattern) && pattern[i] != '/' && pattern[i] != '(' {
+			i++
+		}
+		if i == len(pattern) || pattern[i] != '(' {
+			continue
+		}
+
+		end, depth := -1, 0
+		for j := i; j < len(pattern) && end < 0; j++ {
+			switch pattern[j] {
+			case '\\':
+				j++
+			case '(':
+				depth++
+			case ')':
+				depth--
+				if depth == 0 {
+					end = j
+				}
+			}
+		}
+		if end < 0 {
+			return fmt.Errorf("route: unterminated param constraint in %q", pattern[start:])
+		}
+
+		source := pattern[i+1 : end]
+		if strings.Contains(source, "/") {
+			return fmt.Errorf("route: param constraint %q may not contain \"/\"", source)
+		}
+		if _, err := compileConstraint(source); err != nil {
+			return err
+		}
+		i = end
+	}
+	return nil
+}
+
+// compileConstraint returns a constraint that never matches along with the
+// error when source is not a valid expression.
+func compileConstraint(source string) (*Constraint, error) {
+	if cached, ok := constraintCache.Load(source); ok {
+		return cached.(*Constraint), nil
+	}
+
+	expr := source
+	if named, ok := namedConstraints[source]; ok {
+		expr = named
+	}
+
+	re, err := regexp.Compile(`^(?:` + expr + `)$`)
+	if err != nil {
+		return &Constraint{Source: source}, fmt.Errorf("route: invalid param constraint %q: %v", source, err)
+	}
+
+	c := &Constraint{Source: source, re: re}
+	constraintCache.Store(source, c)
+	return c, nil
+}
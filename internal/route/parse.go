@@ -21,6 +21,9 @@ func NormalizePattern(pattern string) string {
 }
 
 func Parse(pattern string, path string, rawQuery string) (Match, error) {
+	if err := CheckPattern(pattern); err != nil {
+		return Match{}, err
+	}
 	normalizedPattern := normalizePattern(pattern)
 	parts := NormalizeParts(path)
 	normalizedPath := parts.Path
@@ -76,25 +79,27 @@ func matchPath(path, pattern string) matchResult {
 				res.rest = "/" + remainder
 			}
 			if remainder != "" {
-				res.score++
+				res.score += 2
 			}
 			break
 		}
-		name, optional := parseParam(seg)
-		if name != "" {
-			if ti >= len(pathSegs) {
-				if optional {
+		if param, ok := ParseSegment(seg); ok {
+			if ti >= len(pathSegs) || !param.Constraint.Match(pathSegs[ti]) {
+				if param.Optional {
 					pi++
 					continue
 				}
 				return matchResult{}
 			}
-			if optional {
-				res.score++
-			} else {
+			if param.Optional {
 				res.score += 2
+			} else {
+				res.score += 4
 			}
-			res.params[name] = pathSegs[ti]
+			if param.Constraint != nil {
+				res.score++
+			}
+			res.params[param.Name] = pathSegs[ti]
 			pi++
 			ti++
 			continue
@@ -105,7 +110,7 @@ func matchPath(path, pattern string) matchResult {
 		if pathSegs[ti] != seg {
 			return matchResult{}
 		}
-		res.score += 3
+		res.score += 6
 		pi++
 		ti++
 	}
@@ -121,8 +126,7 @@ func matchPath(path, pattern string) matchResult {
 		if seg == "" || isWildcard(seg) {
 			continue
 		}
-		_, optional := parseParam(seg)
-		if optional {
+		if param, ok := ParseSegment(seg); ok && param.Optional {
 			continue
 		}
 		return matchResult{}
@@ -203,15 +207,3 @@ func splitSegments(path string) []string {
 func isWildcard(seg string) bool {
 	return strings.HasPrefix(seg, "*")
 }
-
-func parseParam(seg string) (string, bool) {
-	if !strings.HasPrefix(seg, ":") {
-		return "", false
-	}
-	name := strings.TrimPrefix(seg, ":")
-	optional := strings.HasSuffix(name, "?")
-	if optional {
-		name = strings.TrimSuffix(name, "?")
-	}
-	return name, optional
-}
@@ -45,6 +45,52 @@ func TestParseOptional(t *testing.T) {
 	}
 }
 
+func TestParseConstrainedParams(t *testing.T) {
+	if _, err := Parse("/posts/:id(int)", "/posts/banana", ""); err == nil {
+		t.Fatal("expected int constraint to reject banana")
+	}
+	match, err := Parse("/posts/:slug([a-z0-9-]+)", "/posts/hello-world", "")
+	if err != nil {
+		t.Fatalf("expected regex constraint match: %v", err)
+	}
+	if match.Params["slug"] != "hello-world" {
+		t.Fatalf("expected slug=hello-world, got %#v", match.Params)
+	}
+	if _, err := Parse("/posts/:slug([a-z0-9-]+)", "/posts/Hello", ""); err == nil {
+		t.Fatal("expected regex constraint to reject uppercase")
+	}
+}
+
+func TestParseRejectsInvalidConstraints(t *testing.T) {
+	for _, pattern := range []string{
+		"/posts/:id([0-9+)",
+		"/posts/:id([a-z]+/[0-9]+)",
+		"/posts/:id([a-z]+",
+	} {
+		if err := CheckPattern(pattern); err == nil {
+			t.Errorf("%s: expected CheckPattern to fail", pattern)
+		}
+		if _, err := Parse(pattern, "/posts/a", ""); err == nil {
+			t.Errorf("%s: expected Parse to return an error", pattern)
+		}
+	}
+
+	if err := CheckPattern("/posts/:slug((?:[a-z]+)-(?:[0-9]+))/edit"); err != nil {
+		t.Errorf("expected nested groups to be allowed: %v", err)
+	}
+}
+
+func TestBestMatchPrefersConstrainedParam(t *testing.T) {
+	_, idx, ok := BestMatch("/posts/42", "", []string{"/posts/:slug", "/posts/:id(int)"})
+	if !ok || idx != 1 {
+		t.Fatalf("expected constrained pattern, got idx=%d", idx)
+	}
+	_, idx, ok = BestMatch("/posts/42", "", []string{"/posts/:id(int)", "/posts/42"})
+	if !ok || idx != 1 {
+		t.Fatalf("expected static pattern, got idx=%d", idx)
+	}
+}
+
 func TestParseQueryValues(t *testing.T) {
 	match, err := Parse("/search", "/search", "q=golang&tag=ui&tag=go")
 	if err != nil {
@@ -61,3 +61,25 @@ func normalizeSegments(path string) string {
 	}
 	return "/" + strings.Join(segs, "/")
 }
+
+// NormalizeBase trims a mount path to "/prefix" form, or "" for the root.
+func NormalizeBase(base string) string {
+	base = strings.Trim(strings.TrimSpace(base), "/")
+	if base == "" {
+		return ""
+	}
+	return "/" + base
+}
+
+// WithBase mounts an app-relative path under base. Absolute URLs and
+// protocol-relative paths are left alone.
+func WithBase(base, p string) string {
+	base = NormalizeBase(base)
+	if base == "" || !strings.HasPrefix(p, "/") || strings.HasPrefix(p, "//") {
+		return p
+	}
+	if p == "/" {
+		return base + "/"
+	}
+	return base + p
+}
@@ -0,0 +1,243 @@
+package router
+
+import (
+	"sync"
+
+	"github.com/eleven-am/pondlive/internal/runtime"
+	"github.com/eleven-am/pondlive/internal/work"
+)
+
+const blockerScript = `function(element,transport){
+	var active=false;
+	function onBeforeUnload(e){if(!active)return;e.preventDefault();e.returnValue='';return ''}
+	window.addEventListener('beforeunload',onBeforeUnload);
+	transport.on('block',function(v){active=!!v});
+	transport.send('ready',{});
+	return function(){window.removeEventListener('beforeunload',onBeforeUnload)};
+}`
+
+type heldNavigation struct {
+	event   NavigationEvent
+	proceed func()
+}
+
+type blockerEntry struct {
+	when    bool
+	onBlock func(NavigationEvent) bool
+	hold    func(*heldNavigation)
+}
+
+type blockerStore struct {
+	mu      sync.Mutex
+	entries map[*blockerEntry]struct{}
+	script  runtime.ScriptHandle
+	ready   bool
+	sent    bool
+	passing *Location
+}
+
+type Blocker struct {
+	held    *heldNavigation
+	setHeld func(*heldNavigation)
+}
+
+var blockerStoreCtx = runtime.CreateContext[*blockerStore](nil)
+
+func newBlockerStore() *blockerStore {
+	return &blockerStore{entries: make(map[*blockerEntry]struct{})}
+}
+
+func UseBlocker(ctx *runtime.Ctx, when bool, onBlock func(NavigationEvent) bool) *Blocker {
+	store := blockerStoreCtx.UseContextValue(ctx)
+	held, setHeld := runtime.UseState[*heldNavigation](ctx, nil)
+	entryRef := runtime.UseRef(ctx, &blockerEntry{})
+	entry := entryRef.Current
+
+	runtime.UseEffect(ctx, func() func() {
+		store.add(entry)
+		return func() {
+			store.remove(entry)
+		}
+	}, store, entry)
+
+	// Updating can tell the client to start or stop guarding unload, so it
+	// waits for the commit instead of sending mid-render.
+	runtime.UseEffect(ctx, func() func() {
+		store.update(entry, when, onBlock, setHeld)
+		return nil
+	})
+
+	return &Blocker{held: held, setHeld: setHeld}
+}
+
+func (b *Blocker) Blocked() bool {
+	return b != nil && b.held != nil
+}
+
+func (b *Blocker) Event() NavigationEvent {
+	if b == nil || b.held == nil {
+		return NavigationEvent{}
+	}
+	return b.held.event
+}
+
+func (b *Blocker) Proceed() {
+	if b == nil || b.held == nil {
+		return
+	}
+	held := b.held
+	b.held = nil
+	b.setHeld(nil)
+	held.proceed()
+}
+
+func (b *Blocker) Cancel() {
+	if b == nil || b.held == nil {
+		return
+	}
+	b.held = nil
+	b.setHeld(nil)
+}
+
+func (s *blockerStore) update(entry *blockerEntry, when bool, onBlock func(NavigationEvent) bool, hold func(*heldNavigation)) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	entry.when = when
+	entry.onBlock = onBlock
+	entry.hold = hold
+	s.mu.Unlock()
+
+	s.sync()
+}
+
+func (s *blockerStore) add(entry *blockerEntry) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	s.entries[entry] = struct{}{}
+	s.mu.Unlock()
+
+	s.sync()
+}
+
+func (s *blockerStore) remove(entry *blockerEntry) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	delete(s.entries, entry)
+	s.mu.Unlock()
+
+	s.sync()
+}
+
+func (s *blockerStore) intercept(event NavigationEvent, proceed func()) bool {
+	if s == nil {
+		return false
+	}
+
+	// Copy the entries under the lock; a render may update them while the
+	// callbacks run.
+	s.mu.Lock()
+	var active []blockerEntry
+	for entry := range s.entries {
+		if entry.when {
+			active = append(active, *entry)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, entry := range active {
+		if entry.onBlock != nil && entry.onBlock(event) {
+			continue
+		}
+		if entry.hold != nil {
+			entry.hold(&heldNavigation{event: event, proceed: proceed})
+		}
+		return true
+	}
+	return false
+}
+
+// pass lets the next popstate to loc through without asking the blockers; it
+// is the history move that carries out a held popstate once it proceeds.
+func (s *blockerStore) pass(loc Location) {
+	s.mu.Lock()
+	s.passing = &loc
+	s.mu.Unlock()
+}
+
+func (s *blockerStore) passes(loc Location) bool {
+	if s == nil {
+		return false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.passing == nil || !locationEqual(*s.passing, loc) {
+		return false
+	}
+	s.passing = nil
+	return true
+}
+
+func (s *blockerStore) sync() {
+	s.mu.Lock()
+	active := false
+	for entry := range s.entries {
+		if entry.when {
+			active = true
+			break
+		}
+	}
+	changed := s.ready && active != s.sent
+	if changed {
+		s.sent = active
+	}
+	script := s.script
+	s.mu.Unlock()
+
+	if changed {
+		script.Send("block", active)
+	}
+}
+
+func (s *blockerStore) handleReady(any) {
+	s.mu.Lock()
+	s.ready = true
+	s.sent = false
+	s.mu.Unlock()
+
+	s.sync()
+}
+
+var Render = runtime.Component(func(ctx *runtime.Ctx, _ []work.Item) work.Node {
+	store := blockerStoreCtx.UseContextValue(ctx)
+	if store == nil {
+		return nil
+	}
+
+	scriptNode := &work.Element{
+		Tag: "script",
+	}
+
+	store.script.AttachTo(scriptNode)
+	return scriptNode
+})
+
+func newNavigationEvent(from, to Location, replace bool) NavigationEvent {
+	return NavigationEvent{
+		From:         from,
+		To:           to,
+		PathChanged:  from.Path != to.Path,
+		HashChanged:  from.Hash != to.Hash,
+		QueryChanged: from.Query.Encode() != to.Query.Encode(),
+		Replace:      replace,
+	}
+}
@@ -0,0 +1,231 @@
+package router
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/eleven-am/pondlive/internal/protocol"
+	"github.com/eleven-am/pondlive/internal/runtime"
+	"github.com/eleven-am/pondlive/internal/work"
+)
+
+func TestBlockerHoldsNavigationUntilProceed(t *testing.T) {
+	var mu sync.Mutex
+	var blocker *Blocker
+	var loc Location
+	var nav func(string)
+	dirty := true
+	var asked []NavigationEvent
+
+	sess, requestState := guardTestSession("/edit", func(ctx *runtime.Ctx) work.Node {
+		b := UseBlocker(ctx, dirty, func(evt NavigationEvent) bool {
+			asked = append(asked, evt)
+			return evt.To.Path == "/edit/preview"
+		})
+		mu.Lock()
+		blocker, loc = b, UseLocation(ctx)
+		nav = func(href string) { Navigate(ctx, href) }
+		mu.Unlock()
+		return nil
+	})
+	requestState.SetIsLive(true)
+
+	if err := sess.Flush(); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+
+	snapshot := func() (*Blocker, Location) {
+		mu.Lock()
+		defer mu.Unlock()
+		return blocker, loc
+	}
+
+	nav("/elsewhere")
+	if err := sess.Flush(); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+
+	b, current := snapshot()
+	if current.Path != "/edit" {
+		t.Fatalf("expected navigation to be blocked, got %q", current.Path)
+	}
+	if !b.Blocked() || b.Event().To.Path != "/elsewhere" {
+		t.Fatalf("expected blocker to hold /elsewhere, got %+v", b.Event())
+	}
+
+	b.Cancel()
+	if err := sess.Flush(); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+	if b, _ := snapshot(); b.Blocked() {
+		t.Fatal("expected cancel to clear the held navigation")
+	}
+
+	nav("/edit/preview")
+	if err := sess.Flush(); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+	if _, current := snapshot(); current.Path != "/edit/preview" {
+		t.Fatalf("expected onBlock to allow /edit/preview, got %q", current.Path)
+	}
+
+	nav("/elsewhere")
+	if err := sess.Flush(); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+	b, _ = snapshot()
+	b.Proceed()
+	if err := sess.Flush(); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+	if _, current := snapshot(); current.Path != "/elsewhere" {
+		t.Fatalf("expected proceed to navigate, got %q", current.Path)
+	}
+	if len(asked) != 3 {
+		t.Errorf("expected onBlock for each navigation, got %d", len(asked))
+	}
+
+	dirty = false
+	sess.MarkDirty(sess.Root)
+	if err := sess.Flush(); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+	nav("/free")
+	if err := sess.Flush(); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+	if _, current := snapshot(); current.Path != "/free" {
+		t.Fatalf("expected inactive blocker to allow navigation, got %q", current.Path)
+	}
+}
+
+func blockedPopstateSession(t *testing.T) (*runtime.Session, func() (*Blocker, Location, []string)) {
+	t.Helper()
+
+	var mu sync.Mutex
+	var blocker *Blocker
+	var loc Location
+	var commands []string
+
+	sess, requestState := guardTestSession("/edit", func(ctx *runtime.Ctx) work.Node {
+		b := UseBlocker(ctx, true, nil)
+		mu.Lock()
+		blocker, loc = b, UseLocation(ctx)
+		mu.Unlock()
+		return nil
+	})
+	requestState.SetIsLive(true)
+
+	if err := sess.Flush(); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+
+	sess.Bus.SubscribeAll(func(topic protocol.Topic, event string, data interface{}) {
+		if topic != protocol.RouteHandler || event == string(protocol.RouterPopstateAction) {
+			return
+		}
+		var command string
+		switch payload := data.(type) {
+		case protocol.RouterNavPayload:
+			command = event + " " + payload.Path
+		case protocol.RouterGoPayload:
+			command = event + " " + strconv.Itoa(payload.Delta)
+		default:
+			return
+		}
+		mu.Lock()
+		commands = append(commands, command)
+		mu.Unlock()
+	})
+
+	return sess, func() (*Blocker, Location, []string) {
+		mu.Lock()
+		defer mu.Unlock()
+		return blocker, loc, append([]string(nil), commands...)
+	}
+}
+
+func TestBlockerStepsBackOnBlockedPopstate(t *testing.T) {
+	sess, snapshot := blockedPopstateSession(t)
+	popstate := func(path string, delta int) {
+		sess.Bus.Publish(protocol.RouteHandler, string(protocol.RouterPopstateAction), protocol.RouterNavPayload{Path: path, Delta: delta})
+	}
+
+	popstate("/list", -1)
+	waitFor(t, func() bool {
+		_ = sess.Flush()
+		blocker, _, commands := snapshot()
+		return blocker.Blocked() && len(commands) == 1
+	})
+	if _, loc, commands := snapshot(); loc.Path != "/edit" || commands[0] != "go 1" {
+		t.Fatalf("expected the history to step forward to /edit, got loc %q commands %v", loc.Path, commands)
+	}
+	popstate("/edit", 1)
+
+	blocker, _, _ := snapshot()
+	blocker.Proceed()
+	waitFor(t, func() bool {
+		_, _, commands := snapshot()
+		return len(commands) == 2
+	})
+	if _, _, commands := snapshot(); commands[1] != "go -1" {
+		t.Fatalf("expected proceeding to step back again, got %v", commands)
+	}
+
+	popstate("/list", -1)
+	waitFor(t, func() bool {
+		_ = sess.Flush()
+		_, loc, _ := snapshot()
+		return loc.Path == "/list"
+	})
+	if _, _, commands := snapshot(); len(commands) != 2 {
+		t.Errorf("expected no history entries to be added, got %v", commands)
+	}
+}
+
+func TestBlockerReplacesURLWhenPopstateDeltaUnknown(t *testing.T) {
+	sess, snapshot := blockedPopstateSession(t)
+
+	sess.Bus.Publish(protocol.RouteHandler, string(protocol.RouterPopstateAction), protocol.RouterNavPayload{Path: "/list"})
+	waitFor(t, func() bool {
+		_ = sess.Flush()
+		blocker, _, commands := snapshot()
+		return blocker.Blocked() && len(commands) == 1
+	})
+	if _, _, commands := snapshot(); commands[0] != "replace /edit" {
+		t.Fatalf("expected the URL to be replaced with /edit, got %v", commands)
+	}
+
+	blocker, _, _ := snapshot()
+	blocker.Proceed()
+	waitFor(t, func() bool {
+		_ = sess.Flush()
+		_, loc, commands := snapshot()
+		return loc.Path == "/list" && len(commands) == 2
+	})
+	if _, _, commands := snapshot(); commands[1] != "replace /list" {
+		t.Errorf("expected proceeding to replace the URL with /list, got %v", commands)
+	}
+}
+
+func TestBlockerInterceptSnapshotsCallbacks(t *testing.T) {
+	store := newBlockerStore()
+	entry := &blockerEntry{}
+	store.add(entry)
+	store.update(entry, true, func(NavigationEvent) bool { return false }, func(*heldNavigation) {})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			store.update(entry, true, func(NavigationEvent) bool { return false }, func(*heldNavigation) {})
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		if !store.intercept(NavigationEvent{}, func() {}) {
+			t.Fatal("expected the active blocker to hold the navigation")
+		}
+	}
+	<-done
+}
@@ -0,0 +1,317 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/eleven-am/pondlive/internal/route"
+	"github.com/eleven-am/pondlive/internal/runtime"
+	"github.com/eleven-am/pondlive/internal/work"
+)
+
+type RouteDef[T any] struct {
+	*routeDefinition
+	fields map[string]int
+}
+
+// routeDefinition is what a Route made from a RouteDef carries, so the router
+// rendering it can check the definition against the others it uses.
+type routeDefinition struct {
+	pattern string
+	name    string
+	err     error
+}
+
+// routeRegistry holds the definitions one router has rendered. Each Provide
+// has its own, so apps in the same process cannot conflict with each other.
+type routeRegistry struct {
+	mu    sync.Mutex
+	trie  *routerTrie
+	names map[string]*routeDefinition
+	errs  map[*routeDefinition]error
+}
+
+var routeRegistryCtx = runtime.CreateContext[*routeRegistry](nil)
+
+func newRouteRegistry() *routeRegistry {
+	return &routeRegistry{
+		trie:  newRouterTrie(),
+		names: make(map[string]*routeDefinition),
+		errs:  make(map[*routeDefinition]error),
+	}
+}
+
+// DefineRoute declares a typed route. An invalid definition does not panic;
+// it is returned by Err, and a definition that conflicts with another one is
+// reported by the Routes that renders it.
+func DefineRoute[T any](pattern string) *RouteDef[T] {
+	pattern = normalizePath(strings.TrimSpace(pattern))
+	var fields map[string]int
+	err := route.CheckPattern(pattern)
+	if err == nil {
+		fields, err = routeParamFields(reflect.TypeFor[T](), pattern)
+	}
+	return &RouteDef[T]{
+		routeDefinition: &routeDefinition{pattern: pattern, name: pattern, err: err},
+		fields:          fields,
+	}
+}
+
+func (d *RouteDef[T]) Named(name string) *RouteDef[T] {
+	d.name = name
+	return d
+}
+
+// Err reports why the definition is invalid, if it is.
+func (d *RouteDef[T]) Err() error {
+	return d.err
+}
+
+func (d *RouteDef[T]) Name() string {
+	return d.name
+}
+
+func (d *RouteDef[T]) Pattern() string {
+	return d.pattern
+}
+
+func (d *RouteDef[T]) Route(ctx *runtime.Ctx, props RouteProps, children ...work.Node) work.Node {
+	props.Path = d.pattern
+	return routeNode(props, d.routeDefinition, children)
+}
+
+func (d *RouteDef[T]) Href(params T) string {
+	if d.err != nil && d.fields == nil {
+		return d.pattern
+	}
+	value := reflect.ValueOf(params)
+
+	segments := strings.Split(strings.Trim(d.pattern, "/"), "/")
+	for i, seg := range segments {
+		name, ok := patternParam(seg)
+		if !ok {
+			continue
+		}
+		if name == "" {
+			segments[i] = ""
+			continue
+		}
+
+		field := value.Field(d.fields[name])
+		if param, _ := route.ParseSegment(seg); param.Optional && field.IsZero() {
+			segments[i] = ""
+			continue
+		}
+
+		raw := formatParam(field)
+		if strings.HasPrefix(seg, "*") {
+			parts := strings.Split(strings.Trim(raw, "/"), "/")
+			for j, part := range parts {
+				parts[j] = url.PathEscape(part)
+			}
+			segments[i] = strings.Join(parts, "/")
+			continue
+		}
+		segments[i] = url.PathEscape(raw)
+	}
+
+	return normalizePath("/" + strings.Join(segments, "/"))
+}
+
+func (d *RouteDef[T]) Decode(m Match) (T, error) {
+	var params T
+	if d.err != nil {
+		return params, d.err
+	}
+	value := reflect.ValueOf(&params).Elem()
+
+	for name, index := range d.fields {
+		raw, err := m.Param(name)
+		if err != nil {
+			return params, fmt.Errorf("router: %s: %w: %s", d.name, err, name)
+		}
+		if err := parseParam(value.Field(index), raw); err != nil {
+			return params, fmt.Errorf("router: %s: param %s: %w", d.name, name, err)
+		}
+	}
+
+	return params, nil
+}
+
+// register records def the first time the router renders it, remembering
+// why it is invalid or which earlier definition it conflicts with.
+func (r *routeRegistry) register(def *routeDefinition) error {
+	if def == nil {
+		return nil
+	}
+	if r == nil {
+		return def.err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err, ok := r.errs[def]; ok {
+		return err
+	}
+
+	err := def.err
+	if err == nil {
+		if existing, ok := r.trie.conflict(def.pattern); ok {
+			err = fmt.Errorf("router: route %q conflicts with %q", def.pattern, existing)
+		} else if other, ok := r.names[def.name]; ok {
+			err = fmt.Errorf("router: route name %q already used by %q", def.name, other.pattern)
+		} else {
+			r.trie.Insert(def.pattern, routeEntry{pattern: def.pattern, fullPath: def.pattern})
+			r.names[def.name] = def
+		}
+	}
+	r.errs[def] = err
+	return err
+}
+
+// check registers the definitions behind slots and joins their errors with
+// those of any route pattern that does not compile.
+func (r *routeRegistry) check(slots []slotEntry) error {
+	var errs []error
+	for _, slot := range slots {
+		for _, entry := range slot.routes {
+			if err := route.CheckPattern(entry.fullPath); err != nil {
+				errs = append(errs, err)
+			} else if err := r.register(entry.def); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func patternParam(seg string) (string, bool) {
+	if param, ok := route.ParseSegment(seg); ok {
+		return param.Name, true
+	}
+	if strings.HasPrefix(seg, "*") {
+		return strings.TrimPrefix(seg, "*"), true
+	}
+	return "", false
+}
+
+func routeParamFields(t reflect.Type, pattern string) (map[string]int, error) {
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("router: route %q params must be a struct, got %s", pattern, t)
+	}
+
+	byName := make(map[string]int)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := field.Tag.Get("param")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		if !supportedParamKind(field.Type.Kind()) {
+			return nil, fmt.Errorf("router: route %q field %s has unsupported type %s", pattern, field.Name, field.Type)
+		}
+		byName[name] = i
+	}
+
+	fields := make(map[string]int)
+	for _, seg := range strings.Split(strings.Trim(pattern, "/"), "/") {
+		name, ok := patternParam(seg)
+		if !ok || name == "" {
+			continue
+		}
+		index, ok := byName[strings.ToLower(name)]
+		if !ok {
+			index, ok = byName[name]
+		}
+		if !ok {
+			return nil, fmt.Errorf("router: route %q param %q has no field in %s", pattern, name, t)
+		}
+		fields[name] = index
+	}
+
+	for name, index := range byName {
+		found := false
+		for _, used := range fields {
+			if used == index {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("router: route %q has no param for field %s (%q)", pattern, t.Field(index).Name, name)
+		}
+	}
+
+	return fields, nil
+}
+
+func supportedParamKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+func formatParam(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, v.Type().Bits())
+	}
+	return ""
+}
+
+func parseParam(v reflect.Value, raw string) error {
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(raw, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+package router
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/eleven-am/pondlive/internal/runtime"
+	"github.com/eleven-am/pondlive/internal/work"
+)
+
+type userPostParams struct {
+	User string
+	Post int
+}
+
+func TestDefineRouteHrefAndDecode(t *testing.T) {
+	def := DefineRoute[userPostParams]("/users/:user/posts/:post")
+	if err := def.Err(); err != nil {
+		t.Fatalf("define failed: %v", err)
+	}
+
+	href := def.Href(userPostParams{User: "ada lovelace", Post: 7})
+	if href != "/users/ada%20lovelace/posts/7" {
+		t.Errorf("unexpected href %q", href)
+	}
+
+	params, err := def.Decode(Match{params: map[string]string{"user": "ada", "post": "7"}})
+	if err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	if params.User != "ada" || params.Post != 7 {
+		t.Errorf("unexpected params %+v", params)
+	}
+
+	if _, err := def.Decode(Match{params: map[string]string{"user": "ada", "post": "x"}}); err == nil {
+		t.Error("expected invalid int param to fail")
+	}
+}
+
+func TestDefineRouteWildcardAndTags(t *testing.T) {
+	type fileParams struct {
+		Bucket string `param:"bucket_id"`
+		Path   string
+	}
+
+	def := DefineRoute[fileParams]("/files/:bucket_id/*path")
+	if err := def.Err(); err != nil {
+		t.Fatalf("define failed: %v", err)
+	}
+
+	if href := def.Href(fileParams{Bucket: "b1", Path: "docs/a b.txt"}); href != "/files/b1/docs/a%20b.txt" {
+		t.Errorf("unexpected href %q", href)
+	}
+}
+
+func TestDefineRouteRejectsInvalidDefinitions(t *testing.T) {
+	registry := newRouteRegistry()
+	if err := registry.register(DefineRoute[userPostParams]("/users/:user/posts/:post").routeDefinition); err != nil {
+		t.Fatalf("define failed: %v", err)
+	}
+
+	type idParams struct{ ID string }
+	type userParams struct{ User string }
+
+	cases := map[string]*routeDefinition{
+		"duplicate pattern":      DefineRoute[userPostParams]("/users/:user/posts/:post/").routeDefinition,
+		"conflicting param name": DefineRoute[idParams]("/users/:id").routeDefinition,
+		"missing field":          DefineRoute[userParams]("/teams/:team").routeDefinition,
+		"unused field":           DefineRoute[userParams]("/teams").routeDefinition,
+	}
+
+	for name, def := range cases {
+		if err := registry.register(def); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+
+	if err := registry.register(DefineRoute[userParams]("/users/:user").routeDefinition); err != nil {
+		t.Errorf("expected sibling route to be allowed: %v", err)
+	}
+
+	post := DefineRoute[idParams]("/posts/:id").Named("post")
+	if err := registry.register(post.routeDefinition); err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.register(post.routeDefinition); err != nil {
+		t.Errorf("expected registering the same definition twice to be allowed: %v", err)
+	}
+	other := DefineRoute[idParams]("/articles/:id").Named("post")
+	if err := registry.register(other.routeDefinition); err == nil || !strings.Contains(err.Error(), "already used") {
+		t.Errorf("expected duplicate name error, got %v", err)
+	}
+}
+
+func TestDefineRouteReportsConflictWithStatus(t *testing.T) {
+	type idParams struct{ ID string }
+
+	first := DefineRoute[idParams]("/items/:id")
+	dup := DefineRoute[idParams]("/items/:id/")
+	if first.Err() != nil || dup.Err() != nil {
+		t.Fatal("expected conflicts to be found by the router, not the definition")
+	}
+
+	rendered := false
+	component := func(ctx *runtime.Ctx, _ Match) work.Node {
+		rendered = true
+		return &work.Text{Value: "item"}
+	}
+	sess, requestState := guardTestSession("/items/1", func(ctx *runtime.Ctx) work.Node {
+		return Routes(ctx,
+			first.Route(ctx, RouteProps{Component: component}),
+			dup.Route(ctx, RouteProps{Component: component}),
+		)
+	})
+
+	if err := sess.Flush(); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+	if rendered {
+		t.Error("expected routes not to render with conflicting definitions")
+	}
+	if status := requestState.Status(); status != http.StatusInternalServerError {
+		t.Errorf("expected status 500, got %d", status)
+	}
+}
+
+func TestDefineRouteRegistryIsPerRouter(t *testing.T) {
+	type idParams struct{ ID string }
+
+	first := DefineRoute[idParams]("/items/:id")
+	second := DefineRoute[idParams]("/items/:id")
+
+	for _, def := range []*RouteDef[idParams]{first, second} {
+		rendered := false
+		sess, requestState := guardTestSession("/items/1", func(ctx *runtime.Ctx) work.Node {
+			return Routes(ctx, def.Route(ctx, RouteProps{
+				Component: func(ctx *runtime.Ctx, _ Match) work.Node {
+					rendered = true
+					return &work.Text{Value: "item"}
+				},
+			}))
+		})
+
+		if err := sess.Flush(); err != nil {
+			t.Fatalf("flush failed: %v", err)
+		}
+		if !rendered {
+			t.Errorf("expected %s to render in its own router", def.Pattern())
+		}
+		if status := requestState.Status(); status == http.StatusInternalServerError {
+			t.Errorf("expected no conflict across routers, got status %d", status)
+		}
+	}
+}
+
+func TestRoutesReportsInvalidConstraintWithStatus(t *testing.T) {
+	rendered := false
+	sess, requestState := guardTestSession("/posts/1", func(ctx *runtime.Ctx) work.Node {
+		return Routes(ctx, Route(ctx, RouteProps{
+			Path: "/posts/:id([0-9+)",
+			Component: func(ctx *runtime.Ctx, _ Match) work.Node {
+				rendered = true
+				return &work.Text{Value: "post"}
+			},
+		}))
+	})
+
+	if err := sess.Flush(); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+	if rendered {
+		t.Error("expected routes not to render with an invalid constraint")
+	}
+	if status := requestState.Status(); status != http.StatusInternalServerError {
+		t.Errorf("expected status 500, got %d", status)
+	}
+
+	type idParams struct{ ID string }
+	if err := DefineRoute[idParams]("/posts/:id([a-z]/[0-9])").Err(); err == nil || !strings.Contains(err.Error(), "may not contain") {
+		t.Errorf("expected a constraint containing / to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,50 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/eleven-am/pondlive/internal/headers"
+	"github.com/eleven-am/pondlive/internal/runtime"
+	"github.com/eleven-am/pondlive/internal/work"
+)
+
+func runGuards(ctx *runtime.Ctx, guards []func(*runtime.Ctx, Match) GuardResult, match Match) GuardResult {
+	var result GuardResult
+	for _, guard := range guards {
+		if guard == nil {
+			continue
+		}
+		if r := guard(ctx, match); result.Allowed() && !r.Allowed() {
+			result = r
+		}
+	}
+	return result
+}
+
+var guardRedirect = runtime.PropsComponent(func(ctx *runtime.Ctx, result GuardResult, _ []work.Item) work.Node {
+	requestState := headers.UseRequestState(ctx)
+	isLive := requestState != nil && requestState.IsLive()
+
+	status := result.Status
+	if status == 0 {
+		status = http.StatusFound
+	}
+
+	if !isLive {
+		if requestState != nil {
+			setSSRRedirect(requestState, result.Redirect, status)
+		}
+		return &work.Fragment{}
+	}
+
+	runtime.UseEffect(ctx, func() func() {
+		Replace(ctx, result.Redirect)
+		return nil
+	}, result.Redirect)
+
+	return &work.Fragment{}
+})
+
+var guardFallback = runtime.PropsComponent(func(ctx *runtime.Ctx, fallback func(*runtime.Ctx) work.Node, _ []work.Item) work.Node {
+	return fallback(ctx)
+})
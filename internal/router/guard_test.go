@@ -0,0 +1,224 @@
+package router
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/eleven-am/pondlive/internal/headers"
+	"github.com/eleven-am/pondlive/internal/metatags"
+	"github.com/eleven-am/pondlive/internal/protocol"
+	"github.com/eleven-am/pondlive/internal/runtime"
+	"github.com/eleven-am/pondlive/internal/work"
+)
+
+func guardTestSession(path string, render func(ctx *runtime.Ctx) work.Node) (*runtime.Session, *headers.RequestState) {
+	requestState := headers.NewRequestState(&headers.RequestInfo{
+		Method: "GET",
+		Path:   path,
+		Query:  url.Values{},
+	})
+	return requestTestSession(requestState, render), requestState
+}
+
+func requestTestSession(requestState *headers.RequestState, render func(ctx *runtime.Ctx) work.Node) *runtime.Session {
+	root := &runtime.Instance{
+		ID: "root",
+		Fn: func(ctx *runtime.Ctx, _ any, _ []work.Item) work.Node {
+			return headers.Provider(ctx, requestState,
+				Provide(ctx, work.Component(func(ctx *runtime.Ctx, _ any, _ []work.Item) work.Node {
+					return render(ctx)
+				})),
+			)
+		},
+	}
+
+	sess := &runtime.Session{
+		Root:              root,
+		Components:        map[string]*runtime.Instance{"root": root},
+		Handlers:          make(map[string]work.Handler),
+		MountedComponents: make(map[*runtime.Instance]struct{}),
+		Bus:               protocol.NewBus(),
+	}
+	return sess
+}
+
+func requireLogin(loggedIn *bool) func(*runtime.Ctx, Match) GuardResult {
+	return func(ctx *runtime.Ctx, m Match) GuardResult {
+		if *loggedIn {
+			return Allow()
+		}
+		return GuardRedirect("/login?next="+url.QueryEscape(m.Path), http.StatusSeeOther)
+	}
+}
+
+func TestRouteGuardRedirectsDuringSSR(t *testing.T) {
+	loggedIn := false
+	rendered := false
+
+	sess, requestState := guardTestSession("/admin", func(ctx *runtime.Ctx) work.Node {
+		return Routes(ctx,
+			Route(ctx, RouteProps{
+				Path:   "/admin",
+				Guards: []func(*runtime.Ctx, Match) GuardResult{requireLogin(&loggedIn)},
+				Component: func(ctx *runtime.Ctx, _ Match) work.Node {
+					rendered = true
+					return &work.Text{Value: "secret"}
+				},
+			}),
+		)
+	})
+
+	if err := sess.Flush(); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+
+	if rendered {
+		t.Error("expected protected component not to render")
+	}
+
+	location, status, ok := requestState.Redirect()
+	if !ok {
+		t.Fatal("expected guard to set a redirect")
+	}
+	if location != "/login?next=%2Fadmin" || status != http.StatusSeeOther {
+		t.Errorf("unexpected redirect %q (%d)", location, status)
+	}
+}
+
+func TestRouteGuardAllowsAndProtectsNestedRoutes(t *testing.T) {
+	loggedIn := true
+	var rendered []string
+
+	app := func(ctx *runtime.Ctx) work.Node {
+		return Routes(ctx,
+			Route(ctx, RouteProps{
+				Path:   "/admin",
+				Guards: []func(*runtime.Ctx, Match) GuardResult{requireLogin(&loggedIn)},
+				Component: func(ctx *runtime.Ctx, _ Match) work.Node {
+					rendered = append(rendered, "layout")
+					return Outlet(ctx)
+				},
+			},
+				Route(ctx, RouteProps{
+					Path: "/users",
+					Component: func(ctx *runtime.Ctx, _ Match) work.Node {
+						rendered = append(rendered, "users")
+						return &work.Text{Value: "users"}
+					},
+				}),
+			),
+		)
+	}
+
+	sess, requestState := guardTestSession("/admin/users", app)
+	if err := sess.Flush(); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+
+	if len(rendered) != 2 || rendered[0] != "layout" || rendered[1] != "users" {
+		t.Errorf("expected layout and nested route to render, got %v", rendered)
+	}
+	if _, _, ok := requestState.Redirect(); ok {
+		t.Error("expected no redirect for allowed request")
+	}
+
+	loggedIn = false
+	rendered = nil
+	sess, requestState = guardTestSession("/admin/users", app)
+	if err := sess.Flush(); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+
+	if len(rendered) != 0 {
+		t.Errorf("expected nested route to inherit the parent guard, got %v", rendered)
+	}
+	if location, _, ok := requestState.Redirect(); !ok || location != "/login?next=%2Fadmin%2Fusers" {
+		t.Errorf("unexpected redirect %q", location)
+	}
+}
+
+func TestRouteGuardRendersFallback(t *testing.T) {
+	var guardCalls int
+	var text string
+
+	sess, requestState := guardTestSession("/billing", func(ctx *runtime.Ctx) work.Node {
+		return Routes(ctx,
+			Route(ctx, RouteProps{
+				Path: "/billing",
+				Guards: []func(*runtime.Ctx, Match) GuardResult{
+					func(ctx *runtime.Ctx, _ Match) GuardResult {
+						guardCalls++
+						return GuardFallback(func(ctx *runtime.Ctx) work.Node {
+							text = "upgrade required"
+							return &work.Text{Value: text}
+						})
+					},
+					func(ctx *runtime.Ctx, _ Match) GuardResult {
+						guardCalls++
+						return GuardRedirect("/elsewhere", 0)
+					},
+				},
+				Component: func(ctx *runtime.Ctx, _ Match) work.Node {
+					text = "billing"
+					return &work.Text{Value: text}
+				},
+			}),
+		)
+	})
+
+	if err := sess.Flush(); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+
+	if text != "upgrade required" {
+		t.Errorf("expected fallback to render, got %q", text)
+	}
+	if guardCalls != 2 {
+		t.Errorf("expected every guard to run, got %d calls", guardCalls)
+	}
+	if _, _, ok := requestState.Redirect(); ok {
+		t.Error("expected first failing guard to win")
+	}
+}
+
+func TestDeniedRouteDoesNotBuildMeta(t *testing.T) {
+	loggedIn := false
+	metaCalls := 0
+	var matches []RouteMatch
+
+	sess, _ := guardTestSession("/account", func(ctx *runtime.Ctx) work.Node {
+		return Routes(ctx,
+			Route(ctx, RouteProps{
+				Path: "/account",
+				Guards: []func(*runtime.Ctx, Match) GuardResult{
+					func(ctx *runtime.Ctx, _ Match) GuardResult {
+						if loggedIn {
+							return Allow()
+						}
+						return GuardFallback(func(ctx *runtime.Ctx) work.Node {
+							matches = UseMatches(ctx)
+							return nil
+						})
+					},
+				},
+				Meta: func(Match) *metatags.Meta {
+					metaCalls++
+					return &metatags.Meta{Title: "Account of a signed-in user"}
+				},
+				Component: func(ctx *runtime.Ctx, _ Match) work.Node { return nil },
+			}),
+		)
+	})
+
+	if err := sess.Flush(); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+
+	if metaCalls != 0 {
+		t.Errorf("expected meta not to be built for a denied route, got %d calls", metaCalls)
+	}
+	if len(matches) != 1 || matches[0].Meta != nil {
+		t.Errorf("expected the fallback to see the route without meta, got %+v", matches)
+	}
+}
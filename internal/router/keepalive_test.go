@@ -0,0 +1,118 @@
+package router
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/eleven-am/pondlive/internal/runtime"
+	"github.com/eleven-am/pondlive/internal/view"
+	"github.com/eleven-am/pondlive/internal/work"
+)
+
+func TestKeepAliveRoutesKeepStateAcrossNavigation(t *testing.T) {
+	for _, keepAlive := range []bool{true, false} {
+		t.Run(fmt.Sprintf("keepAlive=%v", keepAlive), func(t *testing.T) {
+			var mu sync.Mutex
+			var nav func(string)
+			var setCount func(int)
+			mounts := map[string]int{}
+
+			counter := func(name string) func(*runtime.Ctx, Match) work.Node {
+				return func(ctx *runtime.Ctx, _ Match) work.Node {
+					count, set := runtime.UseState(ctx, 0)
+					runtime.UseEffect(ctx, func() func() {
+						mu.Lock()
+						mounts[name]++
+						mu.Unlock()
+						return nil
+					}, 0)
+					mu.Lock()
+					if name == "a" {
+						setCount = set
+					}
+					mu.Unlock()
+					return &work.Text{Value: fmt.Sprintf("%s:%d", name, count)}
+				}
+			}
+			pageA, pageB := counter("a"), counter("b")
+
+			sess, requestState := guardTestSession("/a", func(ctx *runtime.Ctx) work.Node {
+				mu.Lock()
+				nav = func(href string) { Navigate(ctx, href) }
+				mu.Unlock()
+				return Routes(ctx,
+					Route(ctx, RouteProps{Path: "/a", KeepAlive: keepAlive, Component: pageA}),
+					Route(ctx, RouteProps{Path: "/b", KeepAlive: keepAlive, Component: pageB}),
+				)
+			})
+			requestState.SetIsLive(true)
+
+			snapshot := func() string {
+				mu.Lock()
+				defer mu.Unlock()
+				return viewText(sess.View)
+			}
+			navigate := func(href, want string) {
+				mu.Lock()
+				to := nav
+				mu.Unlock()
+				to(href)
+				waitFor(t, func() bool {
+					_ = sess.Flush()
+					return snapshot() == want
+				})
+			}
+
+			if err := sess.Flush(); err != nil {
+				t.Fatalf("flush failed: %v", err)
+			}
+			mu.Lock()
+			set := setCount
+			mu.Unlock()
+			set(3)
+			if err := sess.Flush(); err != nil {
+				t.Fatalf("flush failed: %v", err)
+			}
+
+			navigate("/b", "b:0")
+			want := "a:0"
+			if keepAlive {
+				want = "a:3"
+			}
+			navigate("/a", want)
+			navigate("/b", "b:0")
+			navigate("/a", want)
+
+			mu.Lock()
+			defer mu.Unlock()
+			wantMounts := 3
+			if keepAlive {
+				wantMounts = 1
+			}
+			if mounts["a"] != wantMounts {
+				t.Errorf("expected route a to mount %d times, got %d", wantMounts, mounts["a"])
+			}
+		})
+	}
+}
+
+func viewText(n view.Node) string {
+	switch n := n.(type) {
+	case *view.Text:
+		return n.Text
+	case *view.Element:
+		var out string
+		for _, child := range n.Children {
+			out += viewText(child)
+		}
+		return out
+	case *view.Fragment:
+		var out string
+		for _, child := range n.Children {
+			out += viewText(child)
+		}
+		return out
+	}
+	return ""
+}
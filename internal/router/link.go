@@ -16,18 +16,14 @@ var Link = runtime.PropsComponent(func(ctx *runtime.Ctx, props LinkProps, childr
 	}
 
 	target := resolveHref(base, props.To)
-	href := buildHref(target.Path, target.Query, target.Hash)
+	href := ctx.BasePath() + buildHref(target.Path, target.Query, target.Hash)
 
 	clickHandler := work.Handler{
 		EventOptions: metadata.EventOptions{
 			Prevent: true,
 		},
 		Fn: func(e work.Event) work.Updates {
-			if props.Replace {
-				Replace(ctx, props.To)
-			} else {
-				Navigate(ctx, props.To)
-			}
+			navigate(ctx, props.To, props.Replace, navOptions{scroll: props.Scroll, focus: props.Focus})
 			return nil
 		},
 	}
@@ -53,7 +49,7 @@ var NavLink = runtime.PropsComponent(func(ctx *runtime.Ctx, props NavLinkProps,
 	}
 
 	target := resolveHref(base, props.To)
-	href := buildHref(target.Path, target.Query, target.Hash)
+	href := ctx.BasePath() + buildHref(target.Path, target.Query, target.Hash)
 
 	isActive := false
 	if props.End {
@@ -87,11 +83,7 @@ var NavLink = runtime.PropsComponent(func(ctx *runtime.Ctx, props NavLinkProps,
 			Prevent: true,
 		},
 		Fn: func(e work.Event) work.Updates {
-			if props.Replace {
-				Replace(ctx, props.To)
-			} else {
-				Navigate(ctx, props.To)
-			}
+			navigate(ctx, props.To, props.Replace, navOptions{scroll: props.Scroll, focus: props.Focus})
 			return nil
 		},
 	}
@@ -0,0 +1,44 @@
+package router
+
+import (
+	"testing"
+
+	"github.com/eleven-am/pondlive/internal/runtime"
+	"github.com/eleven-am/pondlive/internal/view"
+	"github.com/eleven-am/pondlive/internal/work"
+)
+
+func TestLinkHrefIncludesBasePath(t *testing.T) {
+	sess, _ := guardTestSession("/users", func(ctx *runtime.Ctx) work.Node {
+		return Link(ctx, LinkProps{To: "/users/42?tab=info"}, &work.Text{Value: "profile"})
+	})
+	sess.SetBasePath("/admin")
+
+	if err := sess.Flush(); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+
+	var href string
+	var find func(n view.Node)
+	find = func(n view.Node) {
+		switch node := n.(type) {
+		case *view.Element:
+			if node.Tag == "a" {
+				href = node.Attrs["href"][0]
+				return
+			}
+			for _, child := range node.Children {
+				find(child)
+			}
+		case *view.Fragment:
+			for _, child := range node.Children {
+				find(child)
+			}
+		}
+	}
+	find(sess.View)
+
+	if href != "/admin/users/42?tab=info" {
+		t.Errorf("expected mounted href, got %q", href)
+	}
+}
@@ -0,0 +1,406 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/eleven-am/pondlive/internal/runtime"
+)
+
+type loaderResult struct {
+	key    string
+	locKey string
+	done   chan struct{}
+	cancel context.CancelFunc
+	state  loaderState
+}
+
+func (r *loaderResult) wait() *loaderState {
+	<-r.done
+	return &r.state
+}
+
+func (r *loaderResult) settled() (*loaderState, bool) {
+	select {
+	case <-r.done:
+		return &r.state, true
+	default:
+		return nil, false
+	}
+}
+
+type loaderState struct {
+	data    any
+	err     error
+	pending bool
+}
+
+type matchedRoute struct {
+	entry routeEntry
+	match Match
+}
+
+type loaderStore struct {
+	mu         sync.Mutex
+	ctx        context.Context
+	stop       context.CancelFunc
+	results    map[string]*loaderResult
+	tables     map[string][]slotEntry
+	generation int
+	cancel     context.CancelFunc
+	setPending func(bool)
+	rerender   func()
+	ready      func()
+	readyGen   int
+	tries      *trieCache
+}
+
+var loaderStoreCtx = runtime.CreateContext[*loaderStore](nil)
+var loaderDataCtx = runtime.CreateContext[*loaderState](nil)
+var pendingCtx = runtime.CreateContext[bool](false)
+
+func newLoaderStore() *loaderStore {
+	ctx, stop := context.WithCancel(context.Background())
+	return &loaderStore{
+		ctx:     ctx,
+		stop:    stop,
+		results: make(map[string]*loaderResult),
+		tables:  make(map[string][]slotEntry),
+	}
+}
+
+func locationKey(loc Location) string {
+	return loc.Path + "?" + loc.Query.Encode()
+}
+
+func loaderKey(fullPath string, loc Location) string {
+	return fullPath + "\x00" + locationKey(loc)
+}
+
+func runLoader(parent context.Context, route matchedRoute, loc Location) *loaderResult {
+	ctx, cancel := context.WithCancel(parent)
+	result := &loaderResult{
+		key:    loaderKey(route.entry.fullPath, loc),
+		locKey: locationKey(loc),
+		done:   make(chan struct{}),
+		cancel: cancel,
+	}
+
+	go func() {
+		defer close(result.done)
+		defer cancel()
+		defer func() {
+			if r := recover(); r != nil {
+				result.state.err = runtime.NewError(runtime.ErrCodeApp, fmt.Sprintf("router: loader panic: %v", r))
+			}
+		}()
+		result.state.data, result.state.err = route.entry.loader(ctx, route.match)
+	}()
+
+	return result
+}
+
+// start runs the chain's loaders that have no result yet under parent.
+func (s *loaderStore) start(parent context.Context, chain []matchedRoute, loc Location) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, route := range chain {
+		if route.entry.loader == nil {
+			continue
+		}
+		key := loaderKey(route.entry.fullPath, loc)
+		if _, ok := s.results[key]; ok {
+			continue
+		}
+		s.results[key] = runLoader(parent, route, loc)
+	}
+}
+
+func (s *loaderStore) result(fullPath string, loc Location) *loaderResult {
+	if s == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.results[loaderKey(fullPath, loc)]
+}
+
+func (s *loaderStore) prune(loc Location) {
+	if s == nil {
+		return
+	}
+
+	current := locationKey(loc)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, result := range s.results {
+		if result.locKey != current {
+			result.cancel()
+			delete(s.results, key)
+		}
+	}
+}
+
+// drop cancels the loader stored under key if it is still running, so the
+// next route to ask for it starts over.
+func (s *loaderStore) drop(key string) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if result, ok := s.results[key]; ok {
+		if _, settled := result.settled(); !settled {
+			result.cancel()
+			delete(s.results, key)
+		}
+	}
+}
+
+func (s *loaderStore) close() {
+	s.abort()
+	s.stop()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, result := range s.results {
+		result.cancel()
+		delete(s.results, key)
+	}
+}
+
+func (s *loaderStore) register(id string, slots []slotEntry) func() {
+	if s == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	s.tables[id] = slots
+	s.mu.Unlock()
+
+	return func() {
+		s.mu.Lock()
+		delete(s.tables, id)
+		s.mu.Unlock()
+	}
+}
+
+func (s *loaderStore) abort() {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	cancelled := s.cancel != nil
+	s.generation++
+	s.ready = nil
+	if s.cancel != nil {
+		s.cancel()
+		s.cancel = nil
+	}
+	setPending := s.setPending
+	s.mu.Unlock()
+
+	if cancelled && setPending != nil {
+		setPending(false)
+	}
+}
+
+func (s *loaderStore) navigate(target Location, commit func()) bool {
+	if s == nil {
+		return false
+	}
+
+	s.mu.Lock()
+	s.generation++
+	s.ready = nil
+	generation := s.generation
+	wasPending := s.cancel != nil
+	if s.cancel != nil {
+		s.cancel()
+		s.cancel = nil
+	}
+	setPending := s.setPending
+
+	var chain []matchedRoute
+	for _, slots := range s.tables {
+		for _, slot := range slots {
+			chain = append(chain, matchRouteChain(s.tries, slot.routes, target.Path, target)...)
+		}
+	}
+
+	loadCtx, cancel := context.WithCancel(s.ctx)
+	pending := make(map[string]*loaderResult)
+	for _, route := range chain {
+		if route.entry.loader == nil {
+			continue
+		}
+		key := loaderKey(route.entry.fullPath, target)
+		if _, ok := s.results[key]; ok {
+			continue
+		}
+		pending[key] = runLoader(loadCtx, route, target)
+	}
+
+	if len(pending) == 0 {
+		s.mu.Unlock()
+		cancel()
+		if wasPending && setPending != nil {
+			setPending(false)
+		}
+		return false
+	}
+
+	s.cancel = cancel
+	s.mu.Unlock()
+
+	if setPending != nil {
+		setPending(true)
+	}
+
+	go func() {
+		for _, result := range pending {
+			<-result.done
+		}
+
+		s.mu.Lock()
+		if generation != s.generation {
+			s.mu.Unlock()
+			return
+		}
+		for key, result := range pending {
+			s.results[key] = result
+		}
+		s.cancel = nil
+		s.ready = func() {
+			cancel()
+			commit()
+			if setPending != nil {
+				setPending(false)
+			}
+		}
+		s.readyGen = generation
+		rerender := s.rerender
+		s.mu.Unlock()
+
+		if rerender != nil {
+			rerender()
+		} else {
+			s.commitReady()
+		}
+	}()
+
+	return true
+}
+
+// readyGeneration changes each time a navigation's loaders have all settled,
+// so the provider can commit it from an effect.
+func (s *loaderStore) readyGeneration() int {
+	if s == nil {
+		return 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.readyGen
+}
+
+// commitReady commits the navigation whose loaders settled, unless another
+// navigation has superseded it since.
+func (s *loaderStore) commitReady() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	ready := s.ready
+	s.ready = nil
+	if s.readyGen != s.generation {
+		ready = nil
+	}
+	s.mu.Unlock()
+
+	if ready != nil {
+		ready()
+	}
+}
+
+func matchRouteChain(tries *trieCache, entries []routeEntry, pathToMatch string, loc Location) []matchedRoute {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	result := matchRoutes(tries.compile(entries), entries, pathToMatch)
+	if result == nil || result.Entry == nil || len(result.Entry.guards) > 0 {
+		return nil
+	}
+
+	entry := *result.Entry
+	chain := []matchedRoute{{entry: entry, match: buildMatch(entry, result, loc)}}
+	return append(chain, descendantRouteChain(tries, entry, result.Rest, loc)...)
+}
+
+func descendantRouteChain(tries *trieCache, entry routeEntry, rest string, loc Location) []matchedRoute {
+	if len(entry.children) == 0 {
+		return nil
+	}
+
+	pathToMatch := rest
+	if pathToMatch == "" {
+		pathToMatch = loc.Path
+	}
+
+	children := collectRouteEntries(entry.children, trimWildcardSuffix(entry.fullPath))
+	return matchRouteChain(tries, children, pathToMatch, loc)
+}
+
+func (m Match) location() Location {
+	return Location{Path: m.Path, Query: m.query, Hash: m.Hash}
+}
+
+func buildMatch(entry routeEntry, result *matchResult, loc Location) Match {
+	return Match{
+		Pattern:  entry.fullPath,
+		Path:     loc.Path,
+		params:   result.Params,
+		query:    loc.Query,
+		RawQuery: loc.Query.Encode(),
+		Hash:     loc.Hash,
+		Rest:     result.Rest,
+	}
+}
+
+func UseLoaderData[T any](ctx *runtime.Ctx) T {
+	var zero T
+	state := loaderDataCtx.UseContextValue(ctx)
+	if state == nil {
+		return zero
+	}
+	if data, ok := state.data.(T); ok {
+		return data
+	}
+	return zero
+}
+
+func UseLoaderError(ctx *runtime.Ctx) error {
+	state := loaderDataCtx.UseContextValue(ctx)
+	if state == nil {
+		return nil
+	}
+	return state.err
+}
+
+// UseNavigationPending reports whether a navigation is waiting for loaders, or
+// the enclosing route is still rendering without its own loader's data.
+func UseNavigationPending(ctx *runtime.Ctx) bool {
+	state := loaderDataCtx.UseContextValue(ctx)
+	navigating := pendingCtx.UseContextValue(ctx)
+	return navigating || (state != nil && state.pending)
+}
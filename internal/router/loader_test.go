@@ -0,0 +1,405 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/eleven-am/pondlive/internal/headers"
+	"github.com/eleven-am/pondlive/internal/protocol"
+	"github.com/eleven-am/pondlive/internal/runtime"
+	"github.com/eleven-am/pondlive/internal/work"
+)
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestLoaderDataAvailableDuringSSR(t *testing.T) {
+	var rendered string
+
+	sess, _ := guardTestSession("/users/42", func(ctx *runtime.Ctx) work.Node {
+		return Routes(ctx,
+			Route(ctx, RouteProps{
+				Path: "/users/:id",
+				Loader: func(_ context.Context, m Match) (any, error) {
+					id, _ := m.Param("id")
+					return "user " + id, nil
+				},
+				Component: func(ctx *runtime.Ctx, _ Match) work.Node {
+					rendered = UseLoaderData[string](ctx)
+					return &work.Text{Value: rendered}
+				},
+			}),
+		)
+	})
+
+	if err := sess.Flush(); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+
+	if rendered != "user 42" {
+		t.Errorf("expected loader data in first render, got %q", rendered)
+	}
+}
+
+func TestLoaderErrorIsExposed(t *testing.T) {
+	var loaderErr error
+
+	sess, _ := guardTestSession("/broken", func(ctx *runtime.Ctx) work.Node {
+		return Routes(ctx,
+			Route(ctx, RouteProps{
+				Path: "/broken",
+				Loader: func(context.Context, Match) (any, error) {
+					return nil, errors.New("boom")
+				},
+				Component: func(ctx *runtime.Ctx, _ Match) work.Node {
+					loaderErr = UseLoaderError(ctx)
+					return nil
+				},
+			}),
+		)
+	})
+
+	if err := sess.Flush(); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+
+	if loaderErr == nil || loaderErr.Error() != "boom" {
+		t.Errorf("expected loader error, got %v", loaderErr)
+	}
+}
+
+func TestNestedLoadersRunInParallel(t *testing.T) {
+	childStarted := make(chan struct{})
+	var layoutData, pageData string
+
+	sess, _ := guardTestSession("/projects/7/settings", func(ctx *runtime.Ctx) work.Node {
+		return Routes(ctx,
+			Route(ctx, RouteProps{
+				Path: "/projects/:id",
+				Loader: func(context.Context, Match) (any, error) {
+					select {
+					case <-childStarted:
+						return "layout", nil
+					case <-time.After(time.Second):
+						return nil, errors.New("child loader did not start in parallel")
+					}
+				},
+				Component: func(ctx *runtime.Ctx, _ Match) work.Node {
+					layoutData = UseLoaderData[string](ctx)
+					return Outlet(ctx)
+				},
+			},
+				Route(ctx, RouteProps{
+					Path: "/settings",
+					Loader: func(context.Context, Match) (any, error) {
+						close(childStarted)
+						return "settings", nil
+					},
+					Component: func(ctx *runtime.Ctx, _ Match) work.Node {
+						pageData = UseLoaderData[string](ctx)
+						return nil
+					},
+				}),
+			),
+		)
+	})
+
+	if err := sess.Flush(); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+
+	if layoutData != "layout" || pageData != "settings" {
+		t.Errorf("expected both loaders to resolve, got %q and %q", layoutData, pageData)
+	}
+}
+
+func TestLiveNavigationWaitsForLoaderAndCancelsSuperseded(t *testing.T) {
+	release := make(chan struct{})
+	var cancelled atomic.Bool
+
+	var mu sync.Mutex
+	var nav func(string)
+	var current string
+	var pending bool
+	var slowData string
+
+	snapshot := func() (string, bool, string) {
+		mu.Lock()
+		defer mu.Unlock()
+		return current, pending, slowData
+	}
+
+	sess, requestState := guardTestSession("/", func(ctx *runtime.Ctx) work.Node {
+		isPending := UseNavigationPending(ctx)
+		mu.Lock()
+		pending = isPending
+		nav = func(href string) { Navigate(ctx, href) }
+		mu.Unlock()
+
+		return Routes(ctx,
+			Route(ctx, RouteProps{
+				Path: "/",
+				Component: func(ctx *runtime.Ctx, _ Match) work.Node {
+					mu.Lock()
+					current = "home"
+					mu.Unlock()
+					return nil
+				},
+			}),
+			Route(ctx, RouteProps{
+				Path: "/slow",
+				Loader: func(ctx context.Context, _ Match) (any, error) {
+					select {
+					case <-release:
+						return "slow data", nil
+					case <-ctx.Done():
+						cancelled.Store(true)
+						return nil, ctx.Err()
+					}
+				},
+				Component: func(ctx *runtime.Ctx, _ Match) work.Node {
+					data := UseLoaderData[string](ctx)
+					mu.Lock()
+					current = "slow"
+					slowData = data
+					mu.Unlock()
+					return nil
+				},
+			}),
+			Route(ctx, RouteProps{
+				Path: "/other",
+				Component: func(ctx *runtime.Ctx, _ Match) work.Node {
+					mu.Lock()
+					current = "other"
+					mu.Unlock()
+					return nil
+				},
+			}),
+		)
+	})
+	requestState.SetIsLive(true)
+
+	if err := sess.Flush(); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+
+	mu.Lock()
+	navigateTo := nav
+	mu.Unlock()
+
+	navigateTo("/slow")
+	waitFor(t, func() bool {
+		_ = sess.Flush()
+		_, isPending, _ := snapshot()
+		return isPending
+	})
+	if page, _, _ := snapshot(); page != "home" {
+		t.Fatalf("expected old view to stay while loading, got %q", page)
+	}
+
+	navigateTo("/other")
+	waitFor(t, func() bool {
+		_ = sess.Flush()
+		page, isPending, _ := snapshot()
+		return page == "other" && !isPending
+	})
+	waitFor(t, cancelled.Load)
+
+	close(release)
+
+	navigateTo("/slow")
+	waitFor(t, func() bool {
+		_ = sess.Flush()
+		page, isPending, data := snapshot()
+		return page == "slow" && !isPending && data == "slow data"
+	})
+}
+
+func TestPopstateWaitsForLoaderBeforeCommitting(t *testing.T) {
+	release := make(chan struct{})
+
+	var mu sync.Mutex
+	var current, data string
+
+	snapshot := func() (string, string) {
+		mu.Lock()
+		defer mu.Unlock()
+		return current, data
+	}
+
+	sess, requestState := guardTestSession("/", func(ctx *runtime.Ctx) work.Node {
+		return Routes(ctx,
+			Route(ctx, RouteProps{
+				Path: "/",
+				Component: func(ctx *runtime.Ctx, _ Match) work.Node {
+					mu.Lock()
+					current = "home"
+					mu.Unlock()
+					return nil
+				},
+			}),
+			Route(ctx, RouteProps{
+				Path: "/slow",
+				Loader: func(context.Context, Match) (any, error) {
+					<-release
+					return "slow data", nil
+				},
+				Component: func(ctx *runtime.Ctx, _ Match) work.Node {
+					loaded := UseLoaderData[string](ctx)
+					mu.Lock()
+					current, data = "slow", loaded
+					mu.Unlock()
+					return nil
+				},
+			}),
+		)
+	})
+	requestState.SetIsLive(true)
+
+	if err := sess.Flush(); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+
+	sess.Bus.Publish(protocol.RouteHandler, string(protocol.RouterPopstateAction), protocol.RouterNavPayload{Path: "/slow"})
+	if err := sess.Flush(); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+	if page, _ := snapshot(); page != "home" {
+		t.Fatalf("expected popstate to wait for the loader, got %q", page)
+	}
+
+	close(release)
+	waitFor(t, func() bool {
+		_ = sess.Flush()
+		page, loaded := snapshot()
+		return page == "slow" && loaded == "slow data"
+	})
+}
+
+func TestLiveRenderShowsPendingLoaderWithoutBlocking(t *testing.T) {
+	release := make(chan struct{})
+	loggedIn := true
+	var cancelled atomic.Bool
+
+	var mu sync.Mutex
+	var pending bool
+	var data string
+	var nav func(string)
+
+	snapshot := func() (bool, string) {
+		mu.Lock()
+		defer mu.Unlock()
+		return pending, data
+	}
+
+	sess, requestState := guardTestSession("/", func(ctx *runtime.Ctx) work.Node {
+		mu.Lock()
+		nav = func(href string) { Navigate(ctx, href) }
+		mu.Unlock()
+
+		return Routes(ctx,
+			Route(ctx, RouteProps{
+				Path:      "/",
+				Component: func(*runtime.Ctx, Match) work.Node { return nil },
+			}),
+			Route(ctx, RouteProps{
+				Path:   "/account",
+				Guards: []func(*runtime.Ctx, Match) GuardResult{requireLogin(&loggedIn)},
+				Loader: func(ctx context.Context, _ Match) (any, error) {
+					select {
+					case <-release:
+						return "account", nil
+					case <-ctx.Done():
+						cancelled.Store(true)
+						return nil, ctx.Err()
+					}
+				},
+				Component: func(ctx *runtime.Ctx, _ Match) work.Node {
+					isPending, loaded := UseNavigationPending(ctx), UseLoaderData[string](ctx)
+					mu.Lock()
+					pending, data = isPending, loaded
+					mu.Unlock()
+					return nil
+				},
+			}),
+		)
+	})
+	requestState.SetIsLive(true)
+
+	if err := sess.Flush(); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+	mu.Lock()
+	navigateTo := nav
+	mu.Unlock()
+
+	navigateTo("/account")
+	if err := sess.Flush(); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+	if isPending, _ := snapshot(); !isPending {
+		t.Fatal("expected the guarded route to render its pending state")
+	}
+
+	navigateTo("/")
+	if err := sess.Flush(); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+	waitFor(t, cancelled.Load)
+
+	close(release)
+	navigateTo("/account")
+	waitFor(t, func() bool {
+		_ = sess.Flush()
+		isPending, loaded := snapshot()
+		return !isPending && loaded == "account"
+	})
+}
+
+func TestSSRLoaderStopsWithRequest(t *testing.T) {
+	reqCtx, cancel := context.WithCancel(context.Background())
+	req := httptest.NewRequest(http.MethodGet, "/slow", nil).WithContext(reqCtx)
+	requestState := headers.NewRequestState(headers.NewRequestInfo(req))
+
+	sess := requestTestSession(requestState, func(ctx *runtime.Ctx) work.Node {
+		return Routes(ctx,
+			Route(ctx, RouteProps{
+				Path: "/slow",
+				Loader: func(ctx context.Context, _ Match) (any, error) {
+					<-ctx.Done()
+					return nil, ctx.Err()
+				},
+				Component: func(ctx *runtime.Ctx, _ Match) work.Node {
+					return &work.Text{Value: "slow"}
+				},
+			}),
+		)
+	})
+
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	done := make(chan error, 1)
+	go func() { done <- sess.Flush() }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("flush failed: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected the SSR loader to stop when the request was cancelled")
+	}
+}
@@ -0,0 +1,115 @@
+package router
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/eleven-am/pondlive/internal/metatags"
+	"github.com/eleven-am/pondlive/internal/runtime"
+)
+
+type RouteMatch struct {
+	Pattern string
+	Href    string
+	Params  map[string]string
+	Meta    *metatags.Meta
+	Handle  any
+}
+
+type matchChain struct {
+	routes []RouteMatch
+	index  int
+}
+
+var matchesCtx = runtime.CreateContext[*matchChain](nil)
+
+func UseMatches(ctx *runtime.Ctx) []RouteMatch {
+	chain := matchesCtx.UseContextValue(ctx)
+	if chain == nil {
+		return nil
+	}
+	return slices.Clone(chain.routes)
+}
+
+func useMatchChain(ctx *runtime.Ctx, props routeMountProps) *matchChain {
+	parent := props.parentChain
+	self := matchedRoute{
+		entry: routeEntry{fullPath: props.match.Pattern, meta: props.meta, handle: props.handle},
+		match: props.match,
+	}
+	routes := append([]matchedRoute{self}, props.descendants...)
+
+	chain := runtime.UseMemo(ctx, func() *matchChain {
+		return buildMatchChain(parent, routes)
+	}, parent, matchChainKey(routes))
+
+	_, setChain := matchesCtx.UseProvider(ctx, chain)
+	setChain(chain)
+	return chain
+}
+
+func buildMatchChain(parent *matchChain, routes []matchedRoute) *matchChain {
+	var out []RouteMatch
+	if parent != nil {
+		out = append(out, parent.routes[:parent.index+1]...)
+	}
+	index := len(out)
+
+	for _, r := range routes {
+		m := RouteMatch{
+			Pattern: r.match.Pattern,
+			Href:    matchedHref(r.match),
+			Params:  r.match.params,
+			Handle:  r.entry.handle,
+		}
+		if r.entry.meta != nil {
+			m.Meta = r.entry.meta(r.match)
+		}
+		out = append(out, m)
+	}
+
+	return &matchChain{routes: out, index: index}
+}
+
+func matchChainKey(routes []matchedRoute) string {
+	var b strings.Builder
+	for _, r := range routes {
+		b.WriteString(r.match.Pattern)
+		b.WriteByte('|')
+		b.WriteString(r.match.Path)
+		b.WriteByte('?')
+		b.WriteString(r.match.RawQuery)
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+func matchedHref(m Match) string {
+	if m.Rest == "" || m.Rest == "/" {
+		return normalizePath(m.Path)
+	}
+	return normalizePath(strings.TrimSuffix(m.Path, m.Rest))
+}
+
+func expandPattern(pattern string, params map[string]string) string {
+	segments := strings.Split(strings.Trim(strings.TrimSuffix(pattern, "/*"), "/"), "/")
+	out := segments[:0]
+	for _, seg := range segments {
+		name, ok := patternParam(seg)
+		if !ok {
+			out = append(out, seg)
+			continue
+		}
+		value := params[name]
+		if value == "" {
+			continue
+		}
+		if strings.HasPrefix(seg, "*") {
+			out = append(out, value)
+			continue
+		}
+		out = append(out, url.PathEscape(value))
+	}
+	return normalizePath("/" + strings.Join(out, "/"))
+}
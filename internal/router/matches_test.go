@@ -0,0 +1,116 @@
+package router
+
+import (
+	"testing"
+
+	"github.com/eleven-am/pondlive/internal/metatags"
+	"github.com/eleven-am/pondlive/internal/runtime"
+	"github.com/eleven-am/pondlive/internal/work"
+)
+
+func TestUseMatchesReturnsChainWithMeta(t *testing.T) {
+	var fromLayout, fromLeaf []RouteMatch
+
+	sess, _ := guardTestSession("/users/42/settings", func(ctx *runtime.Ctx) work.Node {
+		return Routes(ctx,
+			Route(ctx, RouteProps{
+				Path:   "/users/:id",
+				Handle: "user",
+				Meta: func(m Match) *metatags.Meta {
+					id, _ := m.Param("id")
+					return &metatags.Meta{Title: "User " + id}
+				},
+				Component: func(ctx *runtime.Ctx, _ Match) work.Node {
+					fromLayout = UseMatches(ctx)
+					return Outlet(ctx)
+				},
+			},
+				Route(ctx, RouteProps{
+					Path:   "/settings",
+					Handle: "settings",
+					Meta:   func(Match) *metatags.Meta { return &metatags.Meta{Title: "Settings"} },
+					Component: func(ctx *runtime.Ctx, _ Match) work.Node {
+						fromLeaf = UseMatches(ctx)
+						return nil
+					},
+				}),
+			),
+		)
+	})
+
+	if err := sess.Flush(); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+
+	for name, chain := range map[string][]RouteMatch{"layout": fromLayout, "leaf": fromLeaf} {
+		if len(chain) != 2 {
+			t.Fatalf("%s: expected 2 matches, got %d", name, len(chain))
+		}
+		if chain[0].Href != "/users/42" || chain[1].Href != "/users/42/settings" {
+			t.Errorf("%s: unexpected hrefs %q, %q", name, chain[0].Href, chain[1].Href)
+		}
+		if chain[0].Meta == nil || chain[0].Meta.Title != "User 42" || chain[1].Meta == nil || chain[1].Meta.Title != "Settings" {
+			t.Errorf("%s: unexpected meta %+v, %+v", name, chain[0].Meta, chain[1].Meta)
+		}
+		if chain[0].Handle != "user" || chain[1].Handle != "settings" {
+			t.Errorf("%s: unexpected handles %v, %v", name, chain[0].Handle, chain[1].Handle)
+		}
+		if chain[0].Params["id"] != "42" {
+			t.Errorf("%s: expected id param, got %v", name, chain[0].Params)
+		}
+	}
+}
+
+func TestExpandPattern(t *testing.T) {
+	cases := []struct {
+		pattern string
+		params  map[string]string
+		want    string
+	}{
+		{"/", nil, "/"},
+		{"/docs/*", nil, "/docs"},
+		{"/users/:id(int)", map[string]string{"id": "7"}, "/users/7"},
+		{"/blog/:year?/:slug", map[string]string{"slug": "hello world"}, "/blog/hello%20world"},
+		{"/files/*path", map[string]string{"path": "a/b.txt"}, "/files/a/b.txt"},
+	}
+
+	for _, tc := range cases {
+		if got := expandPattern(tc.pattern, tc.params); got != tc.want {
+			t.Errorf("expandPattern(%q) = %q, want %q", tc.pattern, got, tc.want)
+		}
+	}
+}
+
+func TestRouteMetaIsStableAcrossRenders(t *testing.T) {
+	var seen []*metatags.Meta
+
+	sess, _ := guardTestSession("/about", func(ctx *runtime.Ctx) work.Node {
+		return metatags.Provider(ctx,
+			Routes(ctx,
+				Route(ctx, RouteProps{
+					Path: "/about",
+					Meta: func(Match) *metatags.Meta { return &metatags.Meta{Title: "About"} },
+					Component: func(ctx *runtime.Ctx, _ Match) work.Node {
+						seen = append(seen, UseMatches(ctx)[0].Meta)
+						return nil
+					},
+				}),
+			),
+		)
+	})
+
+	for i := 0; i < 3; i++ {
+		if err := sess.Flush(); err != nil {
+			t.Fatalf("flush failed: %v", err)
+		}
+	}
+
+	if len(seen) < 2 || seen[0] == nil || seen[0].Title != "About" {
+		t.Fatalf("expected route meta to be rendered, got %v", seen)
+	}
+	for _, meta := range seen[1:] {
+		if meta != seen[0] {
+			t.Fatal("expected route meta to be reused while the match is unchanged")
+		}
+	}
+}
@@ -10,11 +10,11 @@ import (
 )
 
 func Navigate(ctx *runtime.Ctx, href string) {
-	navigate(ctx, href, false)
+	navigate(ctx, href, false, navOptions{})
 }
 
 func Replace(ctx *runtime.Ctx, href string) {
-	navigate(ctx, href, true)
+	navigate(ctx, href, true, navOptions{})
 }
 
 func NavigateWith(ctx *runtime.Ctx, fn func(Location) Location) {
@@ -53,27 +53,21 @@ func Forward(ctx *runtime.Ctx) {
 	bus.PublishRouterForward()
 }
 
-func navigate(ctx *runtime.Ctx, href string, replace bool) {
+func navigate(ctx *runtime.Ctx, href string, replace bool, opts navOptions) {
 	requestState := headers.UseRequestState(ctx)
 	currentLoc, setLocation := locationCtx.UseContext(ctx)
 	emitter := emitterCtx.UseContextValue(ctx)
+	loaders := loaderStoreCtx.UseContextValue(ctx)
+	blockers := blockerStoreCtx.UseContextValue(ctx)
 	bus := runtime.GetBus(ctx)
 
 	if bus == nil || requestState == nil || !requestState.IsLive() {
 		if requestState != nil {
-			current := Location{
-				Path:  requestState.Path(),
-				Query: requestState.Query(),
-				Hash:  requestState.Hash(),
-			}
-
-			target := resolveHref(current, href)
-			redirectURL := buildHref(target.Path, target.Query, target.Hash)
 			status := http.StatusFound
 			if replace {
 				status = http.StatusSeeOther
 			}
-			requestState.SetRedirect(redirectURL, status)
+			setSSRRedirect(requestState, href, status)
 		}
 
 		return
@@ -86,6 +80,23 @@ func navigate(ctx *runtime.Ctx, href string, replace bool) {
 	target := resolveHref(currentLoc, href)
 	target = canonicalizeLocation(target)
 
+	opts = opts.merge(loaders.navOptions(target))
+	commit := func() {
+		commitNavigation(emitter, setLocation, bus, currentLoc, target, replace, opts)
+	}
+	proceed := func() {
+		if !loaders.navigate(target, commit) {
+			commit()
+		}
+	}
+
+	if !locationEqual(currentLoc, target) && blockers.intercept(newNavigationEvent(currentLoc, target, replace), proceed) {
+		return
+	}
+	proceed()
+}
+
+func commitNavigation(emitter *RouterEventEmitter, setLocation func(Location), bus *protocol.Bus, currentLoc, target Location, replace bool, opts navOptions) {
 	if emitter != nil {
 		emitter.Emit("beforeNavigate", NavigationEvent{
 			From:         currentLoc,
@@ -101,16 +112,33 @@ func navigate(ctx *runtime.Ctx, href string, replace bool) {
 		setLocation(target)
 	}
 
-	payload := protocol.RouterNavPayload{
+	payload := navPayload(target, replace)
+	payload.Scroll = string(opts.scroll)
+	payload.Focus = opts.focus
+
+	if replace {
+		bus.PublishRouterReplace(payload)
+	} else {
+		bus.PublishRouterPush(payload)
+	}
+}
+
+func navPayload(target Location, replace bool) protocol.RouterNavPayload {
+	return protocol.RouterNavPayload{
 		Path:    target.Path,
 		Query:   target.Query.Encode(),
 		Hash:    target.Hash,
 		Replace: replace,
 	}
+}
 
-	if replace {
-		bus.PublishRouterReplace(payload)
-	} else {
-		bus.PublishRouterPush(payload)
+func setSSRRedirect(requestState *headers.RequestState, href string, status int) {
+	current := Location{
+		Path:  requestState.Path(),
+		Query: requestState.Query(),
+		Hash:  requestState.Hash(),
 	}
+
+	target := resolveHref(current, href)
+	requestState.SetRedirect(buildHref(target.Path, target.Query, target.Hash), status)
 }
@@ -32,6 +32,47 @@ var Provide = runtime.Component(func(ctx *runtime.Ctx, children []work.Item) wor
 	emitterRef := runtime.UseRef(ctx, NewRouterEventEmitter())
 	emitterCtx.UseProvider(ctx, emitterRef.Current)
 
+	registryRef := runtime.UseRef(ctx, newRouteRegistry())
+	routeRegistryCtx.UseProvider(ctx, registryRef.Current)
+
+	triesRef := runtime.UseRef(ctx, newTrieCache(maxCachedTries))
+	trieCacheCtx.UseProvider(ctx, triesRef.Current)
+
+	loadersRef := runtime.UseRef(ctx, newLoaderStore())
+	loaderStoreCtx.UseProvider(ctx, loadersRef.Current)
+	_, setPending := pendingCtx.UseProvider(ctx, false)
+	rerender := runtime.UseRerender(ctx)
+	loadersRef.Current.mu.Lock()
+	loadersRef.Current.setPending = setPending
+	loadersRef.Current.rerender = rerender
+	loadersRef.Current.tries = triesRef.Current
+	loadersRef.Current.mu.Unlock()
+
+	// Loaders settle on their own goroutines; they only mark the provider for a
+	// render, and the navigation is committed from this effect.
+	runtime.UseEffect(ctx, func() func() {
+		loadersRef.Current.commitReady()
+		return nil
+	}, loadersRef.Current.readyGeneration())
+
+	runtime.UseEffect(ctx, func() func() {
+		loadersRef.Current.prune(loc)
+		return nil
+	}, loc)
+
+	runtime.UseEffect(ctx, func() func() {
+		return loadersRef.Current.close
+	}, loadersRef.Current)
+
+	blockersRef := runtime.UseRef(ctx, newBlockerStore())
+	blockersRef.Current.script = runtime.UseScript(ctx, blockerScript)
+	blockerStoreCtx.UseProvider(ctx, blockersRef.Current)
+
+	runtime.UseEffect(ctx, func() func() {
+		blockersRef.Current.script.On("ready", blockersRef.Current.handleReady)
+		return nil
+	}, blockersRef.Current)
+
 	prevLocRef := runtime.UseRef(ctx, loc)
 
 	runtime.UseEffect(ctx, func() func() {
@@ -68,17 +109,50 @@ var Provide = runtime.Component(func(ctx *runtime.Ctx, children []work.Item) wor
 			}
 			newLoc = canonicalizeLocation(newLoc)
 
-			if !route.LocEqual(loc, newLoc) {
-				emitterRef.Current.Emit("beforeNavigate", NavigationEvent{
-					From:         loc,
-					To:           newLoc,
-					PathChanged:  loc.Path != newLoc.Path,
-					HashChanged:  loc.Hash != newLoc.Hash,
-					QueryChanged: loc.Query.Encode() != newLoc.Query.Encode(),
-					Replace:      false,
-				})
+			loadersRef.Current.abort()
+
+			if route.LocEqual(loc, newLoc) {
+				return
+			}
+
+			event := newNavigationEvent(loc, newLoc, false)
+			commit := func() {
+				emitterRef.Current.Emit("beforeNavigate", event)
 				setLoc(newLoc)
 			}
+			proceed := func() {
+				if !loadersRef.Current.navigate(newLoc, commit) {
+					commit()
+				}
+			}
+
+			if blockersRef.Current.passes(newLoc) {
+				proceed()
+				return
+			}
+
+			// The browser has already moved, so a blocked popstate steps back to
+			// the entry it left rather than pushing a copy of it. Clients that
+			// cannot tell how far it moved get the URL replaced instead.
+			delta := payload.Delta
+			blocked := blockersRef.Current.intercept(event, func() {
+				if delta != 0 {
+					blockersRef.Current.pass(newLoc)
+					bus.PublishRouterGo(delta)
+					return
+				}
+				proceed()
+				bus.PublishRouterReplace(navPayload(newLoc, true))
+			})
+			if blocked {
+				if delta != 0 {
+					bus.PublishRouterGo(-delta)
+				} else {
+					bus.PublishRouterReplace(navPayload(loc, true))
+				}
+				return
+			}
+			proceed()
 		})
 
 		return func() {
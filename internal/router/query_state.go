@@ -0,0 +1,300 @@
+package router
+
+import (
+	"encoding"
+	"net/url"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/eleven-am/pondlive/internal/runtime"
+)
+
+type QueryStateOptions[T any] struct {
+	Default  T
+	Replace  bool
+	Debounce time.Duration
+}
+
+type queryField struct {
+	index  int
+	key    string
+	layout string
+	enum   []string
+}
+
+type queryWriter struct {
+	mu    sync.Mutex
+	timer *time.Timer
+	seq   int
+	due   int
+	write func()
+}
+
+var (
+	timeType            = reflect.TypeFor[time.Time]()
+	durationType        = reflect.TypeFor[time.Duration]()
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+)
+
+// UseQueryState binds the struct T to the query string. T must be a struct;
+// any other type leaves the URL alone, always returning opts.Default with a
+// setter that does nothing.
+func UseQueryState[T any](ctx *runtime.Ctx, opts QueryStateOptions[T]) (T, func(T)) {
+	loc := UseLocation(ctx)
+	pending, setPending := runtime.UseState[*T](ctx, nil)
+	writer := runtime.UseRef(ctx, &queryWriter{})
+	rerender := runtime.UseRerender(ctx)
+	fields := runtime.UseMemo(ctx, func() []queryField {
+		return queryFields(reflect.TypeFor[T]())
+	})
+
+	runtime.UseEffect(ctx, func() func() {
+		return writer.Current.stop
+	}, writer)
+
+	runtime.UseEffect(ctx, func() func() {
+		writer.Current.flush()
+		return nil
+	}, writer.Current.dueSeq())
+
+	if reflect.TypeFor[T]().Kind() != reflect.Struct {
+		return opts.Default, func(T) {}
+	}
+
+	value := decodeQuery(loc.Query, fields, opts.Default)
+	if pending != nil {
+		value = *pending
+	}
+
+	write := func(next T) {
+		update := func(current Location) Location {
+			current.Query = encodeQuery(current.Query, fields, next, opts.Default)
+			return current
+		}
+		if opts.Replace {
+			ReplaceWith(ctx, update)
+		} else {
+			NavigateWith(ctx, update)
+		}
+		setPending(nil)
+	}
+
+	set := func(next T) {
+		if opts.Debounce <= 0 {
+			write(next)
+			return
+		}
+		setPending(&next)
+		writer.Current.schedule(opts.Debounce, rerender, func() { write(next) })
+	}
+
+	return value, set
+}
+
+// schedule arranges for fn to run once delay passes without another write.
+// The timer only asks for a render; fn runs from the component's effect, and a
+// write superseded or stopped before then is dropped.
+func (w *queryWriter) schedule(delay time.Duration, rerender func(), fn func()) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+	w.seq++
+	seq := w.seq
+	w.write = fn
+	w.timer = time.AfterFunc(delay, func() {
+		w.mu.Lock()
+		if w.seq != seq {
+			w.mu.Unlock()
+			return
+		}
+		w.timer = nil
+		w.due = seq
+		w.mu.Unlock()
+		rerender()
+	})
+}
+
+func (w *queryWriter) dueSeq() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.due
+}
+
+// flush runs the write whose timer fired, if it is still the latest one.
+func (w *queryWriter) flush() {
+	w.mu.Lock()
+	write := w.write
+	if w.due != w.seq || write == nil {
+		w.mu.Unlock()
+		return
+	}
+	w.write = nil
+	w.mu.Unlock()
+	write()
+}
+
+func (w *queryWriter) stop() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.seq++
+	w.write = nil
+	if w.timer != nil {
+		w.timer.Stop()
+		w.timer = nil
+	}
+}
+
+func queryFields(t reflect.Type) []queryField {
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var fields []queryField
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		tag := sf.Tag.Get("query")
+		if tag == "-" {
+			continue
+		}
+
+		parts := strings.Split(tag, ",")
+		field := queryField{index: i, key: parts[0]}
+		if field.key == "" {
+			field.key = strings.ToLower(sf.Name)
+		}
+		for _, opt := range parts[1:] {
+			name, val, _ := strings.Cut(opt, "=")
+			switch name {
+			case "layout":
+				field.layout = val
+			case "enum":
+				field.enum = strings.Split(val, "|")
+			}
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+func decodeQuery[T any](query url.Values, fields []queryField, defaults T) T {
+	out := defaults
+	value := reflect.ValueOf(&out).Elem()
+
+	for _, field := range fields {
+		raw, ok := query[field.key]
+		if !ok || len(raw) == 0 {
+			continue
+		}
+
+		target := value.Field(field.index)
+		if target.Kind() == reflect.Slice && target.Type() != reflect.TypeFor[[]byte]() {
+			items := reflect.MakeSlice(target.Type(), 0, len(raw))
+			for _, r := range raw {
+				item := reflect.New(target.Type().Elem()).Elem()
+				if decodeQueryValue(item, r, field) {
+					items = reflect.Append(items, item)
+				}
+			}
+			target.Set(items)
+			continue
+		}
+
+		parsed := reflect.New(target.Type()).Elem()
+		if decodeQueryValue(parsed, raw[0], field) {
+			target.Set(parsed)
+		}
+	}
+
+	return out
+}
+
+func encodeQuery[T any](current url.Values, fields []queryField, next, defaults T) url.Values {
+	query := cloneValues(current)
+	if query == nil {
+		query = url.Values{}
+	}
+
+	value := reflect.ValueOf(next)
+	def := reflect.ValueOf(defaults)
+
+	for _, field := range fields {
+		query.Del(field.key)
+
+		v := value.Field(field.index)
+		if reflect.DeepEqual(v.Interface(), def.Field(field.index).Interface()) {
+			continue
+		}
+
+		if v.Kind() == reflect.Slice && v.Type() != reflect.TypeFor[[]byte]() {
+			for i := 0; i < v.Len(); i++ {
+				query.Add(field.key, encodeQueryValue(v.Index(i), field))
+			}
+			continue
+		}
+		query.Set(field.key, encodeQueryValue(v, field))
+	}
+
+	return query
+}
+
+func decodeQueryValue(v reflect.Value, raw string, field queryField) bool {
+	if len(field.enum) > 0 && !slices.Contains(field.enum, raw) {
+		return false
+	}
+
+	switch {
+	case v.Type() == timeType:
+		t, err := time.Parse(queryLayout(field), raw)
+		if err != nil {
+			return false
+		}
+		v.Set(reflect.ValueOf(t))
+		return true
+	case v.Type() == durationType:
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return false
+		}
+		v.SetInt(int64(d))
+		return true
+	case reflect.PointerTo(v.Type()).Implements(textUnmarshalerType):
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(raw)) == nil
+	}
+
+	if !supportedParamKind(v.Kind()) {
+		return false
+	}
+	return parseParam(v, raw) == nil
+}
+
+func encodeQueryValue(v reflect.Value, field queryField) string {
+	switch {
+	case v.Type() == timeType:
+		return v.Interface().(time.Time).Format(queryLayout(field))
+	case v.Type() == durationType:
+		return time.Duration(v.Int()).String()
+	}
+	if marshaler, ok := v.Interface().(encoding.TextMarshaler); ok {
+		text, err := marshaler.MarshalText()
+		if err == nil {
+			return string(text)
+		}
+	}
+	return formatParam(v)
+}
+
+func queryLayout(field queryField) string {
+	if field.layout != "" {
+		return field.layout
+	}
+	return time.RFC3339
+}
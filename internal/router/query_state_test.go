@@ -0,0 +1,190 @@
+package router
+
+import (
+	"net/url"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/eleven-am/pondlive/internal/runtime"
+	"github.com/eleven-am/pondlive/internal/work"
+)
+
+type productFilters struct {
+	Page  int       `query:"page"`
+	Sort  string    `query:"sort,enum=asc|desc"`
+	Tags  []string  `query:"tag"`
+	Sale  bool      `query:"sale"`
+	Since time.Time `query:"since,layout=2006-01-02"`
+}
+
+func TestQueryStateDecodesTypedValues(t *testing.T) {
+	values, _ := url.ParseQuery("page=3&sort=sideways&tag=a&tag=b&sale=true&since=2024-05-01&other=x")
+	q := decodeQuery(values, queryFields(reflect.TypeFor[productFilters]()), productFilters{Page: 1, Sort: "asc"})
+
+	if q.Page != 3 || q.Sort != "asc" || !q.Sale {
+		t.Errorf("unexpected decoded filters %+v", q)
+	}
+	if len(q.Tags) != 2 || q.Tags[0] != "a" || q.Tags[1] != "b" {
+		t.Errorf("expected repeated tags, got %v", q.Tags)
+	}
+	if q.Since.Format("2006-01-02") != "2024-05-01" {
+		t.Errorf("unexpected since %v", q.Since)
+	}
+}
+
+func TestQueryStateEncodeDropsDefaults(t *testing.T) {
+	defaults := productFilters{Page: 1, Sort: "asc"}
+	current := url.Values{"other": {"x"}, "page": {"4"}}
+
+	query := encodeQuery(current, queryFields(reflect.TypeFor[productFilters]()), productFilters{Page: 1, Sort: "desc", Tags: []string{"go", "ui"}}, defaults)
+
+	if got := query.Encode(); got != "other=x&sort=desc&tag=go&tag=ui" {
+		t.Errorf("unexpected query %q", got)
+	}
+}
+
+func TestUseQueryStateDebouncesURLWrites(t *testing.T) {
+	var mu sync.Mutex
+	var filters productFilters
+	var setFilters func(productFilters)
+	var loc Location
+
+	sess, requestState := guardTestSession("/products", func(ctx *runtime.Ctx) work.Node {
+		value, set := UseQueryState(ctx, QueryStateOptions[productFilters]{
+			Default:  productFilters{Page: 1},
+			Replace:  true,
+			Debounce: 20 * time.Millisecond,
+		})
+		mu.Lock()
+		filters, setFilters, loc = value, set, UseLocation(ctx)
+		mu.Unlock()
+		return nil
+	})
+	requestState.SetIsLive(true)
+
+	if err := sess.Flush(); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+
+	snapshot := func() (productFilters, Location) {
+		mu.Lock()
+		defer mu.Unlock()
+		return filters, loc
+	}
+
+	mu.Lock()
+	set := setFilters
+	mu.Unlock()
+
+	set(productFilters{Page: 2})
+	set(productFilters{Page: 3})
+	if err := sess.Flush(); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+
+	value, current := snapshot()
+	if value.Page != 3 {
+		t.Errorf("expected pending value to show immediately, got %d", value.Page)
+	}
+	if current.Query.Get("page") != "" {
+		t.Errorf("expected URL write to be debounced, got %q", current.Query.Encode())
+	}
+
+	waitFor(t, func() bool {
+		_ = sess.Flush()
+		_, current := snapshot()
+		return current.Query.Get("page") == "3"
+	})
+
+	set(productFilters{Page: 1})
+	waitFor(t, func() bool {
+		_ = sess.Flush()
+		value, current := snapshot()
+		return value.Page == 1 && current.Query.Encode() == ""
+	})
+}
+
+func TestUseQueryStateDropsPendingWriteOnUnmount(t *testing.T) {
+	var mu sync.Mutex
+	var setFilters func(productFilters)
+	var loc Location
+	var unmount func()
+
+	filterPanel := work.Component(func(ctx *runtime.Ctx, _ any, _ []work.Item) work.Node {
+		_, set := UseQueryState(ctx, QueryStateOptions[productFilters]{
+			Default:  productFilters{Page: 1},
+			Debounce: 20 * time.Millisecond,
+		})
+		mu.Lock()
+		setFilters = set
+		mu.Unlock()
+		return nil
+	})
+
+	sess, requestState := guardTestSession("/products", func(ctx *runtime.Ctx) work.Node {
+		current := UseLocation(ctx)
+		show, setShow := runtime.UseState(ctx, true)
+		mu.Lock()
+		loc, unmount = current, func() { setShow(false) }
+		mu.Unlock()
+		if !show {
+			return nil
+		}
+		return filterPanel
+	})
+	requestState.SetIsLive(true)
+
+	if err := sess.Flush(); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+
+	mu.Lock()
+	set, hide := setFilters, unmount
+	mu.Unlock()
+	set(productFilters{Page: 2})
+
+	hide()
+	if err := sess.Flush(); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if err := sess.Flush(); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if loc.Query.Get("page") != "" {
+		t.Errorf("expected the pending write to be dropped on unmount, got %q", loc.Query.Encode())
+	}
+}
+
+func TestUseQueryStateNonStructReturnsDefault(t *testing.T) {
+	var value string
+	var set func(string)
+	var loc Location
+
+	sess, requestState := guardTestSession("/products", func(ctx *runtime.Ctx) work.Node {
+		value, set = UseQueryState(ctx, QueryStateOptions[string]{Default: "all"})
+		loc = UseLocation(ctx)
+		return nil
+	})
+	requestState.SetIsLive(true)
+
+	if err := sess.Flush(); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+	if value != "all" {
+		t.Errorf("expected the default for a non-struct type, got %q", value)
+	}
+
+	set("boots")
+	if err := sess.Flush(); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+	if value != "all" || loc.Query.Encode() != "" {
+		t.Errorf("expected the setter to leave the URL alone, got %q and %q", value, loc.Query.Encode())
+	}
+}
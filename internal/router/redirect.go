@@ -11,7 +11,9 @@ var Redirect = runtime.PropsComponent(func(ctx *runtime.Ctx, props RedirectProps
 	isLive := requestState != nil && requestState.IsLive()
 
 	if !isLive {
-		if props.Replace {
+		if props.Code != 0 && requestState != nil {
+			setSSRRedirect(requestState, props.To, props.Code)
+		} else if props.Replace {
 			Replace(ctx, props.To)
 		} else {
 			Navigate(ctx, props.To)
@@ -1,6 +1,10 @@
 package router
 
 import (
+	"context"
+
+	"github.com/eleven-am/pondlive/internal/headers"
+	"github.com/eleven-am/pondlive/internal/metatags"
 	"github.com/eleven-am/pondlive/internal/runtime"
 	"github.com/eleven-am/pondlive/internal/work"
 )
@@ -11,19 +15,142 @@ type routeMountProps struct {
 	base       string
 	childSlots map[string]outletRenderer
 	component  func(*runtime.Ctx, Match) work.Node
+	keepAlive  bool
+	guards     []func(*runtime.Ctx, Match) GuardResult
+	loader     func(context.Context, Match) (any, error)
+	meta       func(Match) *metatags.Meta
+	handle     any
+
+	parentChain *matchChain
+	descendants []matchedRoute
 }
 
 var routeMount = runtime.PropsComponent(func(ctx *runtime.Ctx, props routeMountProps, _ []work.Item) work.Node {
+	runtime.UseKeepAlive(ctx, runtime.KeepAliveOptions{Disabled: !props.keepAlive})
+
 	_, setMatch := matchCtx.UseProvider(ctx, props.matchState)
 	_, setBase := routeBaseCtx.UseProvider(ctx, props.base)
 	_, setSlots := slotsCtx.UseProvider(ctx, props.childSlots)
 
 	setMatch(props.matchState)
 	setBase(props.base)
+	_, setLoaderData := loaderDataCtx.UseProvider(ctx, nil)
+	store := loaderStoreCtx.UseContextValue(ctx)
+	localResult := runtime.UseRef[*loaderResult](ctx, nil)
+	shown := runtime.UseRef(ctx, routeLoad{})
+	rerender := runtime.UseRerender(ctx)
+	requestState := headers.UseRequestState(ctx)
+	live := requestState != nil && requestState.IsLive()
+
+	key := loaderKey(props.match.Pattern, props.match.location())
+	runtime.UseEffect(ctx, func() func() {
+		return func() {
+			if store != nil {
+				store.drop(key)
+			} else if local := localResult.Current; local != nil && local.key == key {
+				local.cancel()
+			}
+		}
+	}, store, key)
+
 	setSlots(props.childSlots)
 
+	// Guards decide before meta is built, so a denied route's meta never reaches
+	// the page; the meta hooks still run to keep the hook order stable.
+	guard := runGuards(ctx, props.guards, props.match)
+	if !guard.Allowed() {
+		props.meta, props.descendants = nil, nil
+	}
+
+	chain := useMatchChain(ctx, props)
+	metatags.UseMetaTags(ctx, chain.routes[chain.index].Meta)
+
+	if !guard.Allowed() {
+		if guard.Redirect != "" {
+			return guardRedirect(ctx, guard)
+		}
+		return guardFallback(ctx, guard.Fallback)
+	}
+
+	setLoaderData(shown.Current.state(loadRoute(loaderParent(store, requestState, live), store, localResult, props), live, rerender))
+
 	return &work.ComponentNode{
 		Fn:    props.component,
 		Props: props.match,
 	}
 })
+
+// loaderParent is the context loaders run under: the request's during SSR, so
+// they stop with it, and the router's own lifetime once the page is live.
+func loaderParent(store *loaderStore, requestState *headers.RequestState, live bool) context.Context {
+	if !live && requestState != nil {
+		return requestState.Context()
+	}
+	if store != nil {
+		return store.ctx
+	}
+	return context.Background()
+}
+
+func loadRoute(parent context.Context, store *loaderStore, local *runtime.Ref[*loaderResult], props routeMountProps) *loaderResult {
+	loc := props.match.location()
+	self := matchedRoute{
+		entry: routeEntry{fullPath: props.match.Pattern, loader: props.loader},
+		match: props.match,
+	}
+
+	if store == nil {
+		if props.loader == nil {
+			return nil
+		}
+		if local.Current == nil || local.Current.key != loaderKey(props.match.Pattern, loc) {
+			local.Current = runLoader(parent, self, loc)
+		}
+		return local.Current
+	}
+
+	store.start(parent, append([]matchedRoute{self}, props.descendants...), loc)
+	if props.loader == nil {
+		return nil
+	}
+
+	return store.result(props.match.Pattern, loc)
+}
+
+// routeLoad is what a route last rendered from its loader.
+type routeLoad struct {
+	last     *loaderState
+	watching *loaderResult
+	pending  *loaderState
+}
+
+// state returns the loader state to render. SSR waits for the loader. A live
+// render never blocks the session on it: until the loader settles the route
+// keeps its last data marked as pending, and renders again once it has settled.
+func (l *routeLoad) state(result *loaderResult, live bool, rerender func()) *loaderState {
+	if result == nil {
+		return nil
+	}
+	if !live {
+		l.last = result.wait()
+		return l.last
+	}
+	if state, ok := result.settled(); ok {
+		l.last, l.watching, l.pending = state, nil, nil
+		return state
+	}
+	if l.watching == result {
+		return l.pending
+	}
+
+	l.watching = result
+	l.pending = &loaderState{pending: true}
+	if l.last != nil {
+		l.pending.data, l.pending.err = l.last.data, l.last.err
+	}
+	go func() {
+		<-result.done
+		rerender()
+	}()
+	return l.pending
+}
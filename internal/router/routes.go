@@ -1,13 +1,19 @@
 package router
 
 import (
+	"net/http"
 	"strings"
 
+	"github.com/eleven-am/pondlive/internal/headers"
 	"github.com/eleven-am/pondlive/internal/runtime"
 	"github.com/eleven-am/pondlive/internal/work"
 )
 
 func Route(ctx *runtime.Ctx, props RouteProps, children ...work.Node) work.Node {
+	return routeNode(props, nil, children)
+}
+
+func routeNode(props RouteProps, def *routeDefinition, children []work.Node) work.Node {
 	pattern := strings.TrimSpace(props.Path)
 	if pattern == "" {
 		pattern = "/"
@@ -20,6 +26,14 @@ func Route(ctx *runtime.Ctx, props RouteProps, children ...work.Node) work.Node
 				component: props.Component,
 				children:  children,
 				slot:      defaultSlotName,
+				keepAlive: props.KeepAlive,
+				guards:    props.Guards,
+				loader:    props.Loader,
+				scroll:    props.Scroll,
+				focus:     props.Focus,
+				meta:      props.Meta,
+				handle:    props.Handle,
+				def:       def,
 			},
 		},
 	}
@@ -31,12 +45,14 @@ func routes(ctx *runtime.Ctx, items []work.Item) work.Node {
 	loc := locationCtx.UseContextValue(ctx)
 	base := routeBaseCtx.UseContextValue(ctx)
 	parentMatch := matchCtx.UseContextValue(ctx)
+	parentChain := matchesCtx.UseContextValue(ctx)
 
 	pathToMatch := loc.Path
 	if parentMatch != nil && parentMatch.Matched && parentMatch.Rest != "" && parentMatch.Path == loc.Path {
 		pathToMatch = parentMatch.Rest
 	}
 
+	tries := trieCacheCtx.UseContextValue(ctx)
 	allSlots := runtime.UseMemo(ctx, func() []slotEntry {
 		routeEntries := collectRouteEntries(children, base)
 		slotEntries := collectSlotEntries(children, base)
@@ -48,20 +64,46 @@ func routes(ctx *runtime.Ctx, items []work.Item) work.Node {
 			})
 		}
 
+		for i := range slotEntries {
+			slotEntries[i].trie = tries.compile(slotEntries[i].routes)
+		}
+
 		return slotEntries
-	}, fingerprintChildren(children), fingerprintSlots(children), base)
+	}, tries, fingerprintChildren(children), fingerprintSlots(children), base)
+
+	registry := routeRegistryCtx.UseContextValue(ctx)
+	definitionErr := runtime.UseMemo(ctx, func() error {
+		return registry.check(allSlots)
+	}, registry, fingerprintChildren(children), fingerprintSlots(children), base)
+
+	store := loaderStoreCtx.UseContextValue(ctx)
+	topLevel := parentMatch == nil
+	runtime.UseEffect(ctx, func() func() {
+		if !topLevel || definitionErr != nil {
+			return nil
+		}
+		return store.register(ctx.ComponentID(), allSlots)
+	}, store, topLevel, definitionErr, fingerprintChildren(children), fingerprintSlots(children), base)
+
+	// Bad patterns and DefineRoute definitions answer like a missing route, with a
+	// status and nothing rendered, rather than taking the session down.
+	if definitionErr != nil {
+		headers.UseStatus(ctx, http.StatusInternalServerError)
+		_, setMatch := matchCtx.UseProvider(ctx, &MatchState{Matched: false})
+		_, setSlots := slotsCtx.UseProvider(ctx, nil)
+		_, setBase := routeBaseCtx.UseProvider(ctx, base)
+		setMatch(&MatchState{Matched: false})
+		setSlots(nil)
+		setBase(base)
+		return &work.Fragment{}
+	}
 
 	var slots map[string]outletRenderer
 	if pathToMatch != "" {
 		slots = make(map[string]outletRenderer)
 
 		for _, slot := range allSlots {
-			trie := newRouterTrie()
-			for _, e := range slot.routes {
-				trie.Insert(e.fullPath, e)
-			}
-
-			matchResult := trie.Match(pathToMatch)
+			matchResult := matchRoutes(slot.trie, slot.routes, pathToMatch)
 			if matchResult == nil || matchResult.Entry == nil {
 				if slot.fallback != nil {
 					slots[slot.name] = slot.fallback
@@ -79,15 +121,7 @@ func routes(ctx *runtime.Ctx, items []work.Item) work.Node {
 				}
 			}
 
-			capturedMatch := Match{
-				Pattern:  entry.fullPath,
-				Path:     loc.Path,
-				params:   matchResult.Params,
-				query:    loc.Query,
-				RawQuery: loc.Query.Encode(),
-				Hash:     loc.Hash,
-				Rest:     matchResult.Rest,
-			}
+			capturedMatch := buildMatch(*entry, matchResult, loc)
 			capturedMatchState := &MatchState{
 				Matched: true,
 				Pattern: entry.fullPath,
@@ -100,11 +134,18 @@ func routes(ctx *runtime.Ctx, items []work.Item) work.Node {
 
 			renderer := func(ictx *runtime.Ctx) work.Node {
 				return routeMount(ictx, routeMountProps{
-					match:      capturedMatch,
-					matchState: capturedMatchState,
-					base:       basePath,
-					childSlots: childSlots,
-					component:  entry.component,
+					match:       capturedMatch,
+					matchState:  capturedMatchState,
+					base:        basePath,
+					childSlots:  childSlots,
+					component:   entry.component,
+					keepAlive:   entry.keepAlive,
+					guards:      entry.guards,
+					loader:      entry.loader,
+					meta:        entry.meta,
+					handle:      entry.handle,
+					parentChain: parentChain,
+					descendants: descendantRouteChain(tries, *entry, matchResult.Rest, loc),
 				})
 			}
 
@@ -122,6 +163,9 @@ func routes(ctx *runtime.Ctx, items []work.Item) work.Node {
 
 	if hasDefaultSlot {
 		if slots == nil || slots[defaultSlotName] == nil {
+			if pathToMatch != "" {
+				headers.UseStatus(ctx, http.StatusNotFound)
+			}
 			_, setMatch := matchCtx.UseProvider(ctx, &MatchState{Matched: false})
 			_, setSlots := slotsCtx.UseProvider(ctx, nil)
 			_, setBase := routeBaseCtx.UseProvider(ctx, base)
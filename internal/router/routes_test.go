@@ -1,6 +1,7 @@
 package router
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/eleven-am/pondlive/internal/work"
@@ -117,6 +118,85 @@ func TestTrieStaticBeforeParam(t *testing.T) {
 	}
 }
 
+func TestTrieConstrainedParams(t *testing.T) {
+	trie := newRouterTrie()
+
+	trie.Insert("/posts/:slug", routeEntry{pattern: "/posts/:slug"})
+	trie.Insert("/posts/:id(int)", routeEntry{pattern: "/posts/:id(int)"})
+	trie.Insert("/items/:id(uuid)", routeEntry{pattern: "/items/:id(uuid)"})
+
+	result := trie.Match("/posts/42")
+	if result == nil || result.Entry.pattern != "/posts/:id(int)" {
+		t.Fatalf("expected constrained route to win, got %+v", result)
+	}
+	if result.Params["id"] != "42" {
+		t.Errorf("expected id=42, got %v", result.Params)
+	}
+
+	result = trie.Match("/posts/banana")
+	if result == nil || result.Entry.pattern != "/posts/:slug" {
+		t.Fatalf("expected fallback to plain param, got %+v", result)
+	}
+
+	if result := trie.Match("/items/banana"); result != nil {
+		t.Errorf("expected uuid constraint to reject banana, got %+v", result.Entry)
+	}
+	if result := trie.Match("/items/6f1c2b1e-8a1d-4a5e-9d3c-2f6b7e8a9c0d"); result == nil {
+		t.Error("expected uuid to match")
+	}
+}
+
+func TestTrieOptionalSegments(t *testing.T) {
+	trie := newRouterTrie()
+
+	trie.Insert("/about", routeEntry{pattern: "/about"})
+	trie.Insert("/:lang(en|fr)?/about", routeEntry{pattern: "/:lang(en|fr)?/about"})
+	trie.Insert("/docs/:page?", routeEntry{pattern: "/docs/:page?"})
+
+	if result := trie.Match("/about"); result == nil || result.Entry.pattern != "/about" {
+		t.Errorf("expected explicit route to keep /about, got %+v", result)
+	}
+
+	result := trie.Match("/fr/about")
+	if result == nil || result.Params["lang"] != "fr" {
+		t.Fatalf("expected lang=fr, got %+v", result)
+	}
+	if result := trie.Match("/de/about"); result != nil {
+		t.Errorf("expected constrained optional to reject de, got %+v", result.Entry)
+	}
+
+	if result := trie.Match("/docs"); result == nil || result.Entry.pattern != "/docs/:page?" {
+		t.Errorf("expected /docs to match without page, got %+v", result)
+	}
+	if result := trie.Match("/docs/intro"); result == nil || result.Params["page"] != "intro" {
+		t.Errorf("expected page=intro, got %+v", result)
+	}
+}
+
+func TestMatchTypedParams(t *testing.T) {
+	m := Match{params: map[string]string{
+		"id":   "42",
+		"slug": "banana",
+		"uuid": "6f1c2b1e-8a1d-4a5e-9d3c-2f6b7e8a9c0d",
+	}}
+
+	if n, err := m.Int("id"); err != nil || n != 42 {
+		t.Errorf("expected 42, got %d (%v)", n, err)
+	}
+	if _, err := m.Int("slug"); !errors.Is(err, ErrInvalidParam) {
+		t.Errorf("expected ErrInvalidParam, got %v", err)
+	}
+	if _, err := m.Int("missing"); !errors.Is(err, ErrParamNotFound) {
+		t.Errorf("expected ErrParamNotFound, got %v", err)
+	}
+	if id, err := m.UUID("uuid"); err != nil || id.String() != "6f1c2b1e-8a1d-4a5e-9d3c-2f6b7e8a9c0d" {
+		t.Errorf("unexpected uuid %v (%v)", id, err)
+	}
+	if _, err := m.UUID("slug"); !errors.Is(err, ErrInvalidParam) {
+		t.Errorf("expected ErrInvalidParam, got %v", err)
+	}
+}
+
 func TestTrieNoMatch(t *testing.T) {
 	trie := newRouterTrie()
 
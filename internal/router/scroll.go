@@ -0,0 +1,57 @@
+package router
+
+func (o navOptions) merge(fallback navOptions) navOptions {
+	if o.scroll == ScrollDefault {
+		o.scroll = fallback.scroll
+	}
+	if o.focus == "" {
+		o.focus = fallback.focus
+	}
+	return o
+}
+
+func (s *loaderStore) navOptions(target Location) navOptions {
+	if s == nil {
+		return navOptions{}
+	}
+
+	s.mu.Lock()
+	tables := make([][]slotEntry, 0, len(s.tables))
+	for _, slots := range s.tables {
+		tables = append(tables, slots)
+	}
+	tries := s.tries
+	s.mu.Unlock()
+
+	var opts navOptions
+	for _, slots := range tables {
+		for _, slot := range slots {
+			opts = routeNavOptions(tries, slot.routes, target.Path).merge(opts)
+		}
+	}
+	return opts
+}
+
+func routeNavOptions(tries *trieCache, entries []routeEntry, pathToMatch string) navOptions {
+	if len(entries) == 0 {
+		return navOptions{}
+	}
+
+	result := matchRoutes(tries.compile(entries), entries, pathToMatch)
+	if result == nil || result.Entry == nil {
+		return navOptions{}
+	}
+
+	entry := result.Entry
+	opts := navOptions{scroll: entry.scroll, focus: entry.focus}
+	if len(entry.children) == 0 {
+		return opts
+	}
+
+	rest := result.Rest
+	if rest == "" {
+		rest = pathToMatch
+	}
+	children := collectRouteEntries(entry.children, trimWildcardSuffix(entry.fullPath))
+	return routeNavOptions(tries, children, rest).merge(opts)
+}
@@ -0,0 +1,80 @@
+package router
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/eleven-am/pondlive/internal/protocol"
+	"github.com/eleven-am/pondlive/internal/runtime"
+	"github.com/eleven-am/pondlive/internal/work"
+)
+
+func TestNavigationCarriesScrollAndFocusOptions(t *testing.T) {
+	var mu sync.Mutex
+	var nav func(string, navOptions)
+
+	sess, requestState := guardTestSession("/", func(ctx *runtime.Ctx) work.Node {
+		mu.Lock()
+		nav = func(href string, opts navOptions) { navigate(ctx, href, false, opts) }
+		mu.Unlock()
+
+		return Routes(ctx,
+			Route(ctx, RouteProps{Path: "/", Component: func(*runtime.Ctx, Match) work.Node { return nil }}),
+			Route(ctx, RouteProps{
+				Path:   "/docs",
+				Scroll: ScrollPreserve,
+				Focus:  "#docs-nav",
+				Component: func(ctx *runtime.Ctx, _ Match) work.Node {
+					return Outlet(ctx)
+				},
+			},
+				Route(ctx, RouteProps{
+					Path:      "/guide",
+					Focus:     "#guide-title",
+					Component: func(*runtime.Ctx, Match) work.Node { return nil },
+				}),
+			),
+		)
+	})
+	requestState.SetIsLive(true)
+
+	if err := sess.Flush(); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+
+	var pushes []protocol.RouterNavPayload
+	sess.Bus.SubscribeAll(func(topic protocol.Topic, event string, data interface{}) {
+		if topic == protocol.RouteHandler && event == string(protocol.RouterPushAction) {
+			mu.Lock()
+			pushes = append(pushes, data.(protocol.RouterNavPayload))
+			mu.Unlock()
+		}
+	})
+	lastPush := func(n int) protocol.RouterNavPayload {
+		waitFor(t, func() bool {
+			_ = sess.Flush()
+			mu.Lock()
+			defer mu.Unlock()
+			return len(pushes) == n
+		})
+		mu.Lock()
+		defer mu.Unlock()
+		return pushes[n-1]
+	}
+
+	mu.Lock()
+	navigateTo := nav
+	mu.Unlock()
+
+	navigateTo("/docs/guide", navOptions{})
+	payload := lastPush(1)
+	if payload.Scroll != string(ScrollPreserve) || payload.Focus != "#guide-title" {
+		t.Errorf("expected nested route options, got scroll=%q focus=%q", payload.Scroll, payload.Focus)
+	}
+
+	navigateTo("/", navOptions{scroll: ScrollAuto, focus: FocusNone})
+	payload = lastPush(2)
+	if payload.Scroll != string(ScrollAuto) || payload.Focus != FocusNone {
+		t.Errorf("expected link options, got scroll=%q focus=%q", payload.Scroll, payload.Focus)
+	}
+}
@@ -0,0 +1,143 @@
+package router
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/eleven-am/pondlive/internal/route"
+	"github.com/eleven-am/pondlive/internal/work"
+)
+
+type SitemapOptions struct {
+	BaseURL string
+	// BasePath is the app's WithBasePath mount; it prefixes every URL,
+	// including the sitemap link in robots.txt.
+	BasePath string
+	Routes   []work.Node
+	Params   map[string]func(context.Context) ([]map[string]string, error)
+	Disallow []string
+	// IncludeGuarded lists routes that have guards. They are left out by
+	// default, since a crawler is usually turned away from them.
+	IncludeGuarded bool
+}
+
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+	Loc string `xml:"loc"`
+}
+
+func SitemapURLs(ctx context.Context, opts SitemapOptions) ([]string, error) {
+	base := strings.TrimSuffix(opts.BaseURL, "/")
+	seen := make(map[string]bool)
+	var urls []string
+
+	add := func(href string) {
+		if seen[href] {
+			return
+		}
+		seen[href] = true
+		urls = append(urls, base+route.WithBase(opts.BasePath, href))
+	}
+
+	var walk func(entries []routeEntry, prefix string) error
+	walk = func(entries []routeEntry, prefix string) error {
+		for _, entry := range entries {
+			if len(entry.guards) > 0 && !opts.IncludeGuarded {
+				continue
+			}
+
+			pattern := entry.fullPath
+			if !strings.HasPrefix(entry.pattern, "./") {
+				pattern = joinRelativePath(prefix, pattern)
+			}
+
+			if len(entry.children) > 0 {
+				base := trimWildcardSuffix(pattern)
+				if err := walk(collectRouteEntries(entry.children, base), base); err != nil {
+					return err
+				}
+				continue
+			}
+
+			if !hasRouteParams(pattern) {
+				add(expandPattern(pattern, nil))
+				continue
+			}
+
+			enumerate, ok := opts.Params[pattern]
+			if !ok {
+				continue
+			}
+			sets, err := enumerate(ctx)
+			if err != nil {
+				return fmt.Errorf("router: sitemap params for %q: %w", pattern, err)
+			}
+			for _, params := range sets {
+				add(expandPattern(pattern, params))
+			}
+		}
+		return nil
+	}
+
+	if err := walk(collectRouteEntries(opts.Routes, "/"), "/"); err != nil {
+		return nil, err
+	}
+	return urls, nil
+}
+
+func SitemapHandler(opts SitemapOptions) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		urls, err := SitemapURLs(r.Context(), opts)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		set := sitemapURLSet{XMLNS: "http://www.sitemaps.org/schemas/sitemap/0.9"}
+		for _, loc := range urls {
+			set.URLs = append(set.URLs, sitemapURL{Loc: loc})
+		}
+
+		w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+		_, _ = w.Write([]byte(xml.Header))
+		enc := xml.NewEncoder(w)
+		enc.Indent("", "  ")
+		_ = enc.Encode(set)
+	})
+}
+
+func RobotsHandler(opts SitemapOptions) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var b strings.Builder
+		b.WriteString("User-agent: *\n")
+		if len(opts.Disallow) == 0 {
+			b.WriteString("Disallow:\n")
+		}
+		for _, path := range opts.Disallow {
+			b.WriteString("Disallow: " + path + "\n")
+		}
+		if opts.BaseURL != "" {
+			b.WriteString("\nSitemap: " + strings.TrimSuffix(opts.BaseURL, "/") + route.WithBase(opts.BasePath, "/sitemap.xml") + "\n")
+		}
+
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_, _ = w.Write([]byte(b.String()))
+	})
+}
+
+func hasRouteParams(pattern string) bool {
+	for _, seg := range strings.Split(strings.Trim(pattern, "/"), "/") {
+		if _, ok := patternParam(seg); ok {
+			return true
+		}
+	}
+	return false
+}
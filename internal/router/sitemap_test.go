@@ -0,0 +1,118 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/eleven-am/pondlive/internal/runtime"
+	"github.com/eleven-am/pondlive/internal/work"
+)
+
+func sitemapRoutes() []work.Node {
+	page := func(*runtime.Ctx, Match) work.Node { return nil }
+	return []work.Node{
+		Route(nil, RouteProps{Path: "/", Component: page}),
+		Route(nil, RouteProps{Path: "/about", Component: page}),
+		Route(nil, RouteProps{Path: "/admin", Component: page, Guards: []func(*runtime.Ctx, Match) GuardResult{
+			func(*runtime.Ctx, Match) GuardResult { return Allow() },
+		}}),
+		Route(nil, RouteProps{Path: "/blog", Component: page},
+			Route(nil, RouteProps{Path: "/", Component: page}),
+			Route(nil, RouteProps{Path: "/:slug", Component: page}),
+		),
+		Route(nil, RouteProps{Path: "/users/:id", Component: page}),
+		Route(nil, RouteProps{Path: "*", Component: page}),
+	}
+}
+
+func TestSitemapURLs(t *testing.T) {
+	urls, err := SitemapURLs(context.Background(), SitemapOptions{
+		BaseURL: "https://example.com/",
+		Routes:  sitemapRoutes(),
+		Params: map[string]func(context.Context) ([]map[string]string, error){
+			"/blog/:slug": func(context.Context) ([]map[string]string, error) {
+				return []map[string]string{{"slug": "first"}, {"slug": "second"}}, nil
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{
+		"https://example.com/",
+		"https://example.com/about",
+		"https://example.com/blog",
+		"https://example.com/blog/first",
+		"https://example.com/blog/second",
+	}
+	if strings.Join(urls, "\n") != strings.Join(want, "\n") {
+		t.Errorf("unexpected urls:\n%s", strings.Join(urls, "\n"))
+	}
+}
+
+func TestSitemapHandlers(t *testing.T) {
+	opts := SitemapOptions{
+		BaseURL:  "https://example.com",
+		Routes:   sitemapRoutes(),
+		Disallow: []string{"/admin"},
+	}
+
+	rec := httptest.NewRecorder()
+	SitemapHandler(opts).ServeHTTP(rec, httptest.NewRequest("GET", "/sitemap.xml", nil))
+	body := rec.Body.String()
+	if !strings.Contains(body, "<loc>https://example.com/about</loc>") || strings.Contains(body, "/admin") {
+		t.Errorf("unexpected sitemap:\n%s", body)
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/xml") {
+		t.Errorf("unexpected content type %q", ct)
+	}
+
+	rec = httptest.NewRecorder()
+	RobotsHandler(opts).ServeHTTP(rec, httptest.NewRequest("GET", "/robots.txt", nil))
+	want := "User-agent: *\nDisallow: /admin\n\nSitemap: https://example.com/sitemap.xml\n"
+	if rec.Body.String() != want {
+		t.Errorf("unexpected robots.txt:\n%s", rec.Body.String())
+	}
+
+	opts.Params = map[string]func(context.Context) ([]map[string]string, error){
+		"/users/:id": func(context.Context) ([]map[string]string, error) { return nil, errors.New("db down") },
+	}
+	rec = httptest.NewRecorder()
+	SitemapHandler(opts).ServeHTTP(rec, httptest.NewRequest("GET", "/sitemap.xml", nil))
+	if rec.Code != 500 {
+		t.Errorf("expected 500 when enumerator fails, got %d", rec.Code)
+	}
+}
+
+func TestSitemapBasePathAndGuardedRoutes(t *testing.T) {
+	opts := SitemapOptions{
+		BaseURL:        "https://example.com",
+		BasePath:       "app/",
+		Routes:         sitemapRoutes(),
+		IncludeGuarded: true,
+	}
+
+	urls, err := SitemapURLs(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{
+		"https://example.com/app/",
+		"https://example.com/app/about",
+		"https://example.com/app/admin",
+		"https://example.com/app/blog",
+	}
+	if strings.Join(urls, "\n") != strings.Join(want, "\n") {
+		t.Errorf("unexpected urls:\n%s", strings.Join(urls, "\n"))
+	}
+
+	rec := httptest.NewRecorder()
+	RobotsHandler(opts).ServeHTTP(rec, httptest.NewRequest("GET", "/robots.txt", nil))
+	if !strings.HasSuffix(rec.Body.String(), "\nSitemap: https://example.com/app/sitemap.xml\n") {
+		t.Errorf("unexpected robots.txt:\n%s", rec.Body.String())
+	}
+}
@@ -0,0 +1,79 @@
+package router
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/eleven-am/pondlive/internal/headers"
+	"github.com/eleven-am/pondlive/internal/runtime"
+	"github.com/eleven-am/pondlive/internal/work"
+)
+
+func TestUnmatchedRouteSetsNotFound(t *testing.T) {
+	app := func(ctx *runtime.Ctx) work.Node {
+		return Routes(ctx,
+			Route(ctx, RouteProps{
+				Path: "/docs",
+				Component: func(ctx *runtime.Ctx, _ Match) work.Node {
+					return Outlet(ctx)
+				},
+			},
+				Route(ctx, RouteProps{
+					Path: "/intro",
+					Component: func(ctx *runtime.Ctx, _ Match) work.Node {
+						return &work.Text{Value: "intro"}
+					},
+				}),
+			),
+		)
+	}
+
+	for path, want := range map[string]int{
+		"/docs/intro":   http.StatusOK,
+		"/missing":      http.StatusNotFound,
+		"/docs/missing": http.StatusNotFound,
+	} {
+		sess, requestState := guardTestSession(path, app)
+		if err := sess.Flush(); err != nil {
+			t.Fatalf("flush failed: %v", err)
+		}
+		if got := requestState.Status(); got != want {
+			t.Errorf("%s: expected status %d, got %d", path, want, got)
+		}
+	}
+}
+
+func TestRedirectCodeDuringSSR(t *testing.T) {
+	sess, requestState := guardTestSession("/old", func(ctx *runtime.Ctx) work.Node {
+		return Redirect(ctx, RedirectProps{To: "/new", Code: http.StatusMovedPermanently})
+	})
+	if err := sess.Flush(); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+
+	location, status, ok := requestState.Redirect()
+	if !ok || location != "/new" || status != http.StatusMovedPermanently {
+		t.Errorf("expected 301 to /new, got %q (%d)", location, status)
+	}
+}
+
+func TestRouteComponentSetsStatus(t *testing.T) {
+	sess, requestState := guardTestSession("/gone", func(ctx *runtime.Ctx) work.Node {
+		return Routes(ctx,
+			Route(ctx, RouteProps{
+				Path: "/gone",
+				Component: func(ctx *runtime.Ctx, _ Match) work.Node {
+					headers.UseStatus(ctx, http.StatusGone)
+					return &work.Text{Value: "gone"}
+				},
+			}),
+		)
+	})
+	if err := sess.Flush(); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+
+	if got := requestState.Status(); got != http.StatusGone {
+		t.Errorf("expected status %d, got %d", http.StatusGone, got)
+	}
+}
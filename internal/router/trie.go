@@ -3,6 +3,8 @@ package router
 import (
 	"sort"
 	"strings"
+
+	"github.com/eleven-am/pondlive/internal/route"
 )
 
 type nodeType int
@@ -14,12 +16,13 @@ const (
 )
 
 type node struct {
-	typ      nodeType
-	label    string
-	prefix   string
-	parent   *node
-	children []*node
-	entry    *routeEntry
+	typ        nodeType
+	label      string
+	prefix     string
+	constraint *route.Constraint
+	parent     *node
+	children   []*node
+	entry      *routeEntry
 }
 
 type routerTrie struct {
@@ -53,29 +56,31 @@ func (t *routerTrie) Insert(pattern string, entry routeEntry) {
 		segments = []string{}
 	}
 
-	curr := t.root
-	for _, seg := range segments {
+	t.insert(t.root, segments, &entry, false)
+}
+
+func (t *routerTrie) insert(curr *node, segments []string, entry *routeEntry, implicit bool) {
+	for i, seg := range segments {
 		if seg == "" {
 			continue
 		}
 
-		var child *node
-		typ := nodeStatic
-		label := seg
-		prefix := seg
+		typ, label, constraint, optional := parseTrieSegment(seg)
+		if optional {
+			t.insert(curr, segments[i+1:], entry, true)
+		}
 
-		if strings.HasPrefix(seg, ":") {
-			typ = nodeParam
-			label = strings.TrimPrefix(seg, ":")
+		prefix := seg
+		switch typ {
+		case nodeParam:
 			prefix = ":"
-		} else if strings.HasPrefix(seg, "*") {
-			typ = nodeWildcard
-			label = strings.TrimPrefix(seg, "*")
+		case nodeWildcard:
 			prefix = "*"
 		}
 
+		var child *node
 		for _, c := range curr.children {
-			if c.typ == typ && c.label == label {
+			if c.typ == typ && c.label == label && sameConstraint(c.constraint, constraint) {
 				child = c
 				break
 			}
@@ -83,21 +88,45 @@ func (t *routerTrie) Insert(pattern string, entry routeEntry) {
 
 		if child == nil {
 			child = &node{
-				typ:    typ,
-				label:  label,
-				prefix: prefix,
-				parent: curr,
+				typ:        typ,
+				label:      label,
+				prefix:     prefix,
+				constraint: constraint,
+				parent:     curr,
 			}
 			curr.children = append(curr.children, child)
 
-			sort.Slice(curr.children, func(i, j int) bool {
-				return curr.children[i].typ < curr.children[j].typ
+			children := curr.children
+			sort.SliceStable(children, func(i, j int) bool {
+				if children[i].typ != children[j].typ {
+					return children[i].typ < children[j].typ
+				}
+				return children[i].constraint != nil && children[j].constraint == nil
 			})
 		}
 		curr = child
 	}
 
-	curr.entry = &entry
+	if !implicit || curr.entry == nil {
+		curr.entry = entry
+	}
+}
+
+func parseTrieSegment(seg string) (nodeType, string, *route.Constraint, bool) {
+	if param, ok := route.ParseSegment(seg); ok {
+		return nodeParam, param.Name, param.Constraint, param.Optional
+	}
+	if strings.HasPrefix(seg, "*") {
+		return nodeWildcard, strings.TrimPrefix(seg, "*"), nil, false
+	}
+	return nodeStatic, seg, nil, false
+}
+
+func sameConstraint(a, b *route.Constraint) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Source == b.Source
 }
 
 func (t *routerTrie) Match(path string) *matchResult {
@@ -181,6 +210,9 @@ func (t *routerTrie) Match(path string) *matchResult {
 					}
 				}
 			case nodeParam:
+				if !child.constraint.Match(seg) {
+					continue
+				}
 				newParams := copyParams(params)
 				newParams[child.label] = seg
 				search(child, nextPathIdx, newParams)
@@ -219,3 +251,49 @@ func copyParams(src map[string]string) map[string]string {
 	}
 	return dst
 }
+
+func (t *routerTrie) conflict(pattern string) (string, bool) {
+	curr := t.root
+	for _, seg := range strings.Split(strings.Trim(pattern, "/"), "/") {
+		if seg == "" {
+			continue
+		}
+
+		typ, label, constraint, _ := parseTrieSegment(seg)
+
+		var next *node
+		for _, c := range curr.children {
+			if c.typ != typ || !sameConstraint(c.constraint, constraint) {
+				continue
+			}
+			if c.label == label {
+				next = c
+				break
+			}
+			if typ != nodeStatic {
+				return firstEntryPattern(c), true
+			}
+		}
+		if next == nil {
+			return "", false
+		}
+		curr = next
+	}
+
+	if curr.entry != nil {
+		return curr.entry.fullPath, true
+	}
+	return "", false
+}
+
+func firstEntryPattern(n *node) string {
+	if n.entry != nil {
+		return n.entry.fullPath
+	}
+	for _, c := range n.children {
+		if p := firstEntryPattern(c); p != "" {
+			return p
+		}
+	}
+	return ""
+}
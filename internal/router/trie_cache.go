@@ -0,0 +1,103 @@
+package router
+
+import (
+	"container/list"
+	"strings"
+	"sync"
+
+	"github.com/eleven-am/pondlive/internal/runtime"
+)
+
+const maxCachedTries = 512
+
+// trieCache keeps the tries compiled for one router's route tables, dropping
+// the least recently used once it holds max of them.
+type trieCache struct {
+	mu    sync.Mutex
+	max   int
+	order *list.List
+	tries map[string]*list.Element
+}
+
+type cachedTrie struct {
+	key  string
+	trie *routerTrie
+}
+
+var trieCacheCtx = runtime.CreateContext[*trieCache](nil)
+
+func newTrieCache(max int) *trieCache {
+	return &trieCache{
+		max:   max,
+		order: list.New(),
+		tries: make(map[string]*list.Element),
+	}
+}
+
+func routesFingerprint(entries []routeEntry) string {
+	var b strings.Builder
+	for _, e := range entries {
+		b.WriteString(e.fullPath)
+		b.WriteByte(0)
+	}
+	return b.String()
+}
+
+// compile returns the trie for entries, building it on a miss. A nil cache
+// builds a fresh trie every time.
+func (c *trieCache) compile(entries []routeEntry) *routerTrie {
+	if c == nil {
+		return buildTrie(entries)
+	}
+
+	key := routesFingerprint(entries)
+
+	c.mu.Lock()
+	if elem, ok := c.tries[key]; ok {
+		c.order.MoveToFront(elem)
+		c.mu.Unlock()
+		return elem.Value.(*cachedTrie).trie
+	}
+	c.mu.Unlock()
+
+	trie := buildTrie(entries)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.tries[key]; ok {
+		c.order.MoveToFront(elem)
+		return elem.Value.(*cachedTrie).trie
+	}
+	c.tries[key] = c.order.PushFront(&cachedTrie{key: key, trie: trie})
+	for c.max > 0 && c.order.Len() > c.max {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.tries, oldest.Value.(*cachedTrie).key)
+	}
+	return trie
+}
+
+func buildTrie(entries []routeEntry) *routerTrie {
+	trie := newRouterTrie()
+	for i, e := range entries {
+		trie.Insert(e.fullPath, routeEntry{pattern: e.pattern, fullPath: e.fullPath, index: i})
+	}
+	return trie
+}
+
+func matchRoutes(trie *routerTrie, entries []routeEntry, path string) *matchResult {
+	if trie == nil {
+		return nil
+	}
+
+	result := trie.Match(path)
+	if result == nil || result.Entry == nil {
+		return nil
+	}
+
+	return &matchResult{
+		Entry:  &entries[result.Entry.index],
+		Params: result.Params,
+		Rest:   result.Rest,
+	}
+}
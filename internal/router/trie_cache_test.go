@@ -0,0 +1,108 @@
+package router
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/eleven-am/pondlive/internal/runtime"
+	"github.com/eleven-am/pondlive/internal/work"
+)
+
+func largeRouteTable(n int, component func(*runtime.Ctx, Match) work.Node) []routeEntry {
+	entries := make([]routeEntry, 0, n*3)
+	for i := 0; i < n; i++ {
+		for _, pattern := range []string{
+			fmt.Sprintf("/section%d", i),
+			fmt.Sprintf("/section%d/:id(int)", i),
+			fmt.Sprintf("/section%d/:id/edit", i),
+		} {
+			entries = append(entries, routeEntry{pattern: pattern, fullPath: pattern, component: component})
+		}
+	}
+	return entries
+}
+
+func TestCompiledTrieSharedAcrossIdenticalTables(t *testing.T) {
+	var first, second int
+	a := largeRouteTable(10, func(*runtime.Ctx, Match) work.Node { first++; return nil })
+	b := largeRouteTable(10, func(*runtime.Ctx, Match) work.Node { second++; return nil })
+
+	tries := newTrieCache(maxCachedTries)
+	trieA := tries.compile(a)
+	trieB := tries.compile(b)
+	if trieA != trieB {
+		t.Fatal("expected structurally identical route tables to share a trie")
+	}
+
+	result := matchRoutes(trieB, b, "/section7/42")
+	if result == nil || result.Entry.fullPath != "/section7/:id(int)" || result.Params["id"] != "42" {
+		t.Fatalf("unexpected match %+v", result)
+	}
+	result.Entry.component(nil, Match{})
+	if first != 0 || second != 1 {
+		t.Error("expected match to resolve to the caller's own entries")
+	}
+
+	c := append(largeRouteTable(10, nil), routeEntry{pattern: "/extra", fullPath: "/extra"})
+	if tries.compile(c) == trieA {
+		t.Error("expected a different route table to compile its own trie")
+	}
+}
+
+func TestCachedTrieMatchAvoidsRebuild(t *testing.T) {
+	entries := largeRouteTable(200, nil)
+	tries := newTrieCache(maxCachedTries)
+	tries.compile(entries)
+
+	allocs := testing.AllocsPerRun(20, func() {
+		matchRoutes(tries.compile(entries), entries, "/section150/42/edit")
+	})
+	if allocs > 100 {
+		t.Errorf("expected cached match to skip trie construction, got %.0f allocs", allocs)
+	}
+}
+
+func TestTrieCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	tries := newTrieCache(2)
+	table := func(path string) []routeEntry {
+		return []routeEntry{{pattern: path, fullPath: path}}
+	}
+
+	a := tries.compile(table("/a"))
+	tries.compile(table("/b"))
+	if tries.compile(table("/a")) != a {
+		t.Fatal("expected /a to be cached")
+	}
+	tries.compile(table("/c"))
+
+	if tries.compile(table("/a")) != a {
+		t.Error("expected the recently used /a to survive eviction")
+	}
+	if len(tries.tries) != 2 {
+		t.Errorf("expected the cache to stay at 2 tries, got %d", len(tries.tries))
+	}
+	if _, ok := tries.tries[routesFingerprint(table("/b"))]; ok {
+		t.Error("expected the least recently used /b to be evicted")
+	}
+}
+
+func BenchmarkRouteMatchRebuildTrie(b *testing.B) {
+	entries := largeRouteTable(200, nil)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		trie := newRouterTrie()
+		for _, e := range entries {
+			trie.Insert(e.fullPath, e)
+		}
+		trie.Match("/section150/42/edit")
+	}
+}
+
+func BenchmarkRouteMatchCachedTrie(b *testing.B) {
+	entries := largeRouteTable(200, nil)
+	tries := newTrieCache(maxCachedTries)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		matchRoutes(tries.compile(entries), entries, "/section150/42/edit")
+	}
+}
@@ -1,9 +1,15 @@
 package router
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"net/url"
+	"strconv"
 
+	"github.com/google/uuid"
+
+	"github.com/eleven-am/pondlive/internal/metatags"
 	"github.com/eleven-am/pondlive/internal/route"
 	"github.com/eleven-am/pondlive/internal/runtime"
 	"github.com/eleven-am/pondlive/internal/work"
@@ -12,6 +18,7 @@ import (
 var (
 	ErrParamNotFound      = errors.New("router: param not found")
 	ErrQueryParamNotFound = errors.New("router: query param not found")
+	ErrInvalidParam       = errors.New("router: invalid param")
 )
 
 // Location is re-exported to mirror the public API of the existing router.
@@ -30,6 +37,35 @@ type outletRenderer func(*runtime.Ctx) work.Node
 type RouteProps struct {
 	Path      string
 	Component func(*runtime.Ctx, Match) work.Node
+	KeepAlive bool
+	Guards    []func(*runtime.Ctx, Match) GuardResult
+	Loader    func(context.Context, Match) (any, error)
+	Scroll    ScrollBehavior
+	Focus     string
+	Meta      func(Match) *metatags.Meta
+	Handle    any
+}
+
+type GuardResult struct {
+	Redirect string
+	Status   int
+	Fallback func(*runtime.Ctx) work.Node
+}
+
+func Allow() GuardResult {
+	return GuardResult{}
+}
+
+func GuardRedirect(to string, status int) GuardResult {
+	return GuardResult{Redirect: to, Status: status}
+}
+
+func GuardFallback(fallback func(*runtime.Ctx) work.Node) GuardResult {
+	return GuardResult{Fallback: fallback}
+}
+
+func (r GuardResult) Allowed() bool {
+	return r.Redirect == "" && r.Fallback == nil
 }
 
 type Match struct {
@@ -53,6 +89,30 @@ func (m Match) Param(key string) (string, error) {
 	return val, nil
 }
 
+func (m Match) Int(key string) (int, error) {
+	val, err := m.Param(key)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s", err, key)
+	}
+	n, err := strconv.Atoi(val)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s=%q is not an integer", ErrInvalidParam, key, val)
+	}
+	return n, nil
+}
+
+func (m Match) UUID(key string) (uuid.UUID, error) {
+	val, err := m.Param(key)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("%w: %s", err, key)
+	}
+	id, err := uuid.Parse(val)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("%w: %s=%q is not a UUID", ErrInvalidParam, key, val)
+	}
+	return id, nil
+}
+
 func (m Match) QueryParam(key string) (string, error) {
 	if m.query == nil {
 		return "", ErrQueryParamNotFound
@@ -84,6 +144,15 @@ type routeEntry struct {
 	children  []work.Node
 	slot      string
 	key       string
+	keepAlive bool
+	guards    []func(*runtime.Ctx, Match) GuardResult
+	loader    func(context.Context, Match) (any, error)
+	scroll    ScrollBehavior
+	focus     string
+	meta      func(Match) *metatags.Meta
+	handle    any
+	index     int
+	def       *routeDefinition
 }
 
 const routeMetadataKey = "router:entry"
@@ -98,11 +167,14 @@ type slotEntry struct {
 	name     string
 	fallback func(*runtime.Ctx) work.Node
 	routes   []routeEntry
+	trie     *routerTrie
 }
 
 type LinkProps struct {
 	To      string
 	Replace bool
+	Scroll  ScrollBehavior
+	Focus   string
 }
 
 type NavLinkProps struct {
@@ -111,11 +183,29 @@ type NavLinkProps struct {
 	ClassName   string
 	ActiveClass string
 	End         bool
+	Scroll      ScrollBehavior
+	Focus       string
+}
+
+type ScrollBehavior string
+
+const (
+	ScrollDefault  ScrollBehavior = ""
+	ScrollAuto     ScrollBehavior = "auto"
+	ScrollPreserve ScrollBehavior = "preserve"
+)
+
+const FocusNone = "none"
+
+type navOptions struct {
+	scroll ScrollBehavior
+	focus  string
 }
 
 type RedirectProps struct {
 	To      string
 	Replace bool
+	Code    int
 }
 
 type NavigationEvent struct {
@@ -1,6 +1,8 @@
 package runtime
 
 import (
+	"reflect"
+
 	"github.com/eleven-am/pondlive/internal/work"
 )
 
@@ -12,6 +14,7 @@ type PropsComponentWrapper[P any] = func(ctx *Ctx, props P, items ...work.Item)
 
 func Component(fn func(ctx *Ctx, children []work.Item) work.Node) ComponentWrapper {
 	name := captureComponentName(3)
+	fnID := reflect.ValueOf(fn).Pointer()
 
 	wrappedFn := func(ctx *Ctx, _ any, children []work.Item) work.Node {
 		return fn(ctx, children)
@@ -20,25 +23,59 @@ func Component(fn func(ctx *Ctx, children []work.Item) work.Node) ComponentWrapp
 	return func(ctx *Ctx, items ...work.Item) Node {
 		comp := work.Component(wrappedFn, items...)
 		comp.Name = name
+		comp.FnID = fnID
 		return comp
 	}
 }
 
 func PropsComponent[P any](fn func(ctx *Ctx, props P, children []work.Item) work.Node) PropsComponentWrapper[P] {
 	name := captureComponentName(3)
+	fnID := reflect.ValueOf(fn).Pointer()
+	wrappedFn := wrapPropsFn(fn)
 
-	wrappedFn := func(ctx *Ctx, propsAny any, children []work.Item) work.Node {
-		p, ok := propsAny.(P)
-		if !ok {
-			var zero P
-			p = zero
+	return func(ctx *Ctx, props P, items ...work.Item) Node {
+		comp := work.PropsComponent(wrappedFn, props, items...)
+		comp.Name = name
+		comp.FnID = fnID
+		return comp
+	}
+}
+
+func MemoComponent[P any](fn func(ctx *Ctx, props P, children []work.Item) work.Node, eq ...func(a, b P) bool) PropsComponentWrapper[P] {
+	name := captureComponentName(3)
+	fnID := reflect.ValueOf(fn).Pointer()
+	wrappedFn := wrapPropsFn(fn)
+
+	var propsEq func(a, b any) bool
+	if len(eq) > 0 && eq[0] != nil {
+		custom := eq[0]
+		propsEq = func(a, b any) bool {
+			pa, okA := a.(P)
+			pb, okB := b.(P)
+			if !okA || !okB {
+				return false
+			}
+			return custom(pa, pb)
 		}
-		return fn(ctx, p, children)
 	}
 
 	return func(ctx *Ctx, props P, items ...work.Item) Node {
 		comp := work.PropsComponent(wrappedFn, props, items...)
 		comp.Name = name
+		comp.FnID = fnID
+		comp.Memo = true
+		comp.PropsEqual = propsEq
 		return comp
 	}
 }
+
+func wrapPropsFn[P any](fn func(ctx *Ctx, props P, children []work.Item) work.Node) func(*Ctx, any, []work.Item) work.Node {
+	return func(ctx *Ctx, propsAny any, children []work.Item) work.Node {
+		p, ok := propsAny.(P)
+		if !ok {
+			var zero P
+			p = zero
+		}
+		return fn(ctx, p, children)
+	}
+}
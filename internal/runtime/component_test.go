@@ -1,9 +1,11 @@
 package runtime
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/eleven-am/pondlive/internal/protocol"
+	"github.com/eleven-am/pondlive/internal/view"
 	"github.com/eleven-am/pondlive/internal/work"
 )
 
@@ -149,6 +151,175 @@ func TestPropsComponentWithWrongPropsType(t *testing.T) {
 	}
 }
 
+func TestMemoComponentMarksNode(t *testing.T) {
+	type TestProps struct {
+		Title string
+	}
+
+	fn := func(ctx *Ctx, props TestProps, children []work.Item) work.Node {
+		return &work.Text{Value: props.Title}
+	}
+
+	plain := MemoComponent(fn)(&Ctx{}, TestProps{Title: "a"}).(*work.ComponentNode)
+	if !plain.Memo {
+		t.Error("expected Memo to be set")
+	}
+	if plain.PropsEqual != nil {
+		t.Error("expected no custom PropsEqual without eq")
+	}
+
+	custom := MemoComponent(fn, func(a, b TestProps) bool { return true })(&Ctx{}, TestProps{}).(*work.ComponentNode)
+	if custom.PropsEqual == nil {
+		t.Fatal("expected custom PropsEqual")
+	}
+	if !custom.PropsEqual(TestProps{Title: "a"}, TestProps{Title: "b"}) {
+		t.Error("expected custom eq to be used")
+	}
+	if custom.PropsEqual("wrong", TestProps{}) {
+		t.Error("expected mismatched prop types to compare unequal")
+	}
+}
+
+func memoTestSession(root func(*Ctx, any, []work.Item) work.Node) *Session {
+	sess := &Session{
+		Components:        make(map[string]*Instance),
+		MountedComponents: make(map[*Instance]struct{}),
+		DirtySet:          make(map[*Instance]struct{}),
+		Bus:               protocol.NewBus(),
+	}
+	sess.Root = &Instance{ID: "root", Fn: root, HookFrame: []HookSlot{}}
+	sess.Components["root"] = sess.Root
+	return sess
+}
+
+func TestMemoComponentSkipsRenderWithEqualProps(t *testing.T) {
+	type RowProps struct {
+		Label   string
+		OnClick func()
+	}
+
+	renders := 0
+	row := MemoComponent(func(ctx *Ctx, props RowProps, _ []work.Item) work.Node {
+		renders++
+		return &work.Element{
+			Tag:      "button",
+			Children: []work.Node{&work.Text{Value: props.Label}},
+			Handlers: map[string]work.Handler{
+				"click": {Fn: func(work.Event) work.Updates { return nil }},
+			},
+		}
+	}, func(a, b RowProps) bool { return a.Label == b.Label })
+
+	var setTick func(int)
+	sess := memoTestSession(func(ctx *Ctx, _ any, _ []work.Item) work.Node {
+		tick, set := UseState(ctx, 0)
+		setTick = set
+		return &work.Element{
+			Tag: "div",
+			Children: []work.Node{
+				&work.Text{Value: fmt.Sprintf("tick %d", tick)},
+				row(ctx, RowProps{Label: "row", OnClick: func() {}}),
+			},
+		}
+	})
+
+	if err := sess.Flush(); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+	if renders != 1 {
+		t.Fatalf("expected 1 render after mount, got %d", renders)
+	}
+
+	firstView := sess.View.(*view.Element).Children[1]
+
+	setTick(1)
+
+	if renders != 1 {
+		t.Errorf("expected memo component to skip render, got %d renders", renders)
+	}
+
+	secondView := sess.View.(*view.Element).Children[1]
+	if firstView != secondView {
+		t.Error("expected memo component view subtree to be reused")
+	}
+
+	if len(sess.Root.Children) != 1 {
+		t.Errorf("expected memo child to stay mounted, got %d children", len(sess.Root.Children))
+	}
+
+	handlerID := secondView.(*view.Element).Handlers[0].Handler
+	sess.handlerIDsMu.Lock()
+	_, subscribed := sess.allHandlerSubs[handlerID]
+	sess.handlerIDsMu.Unlock()
+	if !subscribed {
+		t.Error("expected reused handler to stay subscribed")
+	}
+}
+
+func TestMemoComponentRendersWhenPropsChange(t *testing.T) {
+	renders := 0
+	label := MemoComponent(func(ctx *Ctx, props string, _ []work.Item) work.Node {
+		renders++
+		return &work.Text{Value: props}
+	})
+
+	var setLabel func(string)
+	sess := memoTestSession(func(ctx *Ctx, _ any, _ []work.Item) work.Node {
+		text, set := UseState(ctx, "a")
+		setLabel = set
+		return &work.Element{Tag: "div", Children: []work.Node{label(ctx, text)}}
+	})
+
+	if err := sess.Flush(); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+
+	setLabel("b")
+
+	if renders != 2 {
+		t.Errorf("expected 2 renders, got %d", renders)
+	}
+	text, ok := sess.View.(*view.Element).Children[0].(*view.Text)
+	if !ok || text.Text != "b" {
+		t.Errorf("expected updated text, got %#v", sess.View.(*view.Element).Children[0])
+	}
+}
+
+func TestMemoComponentReconvertsWhenDescendantDirty(t *testing.T) {
+	var setInner func(string)
+	inner := Component(func(ctx *Ctx, _ []work.Item) work.Node {
+		text, set := UseState(ctx, "inner")
+		setInner = set
+		return &work.Text{Value: text}
+	})
+
+	outerRenders := 0
+	outer := MemoComponent(func(ctx *Ctx, _ int, _ []work.Item) work.Node {
+		outerRenders++
+		return &work.Element{Tag: "section", Children: []work.Node{inner(ctx)}}
+	})
+
+	sess := memoTestSession(func(ctx *Ctx, _ any, _ []work.Item) work.Node {
+		return &work.Element{Tag: "div", Children: []work.Node{outer(ctx, 1)}}
+	})
+
+	if err := sess.Flush(); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+
+	setInner("changed")
+
+	if outerRenders != 1 {
+		t.Errorf("expected memo component to skip render, got %d renders", outerRenders)
+	}
+
+	section := sess.View.(*view.Element).Children[0].(*view.Element)
+	text, ok := section.Children[0].(*view.Text)
+	if !ok || text.Text != "changed" {
+		t.Errorf("expected descendant update to reach the view, got %#v", section.Children[0])
+	}
+}
+
 func TestSessionSetDevMode(t *testing.T) {
 	sess := &Session{}
 
@@ -287,3 +458,92 @@ func TestInstanceSetDirty(t *testing.T) {
 		}
 	})
 }
+
+func TestComponentIdentityFollowsUserFunction(t *testing.T) {
+	counter := func(label string) func(*Ctx, []work.Item) work.Node {
+		return func(ctx *Ctx, _ []work.Item) work.Node {
+			count, _ := UseState(ctx, len(label))
+			return &work.Text{Value: fmt.Sprintf("%s:%d", label, count)}
+		}
+	}
+	var setBump func(int)
+	bumpable := func(ctx *Ctx, _ []work.Item) work.Node {
+		count, set := UseState(ctx, 0)
+		setBump = set
+		return &work.Text{Value: fmt.Sprintf("bump:%d", count)}
+	}
+
+	cases := map[string]struct {
+		first, second ComponentWrapper
+		keys          [2]string
+		want          string
+	}{
+		"different functions remount": {
+			first:  Component(bumpable),
+			second: Component(counter("other")),
+			want:   "other:5",
+		},
+		"same function keeps state": {
+			first:  Component(bumpable),
+			second: Component(bumpable),
+			want:   "bump:7",
+		},
+		"same function with distinct keys remounts": {
+			first:  Component(bumpable),
+			second: Component(bumpable),
+			keys:   [2]string{"a", "b"},
+			want:   "bump:0",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			setBump = nil
+			var setSecond func(bool)
+			sess := memoTestSession(func(ctx *Ctx, _ any, _ []work.Item) work.Node {
+				second, set := UseState(ctx, false)
+				setSecond = set
+				comp, key := tc.first, tc.keys[0]
+				if second {
+					comp, key = tc.second, tc.keys[1]
+				}
+				var items []work.Item
+				if key != "" {
+					items = append(items, work.Key(key))
+				}
+				return &work.Element{Tag: "div", Children: []work.Node{comp(ctx, items...)}}
+			})
+
+			if err := sess.Flush(); err != nil {
+				t.Fatalf("flush failed: %v", err)
+			}
+			if setBump != nil {
+				setBump(7)
+			}
+			setSecond(true)
+
+			if text := sess.View.(*view.Element).Children[0].(*view.Text).Text; text != tc.want {
+				t.Errorf("expected %q after switching, got %q", tc.want, text)
+			}
+		})
+	}
+}
+
+func TestMemoPropsEqualWithUncomparableInterfaceField(t *testing.T) {
+	type props struct {
+		V any
+	}
+
+	if !memoPropsEqual(props{V: []int{1}}, props{V: []int{1}}) {
+		t.Error("expected equal slices behind an interface field to compare equal")
+	}
+	if memoPropsEqual(props{V: []int{1}}, props{V: []int{2}}) {
+		t.Error("expected different slices to compare unequal")
+	}
+	if memoPropsEqual(props{V: 1}, props{V: []int{1}}) {
+		t.Error("expected mismatched dynamic types to compare unequal")
+	}
+	if !memoPropsEqual(props{V: "a"}, props{V: "a"}) {
+		t.Error("expected comparable values to compare equal")
+	}
+}
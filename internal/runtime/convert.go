@@ -41,12 +41,16 @@ func (s *Session) convertWorkToView(node work.Node, parent *Instance) view.Node
 	case *work.PortalTarget:
 		return s.convertPortalTarget()
 
+	case *work.StaticNode:
+		return s.convertStatic(n, parent)
+
 	default:
 		return nil
 	}
 }
 
 func (s *Session) convertPortalNode(portal *work.PortalNode, parent *Instance) view.Node {
+	s.portalSeq++
 	for _, child := range portal.Children {
 		if viewChild := s.convertWorkToView(child, parent); viewChild != nil {
 			s.PortalViews = append(s.PortalViews, viewChild)
@@ -56,6 +60,7 @@ func (s *Session) convertPortalNode(portal *work.PortalNode, parent *Instance) v
 }
 
 func (s *Session) convertPortalTarget() view.Node {
+	s.portalSeq++
 	if len(s.PortalViews) == 0 {
 		return nil
 	}
@@ -119,9 +124,17 @@ func (s *Session) convertComponent(comp *work.ComponentNode, parent *Instance) v
 		return nil
 	}
 
-	inst := parent.EnsureChild(s, comp.Fn, comp.Key, comp.Props, comp.InputChildren)
+	fnID := comp.FnID
+	if fnID == 0 {
+		fnID = reflect.ValueOf(comp.Fn).Pointer()
+	}
+
+	inst := parent.ensureChild(s, comp.Fn, fnID, comp.Key, comp.Props, comp.InputChildren)
 	inst.Name = comp.Name
 	inst.InputAttrs = comp.InputAttrs
+	if parent.keepAlive != nil {
+		inst.keepAliveEligible = parent.keepAlive.enabled
+	}
 	inst.ParentContextEpoch = parent.CombinedContextEpoch
 	inst.CombinedContextEpochs = inst.buildCombinedContextEpochs()
 
@@ -131,9 +144,9 @@ func (s *Session) convertComponent(comp *work.ComponentNode, parent *Instance) v
 		needsRender = true
 	} else if inst.RenderedThisFlush {
 		needsRender = false
-	} else if inst.Dirty {
+	} else if inst.isDirty() {
 		needsRender = true
-	} else if !propsEqual(inst.PrevProps, comp.Props) {
+	} else if !componentPropsEqual(comp, inst.PrevProps, comp.Props) {
 		needsRender = true
 	} else if inputChildrenChanged(inst.PrevInputChildren, comp.InputChildren) {
 		needsRender = true
@@ -147,8 +160,117 @@ func (s *Session) convertComponent(comp *work.ComponentNode, parent *Instance) v
 		inst.snapshotContextDeps(parent)
 	}
 
+	if comp.Memo && !needsRender && inst.ViewNode != nil && memoSubtreeClean(inst) {
+		s.retainMemoSubtree(inst)
+		return inst.ViewNode
+	}
+
+	portalSeq := s.portalSeq
+	node := s.convertInstanceTree(inst)
+
+	inst.ViewNode = nil
+	if comp.Memo && s.portalSeq == portalSeq {
+		inst.ViewNode = node
+	}
+
+	return node
+}
+
+func (s *Session) convertInstanceTree(inst *Instance) view.Node {
 	inst.NextHandlerIndex = 0
-	return s.convertWorkToView(inst.WorkTree, inst)
+	node := s.convertWorkToView(inst.WorkTree, inst)
+	inst.pruneStaticViews(s.flushSeq)
+	return node
+}
+
+func componentPropsEqual(comp *work.ComponentNode, prev, next any) bool {
+	if comp.PropsEqual != nil {
+		return comp.PropsEqual(prev, next)
+	}
+	if comp.Memo {
+		return memoPropsEqual(prev, next)
+	}
+	return propsEqual(prev, next)
+}
+
+func memoPropsEqual(a, b any) bool {
+	va := reflect.ValueOf(a)
+	vb := reflect.ValueOf(b)
+	if !va.IsValid() || !vb.IsValid() {
+		return va.IsValid() == vb.IsValid()
+	}
+	if va.Type() != vb.Type() {
+		return false
+	}
+	// A comparable type can still hold an uncomparable value in an interface
+	// field, where == would panic, so check the values themselves.
+	if va.Comparable() && vb.Comparable() {
+		return va.Equal(vb)
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func memoSubtreeClean(inst *Instance) bool {
+	if inst.RenderedThisFlush || inst.isDirty() {
+		return false
+	}
+
+	inst.mu.Lock()
+	children := make([]*Instance, len(inst.Children))
+	copy(children, inst.Children)
+	inst.mu.Unlock()
+
+	for _, child := range children {
+		if len(child.ContextDeps) > 0 || !memoSubtreeClean(child) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Session) retainMemoSubtree(inst *Instance) {
+	s.retainHandlers(inst.ViewNode)
+	retainChildren(inst)
+}
+
+func retainChildren(inst *Instance) {
+	inst.mu.Lock()
+	if inst.ReferencedChildren == nil {
+		inst.ReferencedChildren = make(map[string]bool)
+	}
+	children := make([]*Instance, len(inst.Children))
+	copy(children, inst.Children)
+	for _, child := range children {
+		inst.ReferencedChildren[child.ID] = true
+	}
+	inst.mu.Unlock()
+
+	for _, child := range children {
+		retainChildren(child)
+	}
+}
+
+func (s *Session) retainHandlers(node view.Node) {
+	switch n := node.(type) {
+	case *view.Element:
+		if len(n.Handlers) > 0 {
+			s.handlerIDsMu.Lock()
+			if s.currentHandlerIDs == nil {
+				s.currentHandlerIDs = make(map[string]bool)
+			}
+			for _, h := range n.Handlers {
+				s.currentHandlerIDs[h.Handler] = true
+			}
+			s.handlerIDsMu.Unlock()
+		}
+		for _, child := range n.Children {
+			s.retainHandlers(child)
+		}
+	case *view.Fragment:
+		for _, child := range n.Children {
+			s.retainHandlers(child)
+		}
+	}
 }
 
 func propsEqual(a, b any) bool {
@@ -192,6 +314,9 @@ func nodeChanged(prev, curr work.Node) bool {
 		if p.Key != c.Key {
 			return true
 		}
+		if p.FnID != c.FnID || !depsValueEqual(p.Fn, c.Fn) {
+			return true
+		}
 		if !depsValueEqual(p.Props, c.Props) {
 			return true
 		}
@@ -247,6 +372,15 @@ func nodeChanged(prev, curr work.Node) bool {
 			return true
 		}
 
+	case *work.StaticNode:
+		c, ok := curr.(*work.StaticNode)
+		if !ok {
+			return true
+		}
+		if p.Key != c.Key {
+			return true
+		}
+
 	default:
 		return !reflect.DeepEqual(prev, curr)
 	}
@@ -370,7 +504,17 @@ func (s *Session) registerHandler(inst *Instance, elem *work.Element, event stri
 			return
 		}
 
-		handler.Fn(event)
+		event.Value, _ = event.Payload["target.value"].(string)
+		if form, ok := event.Payload["form"].(map[string]any); ok {
+			event.Form = make(map[string]string, len(form))
+			for k, v := range form {
+				if str, ok := v.(string); ok {
+					event.Form[k] = str
+				}
+			}
+		}
+
+		s.handlerBatch(func() { handler.Fn(event) }, handler.Immediate)
 	})
 
 	s.currentHandlerIDs[handlerID] = true
@@ -45,6 +45,13 @@ func NewCtxForTest(inst *Instance, sess *Session) *Ctx {
 	return &Ctx{instance: inst, session: sess}
 }
 
+func (c *Ctx) BasePath() string {
+	if c == nil || c.session == nil {
+		return ""
+	}
+	return c.session.BasePath()
+}
+
 func (c *Ctx) SessionID() string {
 	if c == nil || c.session == nil {
 		return ""
@@ -3,8 +3,10 @@ package runtime
 import (
 	"errors"
 	"fmt"
+	goruntime "runtime"
 	"runtime/debug"
 	"strings"
+	"time"
 
 	"github.com/eleven-am/pondlive/internal/protocol"
 	"github.com/eleven-am/pondlive/internal/view/diff"
@@ -29,13 +31,33 @@ func (s *Session) SetAutoFlush(fn func()) {
 }
 
 func (s *Session) RequestFlush() {
+	s.requestFlush(false)
+}
+
+func (s *Session) requestFlush(immediate bool) {
 	if s == nil {
 		return
 	}
 
+	s.flushMu.Lock()
+	if s.batchDepth > 0 {
+		s.batchPending = true
+		s.flushMu.Unlock()
+		return
+	}
+	s.flushMu.Unlock()
+
+	s.releaseFlush(immediate)
+}
+
+// releaseFlush sends a frame now or schedules it within the frame budget. It
+// ignores open batches, so a closing batch can send what it held.
+func (s *Session) releaseFlush(immediate bool) {
 	s.flushMu.Lock()
 
-	if s.pendingFlush {
+	if immediate {
+		s.stopFlushTimerLocked()
+	} else if s.pendingFlush {
 		s.flushMu.Unlock()
 		return
 	}
@@ -47,14 +69,30 @@ func (s *Session) RequestFlush() {
 		return
 	}
 
-	autoFlush := s.autoFlush
+	if !immediate {
+		if delay := s.frameDelayLocked(time.Now()); delay > 0 {
+			s.scheduleFlushLocked(delay)
+			s.flushMu.Unlock()
+			return
+		}
+	}
 	s.flushMu.Unlock()
 
-	if autoFlush != nil {
-		autoFlush()
-	} else {
-		_ = s.Flush()
+	// Yield once so requests already queued on other goroutines see the
+	// pending flag and join this frame instead of each sending their own.
+	if !immediate {
+		goruntime.Gosched()
+	}
+
+	s.flushMu.Lock()
+	if s.flushing || !s.pendingFlush || s.closed {
+		s.flushMu.Unlock()
+		return
 	}
+	autoFlush := s.autoFlush
+	s.flushMu.Unlock()
+
+	s.runFlush(autoFlush)
 }
 
 func (s *Session) IsFlushing() bool {
@@ -88,6 +126,7 @@ func (s *Session) Flush() error {
 	}
 	s.flushing = true
 	s.pendingFlush = false
+	s.stopFlushTimerLocked()
 	s.flushMu.Unlock()
 
 	defer func() {
@@ -133,6 +172,9 @@ func (s *Session) Flush() error {
 		}
 
 		for _, inst := range dirty {
+			if inst.isDetached() {
+				continue
+			}
 			s.resetRefsForComponent(inst)
 			inst.Render(s)
 		}
@@ -140,19 +182,20 @@ func (s *Session) Flush() error {
 
 	s.flushMu.Lock()
 	s.pendingFlush = false
+	s.lastFrame = time.Now()
 	s.flushMu.Unlock()
 
 	s.clearCurrentHandlers()
 
 	s.PrevView = s.View
 	s.PortalViews = nil
+	s.flushSeq++
 	if s.Root.WorkTree != nil {
-		s.Root.NextHandlerIndex = 0
-		s.View = s.convertWorkToView(s.Root.WorkTree, s.Root)
+		s.View = s.convertInstanceTree(s.Root)
 	}
 
 	if s.Bus != nil {
-		patches := diff.Diff(s.PrevView, s.View)
+		patches := diff.DiffTemplates(s.PrevView, s.View, s.templates)
 		if len(patches) > 0 {
 			s.Bus.PublishFramePatch(patches)
 		}
@@ -302,6 +345,10 @@ func (s *Session) collectRenderedComponents(inst *Instance, result map[*Instance
 	for _, child := range children {
 		s.collectRenderedComponents(child, result)
 	}
+
+	for _, detached := range inst.detachedChildren() {
+		s.collectRenderedComponents(detached, result)
+	}
 }
 
 func (s *Session) cleanupInstance(inst *Instance) {
@@ -357,6 +404,8 @@ func (s *Session) pruneUnreferencedChildren(inst *Instance) {
 		if referencedChildren != nil && referencedChildren[child.ID] {
 			kept = append(kept, child)
 			s.pruneUnreferencedChildren(child)
+		} else if inst.keepAlive != nil && child.keepAliveEligible {
+			s.deactivateChild(inst, child)
 		} else {
 			s.cleanupInstance(child)
 			if s.Components != nil {
@@ -21,7 +21,7 @@ func (h HandlerHandle) URL() string {
 	if h.entry == nil {
 		return ""
 	}
-	return fmt.Sprintf("/_handlers/%s/%s", h.entry.sessionID, h.entry.id)
+	return fmt.Sprintf("%s/_handlers/%s/%s", h.entry.sess.BasePath(), h.entry.sessionID, h.entry.id)
 }
 
 func (h HandlerHandle) GenerateToken() string {
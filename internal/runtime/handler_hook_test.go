@@ -431,3 +431,17 @@ func TestFindHTTPHandlerEmptyID(t *testing.T) {
 		t.Error("expected nil for empty ID")
 	}
 }
+
+func TestUseHandlerURLIncludesBasePath(t *testing.T) {
+	sess := &Session{SessionID: "sess1"}
+	sess.SetBasePath("/admin")
+	root := &Instance{ID: "root"}
+	sess.Root = root
+
+	ctx := &Ctx{instance: root, session: sess}
+	h := UseHandler(ctx, http.MethodGet, func(w http.ResponseWriter, r *http.Request) error { return nil })
+
+	if h.URL() != "/admin/_handlers/sess1/root:h0" {
+		t.Fatalf("unexpected URL: %s", h.URL())
+	}
+}
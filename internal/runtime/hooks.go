@@ -110,6 +110,18 @@ func UseRef[T any](ctx *Ctx, initial T) *Ref[T] {
 	return ref
 }
 
+// UseRerender returns a function that schedules the component to render again.
+// It writes no hook state, so unlike a state setter it is safe to call from any
+// goroutine; the component must guard whatever it renders from that state itself.
+func UseRerender(ctx *Ctx) func() {
+	inst, sess := ctx.instance, ctx.session
+	return func() {
+		if sess != nil {
+			sess.MarkDirty(inst)
+		}
+	}
+}
+
 func UseElement(ctx *Ctx) *ElementRef {
 	idx := ctx.hookIndex
 	ctx.hookIndex++
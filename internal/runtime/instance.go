@@ -7,6 +7,7 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/eleven-am/pondlive/internal/view"
 	"github.com/eleven-am/pondlive/internal/work"
 )
 
@@ -28,9 +29,10 @@ type Instance struct {
 	Parent    *Instance
 	Children  []*Instance
 
-	WorkTree work.Node
-	ViewNode any
-	Wrapper  any
+	WorkTree    work.Node
+	ViewNode    view.Node
+	StaticViews map[string]*staticView
+	Wrapper     any
 
 	Dirty             bool
 	RenderedThisFlush bool
@@ -50,6 +52,10 @@ type Instance struct {
 	ReferencedChildren map[string]bool
 	NextHandlerIndex   int
 
+	keepAlive         *keepAliveCache
+	keepAliveEligible bool
+	deactivated       bool
+
 	RenderError        *Error
 	EffectError        *Error
 	hasDescendantError bool
@@ -75,6 +81,8 @@ const (
 	HookTypeChannel
 	HookTypeUpload
 	HookTypePresence
+	HookTypeKeepAlive
+	HookTypeActivation
 )
 
 type HookSlot struct {
@@ -0,0 +1,165 @@
+package runtime
+
+import "github.com/eleven-am/pondlive/internal/work"
+
+const defaultKeepAliveMax = 10
+
+type KeepAliveOptions struct {
+	Max      int
+	Disabled bool
+}
+
+type KeepAliveProps struct {
+	Max int
+}
+
+type keepAliveCache struct {
+	max      int
+	enabled  bool
+	detached []*Instance
+}
+
+type activationCell struct {
+	activated bool
+	fn        func()
+}
+
+var KeepAlive = PropsComponent(func(ctx *Ctx, props KeepAliveProps, children []work.Item) work.Node {
+	UseKeepAlive(ctx, KeepAliveOptions{Max: props.Max})
+	return &work.Fragment{Children: work.ItemsToNodes(children)}
+})
+
+func UseKeepAlive(ctx *Ctx, opts KeepAliveOptions) {
+	idx := ctx.hookIndex
+	ctx.hookIndex++
+
+	if idx >= len(ctx.instance.HookFrame) {
+		ctx.instance.HookFrame = append(ctx.instance.HookFrame, HookSlot{
+			Type:  HookTypeKeepAlive,
+			Value: &keepAliveCache{},
+		})
+	}
+
+	cache, ok := ctx.instance.HookFrame[idx].Value.(*keepAliveCache)
+	if !ok {
+		panic("runtime: UseKeepAlive hook mismatch")
+	}
+
+	cache.max = opts.Max
+	if cache.max <= 0 {
+		cache.max = defaultKeepAliveMax
+	}
+	cache.enabled = !opts.Disabled
+	ctx.instance.keepAlive = cache
+}
+
+func UseActivated(ctx *Ctx, fn func()) {
+	useActivation(ctx, fn, true)
+}
+
+func UseDeactivated(ctx *Ctx, fn func()) {
+	useActivation(ctx, fn, false)
+}
+
+func useActivation(ctx *Ctx, fn func(), activated bool) {
+	idx := ctx.hookIndex
+	ctx.hookIndex++
+
+	isMount := idx >= len(ctx.instance.HookFrame)
+	if isMount {
+		ctx.instance.HookFrame = append(ctx.instance.HookFrame, HookSlot{
+			Type:  HookTypeActivation,
+			Value: &activationCell{activated: activated},
+		})
+	}
+
+	cell, ok := ctx.instance.HookFrame[idx].Value.(*activationCell)
+	if !ok || cell.activated != activated {
+		panic("runtime: UseActivated/UseDeactivated hook mismatch")
+	}
+	cell.fn = fn
+
+	if isMount && activated && ctx.session != nil && fn != nil {
+		ctx.session.PendingEffects = append(ctx.session.PendingEffects, activationTask(ctx.instance, idx, fn))
+	}
+}
+
+func activationTask(inst *Instance, hookIndex int, fn func()) effectTask {
+	return effectTask{
+		instance:  inst,
+		hookIndex: hookIndex,
+		fn: func() func() {
+			fn()
+			return nil
+		},
+	}
+}
+
+func (s *Session) queueActivation(inst *Instance, activated bool) {
+	if s == nil || inst == nil {
+		return
+	}
+
+	for idx, slot := range inst.HookFrame {
+		if slot.Type != HookTypeActivation {
+			continue
+		}
+		if cell, ok := slot.Value.(*activationCell); ok && cell.activated == activated && cell.fn != nil {
+			s.PendingEffects = append(s.PendingEffects, activationTask(inst, idx, cell.fn))
+		}
+	}
+
+	inst.mu.Lock()
+	children := make([]*Instance, len(inst.Children))
+	copy(children, inst.Children)
+	inst.mu.Unlock()
+
+	for _, child := range children {
+		s.queueActivation(child, activated)
+	}
+}
+
+func (s *Session) deactivateChild(parent, child *Instance) {
+	cache := parent.keepAlive
+	child.deactivated = true
+	cache.detached = append(cache.detached, child)
+	s.queueActivation(child, false)
+
+	for len(cache.detached) > cache.max {
+		evicted := cache.detached[0]
+		cache.detached = cache.detached[1:]
+		s.cleanupInstance(evicted)
+		if s.Components != nil {
+			delete(s.Components, evicted.ID)
+		}
+	}
+}
+
+func (c *keepAliveCache) take(id string) *Instance {
+	if c == nil {
+		return nil
+	}
+	for i, inst := range c.detached {
+		if inst.ID == id {
+			c.detached = append(c.detached[:i], c.detached[i+1:]...)
+			return inst
+		}
+	}
+	return nil
+}
+
+func (inst *Instance) isDetached() bool {
+	for current := inst; current != nil; current = current.Parent {
+		if current.deactivated {
+			return true
+		}
+	}
+	return false
+}
+
+func (inst *Instance) detachedChildren() []*Instance {
+	if inst == nil || inst.keepAlive == nil {
+		return nil
+	}
+	return inst.keepAlive.detached
+}
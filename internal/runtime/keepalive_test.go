@@ -0,0 +1,146 @@
+package runtime
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/eleven-am/pondlive/internal/view"
+	"github.com/eleven-am/pondlive/internal/work"
+)
+
+func TestKeepAlivePreservesStateAcrossSwitches(t *testing.T) {
+	var setCount func(int)
+	var events []string
+
+	tabA := Component(func(ctx *Ctx, _ []work.Item) work.Node {
+		count, set := UseState(ctx, 0)
+		setCount = set
+		UseActivated(ctx, func() { events = append(events, "activated") })
+		UseDeactivated(ctx, func() { events = append(events, "deactivated") })
+		UseEffect(ctx, func() func() {
+			return func() { events = append(events, "cleanup") }
+		}, 0)
+		return &work.Text{Value: fmt.Sprintf("a:%d", count)}
+	})
+	tabB := Component(func(ctx *Ctx, _ []work.Item) work.Node {
+		return &work.Text{Value: "b"}
+	})
+
+	var setTab func(string)
+	sess := memoTestSession(func(ctx *Ctx, _ any, _ []work.Item) work.Node {
+		tab, set := UseState(ctx, "a")
+		setTab = set
+		var active work.Node
+		if tab == "a" {
+			active = tabA(ctx)
+		} else {
+			active = tabB(ctx)
+		}
+		return &work.Element{Tag: "div", Children: []work.Node{KeepAlive(ctx, KeepAliveProps{}, active)}}
+	})
+
+	if err := sess.Flush(); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+
+	setCount(5)
+	setTab("b")
+
+	if text := sess.View.(*view.Element).Children[0].(*view.Text).Text; text != "b" {
+		t.Fatalf("expected tab b to be shown, got %q", text)
+	}
+
+	setTab("a")
+
+	if text := sess.View.(*view.Element).Children[0].(*view.Text).Text; text != "a:5" {
+		t.Errorf("expected tab a state to survive, got %q", text)
+	}
+
+	expected := []string{"activated", "deactivated", "activated"}
+	if fmt.Sprint(events) != fmt.Sprint(expected) {
+		t.Errorf("expected events %v, got %v", expected, events)
+	}
+}
+
+func TestKeepAliveEvictsLeastRecentlyUsed(t *testing.T) {
+	cleanups := map[string]int{}
+	tab := func(ctx *Ctx, name string) work.Node {
+		UseEffect(ctx, func() func() {
+			return func() { cleanups[name]++ }
+		}, 0)
+		return &work.Text{Value: name}
+	}
+	tabs := map[string]ComponentWrapper{
+		"a": Component(func(ctx *Ctx, _ []work.Item) work.Node { return tab(ctx, "a") }),
+		"b": Component(func(ctx *Ctx, _ []work.Item) work.Node { return tab(ctx, "b") }),
+		"c": Component(func(ctx *Ctx, _ []work.Item) work.Node { return tab(ctx, "c") }),
+	}
+
+	var setTab func(string)
+	sess := memoTestSession(func(ctx *Ctx, _ any, _ []work.Item) work.Node {
+		tab, set := UseState(ctx, "a")
+		setTab = set
+		return KeepAlive(ctx, KeepAliveProps{Max: 1}, tabs[tab](ctx))
+	})
+
+	if err := sess.Flush(); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+
+	setTab("b")
+	if cleanups["a"] != 0 {
+		t.Fatalf("expected tab a to be kept alive, got %d cleanups", cleanups["a"])
+	}
+
+	setTab("c")
+	if cleanups["a"] != 1 {
+		t.Errorf("expected tab a to be evicted, got %d cleanups", cleanups["a"])
+	}
+	if cleanups["b"] != 0 {
+		t.Errorf("expected tab b to be kept alive, got %d cleanups", cleanups["b"])
+	}
+}
+
+func TestKeepAliveDefersRenderWhileDetached(t *testing.T) {
+	renders := 0
+	var setCount func(int)
+	tabA := Component(func(ctx *Ctx, _ []work.Item) work.Node {
+		renders++
+		count, set := UseState(ctx, 0)
+		setCount = set
+		return &work.Text{Value: fmt.Sprintf("a:%d", count)}
+	})
+	tabB := Component(func(ctx *Ctx, _ []work.Item) work.Node {
+		return &work.Text{Value: "b"}
+	})
+
+	var setTab func(string)
+	sess := memoTestSession(func(ctx *Ctx, _ any, _ []work.Item) work.Node {
+		tab, set := UseState(ctx, "a")
+		setTab = set
+		if tab == "a" {
+			return KeepAlive(ctx, KeepAliveProps{}, tabA(ctx))
+		}
+		return KeepAlive(ctx, KeepAliveProps{}, tabB(ctx))
+	})
+
+	if err := sess.Flush(); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+
+	setTab("b")
+	setCount(3)
+
+	if renders != 1 {
+		t.Errorf("expected detached component not to render, got %d renders", renders)
+	}
+
+	setTab("a")
+
+	if renders != 2 {
+		t.Errorf("expected deferred render on reactivation, got %d renders", renders)
+	}
+	if text := sess.View.(*view.Text).Text; text != "a:3" {
+		t.Errorf("expected updated state after reactivation, got %q", text)
+	}
+}
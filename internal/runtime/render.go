@@ -16,9 +16,9 @@ func (inst *Instance) Render(sess *Session) work.Node {
 	}
 
 	inst.RenderedThisFlush = true
-	inst.Dirty = false
 
 	inst.mu.Lock()
+	inst.Dirty = false
 	inst.ChildRenderIndex = 0
 	inst.ProviderSeq = 0
 	inst.ReferencedChildren = make(map[string]bool)
@@ -192,11 +192,21 @@ func (inst *Instance) EndRender() {
 
 }
 
+// SetDirty is guarded by inst.mu because MarkDirty may be called from any
+// goroutine while the instance renders.
 func (inst *Instance) SetDirty(dirty bool) {
 	if inst == nil {
 		return
 	}
+	inst.mu.Lock()
 	inst.Dirty = dirty
+	inst.mu.Unlock()
+}
+
+func (inst *Instance) isDirty() bool {
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+	return inst.Dirty
 }
 
 func (inst *Instance) NotifyContextChange(sess *Session, id contextID) {
@@ -226,11 +236,15 @@ func (inst *Instance) NotifyContextChange(sess *Session, id contextID) {
 }
 
 func (inst *Instance) EnsureChild(sess *Session, fn any, key string, props any, children []work.Node) *Instance {
+	return inst.ensureChild(sess, fn, reflect.ValueOf(fn).Pointer(), key, props, children)
+}
+
+func (inst *Instance) ensureChild(sess *Session, fn any, fnID uintptr, key string, props any, children []work.Node) *Instance {
 	if inst == nil {
 		return nil
 	}
 
-	childID := buildComponentID(inst, fn, key)
+	childID := buildComponentID(inst, fnID, key)
 	inst.mu.Lock()
 
 	if inst.ReferencedChildren != nil {
@@ -245,6 +259,14 @@ func (inst *Instance) EnsureChild(sess *Session, fn any, key string, props any,
 		}
 	}
 
+	reactivated := false
+	if child == nil {
+		if child = inst.keepAlive.take(childID); child != nil {
+			inst.Children = append(inst.Children, child)
+			reactivated = true
+		}
+	}
+
 	if child == nil {
 		child = &Instance{
 			ID:                 childID,
@@ -264,6 +286,12 @@ func (inst *Instance) EnsureChild(sess *Session, fn any, key string, props any,
 
 	inst.mu.Unlock()
 
+	if reactivated {
+		child.deactivated = false
+		sess.clearRenderedFlags(child)
+		sess.queueActivation(child, true)
+	}
+
 	child.PrevProps = child.Props
 	child.Props = props
 	child.PrevInputChildren = child.InputChildren
@@ -272,13 +300,11 @@ func (inst *Instance) EnsureChild(sess *Session, fn any, key string, props any,
 	return child
 }
 
-func buildComponentID(parent *Instance, fn any, key string) string {
+func buildComponentID(parent *Instance, fnPtr uintptr, key string) string {
 	if parent == nil {
 		return "root"
 	}
 
-	fnPtr := reflect.ValueOf(fn).Pointer()
-
 	componentKey := key
 	if componentKey == "" {
 		parent.mu.Lock()
@@ -0,0 +1,148 @@
+package runtime
+
+import "time"
+
+// SetFlushWindow delays the flush after the first dirty mark by window so that
+// further marks in that window land in the same frame. Zero flushes as soon as
+// the frame budget allows.
+func (s *Session) SetFlushWindow(window time.Duration) {
+	if s == nil {
+		return
+	}
+	s.flushMu.Lock()
+	s.flushWindow = window
+	s.flushMu.Unlock()
+}
+
+// SetMaxFPS caps how many frames per second the session sends. Zero or a
+// negative value leaves frames uncapped, so each update flushes at once.
+func (s *Session) SetMaxFPS(fps int) {
+	if s == nil {
+		return
+	}
+	s.flushMu.Lock()
+	s.maxFPS = fps
+	s.flushMu.Unlock()
+}
+
+// Batch runs fn and holds back flushes until it returns, so the updates made
+// inside it are sent as a single frame. Nested batches flush when the
+// outermost one ends.
+func (s *Session) Batch(fn func()) {
+	s.batch(fn, false, false)
+}
+
+// BatchImmediate is Batch for latency-critical work: the resulting flush skips
+// the coalescing window and frame budget.
+func (s *Session) BatchImmediate(fn func()) {
+	s.batch(fn, true, false)
+}
+
+// handlerBatch runs an event handler as a batch that sends its frame as soon as
+// it returns, even while a long Batch is open in another goroutine.
+func (s *Session) handlerBatch(fn func(), immediate bool) {
+	s.batch(fn, immediate, true)
+}
+
+func (s *Session) batch(fn func(), immediate, handler bool) {
+	if s == nil {
+		fn()
+		return
+	}
+
+	s.flushMu.Lock()
+	s.batchDepth++
+	s.flushMu.Unlock()
+
+	defer func() {
+		s.flushMu.Lock()
+		s.batchDepth--
+		release := s.batchPending && (handler || s.batchDepth == 0)
+		if release {
+			s.batchPending = false
+		}
+		s.flushMu.Unlock()
+
+		if release {
+			s.releaseFlush(immediate)
+		}
+	}()
+
+	fn()
+}
+
+func (s *Session) frameDelayLocked(now time.Time) time.Duration {
+	delay := s.flushWindow
+
+	if fps := s.maxFPS; fps > 0 && !s.lastFrame.IsZero() {
+		interval := time.Second / time.Duration(fps)
+		if wait := s.lastFrame.Add(interval).Sub(now); wait > delay {
+			delay = wait
+		}
+	}
+	return delay
+}
+
+func (s *Session) scheduleFlushLocked(delay time.Duration) {
+	if s.flushTimer != nil {
+		return
+	}
+	s.flushTimer = time.AfterFunc(delay, s.timerFlush)
+}
+
+func (s *Session) stopFlushTimerLocked() {
+	if s.flushTimer != nil {
+		s.flushTimer.Stop()
+		s.flushTimer = nil
+	}
+}
+
+func (s *Session) timerFlush() {
+	s.flushMu.Lock()
+	s.flushTimer = nil
+	if s.flushing || !s.pendingFlush || s.closed {
+		s.flushMu.Unlock()
+		return
+	}
+	autoFlush := s.autoFlush
+	s.flushMu.Unlock()
+
+	s.runFlush(autoFlush)
+}
+
+func (s *Session) runFlush(autoFlush func()) {
+	if autoFlush != nil {
+		autoFlush()
+	} else {
+		_ = s.Flush()
+	}
+}
+
+// Batch groups the updates made inside fn into a single frame. Useful in
+// goroutines that update state outside of an event handler.
+func (c *Ctx) Batch(fn func()) {
+	if c == nil {
+		fn()
+		return
+	}
+	c.session.Batch(fn)
+}
+
+// FlushImmediately runs fn like Batch but sends its frame right away.
+func (c *Ctx) FlushImmediately(fn func()) {
+	if c == nil {
+		fn()
+		return
+	}
+	c.session.BatchImmediate(fn)
+}
+
+// SetFrameBudget overrides the app-wide flush window and max FPS for the
+// current session.
+func (c *Ctx) SetFrameBudget(window time.Duration, maxFPS int) {
+	if c == nil || c.session == nil {
+		return
+	}
+	c.session.SetFlushWindow(window)
+	c.session.SetMaxFPS(maxFPS)
+}
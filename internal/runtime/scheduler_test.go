@@ -0,0 +1,191 @@
+package runtime
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/eleven-am/pondlive/internal/work"
+)
+
+func schedulerTestSession(t *testing.T) (*Session, *atomic.Int32, *func(int)) {
+	t.Helper()
+	renders := &atomic.Int32{}
+	var setCount func(int)
+	sess := memoTestSession(func(ctx *Ctx, _ any, _ []work.Item) work.Node {
+		renders.Add(1)
+		_, set := UseState(ctx, 0)
+		setCount = set
+		return &work.Element{Tag: "div"}
+	})
+	if err := sess.Flush(); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+	return sess, renders, &setCount
+}
+
+func eventually(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met before deadline")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestMaxFPSCoalescesBurstIntoOneFrame(t *testing.T) {
+	sess, renders, setCount := schedulerTestSession(t)
+	sess.SetMaxFPS(10)
+	time.Sleep(110 * time.Millisecond)
+
+	(*setCount)(1)
+	if renders.Load() != 2 {
+		t.Fatalf("expected first update after idle to flush at once, got %d renders", renders.Load())
+	}
+
+	for i := 2; i <= 50; i++ {
+		(*setCount)(i)
+	}
+	if renders.Load() != 2 {
+		t.Fatalf("expected burst to wait for the frame budget, got %d renders", renders.Load())
+	}
+
+	eventually(t, func() bool { return renders.Load() == 3 })
+	time.Sleep(150 * time.Millisecond)
+	if renders.Load() != 3 {
+		t.Errorf("expected burst to produce a single frame, got %d renders", renders.Load())
+	}
+}
+
+func TestFlushWindowDelaysFirstFrame(t *testing.T) {
+	sess, renders, setCount := schedulerTestSession(t)
+	sess.SetFlushWindow(20 * time.Millisecond)
+
+	(*setCount)(1)
+	(*setCount)(2)
+	if renders.Load() != 1 {
+		t.Fatalf("expected updates to wait for the window, got %d renders", renders.Load())
+	}
+	if !sess.IsFlushPending() {
+		t.Error("expected a pending flush while the window is open")
+	}
+
+	eventually(t, func() bool { return renders.Load() == 2 })
+}
+
+func TestBatchSendsOneFrame(t *testing.T) {
+	sess, renders, setCount := schedulerTestSession(t)
+
+	sess.Batch(func() {
+		for i := 1; i <= 10; i++ {
+			(*setCount)(i)
+		}
+		if renders.Load() != 1 {
+			t.Errorf("expected no flush inside batch, got %d renders", renders.Load())
+		}
+	})
+
+	if renders.Load() != 2 {
+		t.Errorf("expected one flush when the batch ends, got %d renders", renders.Load())
+	}
+}
+
+func TestNestedBatchFlushesAtOutermost(t *testing.T) {
+	sess, renders, setCount := schedulerTestSession(t)
+
+	sess.Batch(func() {
+		sess.Batch(func() { (*setCount)(1) })
+		if renders.Load() != 1 {
+			t.Errorf("expected inner batch to defer to outer, got %d renders", renders.Load())
+		}
+		(*setCount)(2)
+	})
+
+	if renders.Load() != 2 {
+		t.Errorf("expected one flush after outer batch, got %d renders", renders.Load())
+	}
+}
+
+func TestBatchImmediateSkipsFrameBudget(t *testing.T) {
+	sess, renders, setCount := schedulerTestSession(t)
+	sess.SetMaxFPS(1)
+	sess.SetFlushWindow(time.Second)
+
+	sess.BatchImmediate(func() { (*setCount)(1) })
+	if renders.Load() != 2 {
+		t.Fatalf("expected immediate flush, got %d renders", renders.Load())
+	}
+
+	(*setCount)(2)
+	if renders.Load() != 2 {
+		t.Fatalf("expected regular update to be held back, got %d renders", renders.Load())
+	}
+	sess.BatchImmediate(func() {})
+	if renders.Load() != 2 {
+		t.Errorf("expected empty immediate batch not to flush, got %d renders", renders.Load())
+	}
+}
+
+func TestCloseStopsScheduledFlush(t *testing.T) {
+	sess, _, setCount := schedulerTestSession(t)
+	sess.SetFlushWindow(10 * time.Millisecond)
+
+	(*setCount)(1)
+	sess.Close()
+
+	sess.flushMu.Lock()
+	timer := sess.flushTimer
+	sess.flushMu.Unlock()
+	if timer != nil {
+		t.Error("expected close to stop the flush timer")
+	}
+	time.Sleep(30 * time.Millisecond)
+}
+
+func TestUpdatesFlushAtOnceByDefault(t *testing.T) {
+	sess, renders, setCount := schedulerTestSession(t)
+
+	for i := 1; i <= 3; i++ {
+		(*setCount)(i)
+		if renders.Load() != int32(i+1) {
+			t.Fatalf("expected update %d to flush synchronously, got %d renders", i, renders.Load())
+		}
+	}
+	if sess.IsFlushPending() {
+		t.Error("expected no scheduled flush without a frame budget")
+	}
+}
+
+func TestHandlerFrameIsNotHeldByAnotherBatch(t *testing.T) {
+	sess, renders, setCount := schedulerTestSession(t)
+
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		sess.Batch(func() {
+			close(entered)
+			<-release
+		})
+	}()
+	<-entered
+
+	sess.handlerBatch(func() { (*setCount)(1) }, false)
+	if renders.Load() != 2 {
+		t.Errorf("expected a handler to flush while another batch is open, got %d renders", renders.Load())
+	}
+
+	(*setCount)(2)
+	if renders.Load() != 2 {
+		t.Errorf("expected updates outside a batch to wait for the open one, got %d renders", renders.Load())
+	}
+
+	close(release)
+	<-done
+	if renders.Load() != 3 {
+		t.Errorf("expected the held update to flush when the batch ends, got %d renders", renders.Load())
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/eleven-am/pondlive/internal/protocol"
 	"github.com/eleven-am/pondlive/internal/upload"
 	"github.com/eleven-am/pondlive/internal/view"
+	"github.com/eleven-am/pondlive/internal/view/diff"
 	"github.com/eleven-am/pondlive/internal/work"
 )
 
@@ -48,14 +49,25 @@ type Session struct {
 	MountedComponents map[*Instance]struct{}
 
 	PortalViews []view.Node
+	portalSeq   int
+	flushSeq    int
 
 	SessionID string
 
-	devMode bool
+	devMode   bool
+	templates *diff.Templates
+	basePath  string
 
 	pendingFlush bool
 	flushing     bool
 	autoFlush    func()
+	flushWindow  time.Duration
+	maxFPS       int
+	lastFrame    time.Time
+	flushTimer   *time.Timer
+	batchDepth   int
+	batchPending bool
+	closed       bool
 	flushMu      sync.Mutex
 
 	mu sync.Mutex
@@ -115,6 +127,11 @@ func (s *Session) Close() {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	s.flushMu.Lock()
+	s.closed = true
+	s.stopFlushTimerLocked()
+	s.flushMu.Unlock()
+
 	s.cleanupInstanceTree(s.Root)
 
 	for _, task := range s.PendingCleanups {
@@ -173,6 +190,38 @@ func (s *Session) SetDevMode(enabled bool) {
 	s.mu.Unlock()
 }
 
+// SetBasePath records the prefix the app is mounted under, so URLs handed to
+// the browser (links, handler and upload endpoints) include it. It must be
+// set before the first flush.
+func (s *Session) SetBasePath(base string) {
+	if s == nil {
+		return
+	}
+	s.basePath = base
+}
+
+func (s *Session) BasePath() string {
+	if s == nil {
+		return ""
+	}
+	return s.basePath
+}
+
+func (s *Session) SetTemplates(enabled bool) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !enabled {
+		s.templates = nil
+		return
+	}
+	if s.templates == nil {
+		s.templates = diff.NewTemplates()
+	}
+}
+
 func (s *Session) ChannelManager() *ChannelManager {
 	if s == nil {
 		return nil
@@ -199,5 +248,9 @@ func (s *Session) cleanupInstanceTree(inst *Instance) {
 		s.cleanupInstanceTree(child)
 	}
 
+	for _, detached := range inst.detachedChildren() {
+		s.cleanupInstanceTree(detached)
+	}
+
 	s.cleanupInstance(inst)
 }
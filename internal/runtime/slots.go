@@ -424,6 +424,9 @@ func fingerprintNode(node work.Node) string {
 	case *work.ComponentNode:
 		return fmt.Sprintf("c:%p:%s", n.Fn, n.Key)
 
+	case *work.StaticNode:
+		return fmt.Sprintf("s:%s", n.Key)
+
 	default:
 		return "unknown"
 	}
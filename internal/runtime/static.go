@@ -0,0 +1,108 @@
+package runtime
+
+import (
+	"github.com/eleven-am/pondlive/internal/view"
+	"github.com/eleven-am/pondlive/internal/view/diff"
+	"github.com/eleven-am/pondlive/internal/work"
+)
+
+type staticView struct {
+	node         view.Node
+	children     []string
+	childIndex   int
+	handlerIndex int
+	flushSeq     int
+}
+
+func (s *Session) convertStatic(static *work.StaticNode, parent *Instance) view.Node {
+	if static == nil || parent == nil {
+		return nil
+	}
+
+	if cached, ok := parent.StaticViews[static.Key]; ok && s.staticReusable(cached) {
+		s.retainStatic(parent, cached)
+		return cached.node
+	}
+
+	startChildIndex := parent.ChildRenderIndex
+	startHandlerIndex := parent.NextHandlerIndex
+	portalSeq := s.portalSeq
+
+	parent.mu.Lock()
+	referencedBefore := make(map[string]bool, len(parent.ReferencedChildren))
+	for id := range parent.ReferencedChildren {
+		referencedBefore[id] = true
+	}
+	parent.mu.Unlock()
+
+	node := s.convertFragment(&work.Fragment{Children: static.Children}, parent)
+	if node != nil {
+		node = diff.Flatten(node)
+	}
+
+	if s.portalSeq != portalSeq {
+		delete(parent.StaticViews, static.Key)
+		return node
+	}
+
+	entry := &staticView{
+		node:         node,
+		childIndex:   parent.ChildRenderIndex - startChildIndex,
+		handlerIndex: parent.NextHandlerIndex - startHandlerIndex,
+		flushSeq:     s.flushSeq,
+	}
+
+	parent.mu.Lock()
+	for id := range parent.ReferencedChildren {
+		if !referencedBefore[id] {
+			entry.children = append(entry.children, id)
+		}
+	}
+	parent.mu.Unlock()
+
+	if parent.StaticViews == nil {
+		parent.StaticViews = make(map[string]*staticView)
+	}
+	parent.StaticViews[static.Key] = entry
+
+	return node
+}
+
+func (s *Session) staticReusable(cached *staticView) bool {
+	for _, id := range cached.children {
+		child := s.Components[id]
+		if child == nil || len(child.ContextDeps) > 0 || !memoSubtreeClean(child) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Session) retainStatic(parent *Instance, cached *staticView) {
+	cached.flushSeq = s.flushSeq
+
+	parent.mu.Lock()
+	parent.ChildRenderIndex += cached.childIndex
+	if parent.ReferencedChildren == nil {
+		parent.ReferencedChildren = make(map[string]bool)
+	}
+	for _, id := range cached.children {
+		parent.ReferencedChildren[id] = true
+	}
+	parent.mu.Unlock()
+
+	parent.NextHandlerIndex += cached.handlerIndex
+
+	for _, id := range cached.children {
+		retainChildren(s.Components[id])
+	}
+	s.retainHandlers(cached.node)
+}
+
+func (inst *Instance) pruneStaticViews(flushSeq int) {
+	for key, entry := range inst.StaticViews {
+		if entry.flushSeq != flushSeq {
+			delete(inst.StaticViews, key)
+		}
+	}
+}
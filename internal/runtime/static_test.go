@@ -0,0 +1,102 @@
+package runtime
+
+import (
+	"testing"
+
+	"github.com/eleven-am/pondlive/internal/view"
+	"github.com/eleven-am/pondlive/internal/view/diff"
+	"github.com/eleven-am/pondlive/internal/work"
+)
+
+func TestStaticViewReusedAcrossFlushes(t *testing.T) {
+	var setTick func(int)
+	staticKey := "legal"
+	sess := memoTestSession(func(ctx *Ctx, _ any, _ []work.Item) work.Node {
+		tick, set := UseState(ctx, 0)
+		setTick = set
+		return &work.Element{
+			Tag: "div",
+			Children: []work.Node{
+				&work.Text{Value: string(rune('a' + tick))},
+				work.NewStatic(staticKey,
+					&work.Element{Tag: "p", Children: []work.Node{&work.Text{Value: "terms"}}},
+					&work.Element{Tag: "p", Children: []work.Node{&work.Text{Value: "privacy"}}},
+				),
+			},
+		}
+	})
+
+	if err := sess.Flush(); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+	first := sess.View.(*view.Element).Children[1]
+
+	setTick(1)
+
+	second := sess.View.(*view.Element).Children[1]
+	if first != second {
+		t.Fatal("expected static view to be reused")
+	}
+
+	patches := diff.Diff(sess.PrevView, sess.View)
+	if len(patches) != 1 || patches[0].Op != diff.OpSetText {
+		t.Errorf("expected only the dynamic text patch, got %+v", patches)
+	}
+
+	staticKey = "legal-v2"
+	setTick(2)
+
+	third := sess.View.(*view.Element).Children[1]
+	if third == second {
+		t.Error("expected key change to re-render the static subtree")
+	}
+	if _, ok := sess.Root.StaticViews["legal"]; ok {
+		t.Error("expected stale static entry to be pruned")
+	}
+	if _, ok := sess.Root.StaticViews["legal-v2"]; !ok {
+		t.Error("expected new static entry to be cached")
+	}
+}
+
+func TestStaticKeepsSiblingComponentIdentity(t *testing.T) {
+	inner := Component(func(ctx *Ctx, _ []work.Item) work.Node {
+		return &work.Text{Value: "inner"}
+	})
+
+	var setCount func(int)
+	counter := Component(func(ctx *Ctx, _ []work.Item) work.Node {
+		count, set := UseState(ctx, 0)
+		setCount = set
+		return &work.Text{Value: string(rune('0' + count))}
+	})
+
+	var setTick func(int)
+	sess := memoTestSession(func(ctx *Ctx, _ any, _ []work.Item) work.Node {
+		tick, set := UseState(ctx, 0)
+		setTick = set
+		return &work.Element{
+			Tag: "div",
+			Children: []work.Node{
+				&work.Text{Value: string(rune('a' + tick))},
+				work.NewStatic("header", &work.Element{Tag: "header", Children: []work.Node{inner(ctx)}}),
+				counter(ctx),
+			},
+		}
+	})
+
+	if err := sess.Flush(); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+
+	setCount(3)
+	setTick(1)
+
+	if len(sess.Root.Children) != 2 {
+		t.Fatalf("expected static child and sibling to stay mounted, got %d children", len(sess.Root.Children))
+	}
+
+	text, ok := sess.View.(*view.Element).Children[2].(*view.Text)
+	if !ok || text.Text != "3" {
+		t.Errorf("expected sibling state to survive, got %#v", sess.View.(*view.Element).Children[2])
+	}
+}
@@ -70,7 +70,7 @@ function upload(element, transport) {
 
         ensureTus().then(() => {
             const upload = new window.tus.Upload(file, {
-                endpoint: '/tus/',
+                endpoint: uploadConfig.endpoint || '/tus/',
                 retryDelays: [0, 1000, 3000, 5000],
                 metadata: {
                     token: token,
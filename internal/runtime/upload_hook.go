@@ -281,7 +281,8 @@ func (h *UploadHandle) sendStart() {
 	}
 
 	payload := map[string]interface{}{
-		"token": h.token,
+		"token":    h.token,
+		"endpoint": h.session.BasePath() + "/tus/",
 	}
 
 	h.mu.Lock()
@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"strings"
+	"time"
 
 	pond "github.com/eleven-am/pondsocket/go/pondsocket"
 
@@ -30,6 +31,9 @@ type App struct {
 	clientAsset   string
 	pondManager   *pond.Manager
 	mux           *http.ServeMux
+	handler       http.Handler
+	basePath      string
+	transport     TransportMode
 	uploadHandler *upload.Handler
 }
 
@@ -47,6 +51,16 @@ type Config struct {
 	PubSub pond.PubSub
 
 	UploadConfig *upload.Config
+
+	BuildID string
+
+	ReloadGrace time.Duration
+
+	ReconnectGrace time.Duration
+
+	BasePath string
+
+	Transport TransportMode
 }
 
 func New(cfg Config) (*App, error) {
@@ -64,15 +78,17 @@ func New(cfg Config) (*App, error) {
 		pondOpts.PubSub = cfg.PubSub
 	}
 
+	basePath := normalizeBasePath(cfg.BasePath)
 	app := &App{
 		component:     cfg.Component,
 		registry:      NewSessionRegistry(),
-		version:       1,
 		idGenerator:   defaultSessionID,
-		clientAsset:   "/static/pondlive.js",
+		clientAsset:   basePath + staticAssets.Path("pondlive.js"),
 		pondManager:   pond.NewManager(ctx, *pondOpts),
 		sessionConfig: &session.Config{},
 		mux:           http.NewServeMux(),
+		basePath:      basePath,
+		transport:     cfg.Transport,
 	}
 
 	if cfg.IDGenerator != nil {
@@ -84,7 +100,7 @@ func New(cfg Config) (*App, error) {
 		app.sessionConfig = &clone
 
 		if clone.DevMode {
-			app.clientAsset = "/static/pondlive-dev.js"
+			app.clientAsset = basePath + staticAssets.Path("pondlive-dev.js")
 		}
 	}
 
@@ -93,15 +109,21 @@ func New(cfg Config) (*App, error) {
 	}
 
 	app.sessionConfig.ClientAsset = app.clientAsset
+	app.sessionConfig.BasePath = basePath
+	app.version = buildVersion(app.clientAsset, cfg.BuildID)
 
 	endpoint, err := Register(app.pondManager, "/live", app.registry)
 	if err != nil {
 		return nil, err
 	}
+	endpoint.SetVersion(app.version, cfg.ReloadGrace)
+	endpoint.SetReconnectGrace(cfg.ReconnectGrace)
 	app.endpoint = endpoint
 
 	if cfg.UploadConfig != nil {
-		uploadHandler, err := upload.NewHandler(*cfg.UploadConfig, app.lookupUploadCallback, app.removeUploadCallback)
+		uploadCfg := *cfg.UploadConfig
+		uploadCfg.BasePath = basePath + "/tus"
+		uploadHandler, err := upload.NewHandler(uploadCfg, app.lookupUploadCallback, app.removeUploadCallback)
 		if err != nil {
 			return nil, err
 		}
@@ -114,31 +136,40 @@ func New(cfg Config) (*App, error) {
 }
 
 func (a *App) registerRoutes() {
-	a.mux.Handle("/static/", http.StripPrefix("/static/", http.FileServer(http.FS(Assets))))
+	a.mux.Handle(staticPrefix, staticAssets)
 	a.mux.HandleFunc("/live", a.pondManager.HTTPHandler())
+	if a.transport != TransportWebSocket {
+		a.mux.HandleFunc("GET "+ssePath, a.endpoint.serveSSE)
+		a.mux.HandleFunc("POST "+ssePath+"/{conn}/{event}", a.endpoint.serveSSEMessage)
+	}
 	a.mux.Handle(handler.PathPrefix, handler.NewDispatcher(a.registry))
 	if a.uploadHandler != nil {
 		a.mux.Handle("/tus/", http.StripPrefix("/tus", a.uploadHandler))
 	}
 	a.mux.HandleFunc("/", a.serveSSR)
+	a.handler = stripBasePath(a.basePath, a.mux)
 }
 
+// Mux returns the app's inner mux for registering extra routes. With a base
+// path those routes are relative to it ("/robots.txt" is served at
+// "/admin/robots.txt"), and the mux itself must not be served directly: serve
+// Handler, which strips the prefix before handing requests to it.
 func (a *App) Mux() *http.ServeMux {
 	return a.mux
 }
 
 func (a *App) Handler() http.Handler {
-	return a.mux
+	return a.handler
 }
 
 func (a *App) HandlerFunc() http.HandlerFunc {
-	return a.mux.ServeHTTP
+	return a.handler.ServeHTTP
 }
 
 func (a *App) Server(addr string) *http.Server {
 	return &http.Server{
 		Addr:    addr,
-		Handler: a.mux,
+		Handler: a.handler,
 	}
 }
 
@@ -209,12 +240,14 @@ func (a *App) serveSSR(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	status := http.StatusOK
 	if reqState := capture.RequestState(); reqState != nil {
 		if redirectURL, redirectCode, hasRedirect := reqState.Redirect(); hasRedirect {
 			sess.SetTransport(nil)
-			http.Redirect(w, r, redirectURL, redirectCode)
+			http.Redirect(w, r, a.prefixPath(redirectURL), redirectCode)
 			return
 		}
+		status = reqState.Status()
 	}
 
 	rtSession := sess.Session()
@@ -233,8 +266,10 @@ func (a *App) serveSSR(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var clientCfg *protocol.ClientConfig
+	if cfg.DevMode || a.transport != TransportAuto {
+		clientCfg = &protocol.ClientConfig{Transport: string(a.transport)}
+	}
 	if cfg.DevMode {
-		clientCfg = &protocol.ClientConfig{}
 		value := true
 		clientCfg.Debug = &value
 	}
@@ -246,6 +281,7 @@ func (a *App) serveSSR(w http.ResponseWriter, r *http.Request) {
 		Seq:      int(capture.LastSeq()),
 		Patch:    diff.ExtractMetadata(rtSession.View),
 		Location: location,
+		Base:     a.basePath,
 		Client:   clientCfg,
 	}
 
@@ -261,6 +297,7 @@ func (a *App) serveSSR(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Cache-Control", "no-store")
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
 	_, _ = w.Write([]byte(document))
 }
 
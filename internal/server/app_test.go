@@ -907,6 +907,33 @@ func TestAppMux(t *testing.T) {
 	}
 }
 
+func TestAppMuxRoutesAreRelativeToBasePath(t *testing.T) {
+	component := func(ctx *runtime.Ctx) work.Node {
+		return &work.Element{Tag: "div"}
+	}
+
+	app, err := New(Config{Component: component, BasePath: "/admin"})
+	if err != nil {
+		t.Fatalf("failed to create app: %v", err)
+	}
+
+	app.Mux().HandleFunc("/robots.txt", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("robots"))
+	})
+
+	rec := httptest.NewRecorder()
+	app.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/robots.txt", nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != "robots" {
+		t.Errorf("expected prefixed route to be served, got %d %q", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	app.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/robots.txt", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected unprefixed route to 404, got %d", rec.Code)
+	}
+}
+
 func TestAppHandler(t *testing.T) {
 	component := func(ctx *runtime.Ctx) work.Node {
 		return &work.Element{Tag: "div"}
@@ -1190,7 +1217,7 @@ func TestNewAppConfigurations(t *testing.T) {
 		if err != nil {
 			t.Fatalf("failed to create app: %v", err)
 		}
-		if app.clientAsset != "/static/pondlive.js" {
+		if app.clientAsset != staticAssets.Path("pondlive.js") {
 			t.Errorf("expected default client asset, got %s", app.clientAsset)
 		}
 	})
@@ -1203,7 +1230,7 @@ func TestNewAppConfigurations(t *testing.T) {
 		if err != nil {
 			t.Fatalf("failed to create app: %v", err)
 		}
-		if app.clientAsset != "/static/pondlive-dev.js" {
+		if app.clientAsset != staticAssets.Path("pondlive-dev.js") {
 			t.Errorf("expected dev client asset, got %s", app.clientAsset)
 		}
 	})
@@ -1242,13 +1269,36 @@ func TestNewAppConfigurations(t *testing.T) {
 		}
 	})
 
-	t.Run("version defaults to 1", func(t *testing.T) {
+	t.Run("version derives from client asset and build ID", func(t *testing.T) {
 		app, err := New(Config{Component: component})
 		if err != nil {
 			t.Fatalf("failed to create app: %v", err)
 		}
-		if app.version != 1 {
-			t.Errorf("expected version 1, got %d", app.version)
+		again, err := New(Config{Component: component})
+		if err != nil {
+			t.Fatalf("failed to create app: %v", err)
+		}
+		if app.version <= 0 || app.version != again.version {
+			t.Errorf("expected a stable positive version, got %d and %d", app.version, again.version)
+		}
+		if app.endpoint.version != app.version {
+			t.Errorf("expected endpoint to check version %d, got %d", app.version, app.endpoint.version)
+		}
+
+		built, err := New(Config{Component: component, BuildID: "2024-06-01"})
+		if err != nil {
+			t.Fatalf("failed to create app: %v", err)
+		}
+		if built.version == app.version {
+			t.Error("expected build ID to change the version")
+		}
+
+		dev, err := New(Config{Component: component, SessionConfig: &session.Config{DevMode: true}})
+		if err != nil {
+			t.Fatalf("failed to create app: %v", err)
+		}
+		if dev.version == app.version {
+			t.Error("expected a different client bundle to change the version")
 		}
 	})
 }
@@ -1437,6 +1487,30 @@ func TestServeSSR(t *testing.T) {
 		}
 	})
 
+	t.Run("status from render", func(t *testing.T) {
+		statusComponent := func(ctx *runtime.Ctx) work.Node {
+			headers.UseStatus(ctx, http.StatusTeapot)
+			return &work.Element{Tag: "div"}
+		}
+
+		app, err := New(Config{Component: statusComponent})
+		if err != nil {
+			t.Fatalf("failed to create app: %v", err)
+		}
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		rec := httptest.NewRecorder()
+
+		app.serveSSR(rec, req)
+
+		if rec.Code != http.StatusTeapot {
+			t.Errorf("expected 418, got %d", rec.Code)
+		}
+		if !strings.Contains(rec.Body.String(), `<script id="live-boot"`) {
+			t.Error("expected page to still render")
+		}
+	})
+
 	t.Run("version defaults when zero", func(t *testing.T) {
 		app, err := New(Config{Component: component})
 		if err != nil {
@@ -0,0 +1,181 @@
+package server
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	staticPrefix    = "/static/"
+	cacheImmutable  = "public, max-age=31536000, immutable"
+	cacheRevalidate = "no-cache"
+)
+
+type staticAsset struct {
+	hashedName  string
+	hash        string
+	contentType string
+	variants    map[string][]byte
+}
+
+// assetServer serves embedded assets under both their plain and
+// content-hashed names. Hashed names are cached forever; plain names are
+// revalidated through their ETag.
+type assetServer struct {
+	assets map[string]*staticAsset
+	byName map[string]*staticAsset
+}
+
+var staticAssets = newAssetServer(Assets)
+
+func newAssetServer(fsys fs.FS) *assetServer {
+	s := &assetServer{
+		assets: make(map[string]*staticAsset),
+		byName: make(map[string]*staticAsset),
+	}
+	if fsys == nil {
+		return s
+	}
+
+	_ = fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if strings.HasSuffix(name, ".gz") || strings.HasSuffix(name, ".br") {
+			return nil
+		}
+
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil
+		}
+
+		sum := sha256.Sum256(data)
+		asset := &staticAsset{
+			hash:        hex.EncodeToString(sum[:6]),
+			contentType: mime.TypeByExtension(path.Ext(name)),
+			variants:    map[string][]byte{"": data},
+		}
+		asset.hashedName = hashedAssetName(name, asset.hash)
+		if asset.contentType == "" {
+			asset.contentType = "application/octet-stream"
+		}
+		for _, enc := range []string{"br", "gzip"} {
+			if variant, err := fs.ReadFile(fsys, name+encodingSuffix(enc)); err == nil {
+				asset.variants[enc] = variant
+			}
+		}
+
+		s.byName[name] = asset
+		s.assets[name] = asset
+		s.assets[asset.hashedName] = asset
+		return nil
+	})
+	return s
+}
+
+func hashedAssetName(name, hash string) string {
+	ext := path.Ext(name)
+	return strings.TrimSuffix(name, ext) + "." + hash + ext
+}
+
+func encodingSuffix(enc string) string {
+	if enc == "gzip" {
+		return ".gz"
+	}
+	return "." + enc
+}
+
+// Path returns the fingerprinted URL for an embedded asset, or its plain URL
+// when the asset is unknown.
+func (s *assetServer) Path(name string) string {
+	if asset, ok := s.byName[name]; ok {
+		return staticPrefix + asset.hashedName
+	}
+	return staticPrefix + name
+}
+
+func (s *assetServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	name := strings.TrimPrefix(r.URL.Path, staticPrefix)
+	asset, ok := s.assets[name]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	enc := negotiateEncoding(r.Header.Get("Accept-Encoding"), asset.variants)
+	body := asset.variants[enc]
+
+	h := w.Header()
+	h.Add("Vary", "Accept-Encoding")
+	h.Set("Content-Type", asset.contentType)
+	h.Set("ETag", assetETag(asset.hash, enc))
+	if name == asset.hashedName {
+		h.Set("Cache-Control", cacheImmutable)
+	} else {
+		h.Set("Cache-Control", cacheRevalidate)
+	}
+	if enc != "" {
+		h.Set("Content-Encoding", enc)
+	}
+
+	http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(body))
+}
+
+func assetETag(hash, enc string) string {
+	if enc == "" {
+		return `"` + hash + `"`
+	}
+	return `"` + hash + "-" + enc + `"`
+}
+
+// negotiateEncoding picks the best available variant for an Accept-Encoding
+// header, preferring brotli over gzip at equal weight. The empty string
+// selects the uncompressed body.
+func negotiateEncoding(header string, variants map[string][]byte) string {
+	best, bestQ := "", 0.0
+	for _, part := range strings.Split(header, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		if coding == "" {
+			continue
+		}
+
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+
+		candidates := []string{coding}
+		if coding == "*" {
+			candidates = []string{"br", "gzip"}
+		}
+		for _, enc := range candidates {
+			if _, ok := variants[enc]; !ok || q <= 0 {
+				continue
+			}
+			if q > bestQ || (q == bestQ && enc == "br") {
+				best, bestQ = enc, q
+			}
+		}
+	}
+	return best
+}
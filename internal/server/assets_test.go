@@ -0,0 +1,185 @@
+package server
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/eleven-am/pondlive/internal/view/diff"
+)
+
+func testAssetServer() *assetServer {
+	return newAssetServer(fstest.MapFS{
+		"app.js":    {Data: []byte("console.log('app')")},
+		"app.js.gz": {Data: []byte("gzip-bytes")},
+		"app.js.br": {Data: []byte("br-bytes")},
+		"plain.css": {Data: []byte("body{}")},
+	})
+}
+
+func serveAsset(s *assetServer, path string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestAssetServerFingerprintedPath(t *testing.T) {
+	s := testAssetServer()
+
+	hashed := s.Path("app.js")
+	if hashed == "/static/app.js" || !strings.HasPrefix(hashed, "/static/app.") || !strings.HasSuffix(hashed, ".js") {
+		t.Fatalf("expected content-hashed path, got %s", hashed)
+	}
+	if s.Path("missing.js") != "/static/missing.js" {
+		t.Errorf("expected unknown asset to keep its plain path, got %s", s.Path("missing.js"))
+	}
+
+	rec := serveAsset(s, hashed, nil)
+	if rec.Code != http.StatusOK || rec.Body.String() != "console.log('app')" {
+		t.Fatalf("expected asset body, got %d %q", rec.Code, rec.Body.String())
+	}
+	if cc := rec.Header().Get("Cache-Control"); cc != cacheImmutable {
+		t.Errorf("expected immutable caching, got %q", cc)
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.Contains(ct, "javascript") {
+		t.Errorf("expected javascript content type, got %q", ct)
+	}
+
+	plain := serveAsset(s, "/static/app.js", nil)
+	if cc := plain.Header().Get("Cache-Control"); cc != cacheRevalidate {
+		t.Errorf("expected plain path to revalidate, got %q", cc)
+	}
+}
+
+func TestAssetServerContentEncoding(t *testing.T) {
+	s := testAssetServer()
+	path := s.Path("app.js")
+
+	cases := []struct {
+		accept, encoding, body string
+	}{
+		{"gzip, deflate, br", "br", "br-bytes"},
+		{"gzip", "gzip", "gzip-bytes"},
+		{"br;q=0, gzip;q=0.8", "gzip", "gzip-bytes"},
+		{"", "", "console.log('app')"},
+		{"identity", "", "console.log('app')"},
+	}
+	for _, tc := range cases {
+		rec := serveAsset(s, path, map[string]string{"Accept-Encoding": tc.accept})
+		if got := rec.Header().Get("Content-Encoding"); got != tc.encoding {
+			t.Errorf("Accept-Encoding %q: expected encoding %q, got %q", tc.accept, tc.encoding, got)
+		}
+		if rec.Body.String() != tc.body {
+			t.Errorf("Accept-Encoding %q: expected body %q, got %q", tc.accept, tc.body, rec.Body.String())
+		}
+		if rec.Header().Get("Vary") != "Accept-Encoding" {
+			t.Errorf("expected Vary: Accept-Encoding")
+		}
+	}
+
+	css := serveAsset(s, s.Path("plain.css"), map[string]string{"Accept-Encoding": "br"})
+	if css.Header().Get("Content-Encoding") != "" || css.Body.String() != "body{}" {
+		t.Errorf("expected asset without variants to be served as is")
+	}
+}
+
+func TestAssetServerETag(t *testing.T) {
+	s := testAssetServer()
+	path := s.Path("app.js")
+
+	first := serveAsset(s, path, map[string]string{"Accept-Encoding": "gzip"})
+	etag := first.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("expected ETag")
+	}
+	if identity := serveAsset(s, path, nil).Header().Get("ETag"); identity == etag {
+		t.Error("expected encoded variants to have their own ETag")
+	}
+
+	cached := serveAsset(s, path, map[string]string{"Accept-Encoding": "gzip", "If-None-Match": etag})
+	if cached.Code != http.StatusNotModified {
+		t.Errorf("expected 304, got %d", cached.Code)
+	}
+	if cached.Body.Len() != 0 {
+		t.Error("expected empty body on 304")
+	}
+}
+
+func TestAssetServerRejectsUnknownAndWrites(t *testing.T) {
+	s := testAssetServer()
+
+	if rec := serveAsset(s, "/static/nope.js", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", rec.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, s.Path("app.js"), nil)
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405, got %d", rec.Code)
+	}
+}
+
+func TestEmbeddedClientAssetsHaveVariants(t *testing.T) {
+	for _, name := range []string{"pondlive.js", "pondlive-dev.js"} {
+		asset, ok := staticAssets.byName[name]
+		if !ok {
+			t.Fatalf("expected embedded %s", name)
+		}
+		if _, ok := asset.variants["gzip"]; !ok {
+			t.Errorf("expected gzip variant for %s", name)
+		}
+		if _, ok := asset.variants["br"]; !ok {
+			t.Errorf("expected brotli variant for %s", name)
+		}
+	}
+}
+
+// The bundles are built separately from the Go code, so check that the
+// embedded client knows every patch op the server can emit and that the
+// precompressed copies are of the current bundle.
+func TestEmbeddedClientMatchesServer(t *testing.T) {
+	ops := []diff.OpKind{
+		diff.OpSetText, diff.OpAppendText, diff.OpSpliceText, diff.OpSetComment,
+		diff.OpSetAttr, diff.OpDelAttr, diff.OpSetStyle, diff.OpDelStyle,
+		diff.OpSetStyleDecl, diff.OpDelStyleDecl, diff.OpSetHandlers,
+		diff.OpSetScript, diff.OpDelScript, diff.OpSetRef, diff.OpDelRef,
+		diff.OpReplaceNode, diff.OpAddChild, diff.OpDelChild, diff.OpMoveChild,
+		diff.OpDefTemplate, diff.OpSetSlots,
+	}
+
+	for _, name := range []string{"pondlive.js", "pondlive-dev.js"} {
+		asset := staticAssets.byName[name]
+		if asset == nil {
+			t.Fatalf("expected embedded %s", name)
+		}
+		bundle := asset.variants[""]
+
+		for _, op := range ops {
+			if !bytes.Contains(bundle, []byte(`"`+string(op)+`"`)) {
+				t.Errorf("%s does not handle patch op %q; rebuild the client", name, op)
+			}
+		}
+
+		zr, err := gzip.NewReader(bytes.NewReader(asset.variants["gzip"]))
+		if err != nil {
+			t.Fatalf("%s.gz: %v", name, err)
+		}
+		unzipped, err := io.ReadAll(zr)
+		if err != nil {
+			t.Fatalf("%s.gz: %v", name, err)
+		}
+		if !bytes.Equal(unzipped, bundle) {
+			t.Errorf("%s.gz is stale; rebuild the client", name)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package server
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/eleven-am/pondlive/internal/route"
+)
+
+func normalizeBasePath(base string) string {
+	return route.NormalizeBase(base)
+}
+
+// stripBasePath serves next with base removed from the request path, so every
+// internal route is registered once at the mux root regardless of where the
+// app is mounted.
+func stripBasePath(base string, next http.Handler) http.Handler {
+	if base == "" {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rest, ok := strings.CutPrefix(r.URL.Path, base)
+		if !ok || (rest != "" && !strings.HasPrefix(rest, "/")) {
+			http.NotFound(w, r)
+			return
+		}
+		if rest == "" {
+			rest = "/"
+		}
+
+		r2 := new(http.Request)
+		*r2 = *r
+		r2.URL = new(url.URL)
+		*r2.URL = *r.URL
+		r2.URL.Path = rest
+		r2.URL.RawPath = ""
+		next.ServeHTTP(w, r2)
+	})
+}
+
+// prefixPath mounts an app-relative path under the base path. Absolute URLs
+// and protocol-relative paths are left alone.
+func (a *App) prefixPath(p string) string {
+	return route.WithBase(a.basePath, p)
+}
@@ -0,0 +1,98 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/eleven-am/pondlive/internal/runtime"
+	"github.com/eleven-am/pondlive/internal/work"
+)
+
+func TestNormalizeBasePath(t *testing.T) {
+	cases := map[string]string{
+		"":          "",
+		"/":         "",
+		"admin":     "/admin",
+		"/admin/":   "/admin",
+		" /a/b/ ":   "/a/b",
+		"/admin":    "/admin",
+		"//admin//": "/admin",
+	}
+	for in, want := range cases {
+		if got := normalizeBasePath(in); got != want {
+			t.Errorf("normalizeBasePath(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestAppMountedUnderBasePath(t *testing.T) {
+	component := func(ctx *runtime.Ctx) work.Node {
+		return &work.Element{Tag: "div", Children: []work.Node{&work.Text{Value: "admin"}}}
+	}
+
+	app, err := New(Config{Component: component, BasePath: "/admin/"})
+	if err != nil {
+		t.Fatalf("failed to create app: %v", err)
+	}
+
+	if !strings.HasPrefix(app.clientAsset, "/admin/static/pondlive.") {
+		t.Errorf("expected client asset under base path, got %s", app.clientAsset)
+	}
+	if app.sessionConfig.BasePath != "/admin" {
+		t.Errorf("expected session base path, got %q", app.sessionConfig.BasePath)
+	}
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		app.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	page := serve("/admin/users")
+	if page.Code != http.StatusOK {
+		t.Fatalf("expected SSR under base path, got %d", page.Code)
+	}
+	body := page.Body.String()
+	if !strings.Contains(body, app.clientAsset) {
+		t.Error("expected page to reference the mounted client asset")
+	}
+	if !strings.Contains(body, `\"base\":\"/admin\"`) && !strings.Contains(body, `"base":"/admin"`) {
+		t.Error("expected boot payload to carry the base path")
+	}
+	if !strings.Contains(body, `"path":"/users"`) && !strings.Contains(body, `\"path\":\"/users\"`) {
+		t.Error("expected boot location to be relative to the base path")
+	}
+
+	if rec := serve(app.clientAsset); rec.Code != http.StatusOK {
+		t.Errorf("expected asset under base path, got %d", rec.Code)
+	}
+	if rec := serve("/admin"); rec.Code != http.StatusOK {
+		t.Errorf("expected base path root to render, got %d", rec.Code)
+	}
+	if rec := serve("/admin/_handlers/missing/h0"); rec.Code != http.StatusNotFound || !strings.Contains(rec.Body.String(), "session not found") {
+		t.Errorf("expected handler dispatcher under base path, got %d %q", rec.Code, rec.Body.String())
+	}
+	if rec := serve("/users"); rec.Code != http.StatusNotFound {
+		t.Errorf("expected paths outside the base path to 404, got %d", rec.Code)
+	}
+	if rec := serve("/administrator"); rec.Code != http.StatusNotFound {
+		t.Errorf("expected sibling prefix to 404, got %d", rec.Code)
+	}
+}
+
+func TestPrefixPath(t *testing.T) {
+	app := &App{basePath: "/admin"}
+	cases := map[string]string{
+		"/login?next=%2F": "/admin/login?next=%2F",
+		"/":               "/admin/",
+		"https://x.test/": "https://x.test/",
+		"//cdn.test/a":    "//cdn.test/a",
+	}
+	for in, want := range cases {
+		if got := app.prefixPath(in); got != want {
+			t.Errorf("prefixPath(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
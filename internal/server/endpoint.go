@@ -4,6 +4,8 @@ import (
 	"errors"
 	"net/http"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/eleven-am/pondlive/internal/protocol"
 	"github.com/eleven-am/pondlive/internal/session"
@@ -11,14 +13,29 @@ import (
 )
 
 type Endpoint struct {
-	registry    *SessionRegistry
-	endpoint    *pond.Endpoint
-	pubsubLobby *PubSubLobby
+	registry       *SessionRegistry
+	endpoint       *pond.Endpoint
+	pubsubLobby    *PubSubLobby
+	version        int
+	reloadGrace    time.Duration
+	reconnectGrace time.Duration
+
+	graceMu     sync.Mutex
+	graceTimers map[session.SessionID]*time.Timer
+}
+
+// ackingTransport is implemented by the live transports that track which
+// messages the client has received.
+type ackingTransport interface {
+	AckThrough(seq uint64)
+	SendAck(sid string, eid uint64) uint64
 }
 
 const (
 	sessionAssignKey = "live.session"
 	headersAssignKey = "live.headers"
+
+	defaultReconnectGrace = 30 * time.Second
 )
 
 func Register(srv *pond.Manager, path string, registry *SessionRegistry) (*Endpoint, error) {
@@ -35,8 +52,10 @@ func Register(srv *pond.Manager, path string, registry *SessionRegistry) (*Endpo
 	})
 
 	e := &Endpoint{
-		registry: registry,
-		endpoint: endpoint,
+		registry:       registry,
+		endpoint:       endpoint,
+		reconnectGrace: defaultReconnectGrace,
+		graceTimers:    make(map[session.SessionID]*time.Timer),
 	}
 	e.configure()
 	e.pubsubLobby = NewPubSubLobby(endpoint, registry)
@@ -44,6 +63,58 @@ func Register(srv *pond.Manager, path string, registry *SessionRegistry) (*Endpo
 	return e, nil
 }
 
+// SetVersion makes joins from clients booted under another version get a
+// reload directive instead of being attached to a session.
+func (e *Endpoint) SetVersion(version int, reloadGrace time.Duration) {
+	e.version = version
+	e.reloadGrace = reloadGrace
+}
+
+// SetReconnectGrace sets how long a session outlives its dropped connection
+// waiting for the client to come back. Zero keeps the default of 30 seconds.
+func (e *Endpoint) SetReconnectGrace(grace time.Duration) {
+	if grace > 0 {
+		e.reconnectGrace = grace
+	}
+}
+
+// expireAfterGrace removes the session once the reconnect grace passes, unless
+// abandoned still reports false by then. A session keeps at most one pending
+// expiry; detaching again replaces it and a rejoin cancels it.
+func (e *Endpoint) expireAfterGrace(sid session.SessionID, abandoned func() bool) {
+	e.graceMu.Lock()
+	defer e.graceMu.Unlock()
+
+	if timer, ok := e.graceTimers[sid]; ok {
+		timer.Stop()
+	}
+	var timer *time.Timer
+	timer = time.AfterFunc(e.reconnectGrace, func() {
+		e.graceMu.Lock()
+		if e.graceTimers[sid] != timer {
+			e.graceMu.Unlock()
+			return
+		}
+		delete(e.graceTimers, sid)
+		e.graceMu.Unlock()
+
+		if abandoned() {
+			e.registry.Remove(sid)
+		}
+	})
+	e.graceTimers[sid] = timer
+}
+
+func (e *Endpoint) cancelGrace(sid session.SessionID) {
+	e.graceMu.Lock()
+	defer e.graceMu.Unlock()
+
+	if timer, ok := e.graceTimers[sid]; ok {
+		timer.Stop()
+		delete(e.graceTimers, sid)
+	}
+}
+
 func (e *Endpoint) configure() {
 	lobby := e.endpoint.CreateChannel("live/:sid", e.onJoin)
 	lobby.OnMessage("evt", e.onEvt)
@@ -55,6 +126,7 @@ type joinPayload struct {
 	SID string `json:"sid"`
 	Ver int    `json:"ver"`
 	Ack int    `json:"ack"`
+	Enc string `json:"enc,omitempty"`
 }
 
 func (e *Endpoint) onJoin(ctx *pond.JoinContext) error {
@@ -76,6 +148,10 @@ func (e *Endpoint) onJoin(ctx *pond.JoinContext) error {
 		return ctx.Decline(pond.StatusBadRequest, "missing session identifier")
 	}
 
+	if e.version != 0 && payload.Ver != e.version {
+		return e.requestReload(ctx, payload)
+	}
+
 	if _, ok := e.registry.Lookup(session.SessionID(sessionID)); !ok {
 		return ctx.Decline(pond.StatusNotFound, "session not found or expired")
 	}
@@ -96,11 +172,13 @@ func (e *Endpoint) onJoin(ctx *pond.JoinContext) error {
 	}
 
 	transport := session.NewWebSocketTransport(ctx.Channel, user.UserID, headers)
+	transport.SetEncoding(session.ParseEncoding(payload.Enc))
 	sess, err := e.registry.Attach(session.SessionID(sessionID), user.UserID, transport)
 	if err != nil {
 		_ = transport.Close()
 		return err
 	}
+	e.cancelGrace(session.SessionID(sessionID))
 
 	go func() {
 		if err := sess.Flush(); err != nil {
@@ -111,6 +189,27 @@ func (e *Endpoint) onJoin(ctx *pond.JoinContext) error {
 	return nil
 }
 
+func (e *Endpoint) requestReload(ctx *pond.JoinContext, payload joinPayload) error {
+	user := ctx.GetUser()
+	ctx.Accept()
+	if errStr := ctx.Error(); errStr != "" {
+		return errors.New(errStr)
+	}
+
+	transport := session.NewWebSocketTransport(ctx.Channel, user.UserID, nil)
+	transport.SetEncoding(session.ParseEncoding(payload.Enc))
+	defer transport.Close()
+
+	return transport.Send(string(protocol.TopicReload), "reload", e.reloadPayload())
+}
+
+func (e *Endpoint) reloadPayload() protocol.Reload {
+	return protocol.Reload{
+		Ver:   e.version,
+		Grace: int(e.reloadGrace / time.Millisecond),
+	}
+}
+
 func (e *Endpoint) onAck(ctx *pond.EventContext) error {
 	var ack protocol.ClientAck
 	if err := ctx.ParsePayload(&ack); err != nil {
@@ -122,8 +221,8 @@ func (e *Endpoint) onAck(ctx *pond.EventContext) error {
 		return nil
 	}
 
-	if wsTransport, ok := transport.(*session.WebSocketTransport); ok {
-		wsTransport.AckThrough(uint64(ack.Seq))
+	if acker, ok := transport.(ackingTransport); ok {
+		acker.AckThrough(ack.Seq)
 	}
 
 	return nil
@@ -134,11 +233,26 @@ func (e *Endpoint) onLeave(ctx *pond.LeaveContext) {
 		return
 	}
 
-	if sid := ctx.GetAssign(sessionAssignKey); sid != nil {
-		if sessionID, ok := sid.(string); ok {
-			e.registry.Remove(session.SessionID(sessionID))
-		}
+	sid, ok := ctx.GetAssign(sessionAssignKey).(string)
+	if !ok {
+		return
+	}
+	sessionID := session.SessionID(sid)
+
+	// A dropped socket only detaches, so the client can rejoin the same session
+	// and replay the events it has not seen acknowledged.
+	if ctx.GetReason() != "connection_closed" {
+		e.cancelGrace(sessionID)
+		e.registry.Remove(sessionID)
+		return
+	}
+	if user := ctx.GetUser(); user != nil {
+		e.registry.Detach(user.UserID)
 	}
+	e.expireAfterGrace(sessionID, func() bool {
+		_, _, attached := e.registry.ConnectionForSession(sessionID)
+		return !attached
+	})
 }
 
 func (e *Endpoint) getSession(ctx *pond.EventContext, sid string) (*session.LiveSession, session.Transport, bool) {
@@ -166,7 +280,16 @@ func (e *Endpoint) onEvt(ctx *pond.EventContext) error {
 		return nil
 	}
 
-	if bus := sess.Bus(); bus != nil {
+	publishEvt(sess, transport, evt)
+	return nil
+}
+
+// publishEvt hands a client event to the session bus and acknowledges it on
+// the transport it arrived through. Events the client replays after a
+// reconnect are acknowledged again but not republished.
+func publishEvt(sess *session.LiveSession, transport session.Transport, evt protocol.ClientEvt) {
+	fresh, through := sess.AcceptEvent(evt.ID)
+	if bus := sess.Bus(); fresh && bus != nil {
 		if strings.HasPrefix(string(evt.Type), "script:") && evt.Action == "message" {
 			if payload, ok := protocol.DecodePayload[protocol.ScriptPayload](evt.Payload); ok {
 				topic := protocol.Topic(string(evt.Type) + ":" + payload.Event)
@@ -177,11 +300,7 @@ func (e *Endpoint) onEvt(ctx *pond.EventContext) error {
 		}
 	}
 
-	if transport != nil {
-		if wsTransport, isWS := transport.(*session.WebSocketTransport); isWS {
-			wsTransport.SendAck(evt.SID)
-		}
+	if acker, ok := transport.(ackingTransport); ok {
+		acker.SendAck(evt.SID, through)
 	}
-
-	return nil
 }
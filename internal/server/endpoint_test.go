@@ -0,0 +1,194 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/eleven-am/pondlive/internal/protocol"
+	"github.com/eleven-am/pondlive/internal/session"
+	"github.com/gorilla/websocket"
+)
+
+type wsFrame struct {
+	Action      string          `json:"action"`
+	ChannelName string          `json:"channelName"`
+	RequestID   string          `json:"requestId"`
+	Event       string          `json:"event"`
+	Payload     json.RawMessage `json:"payload"`
+}
+
+type wsClient struct {
+	t       *testing.T
+	conn    *websocket.Conn
+	channel string
+	nextReq int
+}
+
+func joinLive(t *testing.T, srv *httptest.Server, sid session.SessionID, version int) *wsClient {
+	t.Helper()
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/live", nil)
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	c := &wsClient{t: t, conn: conn, channel: "live/" + string(sid)}
+	c.send("JOIN_CHANNEL", "JOIN_CHANNEL", map[string]any{"sid": sid, "ver": version})
+	c.next("ACKNOWLEDGE")
+	return c
+}
+
+func (c *wsClient) send(action, event string, payload any) {
+	c.t.Helper()
+	c.nextReq++
+	frame := map[string]any{
+		"action":      action,
+		"channelName": c.channel,
+		"requestId":   fmt.Sprintf("req-%d", c.nextReq),
+		"event":       event,
+		"payload":     payload,
+	}
+	if err := c.conn.WriteJSON(frame); err != nil {
+		c.t.Fatalf("write failed: %v", err)
+	}
+}
+
+func (c *wsClient) next(event string) wsFrame {
+	c.t.Helper()
+	_ = c.conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	for {
+		var frame wsFrame
+		if err := c.conn.ReadJSON(&frame); err != nil {
+			c.t.Fatalf("waiting for %q: %v", event, err)
+		}
+		if frame.Event == event {
+			return frame
+		}
+	}
+}
+
+func (c *wsClient) nextAck() protocol.ServerAck {
+	c.t.Helper()
+	frame := c.next("ack")
+	var msg struct {
+		Data protocol.ServerAck `json:"data"`
+	}
+	if err := json.Unmarshal(frame.Payload, &msg); err != nil {
+		c.t.Fatalf("invalid ack %s: %v", frame.Payload, err)
+	}
+	return msg.Data
+}
+
+func TestDroppedSocketRejoinsAndDeduplicatesReplay(t *testing.T) {
+	app, srv, sid := sseTestApp(t, Config{})
+
+	sess, _ := app.registry.Lookup(sid)
+	received := make(chan any, 4)
+	sess.Bus().SubscribeAll(func(topic protocol.Topic, event string, data interface{}) {
+		if topic == "test" {
+			received <- data
+		}
+	})
+
+	evt := protocol.ClientEvt{
+		Event:   protocol.Event{Type: "test", SID: string(sid)},
+		ID:      1,
+		Action:  "submit",
+		Payload: map[string]any{"amount": 10},
+	}
+
+	first := joinLive(t, srv, sid, app.version)
+	first.send("BROADCAST", "evt", evt)
+	if ack := first.nextAck(); ack.EID != 1 {
+		t.Fatalf("expected ack through event 1, got %d", ack.EID)
+	}
+	_ = first.conn.Close()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if _, _, attached := app.registry.ConnectionForSession(sid); !attached {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected the dropped socket to be detached")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if _, ok := app.registry.Lookup(sid); !ok {
+		t.Fatal("expected the session to outlive its dropped socket")
+	}
+
+	second := joinLive(t, srv, sid, app.version)
+	defer second.conn.Close()
+	second.send("BROADCAST", "evt", evt)
+	if ack := second.nextAck(); ack.EID != 1 {
+		t.Errorf("expected replay to be acked through event 1, got %d", ack.EID)
+	}
+
+	select {
+	case <-received:
+	case <-time.After(time.Second):
+		t.Fatal("expected the first delivery to reach the bus")
+	}
+	select {
+	case data := <-received:
+		t.Errorf("expected the replayed event to be dropped, got %v", data)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestDroppedSocketSessionRemovedAfterGrace(t *testing.T) {
+	app, srv, sid := sseTestApp(t, Config{})
+	app.endpoint.reconnectGrace = 20 * time.Millisecond
+
+	client := joinLive(t, srv, sid, app.version)
+	_ = client.conn.Close()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if _, ok := app.registry.Lookup(sid); !ok {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Error("expected session to be removed once the socket stayed closed")
+}
+
+func TestReconnectGraceKeepsOneTimerPerSession(t *testing.T) {
+	app, srv, sid := sseTestApp(t, Config{ReconnectGrace: time.Hour})
+	if app.endpoint.reconnectGrace != time.Hour {
+		t.Fatalf("expected configured grace, got %v", app.endpoint.reconnectGrace)
+	}
+
+	pendingTimers := func() int {
+		app.endpoint.graceMu.Lock()
+		defer app.endpoint.graceMu.Unlock()
+		return len(app.endpoint.graceTimers)
+	}
+
+	for i := 0; i < 3; i++ {
+		client := joinLive(t, srv, sid, app.version)
+		if n := pendingTimers(); n != 0 {
+			t.Fatalf("expected rejoin to cancel the pending expiry, got %d timers", n)
+		}
+		_ = client.conn.Close()
+
+		deadline := time.Now().Add(2 * time.Second)
+		for pendingTimers() != 1 {
+			if time.Now().After(deadline) {
+				t.Fatalf("expected one pending expiry after drop %d, got %d", i+1, pendingTimers())
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	app.endpoint.expireAfterGrace(sid, func() bool { return true })
+	if n := pendingTimers(); n != 1 {
+		t.Errorf("expected a second detach to replace the expiry, got %d timers", n)
+	}
+	if _, ok := app.registry.Lookup(sid); !ok {
+		t.Error("expected the session to be kept during the grace")
+	}
+}
@@ -13,7 +13,7 @@ var (
 	ErrSessionNotFound = errors.New("server: session not found")
 )
 
-//go:embed static/pondlive.js static/pondlive-dev.js static/pondlive-dev.js.map
+//go:embed static/pondlive.js static/pondlive.js.gz static/pondlive.js.br static/pondlive-dev.js static/pondlive-dev.js.gz static/pondlive-dev.js.br static/pondlive-dev.js.map
 var assetsEmbed embed.FS
 
 var Assets, _ = fs.Sub(assetsEmbed, "static")
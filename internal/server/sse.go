@@ -0,0 +1,134 @@
+package server
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/eleven-am/pondlive/internal/protocol"
+	"github.com/eleven-am/pondlive/internal/session"
+)
+
+const (
+	ssePath         = "/live/sse"
+	sseHeartbeat    = 15 * time.Second
+	sseMaxPostBytes = 4 << 20
+)
+
+// serveSSE attaches a session to a Server-Sent Events stream, the fallback
+// for clients whose WebSocket cannot be opened. A reconnecting EventSource
+// resumes the transport already attached to the session.
+func (e *Endpoint) serveSSE(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	sid := session.SessionID(query.Get("sid"))
+	if sid == "" {
+		http.Error(w, "missing session identifier", http.StatusBadRequest)
+		return
+	}
+	enc := session.ParseEncoding(query.Get("enc"))
+
+	if ver, _ := strconv.Atoi(query.Get("ver")); e.version != 0 && ver != e.version {
+		transport := session.NewSSETransport("", nil)
+		transport.SetEncoding(enc)
+		_ = transport.Send(string(protocol.TopicReload), "reload", e.reloadPayload())
+		_ = transport.Close()
+		_ = transport.Stream(w, r, 0)
+		return
+	}
+
+	sess, ok := e.registry.Lookup(sid)
+	if !ok {
+		http.Error(w, "session not found or expired", http.StatusNotFound)
+		return
+	}
+
+	transport, resumed := e.attachedSSE(sid)
+	if !resumed {
+		connID, err := newConnID()
+		if err != nil {
+			http.Error(w, "failed to allocate connection", http.StatusInternalServerError)
+			return
+		}
+		transport = session.NewSSETransport(connID, cloneHeader(r.Header))
+		if _, err := e.registry.Attach(sid, connID, transport); err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		go func() {
+			if err := sess.Flush(); err != nil {
+				e.registry.Detach(connID)
+			}
+		}()
+	}
+	transport.SetEncoding(enc)
+	e.cancelGrace(sid)
+
+	_ = transport.Stream(w, r, sseHeartbeat)
+
+	e.expireAfterGrace(sid, func() bool {
+		if transport.Streaming() {
+			return false
+		}
+		_, current, ok := e.registry.ConnectionForSession(sid)
+		return !ok || current == session.Transport(transport)
+	})
+}
+
+func (e *Endpoint) attachedSSE(sid session.SessionID) (*session.SSETransport, bool) {
+	_, current, ok := e.registry.ConnectionForSession(sid)
+	if !ok {
+		return nil, false
+	}
+	transport, ok := current.(*session.SSETransport)
+	return transport, ok
+}
+
+// serveSSEMessage accepts the events and acks that a WebSocket client would
+// send over its channel, addressed to the connection announced on the stream.
+func (e *Endpoint) serveSSEMessage(w http.ResponseWriter, r *http.Request) {
+	sess, transport, ok := e.registry.LookupByConnection(r.PathValue("conn"))
+	if !ok || sess == nil {
+		http.Error(w, "connection not found", http.StatusNotFound)
+		return
+	}
+
+	body := http.MaxBytesReader(w, r.Body, sseMaxPostBytes)
+	switch r.PathValue("event") {
+	case "evt":
+		var evt protocol.ClientEvt
+		if err := json.NewDecoder(body).Decode(&evt); err != nil {
+			http.Error(w, "invalid event payload", http.StatusBadRequest)
+			return
+		}
+		if session.SessionID(evt.SID) != sess.ID() {
+			http.Error(w, "session mismatch", http.StatusForbidden)
+			return
+		}
+		publishEvt(sess, transport, evt)
+	case "ack":
+		var ack protocol.ClientAck
+		if err := json.NewDecoder(body).Decode(&ack); err != nil {
+			http.Error(w, "invalid ack payload", http.StatusBadRequest)
+			return
+		}
+		if acker, ok := transport.(ackingTransport); ok {
+			acker.AckThrough(ack.Seq)
+		}
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func newConnID() (string, error) {
+	var buf [16]byte
+	if _, err := rand.Read(buf[:]); err != nil {
+		return "", err
+	}
+	return "sse-" + base64.RawURLEncoding.EncodeToString(buf[:]), nil
+}
@@ -0,0 +1,352 @@
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/eleven-am/pondlive/internal/protocol"
+	"github.com/eleven-am/pondlive/internal/runtime"
+	"github.com/eleven-am/pondlive/internal/session"
+	"github.com/eleven-am/pondlive/internal/work"
+)
+
+type sseEvent struct {
+	id    string
+	event string
+	data  string
+}
+
+type sseReader struct {
+	t      *testing.T
+	events chan sseEvent
+}
+
+func openSSE(t *testing.T, url string) (*http.Response, *sseReader) {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("stream request failed: %v", err)
+	}
+	return resp, readSSE(t, resp.Body)
+}
+
+func readSSE(t *testing.T, body io.Reader) *sseReader {
+	r := &sseReader{t: t, events: make(chan sseEvent, 16)}
+	go func() {
+		defer close(r.events)
+		var ev sseEvent
+		scanner := bufio.NewScanner(body)
+		for scanner.Scan() {
+			line := scanner.Text()
+			switch {
+			case line == "":
+				if ev.data != "" {
+					if ev.event == "" {
+						ev.event = "message"
+					}
+					r.events <- ev
+				}
+				ev = sseEvent{}
+			case strings.HasPrefix(line, "id: "):
+				ev.id = strings.TrimPrefix(line, "id: ")
+			case strings.HasPrefix(line, "event: "):
+				ev.event = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				ev.data = strings.TrimPrefix(line, "data: ")
+			}
+		}
+	}()
+	return r
+}
+
+func (r *sseReader) next(event string) sseEvent {
+	r.t.Helper()
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case ev, ok := <-r.events:
+			if !ok {
+				r.t.Fatalf("stream ended before %q event", event)
+			}
+			if ev.event == event {
+				return ev
+			}
+		case <-timeout:
+			r.t.Fatalf("timed out waiting for %q event", event)
+		}
+	}
+}
+
+// nextMessage waits for a live message with the given event name.
+func (r *sseReader) nextMessage(enc session.Encoding, event string) (sseEvent, session.Message) {
+	r.t.Helper()
+	for {
+		ev := r.next("message")
+		msg, err := session.UnmarshalMessageAs([]byte(ev.data), enc)
+		if err != nil {
+			r.t.Fatalf("invalid message %q: %v", ev.data, err)
+		}
+		if msg.Event == event {
+			return ev, msg
+		}
+	}
+}
+
+func sseTestApp(t *testing.T, cfg Config) (*App, *httptest.Server, session.SessionID) {
+	t.Helper()
+	cfg.Component = func(ctx *runtime.Ctx) work.Node {
+		return &work.Element{Tag: "div", Children: []work.Node{&work.Text{Value: "live"}}}
+	}
+	app, err := New(cfg)
+	if err != nil {
+		t.Fatalf("failed to create app: %v", err)
+	}
+	srv := httptest.NewServer(app.Handler())
+	t.Cleanup(srv.Close)
+
+	resp, err := http.Get(srv.URL + "/")
+	if err != nil {
+		t.Fatalf("ssr request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	var sid session.SessionID
+	app.registry.Range(func(sess *session.LiveSession) bool {
+		sid = sess.ID()
+		return false
+	})
+	if sid == "" {
+		t.Fatal("expected SSR to register a session")
+	}
+	return app, srv, sid
+}
+
+func postSSE(t *testing.T, url string, body any) int {
+	t.Helper()
+	raw, _ := json.Marshal(body)
+	resp, err := http.Post(url, "application/json", strings.NewReader(string(raw)))
+	if err != nil {
+		t.Fatalf("post failed: %v", err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+func TestSSETransportRoundTrip(t *testing.T) {
+	app, srv, sid := sseTestApp(t, Config{})
+
+	resp, stream := openSSE(t, fmt.Sprintf("%s/live/sse?sid=%s&ver=%d&enc=compact", srv.URL, sid, app.version))
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("expected event stream, got %q", ct)
+	}
+
+	var connID string
+	if err := json.Unmarshal([]byte(stream.next("conn").data), &connID); err != nil || connID == "" {
+		t.Fatalf("expected connection id, got %q", connID)
+	}
+
+	_, transport, ok := app.registry.LookupByConnection(connID)
+	if !ok {
+		t.Fatal("expected SSE transport to be attached to the session")
+	}
+	if _, isSSE := transport.(*session.SSETransport); !isSSE {
+		t.Fatalf("expected *session.SSETransport, got %T", transport)
+	}
+
+	evt := protocol.ClientEvt{
+		Event:   protocol.Event{Type: "test", SID: string(sid)},
+		Action:  "ping",
+		Payload: map[string]any{"n": 1},
+	}
+	if code := postSSE(t, srv.URL+"/live/sse/"+connID+"/evt", evt); code != http.StatusNoContent {
+		t.Fatalf("expected 204 for event post, got %d", code)
+	}
+
+	ack, msg := stream.nextMessage(session.EncodingCompact, "ack")
+	if fmt.Sprint(msg.Seq) != ack.id {
+		t.Errorf("expected event id %s to match message seq %d", ack.id, msg.Seq)
+	}
+
+	sseTransport := transport.(*session.SSETransport)
+	var seq uint64
+	fmt.Sscan(ack.id, &seq)
+	postSSE(t, srv.URL+"/live/sse/"+connID+"/ack", protocol.ClientAck{Event: protocol.Event{Type: protocol.AckTopic, SID: string(sid)}, Seq: seq})
+	if sseTransport.Pending() != 0 {
+		t.Errorf("expected ack post to clear pending messages, got %d", sseTransport.Pending())
+	}
+
+	evt.SID = "other"
+	if code := postSSE(t, srv.URL+"/live/sse/"+connID+"/evt", evt); code != http.StatusForbidden {
+		t.Errorf("expected 403 for mismatched session, got %d", code)
+	}
+	if code := postSSE(t, srv.URL+"/live/sse/missing/evt", evt); code != http.StatusNotFound {
+		t.Errorf("expected 404 for unknown connection, got %d", code)
+	}
+}
+
+func TestReplayedEventsAreDeduplicated(t *testing.T) {
+	app, srv, sid := sseTestApp(t, Config{})
+
+	resp, stream := openSSE(t, fmt.Sprintf("%s/live/sse?sid=%s&ver=%d", srv.URL, sid, app.version))
+	defer resp.Body.Close()
+	var connID string
+	_ = json.Unmarshal([]byte(stream.next("conn").data), &connID)
+
+	sess, _ := app.registry.Lookup(sid)
+	received := make(chan any, 4)
+	sess.Bus().SubscribeAll(func(topic protocol.Topic, event string, data interface{}) {
+		if topic == "test" {
+			received <- data
+		}
+	})
+
+	evt := protocol.ClientEvt{
+		Event:   protocol.Event{Type: "test", SID: string(sid)},
+		ID:      1,
+		Action:  "submit",
+		Payload: map[string]any{"amount": 10},
+	}
+	for i := 0; i < 2; i++ {
+		if code := postSSE(t, srv.URL+"/live/sse/"+connID+"/evt", evt); code != http.StatusNoContent {
+			t.Fatalf("expected 204, got %d", code)
+		}
+
+		_, msg := stream.nextMessage(session.EncodingJSON, "ack")
+		var ack protocol.ServerAck
+		raw, _ := json.Marshal(msg.Data)
+		_ = json.Unmarshal(raw, &ack)
+		if ack.EID != 1 {
+			t.Errorf("delivery %d: expected ack through event 1, got %d", i, ack.EID)
+		}
+	}
+
+	select {
+	case <-received:
+	case <-time.After(time.Second):
+		t.Fatal("expected the first delivery to reach the bus")
+	}
+	select {
+	case data := <-received:
+		t.Errorf("expected the replayed event to be dropped, got %v", data)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestSSEReconnectResumesTransport(t *testing.T) {
+	app, srv, sid := sseTestApp(t, Config{})
+	url := fmt.Sprintf("%s/live/sse?sid=%s&ver=%d", srv.URL, sid, app.version)
+
+	resp, stream := openSSE(t, url)
+	conn := stream.next("conn").data
+	resp.Body.Close()
+
+	_, transport, _ := app.registry.ConnectionForSession(sid)
+	_ = transport.Send("frame", "patch", nil)
+	_ = transport.Send("frame", "patch", nil)
+	last := transport.(*session.SSETransport).LastSeq()
+
+	req, _ := http.NewRequest(http.MethodGet, url, nil)
+	req.Header.Set("Last-Event-ID", fmt.Sprint(last-1))
+	resumed, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("resume failed: %v", err)
+	}
+	defer resumed.Body.Close()
+
+	stream = readSSE(t, resumed.Body)
+
+	if got := stream.next("conn").data; got != conn {
+		t.Errorf("expected resumed stream to keep connection %s, got %s", conn, got)
+	}
+	if ev, _ := stream.nextMessage(session.EncodingJSON, "patch"); ev.id != fmt.Sprint(last) {
+		t.Errorf("expected replay to start after Last-Event-ID, got id %s", ev.id)
+	}
+}
+
+func TestSSEStreamErrors(t *testing.T) {
+	app, srv, sid := sseTestApp(t, Config{})
+
+	resp, err := http.Get(srv.URL + "/live/sse?sid=missing&ver=" + fmt.Sprint(app.version))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected 404 for unknown session, got %d", resp.StatusCode)
+	}
+
+	resp, err = http.Get(srv.URL + "/live/sse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400 without session id, got %d", resp.StatusCode)
+	}
+
+	stale, stream := openSSE(t, fmt.Sprintf("%s/live/sse?sid=%s&ver=%d", srv.URL, sid, app.version+1))
+	defer stale.Body.Close()
+	_, reload := stream.nextMessage(session.EncodingJSON, "reload")
+	if reload.Topic != string(protocol.TopicReload) {
+		t.Errorf("expected reload topic, got %q", reload.Topic)
+	}
+	if _, ok := app.registry.Lookup(sid); !ok {
+		t.Error("expected stale client not to drop the session")
+	}
+}
+
+func TestSSESessionRemovedAfterGrace(t *testing.T) {
+	app, srv, sid := sseTestApp(t, Config{})
+	app.endpoint.reconnectGrace = 20 * time.Millisecond
+
+	resp, stream := openSSE(t, fmt.Sprintf("%s/live/sse?sid=%s&ver=%d", srv.URL, sid, app.version))
+	stream.next("conn")
+	resp.Body.Close()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if _, ok := app.registry.Lookup(sid); !ok {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Error("expected session to be removed once the stream stayed closed")
+}
+
+func TestTransportModeConfig(t *testing.T) {
+	app, srv, _ := sseTestApp(t, Config{Transport: TransportSSE})
+
+	rec := httptest.NewRecorder()
+	app.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if !strings.Contains(rec.Body.String(), `"transport":"sse"`) {
+		t.Error("expected boot payload to force the SSE transport")
+	}
+
+	resp, err := http.Get(srv.URL + "/live/sse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected SSE route to be mounted, got %d", resp.StatusCode)
+	}
+
+	wsOnly, err := New(Config{Component: app.component, Transport: TransportWebSocket})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec = httptest.NewRecorder()
+	wsOnly.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/live/sse/conn/evt", nil))
+	if rec.Code == http.StatusNotFound && strings.Contains(rec.Body.String(), "connection not found") {
+		t.Error("expected SSE routes not to be mounted in websocket mode")
+	}
+}
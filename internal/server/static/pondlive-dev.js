@@ -37,14 +37,19 @@ var LiveUIModule = (() => {
     "node_modules/@eleven-am/pondsocket-common/subjects/subject.js"(exports) {
       "use strict";
       var __classPrivateFieldSet = exports && exports.__classPrivateFieldSet || function(receiver, state, value, kind, f) {
-        if (kind === "m") throw new TypeError("Private method is not writable");
-        if (kind === "a" && !f) throw new TypeError("Private accessor was defined without a setter");
-        if (typeof state === "function" ? receiver !== state || !f : !state.has(receiver)) throw new TypeError("Cannot write private member to an object whose class did not declare it");
+        if (kind === "m")
+          throw new TypeError("Private method is not writable");
+        if (kind === "a" && !f)
+          throw new TypeError("Private accessor was defined without a setter");
+        if (typeof state === "function" ? receiver !== state || !f : !state.has(receiver))
+          throw new TypeError("Cannot write private member to an object whose class did not declare it");
         return kind === "a" ? f.call(receiver, value) : f ? f.value = value : state.set(receiver, value), value;
       };
       var __classPrivateFieldGet = exports && exports.__classPrivateFieldGet || function(receiver, state, kind, f) {
-        if (kind === "a" && !f) throw new TypeError("Private accessor was defined without a getter");
-        if (typeof state === "function" ? receiver !== state || !f : !state.has(receiver)) throw new TypeError("Cannot read private member from an object whose class did not declare it");
+        if (kind === "a" && !f)
+          throw new TypeError("Private accessor was defined without a getter");
+        if (typeof state === "function" ? receiver !== state || !f : !state.has(receiver))
+          throw new TypeError("Cannot read private member from an object whose class did not declare it");
         return kind === "m" ? f : kind === "a" ? f.call(receiver) : f ? f.value : state.get(receiver);
       };
       var _Subject_isClosed;
@@ -100,14 +105,19 @@ var LiveUIModule = (() => {
     "node_modules/@eleven-am/pondsocket-common/subjects/behaviorSubject.js"(exports) {
       "use strict";
       var __classPrivateFieldSet = exports && exports.__classPrivateFieldSet || function(receiver, state, value, kind, f) {
-        if (kind === "m") throw new TypeError("Private method is not writable");
-        if (kind === "a" && !f) throw new TypeError("Private accessor was defined without a setter");
-        if (typeof state === "function" ? receiver !== state || !f : !state.has(receiver)) throw new TypeError("Cannot write private member to an object whose class did not declare it");
+        if (kind === "m")
+          throw new TypeError("Private method is not writable");
+        if (kind === "a" && !f)
+          throw new TypeError("Private accessor was defined without a setter");
+        if (typeof state === "function" ? receiver !== state || !f : !state.has(receiver))
+          throw new TypeError("Cannot write private member to an object whose class did not declare it");
         return kind === "a" ? f.call(receiver, value) : f ? f.value = value : state.set(receiver, value), value;
       };
       var __classPrivateFieldGet = exports && exports.__classPrivateFieldGet || function(receiver, state, kind, f) {
-        if (kind === "a" && !f) throw new TypeError("Private accessor was defined without a getter");
-        if (typeof state === "function" ? receiver !== state || !f : !state.has(receiver)) throw new TypeError("Cannot read private member from an object whose class did not declare it");
+        if (kind === "a" && !f)
+          throw new TypeError("Private accessor was defined without a getter");
+        if (typeof state === "function" ? receiver !== state || !f : !state.has(receiver))
+          throw new TypeError("Cannot read private member from an object whose class did not declare it");
         return kind === "m" ? f : kind === "a" ? f.call(receiver) : f ? f.value : state.get(receiver);
       };
       var _BehaviorSubject_lastMessage;
@@ -154,8 +164,9 @@ var LiveUIModule = (() => {
   var require_subjects = __commonJS({
     "node_modules/@eleven-am/pondsocket-common/subjects/index.js"(exports) {
       "use strict";
-      var __createBinding = exports && exports.__createBinding || (Object.create ? (function(o, m, k, k2) {
-        if (k2 === void 0) k2 = k;
+      var __createBinding = exports && exports.__createBinding || (Object.create ? function(o, m, k, k2) {
+        if (k2 === void 0)
+          k2 = k;
         var desc = Object.getOwnPropertyDescriptor(m, k);
         if (!desc || ("get" in desc ? !m.__esModule : desc.writable || desc.configurable)) {
           desc = { enumerable: true, get: function() {
@@ -163,12 +174,15 @@ var LiveUIModule = (() => {
           } };
         }
         Object.defineProperty(o, k2, desc);
-      }) : (function(o, m, k, k2) {
-        if (k2 === void 0) k2 = k;
+      } : function(o, m, k, k2) {
+        if (k2 === void 0)
+          k2 = k;
         o[k2] = m[k];
-      }));
+      });
       var __exportStar = exports && exports.__exportStar || function(m, exports2) {
-        for (var p in m) if (p !== "default" && !Object.prototype.hasOwnProperty.call(exports2, p)) __createBinding(exports2, m, p);
+        for (var p in m)
+          if (p !== "default" && !Object.prototype.hasOwnProperty.call(exports2, p))
+            __createBinding(exports2, m, p);
       };
       Object.defineProperty(exports, "__esModule", { value: true });
       __exportStar(require_behaviorSubject(), exports);
@@ -276,8 +290,9 @@ var LiveUIModule = (() => {
   var require_misc = __commonJS({
     "node_modules/@eleven-am/pondsocket-common/misc/index.js"(exports) {
       "use strict";
-      var __createBinding = exports && exports.__createBinding || (Object.create ? (function(o, m, k, k2) {
-        if (k2 === void 0) k2 = k;
+      var __createBinding = exports && exports.__createBinding || (Object.create ? function(o, m, k, k2) {
+        if (k2 === void 0)
+          k2 = k;
         var desc = Object.getOwnPropertyDescriptor(m, k);
         if (!desc || ("get" in desc ? !m.__esModule : desc.writable || desc.configurable)) {
           desc = { enumerable: true, get: function() {
@@ -285,12 +300,15 @@ var LiveUIModule = (() => {
           } };
         }
         Object.defineProperty(o, k2, desc);
-      }) : (function(o, m, k, k2) {
-        if (k2 === void 0) k2 = k;
+      } : function(o, m, k, k2) {
+        if (k2 === void 0)
+          k2 = k;
         o[k2] = m[k];
-      }));
+      });
       var __exportStar = exports && exports.__exportStar || function(m, exports2) {
-        for (var p in m) if (p !== "default" && !Object.prototype.hasOwnProperty.call(exports2, p)) __createBinding(exports2, m, p);
+        for (var p in m)
+          if (p !== "default" && !Object.prototype.hasOwnProperty.call(exports2, p))
+            __createBinding(exports2, m, p);
       };
       Object.defineProperty(exports, "__esModule", { value: true });
       __exportStar(require_uuid(), exports);
@@ -498,8 +516,9 @@ var LiveUIModule = (() => {
   var require_pondsocket_common = __commonJS({
     "node_modules/@eleven-am/pondsocket-common/index.js"(exports) {
       "use strict";
-      var __createBinding = exports && exports.__createBinding || (Object.create ? (function(o, m, k, k2) {
-        if (k2 === void 0) k2 = k;
+      var __createBinding = exports && exports.__createBinding || (Object.create ? function(o, m, k, k2) {
+        if (k2 === void 0)
+          k2 = k;
         var desc = Object.getOwnPropertyDescriptor(m, k);
         if (!desc || ("get" in desc ? !m.__esModule : desc.writable || desc.configurable)) {
           desc = { enumerable: true, get: function() {
@@ -507,12 +526,15 @@ var LiveUIModule = (() => {
           } };
         }
         Object.defineProperty(o, k2, desc);
-      }) : (function(o, m, k, k2) {
-        if (k2 === void 0) k2 = k;
+      } : function(o, m, k, k2) {
+        if (k2 === void 0)
+          k2 = k;
         o[k2] = m[k];
-      }));
+      });
       var __exportStar = exports && exports.__exportStar || function(m, exports2) {
-        for (var p in m) if (p !== "default" && !Object.prototype.hasOwnProperty.call(exports2, p)) __createBinding(exports2, m, p);
+        for (var p in m)
+          if (p !== "default" && !Object.prototype.hasOwnProperty.call(exports2, p))
+            __createBinding(exports2, m, p);
       };
       Object.defineProperty(exports, "__esModule", { value: true });
       __exportStar(require_subjects(), exports);
@@ -542,14 +564,19 @@ var LiveUIModule = (() => {
     "node_modules/@eleven-am/pondsocket-client/core/channel.js"(exports) {
       "use strict";
       var __classPrivateFieldSet = exports && exports.__classPrivateFieldSet || function(receiver, state, value, kind, f) {
-        if (kind === "m") throw new TypeError("Private method is not writable");
-        if (kind === "a" && !f) throw new TypeError("Private accessor was defined without a setter");
-        if (typeof state === "function" ? receiver !== state || !f : !state.has(receiver)) throw new TypeError("Cannot write private member to an object whose class did not declare it");
+        if (kind === "m")
+          throw new TypeError("Private method is not writable");
+        if (kind === "a" && !f)
+          throw new TypeError("Private accessor was defined without a setter");
+        if (typeof state === "function" ? receiver !== state || !f : !state.has(receiver))
+          throw new TypeError("Cannot write private member to an object whose class did not declare it");
         return kind === "a" ? f.call(receiver, value) : f ? f.value = value : state.set(receiver, value), value;
       };
       var __classPrivateFieldGet = exports && exports.__classPrivateFieldGet || function(receiver, state, kind, f) {
-        if (kind === "a" && !f) throw new TypeError("Private accessor was defined without a getter");
-        if (typeof state === "function" ? receiver !== state || !f : !state.has(receiver)) throw new TypeError("Cannot read private member from an object whose class did not declare it");
+        if (kind === "a" && !f)
+          throw new TypeError("Private accessor was defined without a getter");
+        if (typeof state === "function" ? receiver !== state || !f : !state.has(receiver))
+          throw new TypeError("Cannot read private member from an object whose class did not declare it");
         return kind === "m" ? f : kind === "a" ? f.call(receiver) : f ? f.value : state.get(receiver);
       };
       var _Channel_instances;
@@ -836,14 +863,19 @@ var LiveUIModule = (() => {
     "node_modules/@eleven-am/pondsocket-client/browser/client.js"(exports) {
       "use strict";
       var __classPrivateFieldSet = exports && exports.__classPrivateFieldSet || function(receiver, state, value, kind, f) {
-        if (kind === "m") throw new TypeError("Private method is not writable");
-        if (kind === "a" && !f) throw new TypeError("Private accessor was defined without a setter");
-        if (typeof state === "function" ? receiver !== state || !f : !state.has(receiver)) throw new TypeError("Cannot write private member to an object whose class did not declare it");
+        if (kind === "m")
+          throw new TypeError("Private method is not writable");
+        if (kind === "a" && !f)
+          throw new TypeError("Private accessor was defined without a setter");
+        if (typeof state === "function" ? receiver !== state || !f : !state.has(receiver))
+          throw new TypeError("Cannot write private member to an object whose class did not declare it");
         return kind === "a" ? f.call(receiver, value) : f ? f.value = value : state.set(receiver, value), value;
       };
       var __classPrivateFieldGet = exports && exports.__classPrivateFieldGet || function(receiver, state, kind, f) {
-        if (kind === "a" && !f) throw new TypeError("Private accessor was defined without a getter");
-        if (typeof state === "function" ? receiver !== state || !f : !state.has(receiver)) throw new TypeError("Cannot read private member from an object whose class did not declare it");
+        if (kind === "a" && !f)
+          throw new TypeError("Private accessor was defined without a getter");
+        if (typeof state === "function" ? receiver !== state || !f : !state.has(receiver))
+          throw new TypeError("Cannot read private member from an object whose class did not declare it");
         return kind === "m" ? f : kind === "a" ? f.call(receiver) : f ? f.value : state.get(receiver);
       };
       var _PondClient_instances;
@@ -1039,14 +1071,19 @@ var LiveUIModule = (() => {
     "node_modules/@eleven-am/pondsocket-client/browser/sseClient.js"(exports) {
       "use strict";
       var __classPrivateFieldSet = exports && exports.__classPrivateFieldSet || function(receiver, state, value, kind, f) {
-        if (kind === "m") throw new TypeError("Private method is not writable");
-        if (kind === "a" && !f) throw new TypeError("Private accessor was defined without a setter");
-        if (typeof state === "function" ? receiver !== state || !f : !state.has(receiver)) throw new TypeError("Cannot write private member to an object whose class did not declare it");
+        if (kind === "m")
+          throw new TypeError("Private method is not writable");
+        if (kind === "a" && !f)
+          throw new TypeError("Private accessor was defined without a setter");
+        if (typeof state === "function" ? receiver !== state || !f : !state.has(receiver))
+          throw new TypeError("Cannot write private member to an object whose class did not declare it");
         return kind === "a" ? f.call(receiver, value) : f ? f.value = value : state.set(receiver, value), value;
       };
       var __classPrivateFieldGet = exports && exports.__classPrivateFieldGet || function(receiver, state, kind, f) {
-        if (kind === "a" && !f) throw new TypeError("Private accessor was defined without a getter");
-        if (typeof state === "function" ? receiver !== state || !f : !state.has(receiver)) throw new TypeError("Cannot read private member from an object whose class did not declare it");
+        if (kind === "a" && !f)
+          throw new TypeError("Private accessor was defined without a getter");
+        if (typeof state === "function" ? receiver !== state || !f : !state.has(receiver))
+          throw new TypeError("Cannot read private member from an object whose class did not declare it");
         return kind === "m" ? f : kind === "a" ? f.call(receiver) : f ? f.value : state.get(receiver);
       };
       var _SSEClient_instances;
@@ -1246,13 +1283,17 @@ var LiveUIModule = (() => {
   var require_global = __commonJS({
     "node_modules/es5-ext/global.js"(exports, module) {
       var naiveFallback = function() {
-        if (typeof self === "object" && self) return self;
-        if (typeof window === "object" && window) return window;
+        if (typeof self === "object" && self)
+          return self;
+        if (typeof window === "object" && window)
+          return window;
         throw new Error("Unable to resolve global `this`");
       };
-      module.exports = (function() {
-        if (this) return this;
-        if (typeof globalThis === "object" && globalThis) return globalThis;
+      module.exports = function() {
+        if (this)
+          return this;
+        if (typeof globalThis === "object" && globalThis)
+          return globalThis;
         try {
           Object.defineProperty(Object.prototype, "__global__", {
             get: function() {
@@ -1264,12 +1305,13 @@ var LiveUIModule = (() => {
           return naiveFallback();
         }
         try {
-          if (!__global__) return naiveFallback();
+          if (!__global__)
+            return naiveFallback();
           return __global__;
         } finally {
           delete Object.prototype.__global__;
         }
-      })();
+      }();
     }
   });
 
@@ -1327,7 +1369,7 @@ var LiveUIModule = (() => {
           test: "tape test/unit/*.js",
           gulp: "gulp"
         },
-        main: "index",
+        main: "lib/browser.js",
         directories: {
           lib: "./lib"
         },
@@ -1395,8 +1437,10 @@ var LiveUIModule = (() => {
     "node_modules/@eleven-am/pondsocket-client/node/node.js"(exports) {
       "use strict";
       var __classPrivateFieldGet = exports && exports.__classPrivateFieldGet || function(receiver, state, kind, f) {
-        if (kind === "a" && !f) throw new TypeError("Private accessor was defined without a getter");
-        if (typeof state === "function" ? receiver !== state || !f : !state.has(receiver)) throw new TypeError("Cannot read private member from an object whose class did not declare it");
+        if (kind === "a" && !f)
+          throw new TypeError("Private accessor was defined without a getter");
+        if (typeof state === "function" ? receiver !== state || !f : !state.has(receiver))
+          throw new TypeError("Cannot read private member from an object whose class did not declare it");
         return kind === "m" ? f : kind === "a" ? f.call(receiver) : f ? f.value : state.get(receiver);
       };
       var _PondClient_instances;
@@ -1507,18 +1551,23 @@ var LiveUIModule = (() => {
   });
 
   // src/index.ts
-  var index_exports = {};
-  __export(index_exports, {
+  var src_exports = {};
+  __export(src_exports, {
+    BaseTransport: () => BaseTransport,
     Bus: () => Bus,
     Executor: () => Executor,
+    FallbackTransport: () => FallbackTransport,
     Logger: () => Logger,
     OpKinds: () => OpKinds,
     Patcher: () => Patcher,
     Runtime: () => Runtime,
+    SSETransport: () => SSETransport,
     ScriptExecutor: () => ScriptExecutor,
     Topics: () => Topics,
     Transport: () => Transport,
     boot: () => boot,
+    createTransport: () => createTransport,
+    dispatchMessage: () => dispatchMessage,
     isBoot: () => isBoot,
     isMessage: () => isMessage,
     isServerAck: () => isServerAck,
@@ -1531,7 +1580,8 @@ var LiveUIModule = (() => {
     Router: "router",
     DOM: "dom",
     Frame: "frame",
-    Ack: "ack"
+    Ack: "ack",
+    Reload: "reload"
   };
   function scriptTopic(scriptId) {
     return `script:${scriptId}`;
@@ -1541,6 +1591,8 @@ var LiveUIModule = (() => {
   }
   var OpKinds = {
     SetText: "setText",
+    AppendText: "appendText",
+    SpliceText: "spliceText",
     SetComment: "setComment",
     SetAttr: "setAttr",
     DelAttr: "delAttr",
@@ -1556,8 +1608,16 @@ var LiveUIModule = (() => {
     ReplaceNode: "replaceNode",
     AddChild: "addChild",
     DelChild: "delChild",
-    MoveChild: "moveChild"
+    MoveChild: "moveChild",
+    DefTemplate: "defTemplate",
+    SetSlots: "setSlots"
   };
+  function isHTMLPayload(value) {
+    return typeof value === "object" && value !== null && typeof value.html === "string";
+  }
+  function isTemplatePayload(value) {
+    return typeof value === "object" && value !== null && typeof value.tpl === "number" && Array.isArray(value.slots);
+  }
   function isBoot(msg) {
     return typeof msg === "object" && msg !== null && msg.t === "boot";
   }
@@ -1690,7 +1750,8 @@ var LiveUIModule = (() => {
     }
     unsubscribe(key, subId) {
       const subs = this.subscribers.get(key);
-      if (!subs) return;
+      if (!subs)
+        return;
       const idx = subs.findIndex((s) => s.id === subId);
       if (idx !== -1) {
         subs.splice(idx, 1);
@@ -1707,9 +1768,6 @@ var LiveUIModule = (() => {
     }
   };
 
-  // src/transport.ts
-  var import_pondsocket_client = __toESM(require_pondsocket_client(), 1);
-
   // src/logger.ts
   var levels = {
     debug: 0,
@@ -1723,8 +1781,10 @@ var LiveUIModule = (() => {
       this.level = "info";
     }
     configure(config) {
-      if (config.enabled !== void 0) this.enabled = config.enabled;
-      if (config.level !== void 0) this.level = config.level;
+      if (config.enabled !== void 0)
+        this.enabled = config.enabled;
+      if (config.level !== void 0)
+        this.level = config.level;
     }
     debug(tag, message, ...args) {
       this.log("debug", tag, message, args);
@@ -1739,8 +1799,10 @@ var LiveUIModule = (() => {
       this.log("error", tag, message, args);
     }
     log(level, tag, message, args) {
-      if (!this.enabled) return;
-      if (levels[level] < levels[this.level]) return;
+      if (!this.enabled)
+        return;
+      if (levels[level] < levels[this.level])
+        return;
       const prefix = `[Pond:${tag}]`;
       const fn = console[level] || console.log;
       if (args.length > 0) {
@@ -1753,25 +1815,118 @@ var LiveUIModule = (() => {
   var Logger = new LoggerImpl();
 
   // src/transport.ts
-  var Transport = class {
+  var import_pondsocket_client = __toESM(require_pondsocket_client(), 1);
+
+  // src/wire.ts
+  var compactOps = [
+    "setText",
+    "setComment",
+    "setAttr",
+    "delAttr",
+    "setStyle",
+    "delStyle",
+    "setStyleDecl",
+    "delStyleDecl",
+    "setHandlers",
+    "setScript",
+    "delScript",
+    "setRef",
+    "delRef",
+    "replaceNode",
+    "addChild",
+    "delChild",
+    "moveChild",
+    "appendText",
+    "spliceText",
+    "defTemplate",
+    "setSlots"
+  ];
+  function decodeMessage(payload) {
+    if (isMessage(payload)) {
+      return payload;
+    }
+    if (!Array.isArray(payload) || payload.length !== 4) {
+      return null;
+    }
+    const [seq, topic, event, data] = payload;
+    if (typeof seq !== "number" || typeof topic !== "string" || typeof event !== "string") {
+      return null;
+    }
+    if (topic === "frame" && event === "patch") {
+      const patches = expandPatches(data);
+      if (!patches) {
+        return null;
+      }
+      return { seq, topic, event, data: patches };
+    }
+    return { seq, topic, event, data };
+  }
+  function expandPatches(data) {
+    if (!Array.isArray(data)) {
+      return null;
+    }
+    const patches = [];
+    for (let i = 0; i < data.length; i++) {
+      const row = data[i];
+      if (!Array.isArray(row) || row.length < 2) {
+        return null;
+      }
+      const op = compactOps[row[0]];
+      if (op === void 0) {
+        return null;
+      }
+      const [, path, value, name, selector, index] = row;
+      const patch = { seq: i, path: path ?? null, op };
+      if (value !== void 0 && value !== null) {
+        patch.value = value;
+      }
+      if (name) {
+        patch.name = name;
+      }
+      if (selector) {
+        patch.selector = selector;
+      }
+      if (typeof index === "number") {
+        patch.index = index;
+      }
+      patches.push(patch);
+    }
+    return patches;
+  }
+
+  // src/transport.ts
+  var MAX_UNACKED_EVENTS = 256;
+  var EventLog = class {
+    constructor() {
+      this.nextId = 0;
+      this.pending = [];
+    }
+    record(evt) {
+      evt.id = ++this.nextId;
+      this.pending.push(evt);
+      if (this.pending.length > MAX_UNACKED_EVENTS) {
+        this.pending.shift();
+      }
+      return evt;
+    }
+    ackThrough(eid) {
+      this.pending = this.pending.filter((evt) => (evt.id ?? 0) > eid);
+    }
+    unacked() {
+      return this.pending.slice();
+    }
+  };
+  var BaseTransport = class {
     constructor(config) {
       this.state = "disconnected";
       this.stateListeners = [];
       this.sessionId = config.sessionId;
       this.bus = config.bus;
-      this.client = new import_pondsocket_client.PondClient(config.endpoint);
-      const joinPayload = {
-        sid: config.sessionId,
-        ver: config.version,
-        ack: config.lastAck,
-        loc: config.location
-      };
-      this.channel = this.client.createChannel(`live/${config.sessionId}`, joinPayload);
-      this.channel.onMessage((_event, payload) => {
-        this.handleMessage(payload);
-      });
-      this.channel.onChannelStateChange((channelState) => {
-        this.handleStateChange(channelState);
+      this.events = config.events ?? new EventLog();
+      this.bus.subscribe("ack", "ack", (ack) => {
+        if (ack.eid) {
+          this.events.ackThrough(ack.eid);
+        }
       });
     }
     get sid() {
@@ -1780,18 +1935,6 @@ var LiveUIModule = (() => {
     get connectionState() {
       return this.state;
     }
-    connect() {
-      this.state = "connecting";
-      this.notifyStateChange();
-      this.channel.join();
-      this.client.connect();
-    }
-    disconnect() {
-      this.channel.leave();
-      this.client.disconnect();
-      this.state = "disconnected";
-      this.notifyStateChange();
-    }
     onStateChange(listener) {
       this.stateListeners.push(listener);
       return () => {
@@ -1808,7 +1951,7 @@ var LiveUIModule = (() => {
         a: String(action),
         p: payload
       };
-      this.sendMessage("evt", evt);
+      this.sendEvent(evt);
     }
     sendAck(seq) {
       const ack = {
@@ -1825,7 +1968,7 @@ var LiveUIModule = (() => {
         a: "invoke",
         p: payload
       };
-      this.sendMessage("evt", evt);
+      this.sendEvent(evt);
     }
     sendScript(scriptId, payload) {
       const evt = {
@@ -1834,109 +1977,349 @@ var LiveUIModule = (() => {
         a: "message",
         p: payload
       };
-      this.sendMessage("evt", evt);
+      this.sendEvent(evt);
     }
-    handleMessage(payload) {
-      Logger.info("TRANSPORT", "Transport received message:", payload);
-      if (!isMessage(payload)) {
-        return;
+    setState(state) {
+      const previous = this.state;
+      this.state = state;
+      if (state === "connected" && previous !== "connected") {
+        for (const evt of this.events.unacked()) {
+          this.sendMessage("evt", evt);
+        }
       }
-      const { seq, topic, event, data } = payload;
-      if (!this.isValidTopic(topic)) {
-        return;
+      for (const listener of this.stateListeners) {
+        try {
+          listener(this.state);
+        } catch {
+        }
       }
-      this.publishToBus(topic, event, data, seq);
     }
-    isValidTopic(topic) {
-      return topic === "router" || topic === "dom" || topic === "frame" || topic === "ack" || topic.startsWith("script:");
+    sendEvent(evt) {
+      this.sendMessage("evt", this.events.record(evt));
     }
-    publishToBus(topic, action, data, seq) {
-      switch (topic) {
-        case "frame":
-          if (action === "patch") {
-            const payload = {
-              seq,
-              patches: data
-            };
-            this.bus.publish("frame", "patch", payload);
-          }
-          break;
-        case "router":
-          if (action === "push") {
-            this.bus.publish("router", "push", data);
-          } else if (action === "replace") {
-            this.bus.publish("router", "replace", data);
-          } else if (action === "back") {
-            this.bus.publish("router", "back", void 0);
-          } else if (action === "forward") {
-            this.bus.publish("router", "forward", void 0);
-          }
-          break;
-        case "dom":
-          if (action === "call") {
-            this.bus.publish("dom", "call", data);
-          } else if (action === "set") {
-            this.bus.publish("dom", "set", data);
-          } else if (action === "query") {
-            this.bus.publish("dom", "query", data);
-          } else if (action === "async") {
-            this.bus.publish("dom", "async", data);
-          }
-          break;
-        case "ack":
-          if (action === "ack") {
-            this.bus.publish("ack", "ack", data);
-          }
-          break;
-        default:
-          if (topic.startsWith("script:") && action === "send") {
-            const payload = data;
-            this.bus.publishScript(payload.scriptId, "send", payload);
-          }
-          break;
-      }
+  };
+  var Transport = class extends BaseTransport {
+    constructor(config) {
+      super(config);
+      this.client = new import_pondsocket_client.PondClient(config.endpoint);
+      const joinPayload = {
+        sid: config.sessionId,
+        ver: config.version,
+        ack: config.lastAck,
+        loc: config.location,
+        enc: config.encoding ?? "compact"
+      };
+      this.channel = this.client.createChannel(`live/${config.sessionId}`, joinPayload);
+      this.channel.onMessage((_event, payload) => {
+        Logger.info("TRANSPORT", "Transport received message:", payload);
+        dispatchMessage(this.bus, payload);
+      });
+      this.channel.onChannelStateChange((channelState) => {
+        this.handleStateChange(channelState);
+      });
+    }
+    connect() {
+      this.setState("connecting");
+      this.channel.join();
+      this.client.connect();
+    }
+    disconnect() {
+      this.channel.leave();
+      this.client.disconnect();
+      this.setState("disconnected");
     }
     handleStateChange(channelState) {
       switch (channelState) {
         case import_pondsocket_client.ChannelState.JOINED:
-          this.state = "connected";
+          this.setState("connected");
           break;
         case import_pondsocket_client.ChannelState.STALLED:
-          this.state = "stalled";
+          this.setState("stalled");
           break;
         case import_pondsocket_client.ChannelState.CLOSED:
-          this.state = "disconnected";
+          this.setState("disconnected");
           break;
         case import_pondsocket_client.ChannelState.DECLINED:
-          this.state = "declined";
+          this.setState("declined");
           break;
         case import_pondsocket_client.ChannelState.JOINING:
         case import_pondsocket_client.ChannelState.IDLE:
-          this.state = "connecting";
+          this.setState("connecting");
           break;
       }
-      this.notifyStateChange();
     }
-    notifyStateChange() {
-      for (const listener of this.stateListeners) {
+    sendMessage(type, message) {
+      Logger.info("TRANSPORT", "Transport sending message:", type, message);
+      this.channel.sendMessage(type, message);
+    }
+  };
+  function dispatchMessage(bus, payload) {
+    const message = decodeMessage(payload);
+    if (!message) {
+      return null;
+    }
+    const { seq, topic, event, data } = message;
+    if (!isValidTopic(topic)) {
+      return null;
+    }
+    publishToBus(bus, topic, event, data, seq);
+    return topic;
+  }
+  function isValidTopic(topic) {
+    return topic === "router" || topic === "dom" || topic === "frame" || topic === "ack" || topic === "reload" || topic.startsWith("script:");
+  }
+  function publishToBus(bus, topic, action, data, seq) {
+    switch (topic) {
+      case "frame":
+        if (action === "patch") {
+          const payload = {
+            seq,
+            patches: data
+          };
+          bus.publish("frame", "patch", payload);
+        }
+        break;
+      case "router":
+        if (action === "push") {
+          bus.publish("router", "push", data);
+        } else if (action === "replace") {
+          bus.publish("router", "replace", data);
+        } else if (action === "back") {
+          bus.publish("router", "back", void 0);
+        } else if (action === "forward") {
+          bus.publish("router", "forward", void 0);
+        } else if (action === "go") {
+          bus.publish("router", "go", data);
+        }
+        break;
+      case "dom":
+        if (action === "call") {
+          bus.publish("dom", "call", data);
+        } else if (action === "set") {
+          bus.publish("dom", "set", data);
+        } else if (action === "query") {
+          bus.publish("dom", "query", data);
+        } else if (action === "async") {
+          bus.publish("dom", "async", data);
+        }
+        break;
+      case "ack":
+        if (action === "ack") {
+          bus.publish("ack", "ack", data);
+        }
+        break;
+      case "reload":
+        if (action === "reload") {
+          bus.publish("reload", "reload", data);
+        }
+        break;
+      default:
+        if (topic.startsWith("script:") && action === "send") {
+          const payload = data;
+          bus.publishScript(payload.scriptId, "send", payload);
+        }
+        break;
+    }
+  }
+
+  // src/sse.ts
+  var FALLBACK_TIMEOUT = 5e3;
+  var SSETransport = class extends BaseTransport {
+    constructor(config) {
+      super(config);
+      this.source = null;
+      this.connId = null;
+      this.queue = [];
+      this.outbox = Promise.resolve();
+      const params = new URLSearchParams({
+        sid: config.sessionId,
+        ver: String(config.version),
+        enc: config.encoding ?? "compact"
+      });
+      this.streamUrl = `${config.endpoint}/sse?${params.toString()}`;
+      this.postUrl = `${config.endpoint}/sse`;
+    }
+    connect() {
+      this.setState("connecting");
+      const source = new EventSource(this.streamUrl);
+      this.source = source;
+      source.addEventListener("conn", (e) => {
+        this.connId = JSON.parse(e.data);
+        this.flushQueue();
+        this.setState("connected");
+      });
+      source.onmessage = (e) => {
+        Logger.info("TRANSPORT", "SSE received message:", e.data);
+        let payload;
         try {
-          listener(this.state);
+          payload = JSON.parse(e.data);
         } catch {
+          return;
         }
-      }
+        if (dispatchMessage(this.bus, payload) === "reload") {
+          this.close();
+        }
+      };
+      source.onerror = () => {
+        if (this.source !== source) {
+          return;
+        }
+        if (source.readyState === EventSource.CLOSED) {
+          this.source = null;
+          this.setState("declined");
+        } else {
+          this.setState("stalled");
+        }
+      };
+    }
+    disconnect() {
+      this.close();
+      this.setState("disconnected");
+    }
+    close() {
+      this.source?.close();
+      this.source = null;
+      this.connId = null;
     }
     sendMessage(type, message) {
-      Logger.info("TRANSPORT", "Transport sending message:", type, message);
-      this.channel.sendMessage(type, message);
+      Logger.info("TRANSPORT", "SSE sending message:", type, message);
+      if (type === "evt" && !this.connId) {
+        return;
+      }
+      this.queue.push({ type, message });
+      this.flushQueue();
+    }
+    // Posts are chained so the server sees events in the order they were sent.
+    flushQueue() {
+      const connId = this.connId;
+      if (!connId) {
+        return;
+      }
+      const pending = this.queue;
+      this.queue = [];
+      for (const { type, message } of pending) {
+        this.outbox = this.outbox.then(() => this.post(connId, type, message));
+      }
+    }
+    async post(connId, type, message) {
+      try {
+        const res = await fetch(`${this.postUrl}/${encodeURIComponent(connId)}/${type}`, {
+          method: "POST",
+          headers: { "Content-Type": "application/json" },
+          body: JSON.stringify(message),
+          keepalive: true
+        });
+        if (!res.ok) {
+          Logger.warn("TRANSPORT", "SSE post rejected", type, res.status);
+        }
+      } catch (err) {
+        Logger.error("TRANSPORT", "SSE post failed", type, err);
+      }
+    }
+  };
+  var FallbackTransport = class {
+    constructor(config, timeout = FALLBACK_TIMEOUT) {
+      this.timeout = timeout;
+      this.listeners = [];
+      this.timer = null;
+      this.opened = false;
+      this.fellBack = false;
+      this.config = { ...config, events: config.events ?? new EventLog() };
+      this.current = new Transport(this.config);
+      this.unsubscribe = this.current.onStateChange((state) => this.handleState(state));
+    }
+    get sid() {
+      return this.current.sid;
+    }
+    get connectionState() {
+      return this.current.connectionState;
+    }
+    get usingFallback() {
+      return this.fellBack;
+    }
+    connect() {
+      this.current.connect();
+      if (!this.fellBack && !this.opened) {
+        this.timer = setTimeout(() => this.fallback(), this.timeout);
+      }
+    }
+    disconnect() {
+      this.clearTimer();
+      this.current.disconnect();
+    }
+    onStateChange(listener) {
+      this.listeners.push(listener);
+      return () => {
+        const idx = this.listeners.indexOf(listener);
+        if (idx !== -1) {
+          this.listeners.splice(idx, 1);
+        }
+      };
+    }
+    send(topic, action, payload) {
+      this.current.send(topic, action, payload);
+    }
+    sendAck(seq) {
+      this.current.sendAck(seq);
+    }
+    sendHandler(handlerId, payload) {
+      this.current.sendHandler(handlerId, payload);
+    }
+    sendScript(scriptId, payload) {
+      this.current.sendScript(scriptId, payload);
+    }
+    handleState(state) {
+      if (state === "connected") {
+        this.opened = true;
+        this.clearTimer();
+      } else if (!this.opened && !this.fellBack && (state === "disconnected" || state === "stalled")) {
+        this.fallback();
+        return;
+      }
+      for (const listener of this.listeners) {
+        try {
+          listener(state);
+        } catch {
+        }
+      }
+    }
+    fallback() {
+      if (this.fellBack || this.opened) {
+        return;
+      }
+      Logger.warn("TRANSPORT", "WebSocket did not open, falling back to Server-Sent Events");
+      this.clearTimer();
+      this.fellBack = true;
+      this.unsubscribe();
+      this.current.disconnect();
+      this.current = new SSETransport(this.config);
+      this.unsubscribe = this.current.onStateChange((state) => this.handleState(state));
+      this.current.connect();
+    }
+    clearTimer() {
+      if (this.timer !== null) {
+        clearTimeout(this.timer);
+        this.timer = null;
+      }
     }
   };
+  function createTransport(config, mode = "auto") {
+    if (mode === "sse") {
+      return new SSETransport(config);
+    }
+    if (mode === "websocket" || typeof EventSource === "undefined") {
+      return new Transport(config);
+    }
+    return new FallbackTransport(config);
+  }
 
   // src/patcher.ts
-  var _Patcher = class _Patcher {
+  var _Patcher = class {
     constructor(root, callbacks) {
       this.handlerStore = /* @__PURE__ */ new WeakMap();
       this.scriptStore = /* @__PURE__ */ new WeakMap();
       this.keyedElements = /* @__PURE__ */ new Map();
+      this.templates = /* @__PURE__ */ new Map();
       this.root = root;
       this.callbacks = callbacks;
     }
@@ -1948,11 +2331,21 @@ var LiveUIModule = (() => {
     }
     applyPatch(patch) {
       const node = this.resolvePath(patch.path);
-      if (!node) return;
+      if (!node)
+        return;
+      this.applyTo(node, patch);
+    }
+    applyTo(node, patch) {
       switch (patch.op) {
         case "setText":
           this.setText(node, patch.value);
           break;
+        case "appendText":
+          this.appendText(node, patch.value);
+          break;
+        case "spliceText":
+          this.spliceText(node, patch.value);
+          break;
         case "setComment":
           this.setComment(node, patch.value);
           break;
@@ -1998,16 +2391,28 @@ var LiveUIModule = (() => {
         case "delChild":
           this.delChild(node, patch.index);
           break;
+        case "defTemplate": {
+          const def = patch.value;
+          this.templates.set(def.id, def);
+          break;
+        }
+        case "setSlots":
+          this.setSlots(node, patch.value);
+          break;
         case "moveChild":
           this.moveChild(node, patch.value, patch.path ?? []);
           break;
       }
     }
     resolvePath(path) {
-      let node = this.root;
+      return this.resolveFrom(this.root, path);
+    }
+    resolveFrom(root, path) {
+      let node = root;
       if (path) {
         for (const index of path) {
-          if (!node) return null;
+          if (!node)
+            return null;
           node = node.childNodes[index] ?? null;
         }
       }
@@ -2016,6 +2421,21 @@ var LiveUIModule = (() => {
     setText(node, text) {
       node.textContent = text;
     }
+    appendText(node, text) {
+      if (node instanceof CharacterData) {
+        node.appendData(text);
+      } else {
+        node.textContent = (node.textContent ?? "") + text;
+      }
+    }
+    spliceText(node, splice) {
+      if (node instanceof CharacterData) {
+        node.replaceData(splice.offset, splice.delete, splice.insert);
+        return;
+      }
+      const current = node.textContent ?? "";
+      node.textContent = current.slice(0, splice.offset) + splice.insert + current.slice(splice.offset + splice.delete);
+    }
     setComment(node, text) {
       node.textContent = text;
     }
@@ -2061,7 +2481,8 @@ var LiveUIModule = (() => {
     }
     setStyleDecl(styleEl, selector, prop, value) {
       const sheet = styleEl.sheet;
-      if (!sheet) return;
+      if (!sheet)
+        return;
       const rule = this.findOrCreateRule(sheet, selector);
       if (rule) {
         rule.style.setProperty(prop, value);
@@ -2069,7 +2490,8 @@ var LiveUIModule = (() => {
     }
     delStyleDecl(styleEl, selector, prop) {
       const sheet = styleEl.sheet;
-      if (!sheet) return;
+      if (!sheet)
+        return;
       const rule = this.findRule(sheet, selector);
       if (rule) {
         rule.style.removeProperty(prop);
@@ -2096,7 +2518,8 @@ var LiveUIModule = (() => {
       const oldHandlers = this.handlerStore.get(el);
       if (oldHandlers) {
         oldHandlers.forEach((state) => {
-          if (state.cleanup) state.cleanup();
+          if (state.cleanup)
+            state.cleanup();
         });
       }
       const newHandlers = /* @__PURE__ */ new Map();
@@ -2122,9 +2545,12 @@ var LiveUIModule = (() => {
       let handler;
       if (meta.debounce && meta.debounce > 0) {
         handler = (e) => {
-          if (meta.prevent && e.cancelable) e.preventDefault();
-          if (meta.stop) e.stopPropagation();
-          if (timeoutId) clearTimeout(timeoutId);
+          if (meta.prevent && e.cancelable)
+            e.preventDefault();
+          if (meta.stop)
+            e.stopPropagation();
+          if (timeoutId)
+            clearTimeout(timeoutId);
           timeoutId = setTimeout(() => {
             const data = this.extractEventData(e, meta.props ?? []);
             this.callbacks.onEvent(meta.handler, data);
@@ -2132,8 +2558,10 @@ var LiveUIModule = (() => {
         };
       } else if (meta.throttle && meta.throttle > 0) {
         handler = (e) => {
-          if (meta.prevent && e.cancelable) e.preventDefault();
-          if (meta.stop) e.stopPropagation();
+          if (meta.prevent && e.cancelable)
+            e.preventDefault();
+          if (meta.stop)
+            e.stopPropagation();
           const now = Date.now();
           if (now - lastCall >= meta.throttle) {
             lastCall = now;
@@ -2145,15 +2573,19 @@ var LiveUIModule = (() => {
         handler = invoke;
       }
       const options = {};
-      if (meta.passive) options.passive = true;
-      if (meta.once) options.once = true;
-      if (meta.capture) options.capture = true;
+      if (meta.passive)
+        options.passive = true;
+      if (meta.once)
+        options.once = true;
+      if (meta.capture)
+        options.capture = true;
       el.addEventListener(meta.event, handler, options);
       return {
         listener: handler,
         cleanup: () => {
           el.removeEventListener(meta.event, handler, options);
-          if (timeoutId) clearTimeout(timeoutId);
+          if (timeoutId)
+            clearTimeout(timeoutId);
         }
       };
     }
@@ -2169,9 +2601,13 @@ var LiveUIModule = (() => {
     }
     resolveProp(e, path) {
       const segments = path.split(".").map((s) => s.trim()).filter(Boolean);
-      if (segments.length === 0) return void 0;
+      if (segments.length === 0)
+        return void 0;
       const root = segments.shift();
       let current;
+      if (root === "form" && segments.length === 0) {
+        return this.resolveFormData(e);
+      }
       switch (root) {
         case "event":
           current = e;
@@ -2186,7 +2622,8 @@ var LiveUIModule = (() => {
           current = e[root];
       }
       for (const segment of segments) {
-        if (current == null) return void 0;
+        if (current == null)
+          return void 0;
         try {
           current = current[segment];
         } catch {
@@ -2195,17 +2632,39 @@ var LiveUIModule = (() => {
       }
       return this.serializeValue(current);
     }
+    resolveFormData(e) {
+      let form = null;
+      if (e.currentTarget instanceof HTMLFormElement) {
+        form = e.currentTarget;
+      } else if (e.target instanceof Element) {
+        form = e.target.closest("form");
+      }
+      if (!form)
+        return void 0;
+      const result = {};
+      new FormData(form).forEach((value, key) => {
+        if (typeof value === "string") {
+          result[key] = value;
+        }
+      });
+      return result;
+    }
     serializeValue(value) {
-      if (value === null || value === void 0) return null;
+      if (value === null || value === void 0)
+        return null;
       const type = typeof value;
-      if (type === "string" || type === "number" || type === "boolean") return value;
+      if (type === "string" || type === "number" || type === "boolean")
+        return value;
       if (Array.isArray(value)) {
         const mapped = value.map((v) => this.serializeValue(v)).filter((v) => v !== void 0);
         return mapped.length > 0 ? mapped : null;
       }
-      if (value instanceof Date) return value.toISOString();
-      if (value instanceof DOMTokenList) return Array.from(value);
-      if (value instanceof Node) return void 0;
+      if (value instanceof Date)
+        return value.toISOString();
+      if (value instanceof DOMTokenList)
+        return Array.from(value);
+      if (value instanceof Node)
+        return void 0;
       try {
         return JSON.parse(JSON.stringify(value));
       } catch {
@@ -2225,15 +2684,16 @@ var LiveUIModule = (() => {
       }
     }
     replaceNode(oldNode, newNodeData) {
-      const newNode = this.createNode(newNodeData);
+      const newNode = this.materialize(newNodeData, oldNode.parentNode);
       if (newNode && oldNode.parentNode) {
         this.cleanupTree(oldNode);
         oldNode.parentNode.replaceChild(newNode, oldNode);
       }
     }
     addChild(parent, index, nodeData, parentPath) {
-      const newNode = this.createNode(nodeData);
-      if (!newNode) return;
+      const newNode = this.materialize(nodeData, parent);
+      if (!newNode)
+        return;
       if (nodeData.key && newNode instanceof Element) {
         const keyId = `${parentPath.join(",")}-${nodeData.key}`;
         this.keyedElements.set(keyId, newNode);
@@ -2260,7 +2720,8 @@ var LiveUIModule = (() => {
       if (!child) {
         child = parent.childNodes[move.fromIndex] ?? null;
       }
-      if (!child) return;
+      if (!child)
+        return;
       parent.removeChild(child);
       const refChild = parent.childNodes[move.newIdx] ?? null;
       parent.insertBefore(child, refChild);
@@ -2279,7 +2740,8 @@ var LiveUIModule = (() => {
         return null;
       }
       const match = signature.match(/^E:(\w+)\|(\w+)=(.+)$/);
-      if (!match) return null;
+      if (!match)
+        return null;
       const [, tag, attr, value] = match;
       for (let i = 0; i < parent.childNodes.length; i++) {
         const node = parent.childNodes[i];
@@ -2298,7 +2760,8 @@ var LiveUIModule = (() => {
         const handlers = this.handlerStore.get(el);
         if (handlers) {
           handlers.forEach((state) => {
-            if (state.cleanup) state.cleanup();
+            if (state.cleanup)
+              state.cleanup();
           });
           this.handlerStore.delete(el);
         }
@@ -2312,6 +2775,68 @@ var LiveUIModule = (() => {
         }
       }
     }
+    materialize(data, parent) {
+      if (isTemplatePayload(data)) {
+        const html = this.renderTemplate(data);
+        return html === null ? null : this.createFromHTML({ html, key: data.key, meta: data.meta }, parent);
+      }
+      if (isHTMLPayload(data)) {
+        return this.createFromHTML(data, parent);
+      }
+      return this.createNode(data);
+    }
+    renderTemplate(payload) {
+      const def = this.templates.get(payload.tpl);
+      if (!def)
+        return null;
+      let html = "";
+      def.statics.forEach((part, i) => {
+        html += part;
+        if (i < payload.slots.length) {
+          html += escapeHTML(payload.slots[i]);
+        }
+      });
+      return html;
+    }
+    setSlots(node, update) {
+      const def = this.templates.get(update.tpl);
+      if (!def)
+        return;
+      for (const [index, value] of Object.entries(update.slots)) {
+        const slot = def.slots[Number(index)];
+        if (!slot)
+          continue;
+        const target = this.resolveFrom(node, slot.path);
+        if (!target)
+          continue;
+        if (!slot.attr) {
+          target.textContent = value;
+        } else if (slot.attr === "style" || slot.attr === "data-key") {
+          target.setAttribute(slot.attr, value);
+        } else {
+          this.setAttr(target, { [slot.attr]: [value] });
+        }
+      }
+    }
+    createFromHTML(payload, parent) {
+      const template = document.createElement("template");
+      const inSvg = parent instanceof SVGElement && parent.tagName !== "foreignObject";
+      template.innerHTML = inSvg ? `<svg>${payload.html}</svg>` : payload.html;
+      let node = template.content.firstChild;
+      if (inSvg) {
+        node = node?.firstChild ?? null;
+      }
+      if (!node)
+        return null;
+      const meta = [...payload.meta ?? []].sort((a, b) => a.seq - b.seq);
+      for (const patch of meta) {
+        const target = this.resolveFrom(node, patch.path);
+        if (target) {
+          this.applyTo(target, patch);
+        }
+      }
+      return node;
+    }
     createNode(data, isSvg = false) {
       if (data.text !== void 0) {
         return document.createTextNode(data.text);
@@ -2319,7 +2844,8 @@ var LiveUIModule = (() => {
       if (data.comment !== void 0) {
         return document.createComment(data.comment);
       }
-      if (!data.tag) return null;
+      if (!data.tag)
+        return null;
       const isSvgElement = _Patcher.SVG_TAGS.has(data.tag);
       const useSvg = isSvg || isSvgElement;
       const el = useSvg ? document.createElementNS(_Patcher.SVG_NS, data.tag) : document.createElement(data.tag);
@@ -2352,8 +2878,9 @@ var LiveUIModule = (() => {
       return el;
     }
   };
-  _Patcher.SVG_NS = "http://www.w3.org/2000/svg";
-  _Patcher.SVG_TAGS = /* @__PURE__ */ new Set([
+  var Patcher = _Patcher;
+  Patcher.SVG_NS = "http://www.w3.org/2000/svg";
+  Patcher.SVG_TAGS = /* @__PURE__ */ new Set([
     "svg",
     "animate",
     "animateMotion",
@@ -2415,16 +2942,29 @@ var LiveUIModule = (() => {
     "use",
     "view"
   ]);
-  var Patcher = _Patcher;
+  function escapeHTML(value) {
+    return value.replace(/&/g, "&amp;").replace(/</g, "&lt;").replace(/>/g, "&gt;").replace(/"/g, "&#34;").replace(/'/g, "&#39;");
+  }
 
   // src/executor.ts
+  var DEFAULT_FOCUS_TARGETS = ["main", '[role="main"]', "h1"];
+  var NAVIGATION_SETTLE_MS = 100;
   var Executor = class {
     constructor(config) {
       this.subscriptions = [];
       this.popstateHandler = null;
+      this.scrollPositions = /* @__PURE__ */ new Map();
+      this.pendingNavigation = null;
+      this.settleTimer = null;
       this.bus = config.bus;
       this.transport = config.transport;
       this.resolveRef = config.resolveRef;
+      this.basePath = config.basePath ?? "";
+      this.currentIndex = window.history.state?.idx ?? 0;
+      this.currentKey = this.ensureHistoryKey();
+      if ("scrollRestoration" in window.history) {
+        window.history.scrollRestoration = "manual";
+      }
       this.setupDOMSubscriptions();
       this.setupRouterSubscriptions();
       this.setupPopstateListener();
@@ -2438,6 +2978,14 @@ var LiveUIModule = (() => {
         window.removeEventListener("popstate", this.popstateHandler);
         this.popstateHandler = null;
       }
+      if (this.settleTimer) {
+        clearTimeout(this.settleTimer);
+        this.settleTimer = null;
+      }
+      this.pendingNavigation = null;
+    }
+    afterPatch() {
+      this.settleNavigation();
     }
     setupDOMSubscriptions() {
       this.subscriptions.push(
@@ -2466,21 +3014,40 @@ var LiveUIModule = (() => {
       this.subscriptions.push(
         this.bus.subscribe("router", "forward", () => this.handleForward())
       );
+      this.subscriptions.push(
+        this.bus.subscribe("router", "go", (payload) => this.handleGo(payload))
+      );
     }
     setupPopstateListener() {
-      this.popstateHandler = () => {
+      this.popstateHandler = (event) => {
+        this.saveScroll();
+        const state = event.state;
+        const delta = typeof state?.idx === "number" ? state.idx - this.currentIndex : 0;
+        this.currentIndex = state?.idx ?? this.currentIndex;
+        this.currentKey = state?.key ?? this.ensureHistoryKey();
+        this.scheduleNavigation({
+          restore: this.scrollPositions.get(this.currentKey) ?? { x: 0, y: 0 },
+          scroll: "auto",
+          focus: "",
+          hash: "",
+          pathChanged: true
+        });
         const payload = {
-          path: window.location.pathname,
+          path: this.appPath(window.location.pathname),
           query: window.location.search.replace(/^\?/, ""),
           hash: window.location.hash.replace(/^#/, "")
         };
+        if (delta !== 0) {
+          payload.delta = delta;
+        }
         this.transport.send("router", "popstate", payload);
       };
       window.addEventListener("popstate", this.popstateHandler);
     }
     handleCall(payload) {
       const el = this.resolveRef(payload.ref);
-      if (!el) return;
+      if (!el)
+        return;
       const method = el[payload.method];
       if (typeof method === "function") {
         method.apply(el, payload.args ?? []);
@@ -2488,7 +3055,8 @@ var LiveUIModule = (() => {
     }
     handleSet(payload) {
       const el = this.resolveRef(payload.ref);
-      if (!el) return;
+      if (!el)
+        return;
       el[payload.prop] = payload.value;
     }
     handleQuery(payload) {
@@ -2530,11 +3098,96 @@ var LiveUIModule = (() => {
     }
     handlePush(payload) {
       const url = this.buildUrl(payload);
-      window.history.pushState({}, "", url);
+      const pathChanged = this.basePath + payload.path !== window.location.pathname;
+      this.saveScroll();
+      this.currentKey = this.createKey();
+      this.currentIndex += 1;
+      window.history.pushState({ key: this.currentKey, idx: this.currentIndex }, "", url);
+      this.scheduleNavigation({
+        scroll: payload.scroll ?? "auto",
+        focus: payload.focus ?? "",
+        hash: payload.hash,
+        pathChanged
+      });
     }
     handleReplace(payload) {
       const url = this.buildUrl(payload);
-      window.history.replaceState({}, "", url);
+      window.history.replaceState({ key: this.currentKey, idx: this.currentIndex }, "", url);
+      if (payload.scroll === "auto" || payload.focus) {
+        this.scheduleNavigation({
+          scroll: payload.scroll ?? "preserve",
+          focus: payload.focus ?? "",
+          hash: payload.hash,
+          pathChanged: true
+        });
+      }
+    }
+    scheduleNavigation(pending) {
+      this.pendingNavigation = pending;
+      if (this.settleTimer) {
+        clearTimeout(this.settleTimer);
+      }
+      this.settleTimer = setTimeout(() => this.settleNavigation(), NAVIGATION_SETTLE_MS);
+    }
+    settleNavigation() {
+      const pending = this.pendingNavigation;
+      if (!pending)
+        return;
+      this.pendingNavigation = null;
+      if (this.settleTimer) {
+        clearTimeout(this.settleTimer);
+        this.settleTimer = null;
+      }
+      if (pending.restore) {
+        window.scrollTo(pending.restore.x, pending.restore.y);
+      } else if (pending.scroll !== "preserve") {
+        const target = pending.hash ? this.findHashTarget(pending.hash) : null;
+        if (target) {
+          target.scrollIntoView();
+        } else if (pending.pathChanged) {
+          window.scrollTo(0, 0);
+        }
+      }
+      if (pending.pathChanged && pending.focus !== "none") {
+        this.moveFocus(pending.focus);
+      }
+    }
+    findHashTarget(hash) {
+      const id = decodeURIComponent(hash);
+      return document.getElementById(id) ?? document.getElementsByName(id)[0] ?? null;
+    }
+    moveFocus(selector) {
+      const candidates = selector ? [selector] : DEFAULT_FOCUS_TARGETS;
+      for (const candidate of candidates) {
+        let el = null;
+        try {
+          el = document.querySelector(candidate);
+        } catch {
+          el = null;
+        }
+        if (!el)
+          continue;
+        if (!el.hasAttribute("tabindex") && el.tabIndex < 0) {
+          el.setAttribute("tabindex", "-1");
+        }
+        el.focus({ preventScroll: true });
+        return;
+      }
+    }
+    saveScroll() {
+      this.scrollPositions.set(this.currentKey, { x: window.scrollX, y: window.scrollY });
+    }
+    ensureHistoryKey() {
+      const state = window.history.state;
+      if (state?.key) {
+        return state.key;
+      }
+      const key = this.createKey();
+      window.history.replaceState({ ...state ?? {}, key, idx: this.currentIndex }, "");
+      return key;
+    }
+    createKey() {
+      return Math.random().toString(36).slice(2, 10);
     }
     handleBack() {
       window.history.back();
@@ -2542,8 +3195,17 @@ var LiveUIModule = (() => {
     handleForward() {
       window.history.forward();
     }
+    handleGo(payload) {
+      window.history.go(payload.delta);
+    }
+    appPath(pathname) {
+      if (!this.basePath || pathname !== this.basePath && !pathname.startsWith(this.basePath + "/")) {
+        return pathname;
+      }
+      return pathname.slice(this.basePath.length) || "/";
+    }
     buildUrl(payload) {
-      let url = payload.path;
+      let url = this.basePath + payload.path;
       if (payload.query) {
         url += "?" + payload.query;
       }
@@ -2556,21 +3218,27 @@ var LiveUIModule = (() => {
       const segments = path.split(".");
       let current = el;
       for (const segment of segments) {
-        if (current == null) return void 0;
+        if (current == null)
+          return void 0;
         current = current[segment];
       }
       return this.serializeValue(current);
     }
     serializeValue(value) {
-      if (value === null || value === void 0) return null;
+      if (value === null || value === void 0)
+        return null;
       const type = typeof value;
-      if (type === "string" || type === "number" || type === "boolean") return value;
+      if (type === "string" || type === "number" || type === "boolean")
+        return value;
       if (Array.isArray(value)) {
         return value.map((v) => this.serializeValue(v)).filter((v) => v !== void 0);
       }
-      if (value instanceof Date) return value.toISOString();
-      if (value instanceof DOMTokenList) return Array.from(value);
-      if (value instanceof Node) return void 0;
+      if (value instanceof Date)
+        return value.toISOString();
+      if (value instanceof DOMTokenList)
+        return Array.from(value);
+      if (value instanceof Node)
+        return void 0;
       try {
         return JSON.parse(JSON.stringify(value));
       } catch {
@@ -2680,6 +3348,7 @@ var LiveUIModule = (() => {
   var MAX_RELOADS = 10;
   var RELOAD_TRACKING_KEY = "pond_reload_count";
   var RELOAD_TIMESTAMP_KEY = "pond_reload_timestamp";
+  var VERSION_RELOAD_KEY = "pond_version_reload";
   var RELOAD_WINDOW_MS = 6e4;
   function isResumeOK(msg) {
     return typeof msg === "object" && msg !== null && msg.t === "resume_ok";
@@ -2691,17 +3360,18 @@ var LiveUIModule = (() => {
       this.lastSeq = 0;
       this.connectedState = false;
       this.lastSeq = config.seq;
+      this.version = config.version;
       Logger.configure({ enabled: config.debug ?? false, level: "debug" });
       Logger.info("Runtime", "Initializing", { sid: config.sessionId, ver: config.version });
       this.bus = new Bus();
-      this.transport = new Transport({
+      this.transport = createTransport({
         endpoint: config.endpoint,
         sessionId: config.sessionId,
         version: config.version,
         lastAck: config.seq,
         location: config.location,
         bus: this.bus
-      });
+      }, config.transport);
       const resolveRef = (refId) => this.refs.get(refId);
       this.patcher = new Patcher(config.root, {
         onEvent: (handlerId, data) => this.handleEvent(handlerId, data),
@@ -2719,10 +3389,12 @@ var LiveUIModule = (() => {
       this.executor = new Executor({
         bus: this.bus,
         transport: this.transport,
-        resolveRef
+        resolveRef,
+        basePath: config.basePath
       });
       this.scripts = new ScriptExecutor({ bus: this.bus, transport: this.transport });
       this.bus.subscribe("frame", "patch", (payload) => this.handlePatch(payload));
+      this.bus.subscribe("reload", "reload", (payload) => this.handleReload(payload));
       this.transport.onStateChange((state) => this.handleStateChange(state));
       window.__POND_RUNTIME__ = this;
     }
@@ -2768,9 +3440,23 @@ var LiveUIModule = (() => {
       if (payload.patches && payload.patches.length > 0) {
         this.applyPatches(payload.patches);
       }
+      this.executor.afterPatch();
       this.lastSeq = payload.seq;
       this.transport.sendAck(payload.seq);
     }
+    handleReload(payload) {
+      Logger.warn("Runtime", "Server version changed", { from: this.version, to: payload.ver });
+      try {
+        if (sessionStorage.getItem(VERSION_RELOAD_KEY) === String(payload.ver)) {
+          Logger.error("Runtime", "Still on a stale version after reloading - entering failsafe mode");
+          this.enterFailsafeMode();
+          return;
+        }
+        sessionStorage.setItem(VERSION_RELOAD_KEY, String(payload.ver));
+      } catch {
+      }
+      this.reloadWithJitter(payload.grace);
+    }
     handleResumeOK(resume) {
       Logger.info("Runtime", "Resume OK", { from: resume.from, to: resume.to });
     }
@@ -2800,6 +3486,7 @@ var LiveUIModule = (() => {
       if (!wasConnected && this.connectedState) {
         Logger.info("Runtime", "Connected");
         this.clearReloadTracking();
+        this.clearVersionReload();
       } else if (wasConnected && !this.connectedState) {
         Logger.warn("Runtime", "Disconnected");
       }
@@ -2808,14 +3495,14 @@ var LiveUIModule = (() => {
         this.reloadWithJitter();
       }
     }
-    reloadWithJitter() {
+    reloadWithJitter(delay = 0) {
       if (this.shouldEnterFailsafeMode()) {
         Logger.error("Runtime", "Entering failsafe mode - too many consecutive reloads");
         this.enterFailsafeMode();
         return;
       }
       this.incrementReloadCount();
-      const jitter = Math.floor(Math.random() * (RELOAD_JITTER_MAX - RELOAD_JITTER_MIN)) + RELOAD_JITTER_MIN;
+      const jitter = delay + Math.floor(Math.random() * (RELOAD_JITTER_MAX - RELOAD_JITTER_MIN)) + RELOAD_JITTER_MIN;
       Logger.info("Runtime", `Reloading in ${jitter}ms`);
       setTimeout(() => {
         window.location.reload();
@@ -2855,6 +3542,16 @@ var LiveUIModule = (() => {
       } catch {
       }
     }
+    // Joining on the version a reload asked for means the reload worked, so a
+    // later upgrade to that same version must be allowed to reload again.
+    clearVersionReload() {
+      try {
+        if (sessionStorage.getItem(VERSION_RELOAD_KEY) === String(this.version)) {
+          sessionStorage.removeItem(VERSION_RELOAD_KEY);
+        }
+      } catch {
+      }
+    }
     enterFailsafeMode() {
       try {
         sessionStorage.removeItem(RELOAD_TRACKING_KEY);
@@ -2867,7 +3564,8 @@ var LiveUIModule = (() => {
     }
   };
   function boot() {
-    if (typeof window === "undefined") return null;
+    if (typeof window === "undefined")
+      return null;
     const script = document.getElementById("live-boot");
     let bootData = null;
     if (script?.textContent) {
@@ -2889,9 +3587,11 @@ var LiveUIModule = (() => {
       sessionId: bootData.sid,
       version: bootData.ver,
       seq: bootData.seq,
-      endpoint: "/live",
+      endpoint: `${bootData.base ?? ""}/live`,
       location: bootData.location,
-      debug: bootData.client?.debug
+      basePath: bootData.base,
+      debug: bootData.client?.debug,
+      transport: bootData.client?.transport
     };
     const runtime = new Runtime(config);
     runtime.handleBoot(bootData);
@@ -2910,6 +3610,6 @@ var LiveUIModule = (() => {
       boot();
     }
   }
-  return __toCommonJS(index_exports);
+  return __toCommonJS(src_exports);
 })();
 //# sourceMappingURL=pondlive-dev.js.map
@@ -1,2 +1,5 @@
-// LiveUI Client v1.0.0
-"use strict";(()=>{function e(e){return"script:"+e}function t(e){return"object"==typeof e&&null!==e&&"boot"===e.t}function i(e){return"object"==typeof e&&null!==e&&"error"===e.t}function n(e){return"object"==typeof e&&null!==e&&"string"==typeof e.t&&"string"==typeof e.a}function s(e){return"object"==typeof e&&null!==e&&"ack"===e.t&&"number"==typeof e.seq}function r(e){return"object"==typeof e&&null!==e&&"number"==typeof e.seq&&"string"==typeof e.topic&&"string"==typeof e.event}function o(){if(typeof window>"u")return null;let e=document.getElementById("live-boot"),i=null;if(e?.textContent)try{i=JSON.parse(e.textContent)}catch{d.error("Runtime","Failed to parse boot payload")}if(i||(i=window.__LIVEUI_BOOT__??null),!i||!t(i))return d.error("Runtime","No boot payload found"),null;let n={root:document.documentElement,sessionId:i.sid,version:i.ver,seq:i.seq,endpoint:"/live",location:i.location,debug:i.client?.debug},s=new g(n);return s.handleBoot(i),s.connect(),s}var a,h,c,l,u,d,f,p,w,m,v,b,y,g,S,E,M=Object.create,O=Object.defineProperty,T=Object.getOwnPropertyDescriptor,k=Object.getOwnPropertyNames,C=Object.getPrototypeOf,N={}.hasOwnProperty,R=(e,t)=>()=>(t||e((t={exports:{}}).exports,t),t.exports),I=(e,t,i,n)=>{if(t&&"object"==typeof t||"function"==typeof t)for(let s of k(t))!N.call(e,s)&&s!==i&&O(e,s,{get:()=>t[s],enumerable:!(n=T(t,s))||n.enumerable});return e},j=R(e=>{var t,i,n=e&&e.i||function(e,t,i,n,s){if("m"===n)throw new TypeError("Private method is not writable");if("a"===n&&!s)throw new TypeError("Private accessor was defined without a setter");if("function"==typeof t?e!==t||!s:!t.has(e))throw new TypeError("Cannot write private member to an object whose class did not declare it");return"a"===n?s.call(e,i):s?s.value=i:t.set(e,i),i},s=e&&e.o||function(e,t,i,n){if("a"===i&&!n)throw new TypeError("Private accessor was defined without a getter");if("function"==typeof t?e!==t||!n:!t.has(e))throw new TypeError("Cannot read private member from an object whose class did not declare it");return"m"===i?n:"a"===i?n.call(e):n?n.value:t.get(e)};Object.defineProperty(e,"h",{value:1}),e.Subject=void 0,e.Subject=class{constructor(){t.set(this,void 0),i.set(this,void 0),n(this,t,0,"f"),n(this,i,new Set,"f")}get size(){return s(this,i,"f").size}subscribe(e){if(s(this,t,"f"))throw Error("Cannot subscribe to a closed subject");return s(this,i,"f").add(e),()=>s(this,i,"f").delete(e)}publish(e){s(this,i,"f").forEach(t=>t(e))}close(){s(this,i,"f").clear(),n(this,t,1,"f")}},t=new WeakMap,i=new WeakMap}),x=R(e=>{var t,i,n=e&&e.i||function(e,t,i,n,s){if("m"===n)throw new TypeError("Private method is not writable");if("a"===n&&!s)throw new TypeError("Private accessor was defined without a setter");if("function"==typeof t?e!==t||!s:!t.has(e))throw new TypeError("Cannot write private member to an object whose class did not declare it");return"a"===n?s.call(e,i):s?s.value=i:t.set(e,i),i},s=e&&e.o||function(e,t,i,n){if("a"===i&&!n)throw new TypeError("Private accessor was defined without a getter");if("function"==typeof t?e!==t||!n:!t.has(e))throw new TypeError("Cannot read private member from an object whose class did not declare it");return"m"===i?n:"a"===i?n.call(e):n?n.value:t.get(e)};Object.defineProperty(e,"h",{value:1}),e.BehaviorSubject=void 0,i=j(),e.BehaviorSubject=class extends i.Subject{constructor(e){super(),t.set(this,void 0),n(this,t,e,"f")}get value(){return s(this,t,"f")}publish(e){n(this,t,e,"f"),super.publish(e)}subscribe(e){return s(this,t,"f")&&e(s(this,t,"f")),super.subscribe(e)}},t=new WeakMap}),_=R(e=>{var t=e&&e.l||(Object.create?function(e,t,i,n){void 0===n&&(n=i);var s=Object.getOwnPropertyDescriptor(t,i);(!s||("get"in s?!t.h:s.writable||s.configurable))&&(s={enumerable:1,get:function(){return t[i]}}),Object.defineProperty(e,n,s)}:function(e,t,i,n){void 0===n&&(n=i),e[n]=t[i]}),i=e&&e.u||function(e,i){for(var n in e)"default"!==n&&!{}.hasOwnProperty.call(i,n)&&t(i,e,n)};Object.defineProperty(e,"h",{value:1}),i(x(),e),i(j(),e)}),D=R(e=>{var t,i,n,s,r,o,a,h,c,l;Object.defineProperty(e,"h",{value:1}),e.PubSubEvents=e.Events=e.ChannelReceiver=e.SystemSender=e.ErrorTypes=e.ChannelState=e.ClientActions=e.ServerActions=e.PresenceEventTypes=void 0,(l=t||(e.PresenceEventTypes=t={})).JOIN="JOIN",l.LEAVE="LEAVE",l.UPDATE="UPDATE",function(e){e.PRESENCE="PRESENCE",e.SYSTEM="SYSTEM",e.BROADCAST="BROADCAST",e.ERROR="ERROR",e.CONNECT="CONNECT"}(i||(e.ServerActions=i={})),function(e){e.JOIN_CHANNEL="JOIN_CHANNEL",e.LEAVE_CHANNEL="LEAVE_CHANNEL",e.BROADCAST="BROADCAST"}(n||(e.ClientActions=n={})),function(e){e.IDLE="IDLE",e.JOINING="JOINING",e.JOINED="JOINED",e.STALLED="STALLED",e.CLOSED="CLOSED",e.DECLINED="DECLINED"}(s||(e.ChannelState=s={})),function(e){e.UNAUTHORIZED_CONNECTION="UNAUTHORIZED_CONNECTION",e.UNAUTHORIZED_JOIN_REQUEST="UNAUTHORIZED_JOIN_REQUEST",e.UNAUTHORIZED_BROADCAST="UNAUTHORIZED_BROADCAST",e.INVALID_MESSAGE="INVALID_MESSAGE",e.HANDLER_NOT_FOUND="HANDLER_NOT_FOUND",e.PRESENCE_ERROR="PRESENCE_ERROR",e.CHANNEL_ERROR="CHANNEL_ERROR",e.ENDPOINT_ERROR="ENDPOINT_ERROR",e.INTERNAL_SERVER_ERROR="INTERNAL_SERVER_ERROR"}(r||(e.ErrorTypes=r={})),function(e){e.ENDPOINT="ENDPOINT",e.CHANNEL="CHANNEL"}(o||(e.SystemSender=o={})),function(e){e.ALL_USERS="ALL_USERS",e.ALL_EXCEPT_SENDER="ALL_EXCEPT_SENDER"}(a||(e.ChannelReceiver=a={})),function(e){e.ACKNOWLEDGE="ACKNOWLEDGE",e.CONNECTION="CONNECTION",e.UNAUTHORIZED="UNAUTHORIZED"}(h||(e.Events=h={})),function(e){e.MESSAGE="MESSAGE",e.PRESENCE="PRESENCE",e.GET_PRESENCE="GET_PRESENCE"}(c||(e.PubSubEvents=c={}))}),A=R(e=>{Object.defineProperty(e,"h",{value:1}),e.uuid=function(){return"xxxxxxxx-xxxx-4xxx-yxxx-xxxxxxxxxxxx".replace(/[xy]/g,e=>{let t=16*Math.random()|0;return("x"===e?t:3&t|8).toString(16)})}}),q=R(e=>{Object.defineProperty(e,"h",{value:1})}),L=R(e=>{var t=e&&e.l||(Object.create?function(e,t,i,n){void 0===n&&(n=i);var s=Object.getOwnPropertyDescriptor(t,i);(!s||("get"in s?!t.h:s.writable||s.configurable))&&(s={enumerable:1,get:function(){return t[i]}}),Object.defineProperty(e,n,s)}:function(e,t,i,n){void 0===n&&(n=i),e[n]=t[i]}),i=e&&e.u||function(e,i){for(var n in e)"default"!==n&&!{}.hasOwnProperty.call(i,n)&&t(i,e,n)};Object.defineProperty(e,"h",{value:1}),i(A(),e),i(q(),e)}),P=R(e=>{function t(e){return"object"==typeof e&&null!==e&&!Array.isArray(e)}function i(e,t){if(!function(e){return"string"==typeof e}(e))throw new a("Expected string, got "+typeof e,t)}function n(e,i){if(!t(e))throw new a("Expected object, got "+typeof e,i)}function s(e,i){if(!function(e){return t(e)?Object.keys(e).every(e=>"string"==typeof e):0}(e))throw new a("Expected record with string keys, got "+typeof e,i)}function r(e,t,i){let n=Object.values(t);if(!n.includes(e))throw new a(`Expected one of [${n.join(", ")}], got ${JSON.stringify(e)}`,i)}Object.defineProperty(e,"h",{value:1}),e.channelEventSchema=e.serverMessageSchema=e.presenceMessageSchema=e.clientMessageSchema=e.ValidationError=void 0;var o=D(),a=class extends Error{constructor(e,t){super(t?`${t}: ${e}`:e),this.path=t,this.name="ValidationError"}};e.ValidationError=a,e.clientMessageSchema={parse(e){n(e,"clientMessage");let t=e;if(!("event"in t))throw new a("Missing required field","event");if(!("requestId"in t))throw new a("Missing required field","requestId");if(!("channelName"in t))throw new a("Missing required field","channelName");if(!("payload"in t))throw new a("Missing required field","payload");if(!("action"in t))throw new a("Missing required field","action");return i(t.event,"event"),i(t.requestId,"requestId"),i(t.channelName,"channelName"),s(t.payload,"payload"),r(t.action,o.ClientActions,"action"),{event:t.event,requestId:t.requestId,channelName:t.channelName,payload:t.payload,action:t.action}}},e.presenceMessageSchema={parse(e){n(e,"presenceMessage");let t=e;if(!("requestId"in t))throw new a("Missing required field","requestId");if(!("channelName"in t))throw new a("Missing required field","channelName");if(!("event"in t))throw new a("Missing required field","event");if(!("action"in t))throw new a("Missing required field","action");if(!("payload"in t))throw new a("Missing required field","payload");if(i(t.requestId,"requestId"),i(t.channelName,"channelName"),r(t.event,o.PresenceEventTypes,"event"),t.action!==o.ServerActions.PRESENCE)throw new a(`Expected ${o.ServerActions.PRESENCE}, got ${JSON.stringify(t.action)}`,"action");n(t.payload,"payload");let h=t.payload;if(!("presence"in h))throw new a("Missing required field","payload.presence");if(!("changed"in h))throw new a("Missing required field","payload.changed");return function(e){if(!function(e){return Array.isArray(e)}(e))throw new a("Expected array, got "+typeof e,"payload.presence")}(h.presence),h.presence.forEach((e,t)=>{s(e,`payload.presence[${t}]`)}),s(h.changed,"payload.changed"),{requestId:t.requestId,channelName:t.channelName,event:t.event,action:o.ServerActions.PRESENCE,payload:{presence:h.presence,changed:h.changed}}}},e.serverMessageSchema={parse(e){n(e,"serverMessage");let t=e;if(!("event"in t))throw new a("Missing required field","event");if(!("requestId"in t))throw new a("Missing required field","requestId");if(!("channelName"in t))throw new a("Missing required field","channelName");if(!("payload"in t))throw new a("Missing required field","payload");if(!("action"in t))throw new a("Missing required field","action");i(t.event,"event"),i(t.requestId,"requestId"),i(t.channelName,"channelName"),s(t.payload,"payload");let r=[o.ServerActions.BROADCAST,o.ServerActions.CONNECT,o.ServerActions.ERROR,o.ServerActions.SYSTEM];if(!r.includes(t.action))throw new a(`Expected one of [${r.join(", ")}], got ${JSON.stringify(t.action)}`,"action");return{event:t.event,requestId:t.requestId,channelName:t.channelName,payload:t.payload,action:t.action}}},e.channelEventSchema={parse(t){n(t,"channelEvent");let i=t;if(!("action"in i))throw new a("Missing required field","action");return i.action===o.ServerActions.PRESENCE?e.presenceMessageSchema.parse(t):e.serverMessageSchema.parse(t)}}}),W=R(e=>{var t=e&&e.l||(Object.create?function(e,t,i,n){void 0===n&&(n=i);var s=Object.getOwnPropertyDescriptor(t,i);(!s||("get"in s?!t.h:s.writable||s.configurable))&&(s={enumerable:1,get:function(){return t[i]}}),Object.defineProperty(e,n,s)}:function(e,t,i,n){void 0===n&&(n=i),e[n]=t[i]}),i=e&&e.u||function(e,i){for(var n in e)"default"!==n&&!{}.hasOwnProperty.call(i,n)&&t(i,e,n)};Object.defineProperty(e,"h",{value:1}),i(_(),e),i(D(),e),i(L(),e),i(P(),e)}),$=R(e=>{var t,i;Object.defineProperty(e,"h",{value:1}),e.ConnectionState=void 0,(i=t||(e.ConnectionState=t={})).DISCONNECTED="disconnected",i.CONNECTING="connecting",i.CONNECTED="connected"}),H=R(e=>{var t,i,n,s,r,o,a,h,c,l,u,d,f,p,w,m,v,b=e&&e.i||function(e,t,i,n,s){if("m"===n)throw new TypeError("Private method is not writable");if("a"===n&&!s)throw new TypeError("Private accessor was defined without a setter");if("function"==typeof t?e!==t||!s:!t.has(e))throw new TypeError("Cannot write private member to an object whose class did not declare it");return"a"===n?s.call(e,i):s?s.value=i:t.set(e,i),i},y=e&&e.o||function(e,t,i,n){if("a"===i&&!n)throw new TypeError("Private accessor was defined without a getter");if("function"==typeof t?e!==t||!n:!t.has(e))throw new TypeError("Cannot read private member from an object whose class did not declare it");return"m"===i?n:"a"===i?n.call(e):n?n.value:t.get(e)};Object.defineProperty(e,"h",{value:1}),e.Channel=void 0,m=W(),v=$(),e.Channel=class{constructor(e,u,d,f){t.add(this),i.set(this,void 0),n.set(this,void 0),s.set(this,void 0),r.set(this,void 0),o.set(this,void 0),a.set(this,void 0),h.set(this,void 0),c.set(this,void 0),l.set(this,void 0),b(this,i,d,"f"),b(this,n,[],"f"),b(this,s,[],"f"),b(this,a,f,"f"),b(this,o,e,"f"),b(this,c,u,"f"),b(this,h,new m.Subject,"f"),b(this,l,new m.BehaviorSubject(m.ChannelState.IDLE),"f"),b(this,r,()=>{},"f")}get channelState(){return y(this,l,"f").value}get presence(){return y(this,s,"f")}acknowledge(e){y(this,l,"f").publish(m.ChannelState.JOINED),y(this,t,"m",d).call(this,e),y(this,t,"m",u).call(this)}decline(e){y(this,l,"f").publish(m.ChannelState.DECLINED),b(this,n,[],"f")}join(){let e={action:m.ClientActions.JOIN_CHANNEL,event:m.ClientActions.JOIN_CHANNEL,payload:y(this,a,"f"),channelName:y(this,i,"f"),requestId:(0,m.uuid)()};if(y(this,l,"f").value!==m.ChannelState.JOINED)if(y(this,l,"f").publish(m.ChannelState.JOINING),y(this,c,"f").value===v.ConnectionState.CONNECTED)y(this,o,"f").call(this,e);else{let t=y(this,c,"f").subscribe(i=>{i===v.ConnectionState.CONNECTED&&(t(),y(this,l,"f").value===m.ChannelState.JOINING&&y(this,o,"f").call(this,e))})}}leave(){let e={action:m.ClientActions.LEAVE_CHANNEL,event:m.ClientActions.LEAVE_CHANNEL,channelName:y(this,i,"f"),requestId:(0,m.uuid)(),payload:{}};y(this,t,"m",p).call(this,e),y(this,l,"f").publish(m.ChannelState.CLOSED),y(this,r,"f").call(this)}onChannelStateChange(e){return y(this,l,"f").subscribe(t=>{e(t)})}onJoin(e){return y(this,t,"m",w).call(this,(t,i)=>{if(t===m.PresenceEventTypes.JOIN)return e(i.changed)})}onLeave(e){return y(this,t,"m",w).call(this,(t,i)=>{if(t===m.PresenceEventTypes.LEAVE)return e(i.changed)})}onMessage(e){return y(this,t,"m",f).call(this,(t,i)=>{e(t,i)})}onMessageEvent(e,t){return this.onMessage((i,n)=>{if(i===e)return t(n)})}onPresenceChange(e){return y(this,t,"m",w).call(this,(t,i)=>{if(t===m.PresenceEventTypes.UPDATE)return e(i)})}onUsersChange(e){return y(this,t,"m",w).call(this,(t,i)=>e(i.presence))}sendMessage(e,n){let s=(0,m.uuid)(),r={action:m.ClientActions.BROADCAST,channelName:y(this,i,"f"),requestId:s,event:e,payload:n};y(this,t,"m",p).call(this,r)}sendForResponse(e,n){let s=(0,m.uuid)();return new Promise(r=>{let o=y(this,t,"m",f).call(this,(e,t,i)=>{s===i&&(r(t),o())}),a={action:m.ClientActions.BROADCAST,channelName:y(this,i,"f"),requestId:s,event:e,payload:n};y(this,t,"m",p).call(this,a)})}},i=new WeakMap,n=new WeakMap,s=new WeakMap,r=new WeakMap,o=new WeakMap,a=new WeakMap,h=new WeakMap,c=new WeakMap,l=new WeakMap,t=new WeakSet,u=function(){y(this,n,"f").forEach(e=>y(this,o,"f").call(this,e)),b(this,n,[],"f")},d=function(e){y(this,r,"f").call(this);let n=e.subscribe(e=>{e.channelName===y(this,i,"f")&&this.channelState===m.ChannelState.JOINED&&y(this,h,"f").publish(e)}),u=y(this,c,"f").subscribe(e=>{if(e===v.ConnectionState.CONNECTED&&y(this,l,"f").value===m.ChannelState.STALLED){let e={action:m.ClientActions.JOIN_CHANNEL,event:m.ClientActions.JOIN_CHANNEL,payload:y(this,a,"f"),channelName:y(this,i,"f"),requestId:(0,m.uuid)()};y(this,o,"f").call(this,e)}else e!==v.ConnectionState.CONNECTED&&y(this,l,"f").value===m.ChannelState.JOINED&&y(this,l,"f").publish(m.ChannelState.STALLED)}),d=y(this,t,"m",w).call(this,(e,t)=>{b(this,s,t.presence,"f")});b(this,r,()=>{n(),u(),d()},"f")},f=function(e){return y(this,h,"f").subscribe(t=>{if(t.action!==m.ServerActions.PRESENCE)return e(t.event,t.payload,t.requestId)})},p=function(e){if(y(this,l,"f").value===m.ChannelState.JOINED)return y(this,o,"f").call(this,e);y(this,n,"f").push(e)},w=function(e){return y(this,h,"f").subscribe(t=>{if(t.action===m.ServerActions.PRESENCE)return e(t.event,t.payload)})}}),J=R(e=>{var t,i,n,s,r,o,a,h,c,l,u=e&&e.i||function(e,t,i,n,s){if("m"===n)throw new TypeError("Private method is not writable");if("a"===n&&!s)throw new TypeError("Private accessor was defined without a setter");if("function"==typeof t?e!==t||!s:!t.has(e))throw new TypeError("Cannot write private member to an object whose class did not declare it");return"a"===n?s.call(e,i):s?s.value=i:t.set(e,i),i},d=e&&e.o||function(e,t,i,n){if("a"===i&&!n)throw new TypeError("Private accessor was defined without a getter");if("function"==typeof t?e!==t||!n:!t.has(e))throw new TypeError("Cannot read private member from an object whose class did not declare it");return"m"===i?n:"a"===i?n.call(e):n?n.value:t.get(e)};Object.defineProperty(e,"h",{value:1}),e.PondClient=void 0,h=W(),c=H(),l=$(),e.PondClient=class{constructor(e,n={},s={}){var r,o;let c;t.add(this),i.set(this,void 0);try{c=new URL(e)}catch{c=new URL(""+window.location),c.pathname=e}this.m=0,this.v=0;let f=new URLSearchParams(n);c.search=""+f,"wss:"!==c.protocol&&"ws:"!==c.protocol&&(c.protocol="https:"===c.protocol?"wss:":"ws:"),this.S=c,this.M={connectionTimeout:null!==(r=s.connectionTimeout)&&void 0!==r?r:1e4,maxReconnectDelay:null!==(o=s.maxReconnectDelay)&&void 0!==o?o:3e4,pingInterval:s.pingInterval},u(this,i,new Map,"f"),this.O=new h.Subject,this.T=new h.BehaviorSubject(l.ConnectionState.DISCONNECTED),this.k=new h.Subject,d(this,t,"m",a).call(this)}connect(){this.m=0,this.T.publish(l.ConnectionState.CONNECTING);let e=new WebSocket(""+this.S);this.C=setTimeout(()=>{e.readyState===WebSocket.CONNECTING&&(this.k.publish(Error("Connection timeout")),e.close())},this.M.connectionTimeout),e.onopen=()=>{d(this,t,"m",n).call(this),this.v=0,this.M.pingInterval&&(this.N=setInterval(()=>{e.readyState===WebSocket.OPEN&&e.send(JSON.stringify({action:"ping"}))},this.M.pingInterval))},e.onmessage=e=>{let t=e.data.trim().split("\n");for(let e of t)if(e.trim()){let t=JSON.parse(e),i=h.channelEventSchema.parse(t);this.O.publish(i)}},e.onerror=()=>{this.k.publish(Error("WebSocket error")),e.close()},e.onclose=()=>{if(d(this,t,"m",n).call(this),this.T.publish(l.ConnectionState.DISCONNECTED),this.m)return;let e=Math.min(1e3*Math.pow(2,this.v),this.M.maxReconnectDelay);this.v++,setTimeout(()=>{this.connect()},e)},this.R=e}getState(){return this.T.value}disconnect(){var e;d(this,t,"m",n).call(this),this.m=1,this.T.publish(l.ConnectionState.DISCONNECTED),null===(e=this.R)||void 0===e||e.close(),d(this,i,"f").clear()}createChannel(e,n){let r=d(this,i,"f").get(e);if(r&&r.channelState!==h.ChannelState.CLOSED)return r;let o=d(this,t,"m",s).call(this),a=new c.Channel(o,this.T,e,n||{});return d(this,i,"f").set(e,a),a}onConnectionChange(e){return this.T.subscribe(e)}onError(e){return this.k.subscribe(e)}},i=new WeakMap,t=new WeakSet,n=function(){this.C&&(clearTimeout(this.C),this.C=void 0),this.N&&(clearInterval(this.N),this.N=void 0)},s=function(){return e=>{this.T.value===l.ConnectionState.CONNECTED&&this.R.send(JSON.stringify(e))}},r=function(e){var n;let r=null!==(n=d(this,i,"f").get(e.channelName))&&void 0!==n?n:new c.Channel(d(this,t,"m",s).call(this),this.T,e.channelName,{});d(this,i,"f").set(e.channelName,r),r.acknowledge(this.O)},o=function(e){let t=d(this,i,"f").get(e.channelName);t&&t.decline(e.payload)},a=function(){this.O.subscribe(e=>{e.event===h.Events.ACKNOWLEDGE?d(this,t,"m",r).call(this,e):e.event===h.Events.UNAUTHORIZED?d(this,t,"m",o).call(this,e):e.event===h.Events.CONNECTION&&e.action===h.ServerActions.CONNECT&&this.T.publish(l.ConnectionState.CONNECTED)})}}),U=R(e=>{var t,i,n,s,r,o,a,h,c,l,u,d=e&&e.i||function(e,t,i,n,s){if("m"===n)throw new TypeError("Private method is not writable");if("a"===n&&!s)throw new TypeError("Private accessor was defined without a setter");if("function"==typeof t?e!==t||!s:!t.has(e))throw new TypeError("Cannot write private member to an object whose class did not declare it");return"a"===n?s.call(e,i):s?s.value=i:t.set(e,i),i},f=e&&e.o||function(e,t,i,n){if("a"===i&&!n)throw new TypeError("Private accessor was defined without a getter");if("function"==typeof t?e!==t||!n:!t.has(e))throw new TypeError("Cannot read private member from an object whose class did not declare it");return"m"===i?n:"a"===i?n.call(e):n?n.value:t.get(e)};Object.defineProperty(e,"h",{value:1}),e.SSEClient=void 0,c=W(),l=H(),u=$(),e.SSEClient=class{constructor(e,n={},s={}){var r,o;let a;t.add(this),i.set(this,void 0);try{a=new URL(e)}catch{a=new URL(""+window.location),a.pathname=e}this.m=0,this.v=0;let l=new URLSearchParams(n);a.search=""+l,"https:"!==a.protocol&&"http:"!==a.protocol&&(a.protocol=window.location.protocol),this.S=a,this.I=new URL(""+a),this.M={connectionTimeout:null!==(r=s.connectionTimeout)&&void 0!==r?r:1e4,maxReconnectDelay:null!==(o=s.maxReconnectDelay)&&void 0!==o?o:3e4,pingInterval:s.pingInterval,withCredentials:s.withCredentials},d(this,i,new Map,"f"),this.O=new c.Subject,this.T=new c.BehaviorSubject(u.ConnectionState.DISCONNECTED),this.k=new c.Subject,f(this,t,"m",h).call(this)}connect(){var e;this.m=0,this.T.publish(u.ConnectionState.CONNECTING);let i=new EventSource(""+this.S,{withCredentials:null!==(e=this.M.withCredentials)&&void 0!==e?e:0});this.C=setTimeout(()=>{i.readyState===EventSource.CONNECTING&&(this.k.publish(Error("Connection timeout")),i.close())},this.M.connectionTimeout),i.onopen=()=>{f(this,t,"m",s).call(this),this.v=0},i.onmessage=e=>{f(this,t,"m",n).call(this,e.data)},i.onerror=()=>{if(this.k.publish(Error("SSE connection error")),i.close(),f(this,t,"m",s).call(this),this.T.publish(u.ConnectionState.DISCONNECTED),this.m)return;let e=Math.min(1e3*Math.pow(2,this.v),this.M.maxReconnectDelay);this.v++,setTimeout(()=>{this.connect()},e)},this.j=i}getState(){return this.T.value}getConnectionId(){return this._}disconnect(){var e;f(this,t,"m",s).call(this),this.m=1,this.T.publish(u.ConnectionState.DISCONNECTED),this._&&fetch(""+this.I,{method:"DELETE",headers:{"X-Connection-ID":this._},credentials:this.M.withCredentials?"include":"same-origin"}).catch(()=>{}),null===(e=this.j)||void 0===e||e.close(),this._=void 0,f(this,i,"f").clear()}createChannel(e,n){let s=f(this,i,"f").get(e);if(s&&s.channelState!==c.ChannelState.CLOSED)return s;let o=f(this,t,"m",r).call(this),a=new l.Channel(o,this.T,e,n||{});return f(this,i,"f").set(e,a),a}onConnectionChange(e){return this.T.subscribe(e)}onError(e){return this.k.subscribe(e)}},i=new WeakMap,t=new WeakSet,n=function(e){try{let t=e.trim().split("\n");for(let e of t)if(e.trim()){let t=JSON.parse(e),i=c.channelEventSchema.parse(t);i.event===c.Events.CONNECTION&&i.action===c.ServerActions.CONNECT&&i.payload&&"object"==typeof i.payload&&"connectionId"in i.payload&&(this._=i.payload.connectionId),this.O.publish(i)}}catch(e){this.k.publish(e instanceof Error?e:Error("Failed to parse SSE message"))}},s=function(){this.C&&(clearTimeout(this.C),this.C=void 0)},r=function(){return e=>{this.T.value===u.ConnectionState.CONNECTED&&this._&&fetch(""+this.I,{method:"POST",headers:{"Content-Type":"application/json","X-Connection-ID":this._},body:JSON.stringify(e),credentials:this.M.withCredentials?"include":"same-origin"}).catch(e=>{this.k.publish(e instanceof Error?e:Error("Failed to send message"))})}},o=function(e){var n;let s=null!==(n=f(this,i,"f").get(e.channelName))&&void 0!==n?n:new l.Channel(f(this,t,"m",r).call(this),this.T,e.channelName,{});f(this,i,"f").set(e.channelName,s),s.acknowledge(this.O)},a=function(e){let t=f(this,i,"f").get(e.channelName);t&&t.decline(e.payload)},h=function(){this.O.subscribe(e=>{e.event===c.Events.ACKNOWLEDGE?f(this,t,"m",o).call(this,e):e.event===c.Events.UNAUTHORIZED?f(this,t,"m",a).call(this,e):e.event===c.Events.CONNECTION&&e.action===c.ServerActions.CONNECT&&this.T.publish(u.ConnectionState.CONNECTED)})}}),F=R((e,t)=>{var i=function(){if("object"==typeof self&&self)return self;if("object"==typeof window&&window)return window;throw Error("Unable to resolve global `this`")};t.exports=function(){if(this)return this;if("object"==typeof globalThis&&globalThis)return globalThis;try{Object.defineProperty(Object.prototype,"D",{get:function(){return this},configurable:1})}catch{return i()}try{return __global__||i()}finally{delete Object.prototype.D}}()}),B=R((e,t)=>{t.exports={name:"websocket",description:"Websocket Client & Server Library implementing the WebSocket protocol as specified in RFC 6455.",keywords:["websocket","websockets","socket","networking","comet","push","RFC-6455","realtime","server","client"],author:"Brian McKelvey <theturtle32@gmail.com> (https://github.com/theturtle32)",contributors:["Iñaki Baz Castillo <ibc@aliax.net> (http://dev.sipdoc.net)"],version:"1.0.35",repository:{type:"git",url:"https://github.com/theturtle32/WebSocket-Node.git"},homepage:"https://github.com/theturtle32/WebSocket-Node",engines:{node:">=4.0.0"},dependencies:{bufferutil:"^4.0.1",debug:"^2.2.0","es5-ext":"^0.10.63","typedarray-to-buffer":"^3.1.5","utf-8-validate":"^5.0.2",yaeti:"^0.0.6"},devDependencies:{"buffer-equal":"^1.0.0",gulp:"^4.0.2","gulp-jshint":"^2.0.4","jshint-stylish":"^2.2.1",jshint:"^2.0.0",tape:"^4.9.1"},config:{verbose:0},scripts:{test:"tape test/unit/*.js",gulp:"gulp"},main:"index",directories:{lib:"./lib"},browser:"lib/browser.js",license:"Apache-2.0"}}),G=R((e,t)=>{t.exports=B().version}),V=R((e,t)=>{function i(e,t){return t?new s(e,t):new s(e)}var n,s,r;if("object"==typeof globalThis)n=globalThis;else try{n=F()}catch{}finally{if(!n&&"u">typeof window&&(n=window),!n)throw Error("Could not determine global this")}s=n.WebSocket||n.MozWebSocket,r=G(),s&&["CONNECTING","OPEN","CLOSING","CLOSED"].forEach(function(e){Object.defineProperty(i,e,{get:function(){return s[e]}})}),t.exports={w3cwebsocket:s?i:null,version:r}}),K=R(e=>{var t,i,n,s,r,o,a=e&&e.o||function(e,t,i,n){if("a"===i&&!n)throw new TypeError("Private accessor was defined without a getter");if("function"==typeof t?e!==t||!n:!t.has(e))throw new TypeError("Cannot read private member from an object whose class did not declare it");return"m"===i?n:"a"===i?n.call(e):n?n.value:t.get(e)};Object.defineProperty(e,"h",{value:1}),e.PondClient=void 0,n=W(),s=J(),r=$(),o=V().w3cwebsocket,e.PondClient=class extends s.PondClient{constructor(){super(...arguments),t.add(this)}connect(){this.m=0,this.T.publish(r.ConnectionState.CONNECTING);let e=new o(""+this.S);this.C=setTimeout(()=>{e.readyState===o.CONNECTING&&(this.k.publish(Error("Connection timeout")),e.close())},this.M.connectionTimeout),e.onopen=()=>{a(this,t,"m",i).call(this),this.v=0,this.M.pingInterval&&(this.N=setInterval(()=>{e.readyState===o.OPEN&&e.send(JSON.stringify({action:"ping"}))},this.M.pingInterval))},e.onmessage=e=>{let t=e.data.trim().split("\n");for(let e of t)if(e.trim()){let t=JSON.parse(e),i=n.channelEventSchema.parse(t);this.O.publish(i)}},e.onerror=()=>{this.k.publish(Error("WebSocket error")),e.close()},e.onclose=()=>{if(a(this,t,"m",i).call(this),this.T.publish(r.ConnectionState.DISCONNECTED),this.m)return;let e=Math.min(1e3*Math.pow(2,this.v),this.M.maxReconnectDelay);this.v++,setTimeout(()=>{this.connect()},e)},this.R=e}},t=new WeakSet,i=function(){this.C&&(clearTimeout(this.C),this.C=void 0),this.N&&(clearInterval(this.N),this.N=void 0)}}),z=R(e=>{var t,i,n,s,r,o;Object.defineProperty(e,"h",{value:1}),e.SSEClient=e.PondClient=e.ConnectionState=e.ChannelState=void 0,t=W(),Object.defineProperty(e,"ChannelState",{enumerable:1,get:function(){return t.ChannelState}}),i=J(),n=U(),Object.defineProperty(e,"SSEClient",{enumerable:1,get:function(){return n.SSEClient}}),s=K(),r=$(),Object.defineProperty(e,"ConnectionState",{enumerable:1,get:function(){return r.ConnectionState}}),o=typeof window>"u"?s.PondClient:i.PondClient,e.PondClient=o}),Z={};((e,t)=>{for(var i in t)O(e,i,{get:t[i],enumerable:1})})(Z,{Bus:()=>c,Executor:()=>m,Logger:()=>d,OpKinds:()=>h,Patcher:()=>w,Runtime:()=>g,ScriptExecutor:()=>v,Topics:()=>a,Transport:()=>f,boot:()=>o,isBoot:()=>t,isMessage:()=>r,isServerAck:()=>s,isServerError:()=>i,isServerEvt:()=>n}),a={Router:"router",DOM:"dom",Frame:"frame",Ack:"ack"},h={SetText:"setText",SetComment:"setComment",SetAttr:"setAttr",DelAttr:"delAttr",SetStyle:"setStyle",DelStyle:"delStyle",SetStyleDecl:"setStyleDecl",DelStyleDecl:"delStyleDecl",SetHandlers:"setHandlers",SetScript:"setScript",DelScript:"delScript",SetRef:"setRef",DelRef:"delRef",ReplaceNode:"replaceNode",AddChild:"addChild",DelChild:"delChild",MoveChild:"moveChild"},c=class{constructor(){this.subscribers=new Map,this.wildcardSubscribers=[],this.nextSubId=0}subscribe(e,t,i){let n=`${e}:${t+""}`,s=++this.nextSubId,r={id:s,callback:i},o=this.subscribers.get(n)??[];return o.push(r),this.subscribers.set(n,o),{unsubscribe:()=>this.unsubscribe(n,s)}}upsert(e,t,i){let n=`${e}:${t+""}`,s=this.subscribers.get(n);if(s&&s.length>0){let e=s[0];e.callback=i,s.length>1&&this.subscribers.set(n,[e]);let t=e.id;return{unsubscribe:()=>this.unsubscribe(n,t)}}let r=++this.nextSubId;return this.subscribers.set(n,[{id:r,callback:i}]),{unsubscribe:()=>this.unsubscribe(n,r)}}subscribeAll(e){let t=++this.nextSubId;return this.wildcardSubscribers.push({id:t,callback:e}),{unsubscribe:()=>this.unsubscribeWildcard(t)}}publish(e,t,i){let n=this.subscribers.get(`${e}:${t+""}`)??[];for(let e of n)try{e.callback(i)}catch{}for(let n of this.wildcardSubscribers)try{n.callback(e,t+"",i)}catch{}}subscriberCount(e,t){let i=`${e}:${t+""}`;return this.subscribers.get(i)?.length??0}subscribeScript(t,i,n){return this.subscribe(e(t),i,n)}publishScript(t,i,n){let s=e(t),r=this.subscribers.get(`${s}:${i+""}`)??[];for(let e of r)try{e.callback(n)}catch{}for(let e of this.wildcardSubscribers)try{e.callback(s,i+"",n)}catch{}}publishHandler(e,t){let i=e,n=this.subscribers.get(i+":invoke")??[];for(let e of n)try{e.callback(t)}catch{}for(let e of this.wildcardSubscribers)try{e.callback(i,"invoke",t)}catch{}}clear(){this.subscribers.clear(),this.wildcardSubscribers=[]}unsubscribe(e,t){let i=this.subscribers.get(e);if(!i)return;let n=i.findIndex(e=>e.id===t);-1!==n&&(i.splice(n,1),0===i.length&&this.subscribers.delete(e))}unsubscribeWildcard(e){let t=this.wildcardSubscribers.findIndex(t=>t.id===e);-1!==t&&this.wildcardSubscribers.splice(t,1)}},E=null!=(S=z())?M(C(S)):{},l=I(O(E,"default",{value:S,enumerable:1}),S),u={debug:0,info:1,warn:2,error:3},d=new class{constructor(){this.enabled=0,this.level="info"}configure(e){void 0!==e.enabled&&(this.enabled=e.enabled),void 0!==e.level&&(this.level=e.level)}debug(e,t,...i){this.log("debug",e,t,i)}info(e,t,...i){this.log("info",e,t,i)}warn(e,t,...i){this.log("warn",e,t,i)}error(e,t,...i){this.log("error",e,t,i)}log(e,t,i,n){if(!this.enabled||u[this.level]>u[e])return;let s=`[Pond:${t}]`,r=console[e]||console.log;n.length>0?r(s,i,...n):r(s,i)}},f=class{constructor(e){this.state="disconnected",this.stateListeners=[],this.sessionId=e.sessionId,this.bus=e.bus,this.client=new l.PondClient(e.endpoint),this.channel=this.client.createChannel("live/"+e.sessionId,{sid:e.sessionId,ver:e.version,ack:e.lastAck,loc:e.location}),this.channel.onMessage((e,t)=>{this.handleMessage(t)}),this.channel.onChannelStateChange(e=>{this.handleStateChange(e)})}get sid(){return this.sessionId}get connectionState(){return this.state}connect(){this.state="connecting",this.notifyStateChange(),this.channel.join(),this.client.connect()}disconnect(){this.channel.leave(),this.client.disconnect(),this.state="disconnected",this.notifyStateChange()}onStateChange(e){return this.stateListeners.push(e),()=>{let t=this.stateListeners.indexOf(e);-1!==t&&this.stateListeners.splice(t,1)}}send(e,t,i){this.sendMessage("evt",{t:e,sid:this.sessionId,a:t+"",p:i})}sendAck(e){this.sendMessage("ack",{t:a.Ack,sid:this.sessionId,seq:e})}sendHandler(e,t){this.sendMessage("evt",{t:e,sid:this.sessionId,a:"invoke",p:t})}sendScript(e,t){this.sendMessage("evt",{t:"script:"+e,sid:this.sessionId,a:"message",p:t})}handleMessage(e){if(d.info("TRANSPORT","Transport received message:",e),!r(e))return;let{seq:t,topic:i,event:n,data:s}=e;this.isValidTopic(i)&&this.publishToBus(i,n,s,t)}isValidTopic(e){return"router"===e||"dom"===e||"frame"===e||"ack"===e||e.startsWith("script:")}publishToBus(e,t,i,n){switch(e){case"frame":"patch"===t&&this.bus.publish("frame","patch",{seq:n,patches:i});break;case"router":"push"===t?this.bus.publish("router","push",i):"replace"===t?this.bus.publish("router","replace",i):"back"===t?this.bus.publish("router","back",void 0):"forward"===t&&this.bus.publish("router","forward",void 0);break;case"dom":"call"===t?this.bus.publish("dom","call",i):"set"===t?this.bus.publish("dom","set",i):"query"===t?this.bus.publish("dom","query",i):"async"===t&&this.bus.publish("dom","async",i);break;case"ack":"ack"===t&&this.bus.publish("ack","ack",i);break;default:e.startsWith("script:")&&"send"===t&&this.bus.publishScript(i.scriptId,"send",i)}}handleStateChange(e){switch(e){case l.ChannelState.JOINED:this.state="connected";break;case l.ChannelState.STALLED:this.state="stalled";break;case l.ChannelState.CLOSED:this.state="disconnected";break;case l.ChannelState.DECLINED:this.state="declined";break;case l.ChannelState.JOINING:case l.ChannelState.IDLE:this.state="connecting"}this.notifyStateChange()}notifyStateChange(){for(let e of this.stateListeners)try{e(this.state)}catch{}}sendMessage(e,t){d.info("TRANSPORT","Transport sending message:",e,t),this.channel.sendMessage(e,t)}},(p=class e{constructor(e,t){this.handlerStore=new WeakMap,this.scriptStore=new WeakMap,this.keyedElements=new Map,this.root=e,this.callbacks=t}apply(e){let t=[...e].sort((e,t)=>e.seq-t.seq);for(let e of t)this.applyPatch(e)}applyPatch(e){let t=this.resolvePath(e.path);if(t)switch(e.op){case"setText":this.setText(t,e.value);break;case"setComment":this.setComment(t,e.value);break;case"setAttr":this.setAttr(t,e.value);break;case"delAttr":this.delAttr(t,e.name);break;case"setStyle":this.setStyle(t,e.value);break;case"delStyle":this.delStyle(t,e.name);break;case"setStyleDecl":this.setStyleDecl(t,e.selector,e.name,e.value);break;case"delStyleDecl":this.delStyleDecl(t,e.selector,e.name);break;case"setHandlers":this.setHandlers(t,e.value);break;case"setScript":this.setScript(t,e.value);break;case"delScript":this.delScript(t);break;case"setRef":this.callbacks.onRef(e.value,t);break;case"delRef":this.callbacks.onRefDelete(e.value);break;case"replaceNode":this.replaceNode(t,e.value);break;case"addChild":this.addChild(t,e.index,e.value,e.path??[]);break;case"delChild":this.delChild(t,e.index);break;case"moveChild":this.moveChild(t,e.value,e.path??[])}}resolvePath(e){let t=this.root;if(e)for(let i of e){if(!t)return null;t=t.childNodes[i]??null}return t}setText(e,t){e.textContent=t}setComment(e,t){e.textContent=t}setAttr(e,t){for(let[i,n]of Object.entries(t))"class"===i?e instanceof SVGElement?e.setAttribute("class",n.join(" ")):e.className=n.join(" "):"value"===i&&e instanceof HTMLInputElement?e.value=n[0]??"":"checked"===i&&e instanceof HTMLInputElement?e.checked=n.length>0&&"false"!==n[0]:"selected"===i&&e instanceof HTMLOptionElement?e.selected=n.length>0&&"false"!==n[0]:e.setAttribute(i,0===n.length?"":n.join(" "))}delAttr(e,t){"value"===t&&e instanceof HTMLInputElement?e.value="":"checked"===t&&e instanceof HTMLInputElement?e.checked=0:"selected"===t&&e instanceof HTMLOptionElement?e.selected=0:e.removeAttribute(t)}setStyle(e,t){for(let[i,n]of Object.entries(t))e.style.setProperty(i,n)}delStyle(e,t){e.style.removeProperty(t)}setStyleDecl(e,t,i,n){let s=e.sheet;if(!s)return;let r=this.findOrCreateRule(s,t);r&&r.style.setProperty(i,n)}delStyleDecl(e,t,i){let n=e.sheet;if(!n)return;let s=this.findRule(n,t);s&&s.style.removeProperty(i)}findRule(e,t){for(let i=0;e.cssRules.length>i;i++){let n=e.cssRules[i];if(n instanceof CSSStyleRule&&n.selectorText===t)return n}return null}findOrCreateRule(e,t){let i=this.findRule(e,t);if(!i){let n=e.insertRule(t+" {}",e.cssRules.length);i=e.cssRules[n]}return i}setHandlers(e,t){let i=this.handlerStore.get(e);i&&i.forEach(e=>{e.cleanup&&e.cleanup()});let n=new Map;for(let i of t){let t=this.createHandler(e,i);n.set(i.event,t)}this.handlerStore.set(e,n)}createHandler(e,t){let i,n=null,s=0;i=t.debounce&&t.debounce>0?e=>{t.prevent&&e.cancelable&&e.preventDefault(),t.stop&&e.stopPropagation(),n&&clearTimeout(n),n=setTimeout(()=>{let i=this.extractEventData(e,t.props??[]);this.callbacks.onEvent(t.handler,i)},t.debounce)}:t.throttle&&t.throttle>0?e=>{t.prevent&&e.cancelable&&e.preventDefault(),t.stop&&e.stopPropagation();let i=Date.now();if(i-s>=t.throttle){s=i;let n=this.extractEventData(e,t.props??[]);this.callbacks.onEvent(t.handler,n)}}:e=>{t.prevent&&e.cancelable&&e.preventDefault(),t.stop&&e.stopPropagation();let i=this.extractEventData(e,t.props??[]);this.callbacks.onEvent(t.handler,i)};let r={};return t.passive&&(r.passive=1),t.once&&(r.once=1),t.capture&&(r.capture=1),e.addEventListener(t.event,i,r),{listener:i,cleanup:()=>{e.removeEventListener(t.event,i,r),n&&clearTimeout(n)}}}extractEventData(e,t){let i={};for(let n of t){let t=this.resolveProp(e,n);void 0!==t&&(i[n]=t)}return i}resolveProp(e,t){let i=t.split(".").map(e=>e.trim()).filter(Boolean);if(0===i.length)return;let n,s=i.shift();switch(s){case"event":n=e;break;case"target":n=e.target;break;case"currentTarget":n=e.currentTarget;break;default:n=e[s]}for(let e of i){if(null==n)return;try{n=n[e]}catch{return}}return this.serializeValue(n)}serializeValue(e){if(null==e)return null;let t=typeof e;if("string"===t||"number"===t||"boolean"===t)return e;if(Array.isArray(e)){let t=e.map(e=>this.serializeValue(e)).filter(e=>void 0!==e);return t.length>0?t:null}if(e instanceof Date)return e.toISOString();if(e instanceof DOMTokenList)return Array.from(e);if(!(e instanceof Node))try{return JSON.parse(JSON.stringify(e))}catch{return}}setScript(e,t){this.delScript(e),this.scriptStore.set(e,t.scriptId),this.callbacks.onScript(t,e)}delScript(e){let t=this.scriptStore.get(e);t&&(this.scriptStore.delete(e),this.callbacks.onScriptCleanup(t))}replaceNode(e,t){let i=this.createNode(t);i&&e.parentNode&&(this.cleanupTree(e),e.parentNode.replaceChild(i,e))}addChild(e,t,i,n){let s=this.createNode(i);if(s){if(i.key&&s instanceof Element){let e=`${n.join(",")}-${i.key}`;this.keyedElements.set(e,s)}e.insertBefore(s,e.childNodes[t]??null)}}delChild(e,t){let i=e.childNodes[t];i&&(this.cleanupTree(i),e.removeChild(i))}moveChild(e,t,i){let n=null;if(t.key){let s=`${i.join(",")}-${t.key}`;n=this.keyedElements.get(s)??null,n||(n=this.findElementBySignature(e,t.key))}n||(n=e.childNodes[t.fromIndex]??null),n&&(e.removeChild(n),e.insertBefore(n,e.childNodes[t.newIdx]??null))}findElementBySignature(e,t){if(t.startsWith("K:")){let i=t.slice(2);for(let t=0;e.childNodes.length>t;t++){let n=e.childNodes[t];if(n.nodeType===Node.ELEMENT_NODE&&n.getAttribute("data-key")===i)return n}return null}let i=t.match(/^E:(\w+)\|(\w+)=(.+)$/);if(!i)return null;let[,n,s,r]=i;for(let t=0;e.childNodes.length>t;t++){let i=e.childNodes[t];if(i.nodeType===Node.ELEMENT_NODE){let e=i;if(e.tagName.toLowerCase()===n&&e.getAttribute(s)===r)return e}}return null}cleanupTree(e){if(e.nodeType===Node.ELEMENT_NODE){let t=e,i=this.handlerStore.get(t);i&&(i.forEach(e=>{e.cleanup&&e.cleanup()}),this.handlerStore.delete(t));let n=this.scriptStore.get(t);n&&(this.scriptStore.delete(t),this.callbacks.onScriptCleanup(n));for(let t=0;e.childNodes.length>t;t++)this.cleanupTree(e.childNodes[t])}}createNode(t,i=0){if(void 0!==t.text)return document.createTextNode(t.text);if(void 0!==t.comment)return document.createComment(t.comment);if(!t.tag)return null;let n=e.SVG_TAGS.has(t.tag),s=i||n,r=s?document.createElementNS(e.SVG_NS,t.tag):document.createElement(t.tag);if(t.attrs&&this.setAttr(r,t.attrs),t.style&&this.setStyle(r,t.style),t.handlers&&t.handlers.length>0&&this.setHandlers(r,t.handlers),t.script&&this.setScript(r,t.script),t.refId&&this.callbacks.onRef(t.refId,r),t.unsafeHTML)r.innerHTML=t.unsafeHTML;else if(t.children){let e=s&&"foreignObject"!==t.tag;for(let i of t.children){let t=this.createNode(i,e);t&&r.appendChild(t)}}return r}}).SVG_NS="http://www.w3.org/2000/svg",p.SVG_TAGS=new Set(["svg","animate","animateMotion","animateTransform","circle","clipPath","defs","desc","ellipse","feBlend","feColorMatrix","feComponentTransfer","feComposite","feConvolveMatrix","feDiffuseLighting","feDisplacementMap","feDistantLight","feDropShadow","feFlood","feFuncA","feFuncB","feFuncG","feFuncR","feGaussianBlur","feImage","feMerge","feMergeNode","feMorphology","feOffset","fePointLight","feSpecularLighting","feSpotLight","feTile","feTurbulence","filter","foreignObject","g","image","line","linearGradient","marker","mask","metadata","mpath","path","pattern","polygon","polyline","radialGradient","rect","set","stop","switch","symbol","text","textPath","title","tspan","use","view"]),w=p,m=class{constructor(e){this.subscriptions=[],this.popstateHandler=null,this.bus=e.bus,this.transport=e.transport,this.resolveRef=e.resolveRef,this.setupDOMSubscriptions(),this.setupRouterSubscriptions(),this.setupPopstateListener()}destroy(){for(let e of this.subscriptions)e.unsubscribe();this.subscriptions.length=0,this.popstateHandler&&(window.removeEventListener("popstate",this.popstateHandler),this.popstateHandler=null)}setupDOMSubscriptions(){this.subscriptions.push(this.bus.subscribe("dom","call",e=>this.handleCall(e))),this.subscriptions.push(this.bus.subscribe("dom","set",e=>this.handleSet(e))),this.subscriptions.push(this.bus.subscribe("dom","query",e=>this.handleQuery(e))),this.subscriptions.push(this.bus.subscribe("dom","async",e=>this.handleAsync(e)))}setupRouterSubscriptions(){this.subscriptions.push(this.bus.subscribe("router","push",e=>this.handlePush(e))),this.subscriptions.push(this.bus.subscribe("router","replace",e=>this.handleReplace(e))),this.subscriptions.push(this.bus.subscribe("router","back",()=>this.handleBack())),this.subscriptions.push(this.bus.subscribe("router","forward",()=>this.handleForward()))}setupPopstateListener(){this.popstateHandler=()=>{let e={path:window.location.pathname,query:window.location.search.replace(/^\?/,""),hash:window.location.hash.replace(/^#/,"")};this.transport.send("router","popstate",e)},window.addEventListener("popstate",this.popstateHandler)}handleCall(e){let t=this.resolveRef(e.ref);if(!t)return;let i=t[e.method];"function"==typeof i&&i.apply(t,e.args??[])}handleSet(e){let t=this.resolveRef(e.ref);t&&(t[e.prop]=e.value)}handleQuery(e){let t=this.resolveRef(e.ref),i={requestId:e.requestId};if(!t)return i.error="ref not found: "+e.ref,void this.transport.send("dom","response",i);let n={};for(let i of e.selectors)n[i]=this.readProperty(t,i);i.values=n,this.transport.send("dom","response",i)}handleAsync(e){let t=this.resolveRef(e.ref),i={requestId:e.requestId};if(!t)return i.error="ref not found: "+e.ref,void this.transport.send("dom","response",i);let n=t[e.method];if("function"!=typeof n)return i.error="method not found: "+e.method,void this.transport.send("dom","response",i);Promise.resolve(n.apply(t,e.args??[])).then(e=>{i.result=this.serializeValue(e),this.transport.send("dom","response",i)}).catch(e=>{i.error=e instanceof Error?e.message:e+"",this.transport.send("dom","response",i)})}handlePush(e){let t=this.buildUrl(e);window.history.pushState({},"",t)}handleReplace(e){let t=this.buildUrl(e);window.history.replaceState({},"",t)}handleBack(){window.history.back()}handleForward(){window.history.forward()}buildUrl(e){let t=e.path;return e.query&&(t+="?"+e.query),e.hash&&(t+="#"+e.hash),t}readProperty(e,t){let i=t.split("."),n=e;for(let e of i){if(null==n)return;n=n[e]}return this.serializeValue(n)}serializeValue(e){if(null==e)return null;let t=typeof e;if("string"===t||"number"===t||"boolean"===t)return e;if(Array.isArray(e))return e.map(e=>this.serializeValue(e)).filter(e=>void 0!==e);if(e instanceof Date)return e.toISOString();if(e instanceof DOMTokenList)return Array.from(e);if(!(e instanceof Node))try{return JSON.parse(JSON.stringify(e))}catch{return}}},v=class{constructor(e){this.scripts=new Map,this.bus=e.bus,this.transport=e.transport}async execute(e,t){let{scriptId:i,script:n}=e;d.debug("Script","execute called",{scriptId:i,element:t.tagName,script:n.substring(0,100)+"..."}),this.cleanup(i);let s={eventHandlers:new Map,subscription:this.bus.subscribeScript(i,"send",e=>{d.debug("Script","server message received",{scriptId:i,event:e.event,data:e.data}),this.handleServerMessage(i,e.event,e.data)})},r={send:(e,t)=>{d.debug("Script","transport.send called",{scriptId:i,event:e,data:t}),this.transport.sendScript(i,{scriptId:i,event:e,data:t})},on:(e,t)=>{d.debug("Script","transport.on registered",{scriptId:i,event:e}),s.eventHandlers.set(e,t)}};try{d.debug("Script","creating function",{scriptId:i});let e=Function("element","transport",`return (${n})(element, transport);`);d.debug("Script","executing function",{scriptId:i});let o=await e(t,r);"function"==typeof o&&(d.debug("Script","cleanup function returned",{scriptId:i}),s.cleanup=o),this.scripts.set(i,s),d.debug("Script","execute complete",{scriptId:i,handlers:Array.from(s.eventHandlers.keys())})}catch(e){throw d.error("Script","execute failed",{scriptId:i,error:e+""}),s.subscription.unsubscribe(),e}}handleServerMessage(e,t,i){d.debug("Script","handleServerMessage",{scriptId:e,event:t,data:i});let n=this.scripts.get(e);if(!n)return void d.warn("Script","no instance found",{scriptId:e});let s=n.eventHandlers.get(t);if(s)try{d.debug("Script","invoking handler",{scriptId:e,event:t}),s(i)}catch(i){d.error("Script","handler error",{scriptId:e,event:t,error:i+""})}else d.warn("Script","no handler found",{scriptId:e,event:t,availableHandlers:Array.from(n.eventHandlers.keys())})}cleanup(e){d.debug("Script","cleanup called",{scriptId:e});let t=this.scripts.get(e);if(t){if(t.cleanup)try{d.debug("Script","running cleanup function",{scriptId:e}),t.cleanup()}catch(t){d.error("Script","cleanup function error",{scriptId:e,error:t+""})}t.subscription.unsubscribe(),this.scripts.delete(e),d.debug("Script","cleanup complete",{scriptId:e})}else d.debug("Script","cleanup: no instance found",{scriptId:e})}destroy(){for(let e of this.scripts.keys())this.cleanup(e)}},b="pond_reload_count",y="pond_reload_timestamp",g=class{constructor(e){this.refs=new Map,this.cseq=0,this.lastSeq=0,this.connectedState=0,this.lastSeq=e.seq,d.configure({enabled:e.debug??0,level:"debug"}),d.info("Runtime","Initializing",{sid:e.sessionId,ver:e.version}),this.bus=new c,this.transport=new f({endpoint:e.endpoint,sessionId:e.sessionId,version:e.version,lastAck:e.seq,location:e.location,bus:this.bus}),this.patcher=new w(e.root,{onEvent:(e,t)=>this.handleEvent(e,t),onRef:(e,t)=>{this.refs.set(e,t),d.debug("Runtime","Ref set",e)},onRefDelete:e=>{this.refs.delete(e),d.debug("Runtime","Ref deleted",e)},onScript:(e,t)=>this.handleScript(e,t),onScriptCleanup:e=>this.handleScriptCleanup(e)}),this.executor=new m({bus:this.bus,transport:this.transport,resolveRef:e=>this.refs.get(e)}),this.scripts=new v({bus:this.bus,transport:this.transport}),this.bus.subscribe("frame","patch",e=>this.handlePatch(e)),this.transport.onStateChange(e=>this.handleStateChange(e)),window.__POND_RUNTIME__=this}connect(){d.info("Runtime","Connecting"),this.transport.connect()}disconnect(){d.info("Runtime","Disconnecting"),this.transport.disconnect(),this.executor.destroy(),this.scripts.destroy(),this.bus.clear(),this.refs.clear()}connected(){return this.connectedState}get seq(){return this.lastSeq}handleBoot(e){d.info("Runtime","Boot received",{ver:e.ver,seq:e.seq,patches:e.patch?.length??0}),e.patch&&e.patch.length>0&&this.applyPatches(e.patch),this.lastSeq=e.seq,this.transport.sendAck(e.seq)}handleMessage(e){if(i(e))d.error("Runtime","Server error",{code:e.code,message:e.message});else if("object"!=typeof(t=e)||null===t||"resume_ok"!==t.t)var t;else this.handleResumeOK(e)}handlePatch(e){d.debug("Runtime","Patch received",{seq:e.seq,count:e.patches?.length??0}),e.patches&&e.patches.length>0&&this.applyPatches(e.patches),this.lastSeq=e.seq,this.transport.sendAck(e.seq)}handleResumeOK(e){d.info("Runtime","Resume OK",{from:e.from,to:e.to})}handleEvent(e,t){this.cseq++,d.debug("Runtime","Event",{handler:e,cseq:this.cseq});let i={...t,cseq:this.cseq};this.transport.sendHandler(e,i)}handleScript(e,t){d.debug("Runtime","Script execute",e.scriptId),this.scripts.execute(e,t).catch(t=>{d.error("Runtime","Script error",{scriptId:e.scriptId,error:t+""})})}handleScriptCleanup(e){d.debug("Runtime","Script cleanup",e),this.scripts.cleanup(e)}handleStateChange(e){d.debug("Runtime","Connection state",e);let t=this.connectedState;this.connectedState="connected"===e,!t&&this.connectedState?(d.info("Runtime","Connected"),this.clearReloadTracking()):t&&!this.connectedState&&d.warn("Runtime","Disconnected"),"declined"===e&&(d.warn("Runtime","Session declined - session expired or not found"),this.reloadWithJitter())}reloadWithJitter(){if(this.shouldEnterFailsafeMode())return d.error("Runtime","Entering failsafe mode - too many consecutive reloads"),void this.enterFailsafeMode();this.incrementReloadCount();let e=Math.floor(9e3*Math.random())+1e3;d.info("Runtime",`Reloading in ${e}ms`),setTimeout(()=>{window.location.reload()},e)}shouldEnterFailsafeMode(){try{let e=parseInt(sessionStorage.getItem(y)||"0",10),t=parseInt(sessionStorage.getItem(b)||"0",10);return Date.now()-e>6e4?0:t>=10}catch{return 0}}incrementReloadCount(){try{let e=parseInt(sessionStorage.getItem(y)||"0",10),t=Date.now();if(t-e>6e4)sessionStorage.setItem(b,"1");else{let e=parseInt(sessionStorage.getItem(b)||"0",10);sessionStorage.setItem(b,e+1+"")}sessionStorage.setItem(y,t+"")}catch{}}clearReloadTracking(){try{sessionStorage.removeItem(b),sessionStorage.removeItem(y)}catch{}}enterFailsafeMode(){try{sessionStorage.removeItem(b),sessionStorage.removeItem(y)}catch{}}applyPatches(e){this.patcher.apply(e)}},"u">typeof window&&"u">typeof document&&(window.location.href.endsWith("#")&&""===window.location.hash&&history.replaceState(null,"",window.location.pathname+window.location.search),"loading"===document.readyState?document.addEventListener("DOMContentLoaded",()=>o()):o()),(e=>{I(O({},"__esModule",{value:1}),e)})(Z)})();
\ No newline at end of file
+// LiveUI Client v1.0.0 - Built with esbuild
+"use strict";var LiveUIModule=(()=>{var tn=Object.create;var Te=Object.defineProperty;var nn=Object.getOwnPropertyDescriptor;var rn=Object.getOwnPropertyNames;var sn=Object.getPrototypeOf,on=Object.prototype.hasOwnProperty;var v=(r,e)=>()=>(e||r((e={exports:{}}).exports,e),e.exports),an=(r,e)=>{for(var t in e)Te(r,t,{get:e[t],enumerable:!0})},Ze=(r,e,t,n)=>{if(e&&typeof e=="object"||typeof e=="function")for(let i of rn(e))!on.call(r,i)&&i!==t&&Te(r,i,{get:()=>e[i],enumerable:!(n=nn(e,i))||n.enumerable});return r};var cn=(r,e,t)=>(t=r!=null?tn(sn(r)):{},Ze(e||!r||!r.__esModule?Te(t,"default",{value:r,enumerable:!0}):t,r)),ln=r=>Ze(Te({},"__esModule",{value:!0}),r);var Be=v(M=>{"use strict";var Fe=M&&M.__classPrivateFieldSet||function(r,e,t,n,i){if(n==="m")throw new TypeError("Private method is not writable");if(n==="a"&&!i)throw new TypeError("Private accessor was defined without a setter");if(typeof e=="function"?r!==e||!i:!e.has(r))throw new TypeError("Cannot write private member to an object whose class did not declare it");return n==="a"?i.call(r,t):i?i.value=t:e.set(r,t),t},X=M&&M.__classPrivateFieldGet||function(r,e,t,n){if(t==="a"&&!n)throw new TypeError("Private accessor was defined without a getter");if(typeof e=="function"?r!==e||!n:!e.has(r))throw new TypeError("Cannot read private member from an object whose class did not declare it");return t==="m"?n:t==="a"?n.call(r):n?n.value:e.get(r)},ue,I;Object.defineProperty(M,"__esModule",{value:!0});M.Subject=void 0;var un=class{constructor(){ue.set(this,void 0),I.set(this,void 0),Fe(this,ue,!1,"f"),Fe(this,I,new Set,"f")}get size(){return X(this,I,"f").size}subscribe(r){if(X(this,ue,"f"))throw new Error("Cannot subscribe to a closed subject");return X(this,I,"f").add(r),()=>X(this,I,"f").delete(r)}publish(r){X(this,I,"f").forEach(e=>e(r))}close(){X(this,I,"f").clear(),Fe(this,ue,!0,"f")}};M.Subject=un;ue=new WeakMap,I=new WeakMap});var ot=v(D=>{"use strict";var st=D&&D.__classPrivateFieldSet||function(r,e,t,n,i){if(n==="m")throw new TypeError("Private method is not writable");if(n==="a"&&!i)throw new TypeError("Private accessor was defined without a setter");if(typeof e=="function"?r!==e||!i:!e.has(r))throw new TypeError("Cannot write private member to an object whose class did not declare it");return n==="a"?i.call(r,t):i?i.value=t:e.set(r,t),t},Ue=D&&D.__classPrivateFieldGet||function(r,e,t,n){if(t==="a"&&!n)throw new TypeError("Private accessor was defined without a getter");if(typeof e=="function"?r!==e||!n:!e.has(r))throw new TypeError("Cannot read private member from an object whose class did not declare it");return t==="m"?n:t==="a"?n.call(r):n?n.value:e.get(r)},W;Object.defineProperty(D,"__esModule",{value:!0});D.BehaviorSubject=void 0;var hn=Be(),pn=class extends hn.Subject{constructor(r){super(),W.set(this,void 0),st(this,W,r,"f")}get value(){return Ue(this,W,"f")}publish(r){st(this,W,r,"f"),super.publish(r)}subscribe(r){return Ue(this,W,"f")&&r(Ue(this,W,"f")),super.subscribe(r)}};D.BehaviorSubject=pn;W=new WeakMap});var ct=v(L=>{"use strict";var fn=L&&L.__createBinding||(Object.create?function(r,e,t,n){n===void 0&&(n=t);var i=Object.getOwnPropertyDescriptor(e,t);(!i||("get"in i?!e.__esModule:i.writable||i.configurable))&&(i={enumerable:!0,get:function(){return e[t]}}),Object.defineProperty(r,n,i)}:function(r,e,t,n){n===void 0&&(n=t),r[n]=e[t]}),at=L&&L.__exportStar||function(r,e){for(var t in r)t!=="default"&&!Object.prototype.hasOwnProperty.call(e,t)&&fn(e,r,t)};Object.defineProperty(L,"__esModule",{value:!0});at(ot(),L);at(Be(),L)});var Ke=v(f=>{"use strict";Object.defineProperty(f,"__esModule",{value:!0});f.PubSubEvents=f.Events=f.ChannelReceiver=f.SystemSender=f.ErrorTypes=f.ChannelState=f.ClientActions=f.ServerActions=f.PresenceEventTypes=void 0;var lt;(function(r){r.JOIN="JOIN",r.LEAVE="LEAVE",r.UPDATE="UPDATE"})(lt||(f.PresenceEventTypes=lt={}));var dt;(function(r){r.PRESENCE="PRESENCE",r.SYSTEM="SYSTEM",r.BROADCAST="BROADCAST",r.ERROR="ERROR",r.CONNECT="CONNECT"})(dt||(f.ServerActions=dt={}));var ut;(function(r){r.JOIN_CHANNEL="JOIN_CHANNEL",r.LEAVE_CHANNEL="LEAVE_CHANNEL",r.BROADCAST="BROADCAST"})(ut||(f.ClientActions=ut={}));var ht;(function(r){r.IDLE="IDLE",r.JOINING="JOINING",r.JOINED="JOINED",r.STALLED="STALLED",r.CLOSED="CLOSED",r.DECLINED="DECLINED"})(ht||(f.ChannelState=ht={}));var pt;(function(r){r.UNAUTHORIZED_CONNECTION="UNAUTHORIZED_CONNECTION",r.UNAUTHORIZED_JOIN_REQUEST="UNAUTHORIZED_JOIN_REQUEST",r.UNAUTHORIZED_BROADCAST="UNAUTHORIZED_BROADCAST",r.INVALID_MESSAGE="INVALID_MESSAGE",r.HANDLER_NOT_FOUND="HANDLER_NOT_FOUND",r.PRESENCE_ERROR="PRESENCE_ERROR",r.CHANNEL_ERROR="CHANNEL_ERROR",r.ENDPOINT_ERROR="ENDPOINT_ERROR",r.INTERNAL_SERVER_ERROR="INTERNAL_SERVER_ERROR"})(pt||(f.ErrorTypes=pt={}));var ft;(function(r){r.ENDPOINT="ENDPOINT",r.CHANNEL="CHANNEL"})(ft||(f.SystemSender=ft={}));var vt;(function(r){r.ALL_USERS="ALL_USERS",r.ALL_EXCEPT_SENDER="ALL_EXCEPT_SENDER"})(vt||(f.ChannelReceiver=vt={}));var St;(function(r){r.ACKNOWLEDGE="ACKNOWLEDGE",r.CONNECTION="CONNECTION",r.UNAUTHORIZED="UNAUTHORIZED"})(St||(f.Events=St={}));var bt;(function(r){r.MESSAGE="MESSAGE",r.PRESENCE="PRESENCE",r.GET_PRESENCE="GET_PRESENCE"})(bt||(f.PubSubEvents=bt={}))});var gt=v(Ge=>{"use strict";Object.defineProperty(Ge,"__esModule",{value:!0});Ge.uuid=vn;function vn(){return"xxxxxxxx-xxxx-4xxx-yxxx-xxxxxxxxxxxx".replace(/[xy]/g,r=>{let e=Math.random()*16|0;return(r==="x"?e:e&3|8).toString(16)})}});var yt=v(mt=>{"use strict";Object.defineProperty(mt,"__esModule",{value:!0})});var Tt=v(q=>{"use strict";var Sn=q&&q.__createBinding||(Object.create?function(r,e,t,n){n===void 0&&(n=t);var i=Object.getOwnPropertyDescriptor(e,t);(!i||("get"in i?!e.__esModule:i.writable||i.configurable))&&(i={enumerable:!0,get:function(){return e[t]}}),Object.defineProperty(r,n,i)}:function(r,e,t,n){n===void 0&&(n=t),r[n]=e[t]}),Et=q&&q.__exportStar||function(r,e){for(var t in r)t!=="default"&&!Object.prototype.hasOwnProperty.call(e,t)&&Sn(e,r,t)};Object.defineProperty(q,"__esModule",{value:!0});Et(gt(),q);Et(yt(),q)});var wt=v(g=>{"use strict";Object.defineProperty(g,"__esModule",{value:!0});g.channelEventSchema=g.serverMessageSchema=g.presenceMessageSchema=g.clientMessageSchema=g.ValidationError=void 0;var _=Ke(),h=class extends Error{constructor(r,e){super(e?`${e}: ${r}`:r),this.path=e,this.name="ValidationError"}};g.ValidationError=h;function Ct(r){return typeof r=="object"&&r!==null&&!Array.isArray(r)}function bn(r){return typeof r=="string"}function gn(r){return Array.isArray(r)}function mn(r){return Ct(r)?Object.keys(r).every(e=>typeof e=="string"):!1}function H(r,e){if(!bn(r))throw new h(`Expected string, got ${typeof r}`,e)}function he(r,e){if(!Ct(r))throw new h(`Expected object, got ${typeof r}`,e)}function Pe(r,e){if(!mn(r))throw new h(`Expected record with string keys, got ${typeof r}`,e)}function yn(r,e){if(!gn(r))throw new h(`Expected array, got ${typeof r}`,e)}function _t(r,e,t){let n=Object.values(e);if(!n.includes(r))throw new h(`Expected one of [${n.join(", ")}], got ${JSON.stringify(r)}`,t)}g.clientMessageSchema={parse(r){he(r,"clientMessage");let e=r;if(!("event"in e))throw new h("Missing required field","event");if(!("requestId"in e))throw new h("Missing required field","requestId");if(!("channelName"in e))throw new h("Missing required field","channelName");if(!("payload"in e))throw new h("Missing required field","payload");if(!("action"in e))throw new h("Missing required field","action");return H(e.event,"event"),H(e.requestId,"requestId"),H(e.channelName,"channelName"),Pe(e.payload,"payload"),_t(e.action,_.ClientActions,"action"),{event:e.event,requestId:e.requestId,channelName:e.channelName,payload:e.payload,action:e.action}}};g.presenceMessageSchema={parse(r){he(r,"presenceMessage");let e=r;if(!("requestId"in e))throw new h("Missing required field","requestId");if(!("channelName"in e))throw new h("Missing required field","channelName");if(!("event"in e))throw new h("Missing required field","event");if(!("action"in e))throw new h("Missing required field","action");if(!("payload"in e))throw new h("Missing required field","payload");if(H(e.requestId,"requestId"),H(e.channelName,"channelName"),_t(e.event,_.PresenceEventTypes,"event"),e.action!==_.ServerActions.PRESENCE)throw new h(`Expected ${_.ServerActions.PRESENCE}, got ${JSON.stringify(e.action)}`,"action");he(e.payload,"payload");let t=e.payload;if(!("presence"in t))throw new h("Missing required field","payload.presence");if(!("changed"in t))throw new h("Missing required field","payload.changed");return yn(t.presence,"payload.presence"),t.presence.forEach((n,i)=>{Pe(n,`payload.presence[${i}]`)}),Pe(t.changed,"payload.changed"),{requestId:e.requestId,channelName:e.channelName,event:e.event,action:_.ServerActions.PRESENCE,payload:{presence:t.presence,changed:t.changed}}}};g.serverMessageSchema={parse(r){he(r,"serverMessage");let e=r;if(!("event"in e))throw new h("Missing required field","event");if(!("requestId"in e))throw new h("Missing required field","requestId");if(!("channelName"in e))throw new h("Missing required field","channelName");if(!("payload"in e))throw new h("Missing required field","payload");if(!("action"in e))throw new h("Missing required field","action");H(e.event,"event"),H(e.requestId,"requestId"),H(e.channelName,"channelName"),Pe(e.payload,"payload");let t=[_.ServerActions.BROADCAST,_.ServerActions.CONNECT,_.ServerActions.ERROR,_.ServerActions.SYSTEM];if(!t.includes(e.action))throw new h(`Expected one of [${t.join(", ")}], got ${JSON.stringify(e.action)}`,"action");return{event:e.event,requestId:e.requestId,channelName:e.channelName,payload:e.payload,action:e.action}}};g.channelEventSchema={parse(r){he(r,"channelEvent");let e=r;if(!("action"in e))throw new h("Missing required field","action");return e.action===_.ServerActions.PRESENCE?g.presenceMessageSchema.parse(r):g.serverMessageSchema.parse(r)}}});var Z=v(w=>{"use strict";var En=w&&w.__createBinding||(Object.create?function(r,e,t,n){n===void 0&&(n=t);var i=Object.getOwnPropertyDescriptor(e,t);(!i||("get"in i?!e.__esModule:i.writable||i.configurable))&&(i={enumerable:!0,get:function(){return e[t]}}),Object.defineProperty(r,n,i)}:function(r,e,t,n){n===void 0&&(n=t),r[n]=e[t]}),xe=w&&w.__exportStar||function(r,e){for(var t in r)t!=="default"&&!Object.prototype.hasOwnProperty.call(e,t)&&En(e,r,t)};Object.defineProperty(w,"__esModule",{value:!0});xe(ct(),w);xe(Ke(),w);xe(Tt(),w);xe(wt(),w)});var Y=v(Ae=>{"use strict";Object.defineProperty(Ae,"__esModule",{value:!0});Ae.ConnectionState=void 0;var Nt;(function(r){r.DISCONNECTED="disconnected",r.CONNECTING="connecting",r.CONNECTED="connected"})(Nt||(Ae.ConnectionState=Nt={}))});var $e=v(F=>{"use strict";var E=F&&F.__classPrivateFieldSet||function(r,e,t,n,i){if(n==="m")throw new TypeError("Private method is not writable");if(n==="a"&&!i)throw new TypeError("Private accessor was defined without a setter");if(typeof e=="function"?r!==e||!i:!e.has(r))throw new TypeError("Cannot write private member to an object whose class did not declare it");return n==="a"?i.call(r,t):i?i.value=t:e.set(r,t),t},l=F&&F.__classPrivateFieldGet||function(r,e,t,n){if(t==="a"&&!n)throw new TypeError("Private accessor was defined without a getter");if(typeof e=="function"?r!==e||!n:!e.has(r))throw new TypeError("Cannot read private member from an object whose class did not declare it");return t==="m"?n:t==="a"?n.call(r):n?n.value:e.get(r)},m,x,$,pe,re,j,fe,ee,te,S,Pt,xt,We,Oe,ne;Object.defineProperty(F,"__esModule",{value:!0});F.Channel=void 0;var u=Z(),ke=Y(),Tn=class{constructor(r,e,t,n){m.add(this),x.set(this,void 0),$.set(this,void 0),pe.set(this,void 0),re.set(this,void 0),j.set(this,void 0),fe.set(this,void 0),ee.set(this,void 0),te.set(this,void 0),S.set(this,void 0),E(this,x,t,"f"),E(this,$,[],"f"),E(this,pe,[],"f"),E(this,fe,n,"f"),E(this,j,r,"f"),E(this,te,e,"f"),E(this,ee,new u.Subject,"f"),E(this,S,new u.BehaviorSubject(u.ChannelState.IDLE),"f"),E(this,re,()=>{},"f")}get channelState(){return l(this,S,"f").value}get presence(){return l(this,pe,"f")}acknowledge(r){l(this,S,"f").publish(u.ChannelState.JOINED),l(this,m,"m",xt).call(this,r),l(this,m,"m",Pt).call(this)}decline(r){l(this,S,"f").publish(u.ChannelState.DECLINED),E(this,$,[],"f")}join(){let r={action:u.ClientActions.JOIN_CHANNEL,event:u.ClientActions.JOIN_CHANNEL,payload:l(this,fe,"f"),channelName:l(this,x,"f"),requestId:(0,u.uuid)()};if(l(this,S,"f").value!==u.ChannelState.JOINED)if(l(this,S,"f").publish(u.ChannelState.JOINING),l(this,te,"f").value===ke.ConnectionState.CONNECTED)l(this,j,"f").call(this,r);else{let e=l(this,te,"f").subscribe(t=>{t===ke.ConnectionState.CONNECTED&&(e(),l(this,S,"f").value===u.ChannelState.JOINING&&l(this,j,"f").call(this,r))})}}leave(){let r={action:u.ClientActions.LEAVE_CHANNEL,event:u.ClientActions.LEAVE_CHANNEL,channelName:l(this,x,"f"),requestId:(0,u.uuid)(),payload:{}};l(this,m,"m",Oe).call(this,r),l(this,S,"f").publish(u.ChannelState.CLOSED),l(this,re,"f").call(this)}onChannelStateChange(r){return l(this,S,"f").subscribe(e=>{r(e)})}onJoin(r){return l(this,m,"m",ne).call(this,(e,t)=>{if(e===u.PresenceEventTypes.JOIN)return r(t.changed)})}onLeave(r){return l(this,m,"m",ne).call(this,(e,t)=>{if(e===u.PresenceEventTypes.LEAVE)return r(t.changed)})}onMessage(r){return l(this,m,"m",We).call(this,(e,t)=>{r(e,t)})}onMessageEvent(r,e){return this.onMessage((t,n)=>{if(t===r)return e(n)})}onPresenceChange(r){return l(this,m,"m",ne).call(this,(e,t)=>{if(e===u.PresenceEventTypes.UPDATE)return r(t)})}onUsersChange(r){return l(this,m,"m",ne).call(this,(e,t)=>r(t.presence))}sendMessage(r,e){let t=(0,u.uuid)(),n={action:u.ClientActions.BROADCAST,channelName:l(this,x,"f"),requestId:t,event:r,payload:e};l(this,m,"m",Oe).call(this,n)}sendForResponse(r,e){let t=(0,u.uuid)();return new Promise(n=>{let i=l(this,m,"m",We).call(this,(o,a,d)=>{t===d&&(n(a),i())}),s={action:u.ClientActions.BROADCAST,channelName:l(this,x,"f"),requestId:t,event:r,payload:e};l(this,m,"m",Oe).call(this,s)})}};F.Channel=Tn;x=new WeakMap,$=new WeakMap,pe=new WeakMap,re=new WeakMap,j=new WeakMap,fe=new WeakMap,ee=new WeakMap,te=new WeakMap,S=new WeakMap,m=new WeakSet,Pt=function(){l(this,$,"f").forEach(e=>l(this,j,"f").call(this,e)),E(this,$,[],"f")},xt=function(e){l(this,re,"f").call(this);let t=e.subscribe(s=>{s.channelName===l(this,x,"f")&&this.channelState===u.ChannelState.JOINED&&l(this,ee,"f").publish(s)}),n=l(this,te,"f").subscribe(s=>{if(s===ke.ConnectionState.CONNECTED&&l(this,S,"f").value===u.ChannelState.STALLED){let o={action:u.ClientActions.JOIN_CHANNEL,event:u.ClientActions.JOIN_CHANNEL,payload:l(this,fe,"f"),channelName:l(this,x,"f"),requestId:(0,u.uuid)()};l(this,j,"f").call(this,o)}else s!==ke.ConnectionState.CONNECTED&&l(this,S,"f").value===u.ChannelState.JOINED&&l(this,S,"f").publish(u.ChannelState.STALLED)}),i=l(this,m,"m",ne).call(this,(s,o)=>{E(this,pe,o.presence,"f")});E(this,re,()=>{t(),n(),i()},"f")},We=function(e){return l(this,ee,"f").subscribe(t=>{if(t.action!==u.ServerActions.PRESENCE)return e(t.event,t.payload,t.requestId)})},Oe=function(e){if(l(this,S,"f").value===u.ChannelState.JOINED)return l(this,j,"f").call(this,e);l(this,$,"f").push(e)},ne=function(e){return l(this,ee,"f").subscribe(t=>{if(t.action===u.ServerActions.PRESENCE)return e(t.event,t.payload)})}});var Ve=v(B=>{"use strict";var Cn=B&&B.__classPrivateFieldSet||function(r,e,t,n,i){if(n==="m")throw new TypeError("Private method is not writable");if(n==="a"&&!i)throw new TypeError("Private accessor was defined without a setter");if(typeof e=="function"?r!==e||!i:!e.has(r))throw new TypeError("Cannot write private member to an object whose class did not declare it");return n==="a"?i.call(r,t):i?i.value=t:e.set(r,t),t},y=B&&B.__classPrivateFieldGet||function(r,e,t,n){if(t==="a"&&!n)throw new TypeError("Private accessor was defined without a getter");if(typeof e=="function"?r!==e||!n:!e.has(r))throw new TypeError("Cannot read private member from an object whose class did not declare it");return t==="m"?n:t==="a"?n.call(r):n?n.value:e.get(r)},N,A,Re,Je,At,Ot,kt;Object.defineProperty(B,"__esModule",{value:!0});B.PondClient=void 0;var O=Z(),Rt=$e(),ie=Y(),_n=1e4,wn=3e4,Nn=class{constructor(r,e={},t={}){var n,i;N.add(this),A.set(this,void 0);let s;try{s=new URL(r)}catch{s=new URL(window.location.toString()),s.pathname=r}this._disconnecting=!1,this._reconnectAttempts=0;let o=new URLSearchParams(e);s.search=o.toString();let a=s.protocol==="https:"?"wss:":"ws:";s.protocol!=="wss:"&&s.protocol!=="ws:"&&(s.protocol=a),this._address=s,this._options={connectionTimeout:(n=t.connectionTimeout)!==null&&n!==void 0?n:_n,maxReconnectDelay:(i=t.maxReconnectDelay)!==null&&i!==void 0?i:wn,pingInterval:t.pingInterval},Cn(this,A,new Map,"f"),this._broadcaster=new O.Subject,this._connectionState=new O.BehaviorSubject(ie.ConnectionState.DISCONNECTED),this._errorSubject=new O.Subject,y(this,N,"m",kt).call(this)}connect(){this._disconnecting=!1,this._connectionState.publish(ie.ConnectionState.CONNECTING);let r=new WebSocket(this._address.toString());this._connectionTimeoutId=setTimeout(()=>{if(r.readyState===WebSocket.CONNECTING){let e=new Error("Connection timeout");this._errorSubject.publish(e),r.close()}},this._options.connectionTimeout),r.onopen=()=>{y(this,N,"m",Re).call(this),this._reconnectAttempts=0,this._options.pingInterval&&(this._pingIntervalId=setInterval(()=>{r.readyState===WebSocket.OPEN&&r.send(JSON.stringify({action:"ping"}))},this._options.pingInterval))},r.onmessage=e=>{let t=e.data.trim().split(`
+`);for(let n of t)if(n.trim()){let i=JSON.parse(n),s=O.channelEventSchema.parse(i);this._broadcaster.publish(s)}},r.onerror=e=>{let t=new Error("WebSocket error");this._errorSubject.publish(t),r.close()},r.onclose=()=>{if(y(this,N,"m",Re).call(this),this._connectionState.publish(ie.ConnectionState.DISCONNECTED),this._disconnecting)return;let e=Math.min(1e3*Math.pow(2,this._reconnectAttempts),this._options.maxReconnectDelay);this._reconnectAttempts++,setTimeout(()=>{this.connect()},e)},this._socket=r}getState(){return this._connectionState.value}disconnect(){var r;y(this,N,"m",Re).call(this),this._disconnecting=!0,this._connectionState.publish(ie.ConnectionState.DISCONNECTED),(r=this._socket)===null||r===void 0||r.close(),y(this,A,"f").clear()}createChannel(r,e){let t=y(this,A,"f").get(r);if(t&&t.channelState!==O.ChannelState.CLOSED)return t;let n=y(this,N,"m",Je).call(this),i=new Rt.Channel(n,this._connectionState,r,e||{});return y(this,A,"f").set(r,i),i}onConnectionChange(r){return this._connectionState.subscribe(r)}onError(r){return this._errorSubject.subscribe(r)}};B.PondClient=Nn;A=new WeakMap,N=new WeakSet,Re=function(){this._connectionTimeoutId&&(clearTimeout(this._connectionTimeoutId),this._connectionTimeoutId=void 0),this._pingIntervalId&&(clearInterval(this._pingIntervalId),this._pingIntervalId=void 0)},Je=function(){return e=>{this._connectionState.value===ie.ConnectionState.CONNECTED&&this._socket.send(JSON.stringify(e))}},At=function(e){var t;let n=(t=y(this,A,"f").get(e.channelName))!==null&&t!==void 0?t:new Rt.Channel(y(this,N,"m",Je).call(this),this._connectionState,e.channelName,{});y(this,A,"f").set(e.channelName,n),n.acknowledge(this._broadcaster)},Ot=function(e){let t=y(this,A,"f").get(e.channelName);if(t){let n=e.payload;t.decline(n)}},kt=function(){this._broadcaster.subscribe(e=>{e.event===O.Events.ACKNOWLEDGE?y(this,N,"m",At).call(this,e):e.event===O.Events.UNAUTHORIZED?y(this,N,"m",Ot).call(this,e):e.event===O.Events.CONNECTION&&e.action===O.ServerActions.CONNECT&&this._connectionState.publish(ie.ConnectionState.CONNECTED)})}});var Ht=v(U=>{"use strict";var Pn=U&&U.__classPrivateFieldSet||function(r,e,t,n,i){if(n==="m")throw new TypeError("Private method is not writable");if(n==="a"&&!i)throw new TypeError("Private accessor was defined without a setter");if(typeof e=="function"?r!==e||!i:!e.has(r))throw new TypeError("Cannot write private member to an object whose class did not declare it");return n==="a"?i.call(r,t):i?i.value=t:e.set(r,t),t},b=U&&U.__classPrivateFieldGet||function(r,e,t,n){if(t==="a"&&!n)throw new TypeError("Private accessor was defined without a getter");if(typeof e=="function"?r!==e||!n:!e.has(r))throw new TypeError("Cannot read private member from an object whose class did not declare it");return t==="m"?n:t==="a"?n.call(r):n?n.value:e.get(r)},T,k,Dt,Ie,ze,It,Mt,Lt;Object.defineProperty(U,"__esModule",{value:!0});U.SSEClient=void 0;var C=Z(),qt=$e(),se=Y(),xn=1e4,An=3e4,On=class{constructor(r,e={},t={}){var n,i;T.add(this),k.set(this,void 0);let s;try{s=new URL(r)}catch{s=new URL(window.location.toString()),s.pathname=r}this._disconnecting=!1,this._reconnectAttempts=0;let o=new URLSearchParams(e);s.search=o.toString(),s.protocol!=="https:"&&s.protocol!=="http:"&&(s.protocol=window.location.protocol),this._address=s,this._postAddress=new URL(s.toString()),this._options={connectionTimeout:(n=t.connectionTimeout)!==null&&n!==void 0?n:xn,maxReconnectDelay:(i=t.maxReconnectDelay)!==null&&i!==void 0?i:An,pingInterval:t.pingInterval,withCredentials:t.withCredentials},Pn(this,k,new Map,"f"),this._broadcaster=new C.Subject,this._connectionState=new C.BehaviorSubject(se.ConnectionState.DISCONNECTED),this._errorSubject=new C.Subject,b(this,T,"m",Lt).call(this)}connect(){var r;this._disconnecting=!1,this._connectionState.publish(se.ConnectionState.CONNECTING);let e=new EventSource(this._address.toString(),{withCredentials:(r=this._options.withCredentials)!==null&&r!==void 0?r:!1});this._connectionTimeoutId=setTimeout(()=>{if(e.readyState===EventSource.CONNECTING){let t=new Error("Connection timeout");this._errorSubject.publish(t),e.close()}},this._options.connectionTimeout),e.onopen=()=>{b(this,T,"m",Ie).call(this),this._reconnectAttempts=0},e.onmessage=t=>{b(this,T,"m",Dt).call(this,t.data)},e.onerror=()=>{let t=new Error("SSE connection error");if(this._errorSubject.publish(t),e.close(),b(this,T,"m",Ie).call(this),this._connectionState.publish(se.ConnectionState.DISCONNECTED),this._disconnecting)return;let n=Math.min(1e3*Math.pow(2,this._reconnectAttempts),this._options.maxReconnectDelay);this._reconnectAttempts++,setTimeout(()=>{this.connect()},n)},this._eventSource=e}getState(){return this._connectionState.value}getConnectionId(){return this._connectionId}disconnect(){var r;b(this,T,"m",Ie).call(this),this._disconnecting=!0,this._connectionState.publish(se.ConnectionState.DISCONNECTED),this._connectionId&&fetch(this._postAddress.toString(),{method:"DELETE",headers:{"X-Connection-ID":this._connectionId},credentials:this._options.withCredentials?"include":"same-origin"}).catch(()=>{}),(r=this._eventSource)===null||r===void 0||r.close(),this._connectionId=void 0,b(this,k,"f").clear()}createChannel(r,e){let t=b(this,k,"f").get(r);if(t&&t.channelState!==C.ChannelState.CLOSED)return t;let n=b(this,T,"m",ze).call(this),i=new qt.Channel(n,this._connectionState,r,e||{});return b(this,k,"f").set(r,i),i}onConnectionChange(r){return this._connectionState.subscribe(r)}onError(r){return this._errorSubject.subscribe(r)}};U.SSEClient=On;k=new WeakMap,T=new WeakSet,Dt=function(e){try{let t=e.trim().split(`
+`);for(let n of t)if(n.trim()){let i=JSON.parse(n),s=C.channelEventSchema.parse(i);s.event===C.Events.CONNECTION&&s.action===C.ServerActions.CONNECT&&s.payload&&typeof s.payload=="object"&&"connectionId"in s.payload&&(this._connectionId=s.payload.connectionId),this._broadcaster.publish(s)}}catch(t){this._errorSubject.publish(t instanceof Error?t:new Error("Failed to parse SSE message"))}},Ie=function(){this._connectionTimeoutId&&(clearTimeout(this._connectionTimeoutId),this._connectionTimeoutId=void 0)},ze=function(){return e=>{this._connectionState.value===se.ConnectionState.CONNECTED&&this._connectionId&&fetch(this._postAddress.toString(),{method:"POST",headers:{"Content-Type":"application/json","X-Connection-ID":this._connectionId},body:JSON.stringify(e),credentials:this._options.withCredentials?"include":"same-origin"}).catch(t=>{this._errorSubject.publish(t instanceof Error?t:new Error("Failed to send message"))})}},It=function(e){var t;let n=(t=b(this,k,"f").get(e.channelName))!==null&&t!==void 0?t:new qt.Channel(b(this,T,"m",ze).call(this),this._connectionState,e.channelName,{});b(this,k,"f").set(e.channelName,n),n.acknowledge(this._broadcaster)},Mt=function(e){let t=b(this,k,"f").get(e.channelName);if(t){let n=e.payload;t.decline(n)}},Lt=function(){this._broadcaster.subscribe(e=>{e.event===C.Events.ACKNOWLEDGE?b(this,T,"m",It).call(this,e):e.event===C.Events.UNAUTHORIZED?b(this,T,"m",Mt).call(this,e):e.event===C.Events.CONNECTION&&e.action===C.ServerActions.CONNECT&&this._connectionState.publish(se.ConnectionState.CONNECTED)})}});var Bt=v((Ir,Ft)=>{var jt=function(){if(typeof self=="object"&&self)return self;if(typeof window=="object"&&window)return window;throw new Error("Unable to resolve global `this`")};Ft.exports=function(){if(this)return this;if(typeof globalThis=="object"&&globalThis)return globalThis;try{Object.defineProperty(Object.prototype,"__global__",{get:function(){return this},configurable:!0})}catch{return jt()}try{return __global__||jt()}finally{delete Object.prototype.__global__}}()});var Ut=v((Mr,kn)=>{kn.exports={name:"websocket",description:"Websocket Client & Server Library implementing the WebSocket protocol as specified in RFC 6455.",keywords:["websocket","websockets","socket","networking","comet","push","RFC-6455","realtime","server","client"],author:"Brian McKelvey <theturtle32@gmail.com> (https://github.com/theturtle32)",contributors:["I\xF1aki Baz Castillo <ibc@aliax.net> (http://dev.sipdoc.net)"],version:"1.0.35",repository:{type:"git",url:"https://github.com/theturtle32/WebSocket-Node.git"},homepage:"https://github.com/theturtle32/WebSocket-Node",engines:{node:">=4.0.0"},dependencies:{bufferutil:"^4.0.1",debug:"^2.2.0","es5-ext":"^0.10.63","typedarray-to-buffer":"^3.1.5","utf-8-validate":"^5.0.2",yaeti:"^0.0.6"},devDependencies:{"buffer-equal":"^1.0.0",gulp:"^4.0.2","gulp-jshint":"^2.0.4","jshint-stylish":"^2.2.1",jshint:"^2.0.0",tape:"^4.9.1"},config:{verbose:!1},scripts:{test:"tape test/unit/*.js",gulp:"gulp"},main:"lib/browser.js",directories:{lib:"./lib"},browser:"lib/browser.js",license:"Apache-2.0"}});var Gt=v((Dr,Kt)=>{Kt.exports=Ut().version});var Jt=v((Lr,$t)=>{var J;if(typeof globalThis=="object")J=globalThis;else try{J=Bt()}catch{}finally{if(!J&&typeof window<"u"&&(J=window),!J)throw new Error("Could not determine global this")}var ve=J.WebSocket||J.MozWebSocket,Rn=Gt();function Wt(r,e){var t;return e?t=new ve(r,e):t=new ve(r),t}ve&&["CONNECTING","OPEN","CLOSING","CLOSED"].forEach(function(r){Object.defineProperty(Wt,r,{get:function(){return ve[r]}})});$t.exports={w3cwebsocket:ve?Wt:null,version:Rn}});var Qt=v(oe=>{"use strict";var Vt=oe&&oe.__classPrivateFieldGet||function(r,e,t,n){if(t==="a"&&!n)throw new TypeError("Private accessor was defined without a getter");if(typeof e=="function"?r!==e||!n:!e.has(r))throw new TypeError("Cannot read private member from an object whose class did not declare it");return t==="m"?n:t==="a"?n.call(r):n?n.value:e.get(r)},Me,Xe;Object.defineProperty(oe,"__esModule",{value:!0});oe.PondClient=void 0;var In=Z(),Mn=Ve(),zt=Y(),Qe=Jt().w3cwebsocket,Dn=class extends Mn.PondClient{constructor(){super(...arguments),Me.add(this)}connect(){this._disconnecting=!1,this._connectionState.publish(zt.ConnectionState.CONNECTING);let r=new Qe(this._address.toString());this._connectionTimeoutId=setTimeout(()=>{if(r.readyState===Qe.CONNECTING){let e=new Error("Connection timeout");this._errorSubject.publish(e),r.close()}},this._options.connectionTimeout),r.onopen=()=>{Vt(this,Me,"m",Xe).call(this),this._reconnectAttempts=0,this._options.pingInterval&&(this._pingIntervalId=setInterval(()=>{r.readyState===Qe.OPEN&&r.send(JSON.stringify({action:"ping"}))},this._options.pingInterval))},r.onmessage=e=>{let t=e.data.trim().split(`
+`);for(let n of t)if(n.trim()){let i=JSON.parse(n),s=In.channelEventSchema.parse(i);this._broadcaster.publish(s)}},r.onerror=()=>{let e=new Error("WebSocket error");this._errorSubject.publish(e),r.close()},r.onclose=()=>{if(Vt(this,Me,"m",Xe).call(this),this._connectionState.publish(zt.ConnectionState.DISCONNECTED),this._disconnecting)return;let e=Math.min(1e3*Math.pow(2,this._reconnectAttempts),this._options.maxReconnectDelay);this._reconnectAttempts++,setTimeout(()=>{this.connect()},e)},this._socket=r}};oe.PondClient=Dn;Me=new WeakSet,Xe=function(){this._connectionTimeoutId&&(clearTimeout(this._connectionTimeoutId),this._connectionTimeoutId=void 0),this._pingIntervalId&&(clearInterval(this._pingIntervalId),this._pingIntervalId=void 0)}});var Xt=v(P=>{"use strict";Object.defineProperty(P,"__esModule",{value:!0});P.SSEClient=P.PondClient=P.ConnectionState=P.ChannelState=void 0;var Ln=Z();Object.defineProperty(P,"ChannelState",{enumerable:!0,get:function(){return Ln.ChannelState}});var qn=Ve(),Hn=Ht();Object.defineProperty(P,"SSEClient",{enumerable:!0,get:function(){return Hn.SSEClient}});var jn=Qt(),Fn=Y();Object.defineProperty(P,"ConnectionState",{enumerable:!0,get:function(){return Fn.ConnectionState}});var Bn=typeof window>"u"?jn.PondClient:qn.PondClient;P.PondClient=Bn});var er={};an(er,{BaseTransport:()=>V,Bus:()=>Q,Executor:()=>ce,FallbackTransport:()=>ge,Logger:()=>c,OpKinds:()=>Ye,Patcher:()=>K,Runtime:()=>ye,SSETransport:()=>ae,ScriptExecutor:()=>le,Topics:()=>Ce,Transport:()=>z,boot:()=>Ee,createTransport:()=>De,dispatchMessage:()=>be,isBoot:()=>_e,isMessage:()=>Ne,isServerAck:()=>rt,isServerError:()=>we,isServerEvt:()=>nt});var Ce={Router:"router",DOM:"dom",Frame:"frame",Ack:"ack",Reload:"reload"};function He(r){return`script:${r}`}var Ye={SetText:"setText",AppendText:"appendText",SpliceText:"spliceText",SetComment:"setComment",SetAttr:"setAttr",DelAttr:"delAttr",SetStyle:"setStyle",DelStyle:"delStyle",SetStyleDecl:"setStyleDecl",DelStyleDecl:"delStyleDecl",SetHandlers:"setHandlers",SetScript:"setScript",DelScript:"delScript",SetRef:"setRef",DelRef:"delRef",ReplaceNode:"replaceNode",AddChild:"addChild",DelChild:"delChild",MoveChild:"moveChild",DefTemplate:"defTemplate",SetSlots:"setSlots"};function et(r){return typeof r=="object"&&r!==null&&typeof r.html=="string"}function tt(r){return typeof r=="object"&&r!==null&&typeof r.tpl=="number"&&Array.isArray(r.slots)}function _e(r){return typeof r=="object"&&r!==null&&r.t==="boot"}function we(r){return typeof r=="object"&&r!==null&&r.t==="error"}function nt(r){return typeof r=="object"&&r!==null&&typeof r.t=="string"&&typeof r.a=="string"}function rt(r){return typeof r=="object"&&r!==null&&r.t==="ack"&&typeof r.seq=="number"}function Ne(r){return typeof r=="object"&&r!==null&&typeof r.seq=="number"&&typeof r.topic=="string"&&typeof r.event=="string"}var Q=class{constructor(){this.subscribers=new Map;this.wildcardSubscribers=[];this.nextSubId=0}subscribe(e,t,n){let i=`${e}:${String(t)}`,s=++this.nextSubId,o={id:s,callback:n},a=this.subscribers.get(i)??[];return a.push(o),this.subscribers.set(i,a),{unsubscribe:()=>this.unsubscribe(i,s)}}upsert(e,t,n){let i=`${e}:${String(t)}`,s=this.subscribers.get(i);if(s&&s.length>0){let d=s[0];d.callback=n,s.length>1&&this.subscribers.set(i,[d]);let p=d.id;return{unsubscribe:()=>this.unsubscribe(i,p)}}let o=++this.nextSubId,a={id:o,callback:n};return this.subscribers.set(i,[a]),{unsubscribe:()=>this.unsubscribe(i,o)}}subscribeAll(e){let t=++this.nextSubId,n={id:t,callback:e};return this.wildcardSubscribers.push(n),{unsubscribe:()=>this.unsubscribeWildcard(t)}}publish(e,t,n){let i=`${e}:${String(t)}`,s=this.subscribers.get(i)??[];for(let o of s)try{o.callback(n)}catch{}for(let o of this.wildcardSubscribers)try{o.callback(e,String(t),n)}catch{}}subscriberCount(e,t){let n=`${e}:${String(t)}`;return this.subscribers.get(n)?.length??0}subscribeScript(e,t,n){return this.subscribe(He(e),t,n)}publishScript(e,t,n){let i=He(e),s=`${i}:${String(t)}`,o=this.subscribers.get(s)??[];for(let a of o)try{a.callback(n)}catch{}for(let a of this.wildcardSubscribers)try{a.callback(i,String(t),n)}catch{}}publishHandler(e,t){let n=e,i=`${n}:invoke`,s=this.subscribers.get(i)??[];for(let o of s)try{o.callback(t)}catch{}for(let o of this.wildcardSubscribers)try{o.callback(n,"invoke",t)}catch{}}clear(){this.subscribers.clear(),this.wildcardSubscribers=[]}unsubscribe(e,t){let n=this.subscribers.get(e);if(!n)return;let i=n.findIndex(s=>s.id===t);i!==-1&&(n.splice(i,1),n.length===0&&this.subscribers.delete(e))}unsubscribeWildcard(e){let t=this.wildcardSubscribers.findIndex(n=>n.id===e);t!==-1&&this.wildcardSubscribers.splice(t,1)}};var it={debug:0,info:1,warn:2,error:3},je=class{constructor(){this.enabled=!1;this.level="info"}configure(e){e.enabled!==void 0&&(this.enabled=e.enabled),e.level!==void 0&&(this.level=e.level)}debug(e,t,...n){this.log("debug",e,t,n)}info(e,t,...n){this.log("info",e,t,n)}warn(e,t,...n){this.log("warn",e,t,n)}error(e,t,...n){this.log("error",e,t,n)}log(e,t,n,i){if(!this.enabled||it[e]<it[this.level])return;let s=`[Pond:${t}]`,o=console[e]||console.log;i.length>0?o(s,n,...i):o(s,n)}},c=new je;var R=cn(Xt(),1);var Un=["setText","setComment","setAttr","delAttr","setStyle","delStyle","setStyleDecl","delStyleDecl","setHandlers","setScript","delScript","setRef","delRef","replaceNode","addChild","delChild","moveChild","appendText","spliceText","defTemplate","setSlots"];function Zt(r){if(Ne(r))return r;if(!Array.isArray(r)||r.length!==4)return null;let[e,t,n,i]=r;if(typeof e!="number"||typeof t!="string"||typeof n!="string")return null;if(t==="frame"&&n==="patch"){let s=Kn(i);return s?{seq:e,topic:t,event:n,data:s}:null}return{seq:e,topic:t,event:n,data:i}}function Kn(r){if(!Array.isArray(r))return null;let e=[];for(let t=0;t<r.length;t++){let n=r[t];if(!Array.isArray(n)||n.length<2)return null;let i=Un[n[0]];if(i===void 0)return null;let[,s,o,a,d,p]=n,G={seq:t,path:s??null,op:i};o!=null&&(G.value=o),a&&(G.name=a),d&&(G.selector=d),typeof p=="number"&&(G.index=p),e.push(G)}return e}var Gn=256,Se=class{constructor(){this.nextId=0;this.pending=[]}record(e){return e.id=++this.nextId,this.pending.push(e),this.pending.length>Gn&&this.pending.shift(),e}ackThrough(e){this.pending=this.pending.filter(t=>(t.id??0)>e)}unacked(){return this.pending.slice()}},V=class{constructor(e){this.state="disconnected";this.stateListeners=[];this.sessionId=e.sessionId,this.bus=e.bus,this.events=e.events??new Se,this.bus.subscribe("ack","ack",t=>{t.eid&&this.events.ackThrough(t.eid)})}get sid(){return this.sessionId}get connectionState(){return this.state}onStateChange(e){return this.stateListeners.push(e),()=>{let t=this.stateListeners.indexOf(e);t!==-1&&this.stateListeners.splice(t,1)}}send(e,t,n){let i={t:e,sid:this.sessionId,a:String(t),p:n};this.sendEvent(i)}sendAck(e){let t={t:Ce.Ack,sid:this.sessionId,seq:e};this.sendMessage("ack",t)}sendHandler(e,t){let n={t:e,sid:this.sessionId,a:"invoke",p:t};this.sendEvent(n)}sendScript(e,t){let n={t:`script:${e}`,sid:this.sessionId,a:"message",p:t};this.sendEvent(n)}setState(e){let t=this.state;if(this.state=e,e==="connected"&&t!=="connected")for(let n of this.events.unacked())this.sendMessage("evt",n);for(let n of this.stateListeners)try{n(this.state)}catch{}}sendEvent(e){this.sendMessage("evt",this.events.record(e))}},z=class extends V{constructor(t){super(t);this.client=new R.PondClient(t.endpoint);let n={sid:t.sessionId,ver:t.version,ack:t.lastAck,loc:t.location,enc:t.encoding??"compact"};this.channel=this.client.createChannel(`live/${t.sessionId}`,n),this.channel.onMessage((i,s)=>{c.info("TRANSPORT","Transport received message:",s),be(this.bus,s)}),this.channel.onChannelStateChange(i=>{this.handleStateChange(i)})}connect(){this.setState("connecting"),this.channel.join(),this.client.connect()}disconnect(){this.channel.leave(),this.client.disconnect(),this.setState("disconnected")}handleStateChange(t){switch(t){case R.ChannelState.JOINED:this.setState("connected");break;case R.ChannelState.STALLED:this.setState("stalled");break;case R.ChannelState.CLOSED:this.setState("disconnected");break;case R.ChannelState.DECLINED:this.setState("declined");break;case R.ChannelState.JOINING:case R.ChannelState.IDLE:this.setState("connecting");break}}sendMessage(t,n){c.info("TRANSPORT","Transport sending message:",t,n),this.channel.sendMessage(t,n)}};function be(r,e){let t=Zt(e);if(!t)return null;let{seq:n,topic:i,event:s,data:o}=t;return Wn(i)?($n(r,i,s,o,n),i):null}function Wn(r){return r==="router"||r==="dom"||r==="frame"||r==="ack"||r==="reload"||r.startsWith("script:")}function $n(r,e,t,n,i){switch(e){case"frame":if(t==="patch"){let s={seq:i,patches:n};r.publish("frame","patch",s)}break;case"router":t==="push"?r.publish("router","push",n):t==="replace"?r.publish("router","replace",n):t==="back"?r.publish("router","back",void 0):t==="forward"?r.publish("router","forward",void 0):t==="go"&&r.publish("router","go",n);break;case"dom":t==="call"?r.publish("dom","call",n):t==="set"?r.publish("dom","set",n):t==="query"?r.publish("dom","query",n):t==="async"&&r.publish("dom","async",n);break;case"ack":t==="ack"&&r.publish("ack","ack",n);break;case"reload":t==="reload"&&r.publish("reload","reload",n);break;default:if(e.startsWith("script:")&&t==="send"){let s=n;r.publishScript(s.scriptId,"send",s)}break}}var Jn=5e3,ae=class extends V{constructor(t){super(t);this.source=null;this.connId=null;this.queue=[];this.outbox=Promise.resolve();let n=new URLSearchParams({sid:t.sessionId,ver:String(t.version),enc:t.encoding??"compact"});this.streamUrl=`${t.endpoint}/sse?${n.toString()}`,this.postUrl=`${t.endpoint}/sse`}connect(){this.setState("connecting");let t=new EventSource(this.streamUrl);this.source=t,t.addEventListener("conn",n=>{this.connId=JSON.parse(n.data),this.flushQueue(),this.setState("connected")}),t.onmessage=n=>{c.info("TRANSPORT","SSE received message:",n.data);let i;try{i=JSON.parse(n.data)}catch{return}be(this.bus,i)==="reload"&&this.close()},t.onerror=()=>{this.source===t&&(t.readyState===EventSource.CLOSED?(this.source=null,this.setState("declined")):this.setState("stalled"))}}disconnect(){this.close(),this.setState("disconnected")}close(){this.source?.close(),this.source=null,this.connId=null}sendMessage(t,n){c.info("TRANSPORT","SSE sending message:",t,n),!(t==="evt"&&!this.connId)&&(this.queue.push({type:t,message:n}),this.flushQueue())}flushQueue(){let t=this.connId;if(!t)return;let n=this.queue;this.queue=[];for(let{type:i,message:s}of n)this.outbox=this.outbox.then(()=>this.post(t,i,s))}async post(t,n,i){try{let s=await fetch(`${this.postUrl}/${encodeURIComponent(t)}/${n}`,{method:"POST",headers:{"Content-Type":"application/json"},body:JSON.stringify(i),keepalive:!0});s.ok||c.warn("TRANSPORT","SSE post rejected",n,s.status)}catch(s){c.error("TRANSPORT","SSE post failed",n,s)}}},ge=class{constructor(e,t=Jn){this.timeout=t;this.listeners=[];this.timer=null;this.opened=!1;this.fellBack=!1;this.config={...e,events:e.events??new Se},this.current=new z(this.config),this.unsubscribe=this.current.onStateChange(n=>this.handleState(n))}get sid(){return this.current.sid}get connectionState(){return this.current.connectionState}get usingFallback(){return this.fellBack}connect(){this.current.connect(),!this.fellBack&&!this.opened&&(this.timer=setTimeout(()=>this.fallback(),this.timeout))}disconnect(){this.clearTimer(),this.current.disconnect()}onStateChange(e){return this.listeners.push(e),()=>{let t=this.listeners.indexOf(e);t!==-1&&this.listeners.splice(t,1)}}send(e,t,n){this.current.send(e,t,n)}sendAck(e){this.current.sendAck(e)}sendHandler(e,t){this.current.sendHandler(e,t)}sendScript(e,t){this.current.sendScript(e,t)}handleState(e){if(e==="connected")this.opened=!0,this.clearTimer();else if(!this.opened&&!this.fellBack&&(e==="disconnected"||e==="stalled")){this.fallback();return}for(let t of this.listeners)try{t(e)}catch{}}fallback(){this.fellBack||this.opened||(c.warn("TRANSPORT","WebSocket did not open, falling back to Server-Sent Events"),this.clearTimer(),this.fellBack=!0,this.unsubscribe(),this.current.disconnect(),this.current=new ae(this.config),this.unsubscribe=this.current.onStateChange(e=>this.handleState(e)),this.current.connect())}clearTimer(){this.timer!==null&&(clearTimeout(this.timer),this.timer=null)}};function De(r,e="auto"){return e==="sse"?new ae(r):e==="websocket"||typeof EventSource>"u"?new z(r):new ge(r)}var Le=class{constructor(e,t){this.handlerStore=new WeakMap;this.scriptStore=new WeakMap;this.keyedElements=new Map;this.templates=new Map;this.root=e,this.callbacks=t}apply(e){let t=[...e].sort((n,i)=>n.seq-i.seq);for(let n of t)this.applyPatch(n)}applyPatch(e){let t=this.resolvePath(e.path);t&&this.applyTo(t,e)}applyTo(e,t){switch(t.op){case"setText":this.setText(e,t.value);break;case"appendText":this.appendText(e,t.value);break;case"spliceText":this.spliceText(e,t.value);break;case"setComment":this.setComment(e,t.value);break;case"setAttr":this.setAttr(e,t.value);break;case"delAttr":this.delAttr(e,t.name);break;case"setStyle":this.setStyle(e,t.value);break;case"delStyle":this.delStyle(e,t.name);break;case"setStyleDecl":this.setStyleDecl(e,t.selector,t.name,t.value);break;case"delStyleDecl":this.delStyleDecl(e,t.selector,t.name);break;case"setHandlers":this.setHandlers(e,t.value);break;case"setScript":this.setScript(e,t.value);break;case"delScript":this.delScript(e);break;case"setRef":this.callbacks.onRef(t.value,e);break;case"delRef":this.callbacks.onRefDelete(t.value);break;case"replaceNode":this.replaceNode(e,t.value);break;case"addChild":this.addChild(e,t.index,t.value,t.path??[]);break;case"delChild":this.delChild(e,t.index);break;case"defTemplate":{let n=t.value;this.templates.set(n.id,n);break}case"setSlots":this.setSlots(e,t.value);break;case"moveChild":this.moveChild(e,t.value,t.path??[]);break}}resolvePath(e){return this.resolveFrom(this.root,e)}resolveFrom(e,t){let n=e;if(t)for(let i of t){if(!n)return null;n=n.childNodes[i]??null}return n}setText(e,t){e.textContent=t}appendText(e,t){e instanceof CharacterData?e.appendData(t):e.textContent=(e.textContent??"")+t}spliceText(e,t){if(e instanceof CharacterData){e.replaceData(t.offset,t.delete,t.insert);return}let n=e.textContent??"";e.textContent=n.slice(0,t.offset)+t.insert+n.slice(t.offset+t.delete)}setComment(e,t){e.textContent=t}setAttr(e,t){for(let[n,i]of Object.entries(t))n==="class"?e instanceof SVGElement?e.setAttribute("class",i.join(" ")):e.className=i.join(" "):n==="value"&&e instanceof HTMLInputElement?e.value=i[0]??"":n==="checked"&&e instanceof HTMLInputElement?e.checked=i.length>0&&i[0]!=="false":n==="selected"&&e instanceof HTMLOptionElement?e.selected=i.length>0&&i[0]!=="false":i.length===0?e.setAttribute(n,""):e.setAttribute(n,i.join(" "))}delAttr(e,t){t==="value"&&e instanceof HTMLInputElement?e.value="":t==="checked"&&e instanceof HTMLInputElement?e.checked=!1:t==="selected"&&e instanceof HTMLOptionElement?e.selected=!1:e.removeAttribute(t)}setStyle(e,t){for(let[n,i]of Object.entries(t))e.style.setProperty(n,i)}delStyle(e,t){e.style.removeProperty(t)}setStyleDecl(e,t,n,i){let s=e.sheet;if(!s)return;let o=this.findOrCreateRule(s,t);o&&o.style.setProperty(n,i)}delStyleDecl(e,t,n){let i=e.sheet;if(!i)return;let s=this.findRule(i,t);s&&s.style.removeProperty(n)}findRule(e,t){for(let n=0;n<e.cssRules.length;n++){let i=e.cssRules[n];if(i instanceof CSSStyleRule&&i.selectorText===t)return i}return null}findOrCreateRule(e,t){let n=this.findRule(e,t);if(!n){let i=e.insertRule(`${t} {}`,e.cssRules.length);n=e.cssRules[i]}return n}setHandlers(e,t){let n=this.handlerStore.get(e);n&&n.forEach(s=>{s.cleanup&&s.cleanup()});let i=new Map;for(let s of t){let o=this.createHandler(e,s);i.set(s.event,o)}this.handlerStore.set(e,i)}createHandler(e,t){let n=null,i=0,s=d=>{t.prevent&&d.cancelable&&d.preventDefault(),t.stop&&d.stopPropagation();let p=this.extractEventData(d,t.props??[]);this.callbacks.onEvent(t.handler,p)},o;t.debounce&&t.debounce>0?o=d=>{t.prevent&&d.cancelable&&d.preventDefault(),t.stop&&d.stopPropagation(),n&&clearTimeout(n),n=setTimeout(()=>{let p=this.extractEventData(d,t.props??[]);this.callbacks.onEvent(t.handler,p)},t.debounce)}:t.throttle&&t.throttle>0?o=d=>{t.prevent&&d.cancelable&&d.preventDefault(),t.stop&&d.stopPropagation();let p=Date.now();if(p-i>=t.throttle){i=p;let G=this.extractEventData(d,t.props??[]);this.callbacks.onEvent(t.handler,G)}}:o=s;let a={};return t.passive&&(a.passive=!0),t.once&&(a.once=!0),t.capture&&(a.capture=!0),e.addEventListener(t.event,o,a),{listener:o,cleanup:()=>{e.removeEventListener(t.event,o,a),n&&clearTimeout(n)}}}extractEventData(e,t){let n={};for(let i of t){let s=this.resolveProp(e,i);s!==void 0&&(n[i]=s)}return n}resolveProp(e,t){let n=t.split(".").map(o=>o.trim()).filter(Boolean);if(n.length===0)return;let i=n.shift(),s;if(i==="form"&&n.length===0)return this.resolveFormData(e);switch(i){case"event":s=e;break;case"target":s=e.target;break;case"currentTarget":s=e.currentTarget;break;default:s=e[i]}for(let o of n){if(s==null)return;try{s=s[o]}catch{return}}return this.serializeValue(s)}resolveFormData(e){let t=null;if(e.currentTarget instanceof HTMLFormElement?t=e.currentTarget:e.target instanceof Element&&(t=e.target.closest("form")),!t)return;let n={};return new FormData(t).forEach((i,s)=>{typeof i=="string"&&(n[s]=i)}),n}serializeValue(e){if(e==null)return null;let t=typeof e;if(t==="string"||t==="number"||t==="boolean")return e;if(Array.isArray(e)){let n=e.map(i=>this.serializeValue(i)).filter(i=>i!==void 0);return n.length>0?n:null}if(e instanceof Date)return e.toISOString();if(e instanceof DOMTokenList)return Array.from(e);if(!(e instanceof Node))try{return JSON.parse(JSON.stringify(e))}catch{return}}setScript(e,t){this.delScript(e),this.scriptStore.set(e,t.scriptId),this.callbacks.onScript(t,e)}delScript(e){let t=this.scriptStore.get(e);t&&(this.scriptStore.delete(e),this.callbacks.onScriptCleanup(t))}replaceNode(e,t){let n=this.materialize(t,e.parentNode);n&&e.parentNode&&(this.cleanupTree(e),e.parentNode.replaceChild(n,e))}addChild(e,t,n,i){let s=this.materialize(n,e);if(!s)return;if(n.key&&s instanceof Element){let a=`${i.join(",")}-${n.key}`;this.keyedElements.set(a,s)}let o=e.childNodes[t]??null;e.insertBefore(s,o)}delChild(e,t){let n=e.childNodes[t];n&&(this.cleanupTree(n),e.removeChild(n))}moveChild(e,t,n){let i=null;if(t.key){let o=`${n.join(",")}-${t.key}`;i=this.keyedElements.get(o)??null,i||(i=this.findElementBySignature(e,t.key))}if(i||(i=e.childNodes[t.fromIndex]??null),!i)return;e.removeChild(i);let s=e.childNodes[t.newIdx]??null;e.insertBefore(i,s)}findElementBySignature(e,t){if(t.startsWith("K:")){let a=t.slice(2);for(let d=0;d<e.childNodes.length;d++){let p=e.childNodes[d];if(p.nodeType===Node.ELEMENT_NODE&&p.getAttribute("data-key")===a)return p}return null}let n=t.match(/^E:(\w+)\|(\w+)=(.+)$/);if(!n)return null;let[,i,s,o]=n;for(let a=0;a<e.childNodes.length;a++){let d=e.childNodes[a];if(d.nodeType===Node.ELEMENT_NODE){let p=d;if(p.tagName.toLowerCase()===i&&p.getAttribute(s)===o)return p}}return null}cleanupTree(e){if(e.nodeType===Node.ELEMENT_NODE){let t=e,n=this.handlerStore.get(t);n&&(n.forEach(s=>{s.cleanup&&s.cleanup()}),this.handlerStore.delete(t));let i=this.scriptStore.get(t);i&&(this.scriptStore.delete(t),this.callbacks.onScriptCleanup(i));for(let s=0;s<e.childNodes.length;s++)this.cleanupTree(e.childNodes[s])}}materialize(e,t){if(tt(e)){let n=this.renderTemplate(e);return n===null?null:this.createFromHTML({html:n,key:e.key,meta:e.meta},t)}return et(e)?this.createFromHTML(e,t):this.createNode(e)}renderTemplate(e){let t=this.templates.get(e.tpl);if(!t)return null;let n="";return t.statics.forEach((i,s)=>{n+=i,s<e.slots.length&&(n+=Vn(e.slots[s]))}),n}setSlots(e,t){let n=this.templates.get(t.tpl);if(n)for(let[i,s]of Object.entries(t.slots)){let o=n.slots[Number(i)];if(!o)continue;let a=this.resolveFrom(e,o.path);a&&(o.attr?o.attr==="style"||o.attr==="data-key"?a.setAttribute(o.attr,s):this.setAttr(a,{[o.attr]:[s]}):a.textContent=s)}}createFromHTML(e,t){let n=document.createElement("template"),i=t instanceof SVGElement&&t.tagName!=="foreignObject";n.innerHTML=i?`<svg>${e.html}</svg>`:e.html;let s=n.content.firstChild;if(i&&(s=s?.firstChild??null),!s)return null;let o=[...e.meta??[]].sort((a,d)=>a.seq-d.seq);for(let a of o){let d=this.resolveFrom(s,a.path);d&&this.applyTo(d,a)}return s}createNode(e,t=!1){if(e.text!==void 0)return document.createTextNode(e.text);if(e.comment!==void 0)return document.createComment(e.comment);if(!e.tag)return null;let n=Le.SVG_TAGS.has(e.tag),i=t||n,s=i?document.createElementNS(Le.SVG_NS,e.tag):document.createElement(e.tag);if(e.attrs&&this.setAttr(s,e.attrs),e.style&&this.setStyle(s,e.style),e.handlers&&e.handlers.length>0&&this.setHandlers(s,e.handlers),e.script&&this.setScript(s,e.script),e.refId&&this.callbacks.onRef(e.refId,s),e.unsafeHTML)s.innerHTML=e.unsafeHTML;else if(e.children){let o=i&&e.tag!=="foreignObject";for(let a of e.children){let d=this.createNode(a,o);d&&s.appendChild(d)}}return s}},K=Le;K.SVG_NS="http://www.w3.org/2000/svg",K.SVG_TAGS=new Set(["svg","animate","animateMotion","animateTransform","circle","clipPath","defs","desc","ellipse","feBlend","feColorMatrix","feComponentTransfer","feComposite","feConvolveMatrix","feDiffuseLighting","feDisplacementMap","feDistantLight","feDropShadow","feFlood","feFuncA","feFuncB","feFuncG","feFuncR","feGaussianBlur","feImage","feMerge","feMergeNode","feMorphology","feOffset","fePointLight","feSpecularLighting","feSpotLight","feTile","feTurbulence","filter","foreignObject","g","image","line","linearGradient","marker","mask","metadata","mpath","path","pattern","polygon","polyline","radialGradient","rect","set","stop","switch","symbol","text","textPath","title","tspan","use","view"]);function Vn(r){return r.replace(/&/g,"&amp;").replace(/</g,"&lt;").replace(/>/g,"&gt;").replace(/"/g,"&#34;").replace(/'/g,"&#39;")}var zn=["main",'[role="main"]',"h1"],Qn=100,ce=class{constructor(e){this.subscriptions=[];this.popstateHandler=null;this.scrollPositions=new Map;this.pendingNavigation=null;this.settleTimer=null;this.bus=e.bus,this.transport=e.transport,this.resolveRef=e.resolveRef,this.basePath=e.basePath??"",this.currentIndex=window.history.state?.idx??0,this.currentKey=this.ensureHistoryKey(),"scrollRestoration"in window.history&&(window.history.scrollRestoration="manual"),this.setupDOMSubscriptions(),this.setupRouterSubscriptions(),this.setupPopstateListener()}destroy(){for(let e of this.subscriptions)e.unsubscribe();this.subscriptions.length=0,this.popstateHandler&&(window.removeEventListener("popstate",this.popstateHandler),this.popstateHandler=null),this.settleTimer&&(clearTimeout(this.settleTimer),this.settleTimer=null),this.pendingNavigation=null}afterPatch(){this.settleNavigation()}setupDOMSubscriptions(){this.subscriptions.push(this.bus.subscribe("dom","call",e=>this.handleCall(e))),this.subscriptions.push(this.bus.subscribe("dom","set",e=>this.handleSet(e))),this.subscriptions.push(this.bus.subscribe("dom","query",e=>this.handleQuery(e))),this.subscriptions.push(this.bus.subscribe("dom","async",e=>this.handleAsync(e)))}setupRouterSubscriptions(){this.subscriptions.push(this.bus.subscribe("router","push",e=>this.handlePush(e))),this.subscriptions.push(this.bus.subscribe("router","replace",e=>this.handleReplace(e))),this.subscriptions.push(this.bus.subscribe("router","back",()=>this.handleBack())),this.subscriptions.push(this.bus.subscribe("router","forward",()=>this.handleForward())),this.subscriptions.push(this.bus.subscribe("router","go",e=>this.handleGo(e)))}setupPopstateListener(){this.popstateHandler=e=>{this.saveScroll();let t=e.state,n=typeof t?.idx=="number"?t.idx-this.currentIndex:0;this.currentIndex=t?.idx??this.currentIndex,this.currentKey=t?.key??this.ensureHistoryKey(),this.scheduleNavigation({restore:this.scrollPositions.get(this.currentKey)??{x:0,y:0},scroll:"auto",focus:"",hash:"",pathChanged:!0});let i={path:this.appPath(window.location.pathname),query:window.location.search.replace(/^\?/,""),hash:window.location.hash.replace(/^#/,"")};n!==0&&(i.delta=n),this.transport.send("router","popstate",i)},window.addEventListener("popstate",this.popstateHandler)}handleCall(e){let t=this.resolveRef(e.ref);if(!t)return;let n=t[e.method];typeof n=="function"&&n.apply(t,e.args??[])}handleSet(e){let t=this.resolveRef(e.ref);t&&(t[e.prop]=e.value)}handleQuery(e){let t=this.resolveRef(e.ref),n={requestId:e.requestId};if(!t){n.error=`ref not found: ${e.ref}`,this.transport.send("dom","response",n);return}let i={};for(let s of e.selectors)i[s]=this.readProperty(t,s);n.values=i,this.transport.send("dom","response",n)}handleAsync(e){let t=this.resolveRef(e.ref),n={requestId:e.requestId};if(!t){n.error=`ref not found: ${e.ref}`,this.transport.send("dom","response",n);return}let i=t[e.method];if(typeof i!="function"){n.error=`method not found: ${e.method}`,this.transport.send("dom","response",n);return}Promise.resolve(i.apply(t,e.args??[])).then(s=>{n.result=this.serializeValue(s),this.transport.send("dom","response",n)}).catch(s=>{n.error=s instanceof Error?s.message:String(s),this.transport.send("dom","response",n)})}handlePush(e){let t=this.buildUrl(e),n=this.basePath+e.path!==window.location.pathname;this.saveScroll(),this.currentKey=this.createKey(),this.currentIndex+=1,window.history.pushState({key:this.currentKey,idx:this.currentIndex},"",t),this.scheduleNavigation({scroll:e.scroll??"auto",focus:e.focus??"",hash:e.hash,pathChanged:n})}handleReplace(e){let t=this.buildUrl(e);window.history.replaceState({key:this.currentKey,idx:this.currentIndex},"",t),(e.scroll==="auto"||e.focus)&&this.scheduleNavigation({scroll:e.scroll??"preserve",focus:e.focus??"",hash:e.hash,pathChanged:!0})}scheduleNavigation(e){this.pendingNavigation=e,this.settleTimer&&clearTimeout(this.settleTimer),this.settleTimer=setTimeout(()=>this.settleNavigation(),Qn)}settleNavigation(){let e=this.pendingNavigation;if(e){if(this.pendingNavigation=null,this.settleTimer&&(clearTimeout(this.settleTimer),this.settleTimer=null),e.restore)window.scrollTo(e.restore.x,e.restore.y);else if(e.scroll!=="preserve"){let t=e.hash?this.findHashTarget(e.hash):null;t?t.scrollIntoView():e.pathChanged&&window.scrollTo(0,0)}e.pathChanged&&e.focus!=="none"&&this.moveFocus(e.focus)}}findHashTarget(e){let t=decodeURIComponent(e);return document.getElementById(t)??document.getElementsByName(t)[0]??null}moveFocus(e){let t=e?[e]:zn;for(let n of t){let i=null;try{i=document.querySelector(n)}catch{i=null}if(i){!i.hasAttribute("tabindex")&&i.tabIndex<0&&i.setAttribute("tabindex","-1"),i.focus({preventScroll:!0});return}}}saveScroll(){this.scrollPositions.set(this.currentKey,{x:window.scrollX,y:window.scrollY})}ensureHistoryKey(){let e=window.history.state;if(e?.key)return e.key;let t=this.createKey();return window.history.replaceState({...e??{},key:t,idx:this.currentIndex},""),t}createKey(){return Math.random().toString(36).slice(2,10)}handleBack(){window.history.back()}handleForward(){window.history.forward()}handleGo(e){window.history.go(e.delta)}appPath(e){return!this.basePath||e!==this.basePath&&!e.startsWith(this.basePath+"/")?e:e.slice(this.basePath.length)||"/"}buildUrl(e){let t=this.basePath+e.path;return e.query&&(t+="?"+e.query),e.hash&&(t+="#"+e.hash),t}readProperty(e,t){let n=t.split("."),i=e;for(let s of n){if(i==null)return;i=i[s]}return this.serializeValue(i)}serializeValue(e){if(e==null)return null;let t=typeof e;if(t==="string"||t==="number"||t==="boolean")return e;if(Array.isArray(e))return e.map(n=>this.serializeValue(n)).filter(n=>n!==void 0);if(e instanceof Date)return e.toISOString();if(e instanceof DOMTokenList)return Array.from(e);if(!(e instanceof Node))try{return JSON.parse(JSON.stringify(e))}catch{return}}};var le=class{constructor(e){this.scripts=new Map;this.bus=e.bus,this.transport=e.transport}async execute(e,t){let{scriptId:n,script:i}=e;c.debug("Script","execute called",{scriptId:n,element:t.tagName,script:i.substring(0,100)+"..."}),this.cleanup(n);let s={eventHandlers:new Map,subscription:this.bus.subscribeScript(n,"send",a=>{c.debug("Script","server message received",{scriptId:n,event:a.event,data:a.data}),this.handleServerMessage(n,a.event,a.data)})},o={send:(a,d)=>{c.debug("Script","transport.send called",{scriptId:n,event:a,data:d});let p={scriptId:n,event:a,data:d};this.transport.sendScript(n,p)},on:(a,d)=>{c.debug("Script","transport.on registered",{scriptId:n,event:a}),s.eventHandlers.set(a,d)}};try{c.debug("Script","creating function",{scriptId:n});let a=new Function("element","transport",`return (${i})(element, transport);`);c.debug("Script","executing function",{scriptId:n});let d=await a(t,o);typeof d=="function"&&(c.debug("Script","cleanup function returned",{scriptId:n}),s.cleanup=d),this.scripts.set(n,s),c.debug("Script","execute complete",{scriptId:n,handlers:Array.from(s.eventHandlers.keys())})}catch(a){throw c.error("Script","execute failed",{scriptId:n,error:String(a)}),s.subscription.unsubscribe(),a}}handleServerMessage(e,t,n){c.debug("Script","handleServerMessage",{scriptId:e,event:t,data:n});let i=this.scripts.get(e);if(!i){c.warn("Script","no instance found",{scriptId:e});return}let s=i.eventHandlers.get(t);if(!s){c.warn("Script","no handler found",{scriptId:e,event:t,availableHandlers:Array.from(i.eventHandlers.keys())});return}try{c.debug("Script","invoking handler",{scriptId:e,event:t}),s(n)}catch(o){c.error("Script","handler error",{scriptId:e,event:t,error:String(o)})}}cleanup(e){c.debug("Script","cleanup called",{scriptId:e});let t=this.scripts.get(e);if(!t){c.debug("Script","cleanup: no instance found",{scriptId:e});return}if(t.cleanup)try{c.debug("Script","running cleanup function",{scriptId:e}),t.cleanup()}catch(n){c.error("Script","cleanup function error",{scriptId:e,error:String(n)})}t.subscription.unsubscribe(),this.scripts.delete(e),c.debug("Script","cleanup complete",{scriptId:e})}destroy(){for(let e of this.scripts.keys())this.cleanup(e)}};var Yt=1e3,Xn=1e4,Zn=10,de="pond_reload_count",me="pond_reload_timestamp",qe="pond_version_reload",en=6e4;function Yn(r){return typeof r=="object"&&r!==null&&r.t==="resume_ok"}var ye=class{constructor(e){this.refs=new Map;this.cseq=0;this.lastSeq=0;this.connectedState=!1;this.lastSeq=e.seq,this.version=e.version,c.configure({enabled:e.debug??!1,level:"debug"}),c.info("Runtime","Initializing",{sid:e.sessionId,ver:e.version}),this.bus=new Q,this.transport=De({endpoint:e.endpoint,sessionId:e.sessionId,version:e.version,lastAck:e.seq,location:e.location,bus:this.bus},e.transport);let t=n=>this.refs.get(n);this.patcher=new K(e.root,{onEvent:(n,i)=>this.handleEvent(n,i),onRef:(n,i)=>{this.refs.set(n,i),c.debug("Runtime","Ref set",n)},onRefDelete:n=>{this.refs.delete(n),c.debug("Runtime","Ref deleted",n)},onScript:(n,i)=>this.handleScript(n,i),onScriptCleanup:n=>this.handleScriptCleanup(n)}),this.executor=new ce({bus:this.bus,transport:this.transport,resolveRef:t,basePath:e.basePath}),this.scripts=new le({bus:this.bus,transport:this.transport}),this.bus.subscribe("frame","patch",n=>this.handlePatch(n)),this.bus.subscribe("reload","reload",n=>this.handleReload(n)),this.transport.onStateChange(n=>this.handleStateChange(n)),window.__POND_RUNTIME__=this}connect(){c.info("Runtime","Connecting"),this.transport.connect()}disconnect(){c.info("Runtime","Disconnecting"),this.transport.disconnect(),this.executor.destroy(),this.scripts.destroy(),this.bus.clear(),this.refs.clear()}connected(){return this.connectedState}get seq(){return this.lastSeq}handleBoot(e){c.info("Runtime","Boot received",{ver:e.ver,seq:e.seq,patches:e.patch?.length??0}),e.patch&&e.patch.length>0&&this.applyPatches(e.patch),this.lastSeq=e.seq,this.transport.sendAck(e.seq)}handleMessage(e){if(we(e)){let t=e;c.error("Runtime","Server error",{code:t.code,message:t.message});return}if(Yn(e)){this.handleResumeOK(e);return}}handlePatch(e){c.debug("Runtime","Patch received",{seq:e.seq,count:e.patches?.length??0}),e.patches&&e.patches.length>0&&this.applyPatches(e.patches),this.executor.afterPatch(),this.lastSeq=e.seq,this.transport.sendAck(e.seq)}handleReload(e){c.warn("Runtime","Server version changed",{from:this.version,to:e.ver});try{if(sessionStorage.getItem(qe)===String(e.ver)){c.error("Runtime","Still on a stale version after reloading - entering failsafe mode"),this.enterFailsafeMode();return}sessionStorage.setItem(qe,String(e.ver))}catch{}this.reloadWithJitter(e.grace)}handleResumeOK(e){c.info("Runtime","Resume OK",{from:e.from,to:e.to})}handleEvent(e,t){this.cseq++,c.debug("Runtime","Event",{handler:e,cseq:this.cseq});let n={...t,cseq:this.cseq};this.transport.sendHandler(e,n)}handleScript(e,t){c.debug("Runtime","Script execute",e.scriptId),this.scripts.execute(e,t).catch(n=>{c.error("Runtime","Script error",{scriptId:e.scriptId,error:String(n)})})}handleScriptCleanup(e){c.debug("Runtime","Script cleanup",e),this.scripts.cleanup(e)}handleStateChange(e){c.debug("Runtime","Connection state",e);let t=this.connectedState;this.connectedState=e==="connected",!t&&this.connectedState?(c.info("Runtime","Connected"),this.clearReloadTracking(),this.clearVersionReload()):t&&!this.connectedState&&c.warn("Runtime","Disconnected"),e==="declined"&&(c.warn("Runtime","Session declined - session expired or not found"),this.reloadWithJitter())}reloadWithJitter(e=0){if(this.shouldEnterFailsafeMode()){c.error("Runtime","Entering failsafe mode - too many consecutive reloads"),this.enterFailsafeMode();return}this.incrementReloadCount();let t=e+Math.floor(Math.random()*(Xn-Yt))+Yt;c.info("Runtime",`Reloading in ${t}ms`),setTimeout(()=>{window.location.reload()},t)}shouldEnterFailsafeMode(){try{let e=parseInt(sessionStorage.getItem(me)||"0",10),t=parseInt(sessionStorage.getItem(de)||"0",10);return Date.now()-e>en?!1:t>=Zn}catch{return!1}}incrementReloadCount(){try{let e=parseInt(sessionStorage.getItem(me)||"0",10),t=Date.now();if(t-e>en)sessionStorage.setItem(de,"1");else{let n=parseInt(sessionStorage.getItem(de)||"0",10);sessionStorage.setItem(de,String(n+1))}sessionStorage.setItem(me,String(t))}catch{}}clearReloadTracking(){try{sessionStorage.removeItem(de),sessionStorage.removeItem(me)}catch{}}clearVersionReload(){try{sessionStorage.getItem(qe)===String(this.version)&&sessionStorage.removeItem(qe)}catch{}}enterFailsafeMode(){try{sessionStorage.removeItem(de),sessionStorage.removeItem(me)}catch{}}applyPatches(e){this.patcher.apply(e)}};function Ee(){if(typeof window>"u")return null;let r=document.getElementById("live-boot"),e=null;if(r?.textContent)try{e=JSON.parse(r.textContent)}catch{c.error("Runtime","Failed to parse boot payload")}if(e||(e=window.__LIVEUI_BOOT__??null),!e||!_e(e))return c.error("Runtime","No boot payload found"),null;let t={root:document.documentElement,sessionId:e.sid,version:e.ver,seq:e.seq,endpoint:`${e.base??""}/live`,location:e.location,basePath:e.base,debug:e.client?.debug,transport:e.client?.transport},n=new ye(t);return n.handleBoot(e),n.connect(),n}typeof window<"u"&&typeof document<"u"&&(window.location.href.endsWith("#")&&window.location.hash===""&&history.replaceState(null,"",window.location.pathname+window.location.search),document.readyState==="loading"?document.addEventListener("DOMContentLoaded",()=>Ee()):Ee());return ln(er);})();
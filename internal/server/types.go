@@ -5,3 +5,13 @@ type Event struct {
 	Target  string      `json:"target"`
 	Data    interface{} `json:"data"`
 }
+
+// TransportMode selects how browsers hold their live connection. The default
+// opens a WebSocket and falls back to Server-Sent Events when it cannot.
+type TransportMode string
+
+const (
+	TransportAuto      TransportMode = ""
+	TransportWebSocket TransportMode = "websocket"
+	TransportSSE       TransportMode = "sse"
+)
@@ -0,0 +1,20 @@
+package server
+
+import "hash/fnv"
+
+// buildVersion identifies a deploy: the client bundle served to browsers plus
+// the app-supplied build ID. Embedded bundles are served under content-hashed
+// paths, so the path alone changes with the bundle. Tabs booted under another
+// version are asked to reload when they rejoin.
+func buildVersion(clientAsset, buildID string) int {
+	h := fnv.New32a()
+	h.Write([]byte(clientAsset))
+	h.Write([]byte{0})
+	h.Write([]byte(buildID))
+
+	version := int(h.Sum32() & 0x7fffffff)
+	if version == 0 {
+		version = 1
+	}
+	return version
+}
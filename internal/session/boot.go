@@ -8,6 +8,7 @@ import (
 	"github.com/eleven-am/pondlive/internal/portal"
 	"github.com/eleven-am/pondlive/internal/router"
 	"github.com/eleven-am/pondlive/internal/runtime"
+	"github.com/eleven-am/pondlive/internal/storage"
 	"github.com/eleven-am/pondlive/internal/styles"
 	"github.com/eleven-am/pondlive/internal/work"
 )
@@ -32,29 +33,33 @@ func bootComponent(ctx *runtime.Ctx, props bootProps, _ []work.Item) work.Node {
 		metatags.Provider(ctx,
 			router.Provide(ctx,
 				styles.Provider(ctx,
-					document.Provider(ctx,
-						document.HtmlElement(ctx,
-							&work.Element{
-								Tag: "head",
-								Children: []work.Node{
-									metatags.Render(ctx),
-									styles.Render(ctx),
-									headers.Render(ctx),
-								},
-							},
-							document.BodyElement(ctx,
-								errors.Provider(ctx,
-									errors.Props{DevMode: props.DevMode},
-									work.Component(app),
-								),
-								portal.Target(),
+					storage.Provider(ctx,
+						document.Provider(ctx,
+							document.HtmlElement(ctx,
 								&work.Element{
-									Tag: "script",
-									Attrs: map[string][]string{
-										"src":   {props.ClientAsset},
-										"defer": {""},
+									Tag: "head",
+									Children: []work.Node{
+										metatags.Render(ctx),
+										styles.Render(ctx),
+										headers.Render(ctx),
+										storage.Render(ctx),
+										router.Render(ctx),
 									},
 								},
+								document.BodyElement(ctx,
+									errors.Provider(ctx,
+										errors.Props{DevMode: props.DevMode},
+										work.Component(app),
+									),
+									portal.Target(),
+									&work.Element{
+										Tag: "script",
+										Attrs: map[string][]string{
+											"src":   {props.ClientAsset},
+											"defer": {""},
+										},
+									},
+								),
 							),
 						),
 					),
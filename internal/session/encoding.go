@@ -0,0 +1,216 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/eleven-am/pondlive/internal/view/diff"
+)
+
+type Encoding string
+
+const (
+	EncodingJSON    Encoding = "json"
+	EncodingCompact Encoding = "compact"
+)
+
+// compactOps is the wire table for patch opcodes in compact frames. Entries
+// are only ever appended so older clients keep decoding known ops.
+var compactOps = []diff.OpKind{
+	diff.OpSetText,
+	diff.OpSetComment,
+	diff.OpSetAttr,
+	diff.OpDelAttr,
+	diff.OpSetStyle,
+	diff.OpDelStyle,
+	diff.OpSetStyleDecl,
+	diff.OpDelStyleDecl,
+	diff.OpSetHandlers,
+	diff.OpSetScript,
+	diff.OpDelScript,
+	diff.OpSetRef,
+	diff.OpDelRef,
+	diff.OpReplaceNode,
+	diff.OpAddChild,
+	diff.OpDelChild,
+	diff.OpMoveChild,
+	diff.OpAppendText,
+	diff.OpSpliceText,
+	diff.OpDefTemplate,
+	diff.OpSetSlots,
+}
+
+var compactOpCodes = func() map[diff.OpKind]int {
+	codes := make(map[diff.OpKind]int, len(compactOps))
+	for i, op := range compactOps {
+		codes[op] = i
+	}
+	return codes
+}()
+
+func ParseEncoding(s string) Encoding {
+	if Encoding(s) == EncodingCompact {
+		return EncodingCompact
+	}
+	return EncodingJSON
+}
+
+// EncodeMessage returns the value handed to the socket for msg. Compact frames
+// are [seq, topic, event, data]; patch frames carry each patch as
+// [op, path, value, name, selector, index] with trailing empty fields dropped
+// and the patch seq implied by its position.
+func EncodeMessage(msg Message, enc Encoding) (any, error) {
+	if enc != EncodingCompact {
+		return msg, nil
+	}
+
+	data := msg.Data
+	if msg.Topic == "frame" && msg.Event == "patch" {
+		patches, err := asPatches(msg.Data)
+		if err != nil {
+			return nil, err
+		}
+		data, err = compactPatches(patches)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return []any{msg.Seq, msg.Topic, msg.Event, data}, nil
+}
+
+func MarshalMessageAs(msg Message, enc Encoding) ([]byte, error) {
+	wire, err := EncodeMessage(msg, enc)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(wire)
+}
+
+func UnmarshalMessageAs(data []byte, enc Encoding) (Message, error) {
+	if enc != EncodingCompact {
+		return UnmarshalMessage(data)
+	}
+
+	var fields []json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return Message{}, err
+	}
+	if len(fields) != 4 {
+		return Message{}, fmt.Errorf("session: compact message has %d fields, want 4", len(fields))
+	}
+
+	var msg Message
+	if err := json.Unmarshal(fields[0], &msg.Seq); err != nil {
+		return Message{}, err
+	}
+	if err := json.Unmarshal(fields[1], &msg.Topic); err != nil {
+		return Message{}, err
+	}
+	if err := json.Unmarshal(fields[2], &msg.Event); err != nil {
+		return Message{}, err
+	}
+
+	if msg.Topic == "frame" && msg.Event == "patch" {
+		patches, err := expandPatches(fields[3])
+		if err != nil {
+			return Message{}, err
+		}
+		msg.Data = patches
+		return msg, nil
+	}
+
+	if err := json.Unmarshal(fields[3], &msg.Data); err != nil {
+		return Message{}, err
+	}
+	return msg, nil
+}
+
+func asPatches(data any) ([]diff.Patch, error) {
+	switch v := data.(type) {
+	case []diff.Patch:
+		return v, nil
+	case nil:
+		return nil, nil
+	}
+
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	var patches []diff.Patch
+	if err := json.Unmarshal(raw, &patches); err != nil {
+		return nil, fmt.Errorf("session: frame patch data: %w", err)
+	}
+	return patches, nil
+}
+
+func compactPatches(patches []diff.Patch) ([][]any, error) {
+	out := make([][]any, len(patches))
+	for i, p := range patches {
+		code, ok := compactOpCodes[p.Op]
+		if !ok {
+			return nil, fmt.Errorf("session: no compact opcode for %q", p.Op)
+		}
+
+		path := p.Path
+		if path == nil {
+			path = []int{}
+		}
+		fields := []any{code, path, p.Value, p.Name, p.Selector, p.Index}
+
+		n := len(fields)
+		for n > 2 && isEmptyField(fields[n-1]) {
+			n--
+		}
+		out[i] = fields[:n]
+	}
+	return out, nil
+}
+
+func isEmptyField(v any) bool {
+	switch f := v.(type) {
+	case nil:
+		return true
+	case string:
+		return f == ""
+	case *int:
+		return f == nil
+	}
+	return false
+}
+
+func expandPatches(data json.RawMessage) ([]diff.Patch, error) {
+	var rows [][]json.RawMessage
+	if err := json.Unmarshal(data, &rows); err != nil {
+		return nil, err
+	}
+
+	patches := make([]diff.Patch, len(rows))
+	for i, row := range rows {
+		if len(row) < 2 {
+			return nil, fmt.Errorf("session: compact patch %d has %d fields", i, len(row))
+		}
+
+		var code int
+		if err := json.Unmarshal(row[0], &code); err != nil {
+			return nil, err
+		}
+		if code < 0 || code >= len(compactOps) {
+			return nil, fmt.Errorf("session: unknown compact opcode %d", code)
+		}
+
+		p := diff.Patch{Seq: i, Op: compactOps[code]}
+		targets := []any{&p.Path, &p.Value, &p.Name, &p.Selector, &p.Index}
+		for j, field := range row[1:] {
+			if j >= len(targets) {
+				break
+			}
+			if err := json.Unmarshal(field, targets[j]); err != nil {
+				return nil, err
+			}
+		}
+		patches[i] = p
+	}
+	return patches, nil
+}
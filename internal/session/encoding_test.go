@@ -0,0 +1,124 @@
+package session
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/eleven-am/pondlive/internal/view/diff"
+)
+
+type wireFixture struct {
+	Name    string          `json:"name"`
+	Message json.RawMessage `json:"message"`
+	Compact json.RawMessage `json:"compact"`
+}
+
+func loadWireFixtures(t *testing.T) []wireFixture {
+	t.Helper()
+	raw, err := os.ReadFile("testdata/wire_fixtures.json")
+	if err != nil {
+		t.Fatalf("read fixtures: %v", err)
+	}
+	var fixtures []wireFixture
+	if err := json.Unmarshal(raw, &fixtures); err != nil {
+		t.Fatalf("parse fixtures: %v", err)
+	}
+	return fixtures
+}
+
+func assertSameJSON(t *testing.T, got, want []byte) {
+	t.Helper()
+	var g, w any
+	if err := json.Unmarshal(got, &g); err != nil {
+		t.Fatalf("unmarshal got: %v", err)
+	}
+	if err := json.Unmarshal(want, &w); err != nil {
+		t.Fatalf("unmarshal want: %v", err)
+	}
+	if !reflect.DeepEqual(g, w) {
+		t.Fatalf("json mismatch\n got: %s\nwant: %s", got, want)
+	}
+}
+
+func TestCompactEncodingMatchesFixtures(t *testing.T) {
+	for _, fx := range loadWireFixtures(t) {
+		t.Run(fx.Name, func(t *testing.T) {
+			msg, err := UnmarshalMessage(fx.Message)
+			if err != nil {
+				t.Fatalf("unmarshal message: %v", err)
+			}
+			got, err := MarshalMessageAs(msg, EncodingCompact)
+			if err != nil {
+				t.Fatalf("marshal compact: %v", err)
+			}
+			assertSameJSON(t, got, fx.Compact)
+		})
+	}
+}
+
+func TestCompactDecodingMatchesFixtures(t *testing.T) {
+	for _, fx := range loadWireFixtures(t) {
+		t.Run(fx.Name, func(t *testing.T) {
+			msg, err := UnmarshalMessageAs(fx.Compact, EncodingCompact)
+			if err != nil {
+				t.Fatalf("unmarshal compact: %v", err)
+			}
+			got, err := MarshalMessage(msg)
+			if err != nil {
+				t.Fatalf("marshal message: %v", err)
+			}
+			assertSameJSON(t, got, fx.Message)
+		})
+	}
+}
+
+func TestCompactOpTableCoversAllOps(t *testing.T) {
+	ops := []diff.OpKind{
+		diff.OpSetText, diff.OpAppendText, diff.OpSpliceText, diff.OpSetComment,
+		diff.OpSetAttr, diff.OpDelAttr, diff.OpSetStyle, diff.OpDelStyle,
+		diff.OpSetStyleDecl, diff.OpDelStyleDecl, diff.OpSetHandlers, diff.OpSetScript,
+		diff.OpDelScript, diff.OpSetRef, diff.OpDelRef, diff.OpReplaceNode,
+		diff.OpAddChild, diff.OpDelChild, diff.OpMoveChild, diff.OpDefTemplate, diff.OpSetSlots,
+	}
+	for _, op := range ops {
+		if _, ok := compactOpCodes[op]; !ok {
+			t.Errorf("op %q has no compact code", op)
+		}
+	}
+	if len(compactOpCodes) != len(compactOps) {
+		t.Errorf("duplicate ops in compact table")
+	}
+}
+
+func TestWebSocketTransportCompactEncoding(t *testing.T) {
+	sender := &mockSender{}
+	transport := NewWebSocketTransport(sender, "user123", nil)
+	transport.SetEncoding(EncodingCompact)
+
+	patches := []diff.Patch{{Seq: 0, Path: []int{0}, Op: diff.OpSetText, Value: "hi"}}
+	if err := transport.Send("frame", "patch", patches); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	raw, err := json.Marshal(sender.LastMessage())
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	assertSameJSON(t, raw, []byte(`[1,"frame","patch",[[0,[0],"hi"]]]`))
+
+	pending := transport.PendingMessages()
+	if len(pending) != 1 || pending[0].Topic != "frame" {
+		t.Fatalf("expected pending message to keep its decoded form, got %+v", pending)
+	}
+}
+
+func TestParseEncoding(t *testing.T) {
+	if ParseEncoding("compact") != EncodingCompact {
+		t.Error("expected compact")
+	}
+	if ParseEncoding("") != EncodingJSON || ParseEncoding("msgpack") != EncodingJSON {
+		t.Error("expected unknown encodings to fall back to json")
+	}
+}
@@ -0,0 +1,49 @@
+package session
+
+import "sync"
+
+const eventWindow = 256
+
+// eventLog remembers which client event IDs a session has handled. Every ID up
+// to floor has been seen; later ones are tracked individually so events
+// replayed out of order after a reconnect are still recognised.
+type eventLog struct {
+	mu    sync.Mutex
+	floor uint64
+	seen  map[uint64]struct{}
+}
+
+func (l *eventLog) accept(id uint64) (bool, uint64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if id == 0 {
+		return true, l.floor
+	}
+	if _, dup := l.seen[id]; dup || id <= l.floor {
+		return false, l.floor
+	}
+
+	if l.seen == nil {
+		l.seen = make(map[uint64]struct{})
+	}
+	l.seen[id] = struct{}{}
+
+	if len(l.seen) > eventWindow {
+		oldest := id
+		for seen := range l.seen {
+			oldest = min(oldest, seen)
+		}
+		delete(l.seen, oldest)
+		l.floor = oldest
+	}
+	for {
+		if _, ok := l.seen[l.floor+1]; !ok {
+			break
+		}
+		delete(l.seen, l.floor+1)
+		l.floor++
+	}
+
+	return true, l.floor
+}
@@ -0,0 +1,63 @@
+package session
+
+import "testing"
+
+func TestEventLogDropsDuplicates(t *testing.T) {
+	var log eventLog
+
+	steps := []struct {
+		id      uint64
+		fresh   bool
+		through uint64
+	}{
+		{1, true, 1},
+		{2, true, 2},
+		{2, false, 2},
+		{4, true, 2},
+		{1, false, 2},
+		{3, true, 4},
+		{4, false, 4},
+		{0, true, 4},
+		{0, true, 4},
+	}
+	for i, step := range steps {
+		fresh, through := log.accept(step.id)
+		if fresh != step.fresh || through != step.through {
+			t.Errorf("step %d: accept(%d) = (%v, %d), want (%v, %d)", i, step.id, fresh, through, step.fresh, step.through)
+		}
+	}
+}
+
+func TestEventLogBoundsOutOfOrderWindow(t *testing.T) {
+	var log eventLog
+
+	for id := uint64(2); id <= eventWindow+2; id++ {
+		log.accept(id)
+	}
+
+	if len(log.seen) > eventWindow {
+		t.Fatalf("expected at most %d tracked ids, got %d", eventWindow, len(log.seen))
+	}
+	if fresh, _ := log.accept(1); fresh {
+		t.Error("expected ids below the advanced floor to be treated as handled")
+	}
+	if fresh, _ := log.accept(eventWindow + 2); fresh {
+		t.Error("expected the newest id to still be remembered")
+	}
+}
+
+func TestLiveSessionAcceptEvent(t *testing.T) {
+	sess := NewLiveSession("sid", 1, dummyComponent, nil)
+
+	if fresh, through := sess.AcceptEvent(1); !fresh || through != 1 {
+		t.Errorf("expected first delivery to be fresh, got (%v, %d)", fresh, through)
+	}
+	if fresh, through := sess.AcceptEvent(1); fresh || through != 1 {
+		t.Errorf("expected replay to be dropped, got (%v, %d)", fresh, through)
+	}
+
+	var nilSess *LiveSession
+	if fresh, _ := nilSess.AcceptEvent(1); fresh {
+		t.Error("expected nil session to reject events")
+	}
+}
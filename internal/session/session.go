@@ -27,6 +27,8 @@ type LiveSession struct {
 	transportMu sync.RWMutex
 	outboundSub *protocol.Subscription
 	closed      bool
+
+	events eventLog
 }
 
 func NewLiveSession(id SessionID, version int, root Component, cfg *Config) *LiveSession {
@@ -35,6 +37,10 @@ func NewLiveSession(id SessionID, version int, root Component, cfg *Config) *Liv
 		effectiveCfg.DevMode = cfg.DevMode
 		effectiveCfg.ClientAsset = cfg.ClientAsset
 		effectiveCfg.DOMTimeout = cfg.DOMTimeout
+		effectiveCfg.Templates = cfg.Templates
+		effectiveCfg.FlushWindow = cfg.FlushWindow
+		effectiveCfg.MaxFPS = cfg.MaxFPS
+		effectiveCfg.BasePath = cfg.BasePath
 	}
 
 	sess := &LiveSession{
@@ -61,6 +67,10 @@ func NewLiveSession(id SessionID, version int, root Component, cfg *Config) *Liv
 	}
 
 	rtSession.SetDevMode(effectiveCfg.DevMode)
+	rtSession.SetTemplates(effectiveCfg.Templates)
+	rtSession.SetFlushWindow(effectiveCfg.FlushWindow)
+	rtSession.SetMaxFPS(effectiveCfg.MaxFPS)
+	rtSession.SetBasePath(effectiveCfg.BasePath)
 	if effectiveCfg.DOMTimeout > 0 {
 		rtSession.SetDOMTimeout(effectiveCfg.DOMTimeout)
 	}
@@ -103,10 +113,14 @@ func (s *LiveSession) Version() int {
 	return s.version
 }
 
+// Session returns the runtime session, or nil once the live session has
+// closed. It is safe to call while another goroutine closes it.
 func (s *LiveSession) Session() *runtime.Session {
 	if s == nil {
 		return nil
 	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	return s.session
 }
 
@@ -120,27 +134,39 @@ func (s *LiveSession) SetTransport(t Transport) {
 	s.transportMu.Unlock()
 
 	if old != nil && old != t {
-		if ws, ok := t.(*WebSocketTransport); ok {
+		if live, ok := t.(requestStateUpdater); ok {
 			if state := old.RequestState(); state != nil {
-				ws.UpdateRequestState(state)
+				live.UpdateRequestState(state)
 			}
 		}
 		_ = old.Close()
 	}
 }
 
+// AcceptEvent records a client event ID and reports whether it is new, along
+// with the ID through which every event has been handled. Events without an
+// ID are always accepted.
+func (s *LiveSession) AcceptEvent(id uint64) (bool, uint64) {
+	if s == nil {
+		return false, 0
+	}
+	return s.events.accept(id)
+}
+
 func (s *LiveSession) Receive(topic, event string, data any) {
-	if s == nil || s.session == nil || s.session.Bus == nil {
+	rt := s.Session()
+	if rt == nil || rt.Bus == nil {
 		return
 	}
-	s.session.Bus.Publish(protocol.Topic(topic), event, data)
+	rt.Bus.Publish(protocol.Topic(topic), event, data)
 }
 
 func (s *LiveSession) Flush() error {
-	if s == nil || s.session == nil {
+	rt := s.Session()
+	if rt == nil {
 		return nil
 	}
-	return s.session.Flush()
+	return rt.Flush()
 }
 
 func (s *LiveSession) Close() error {
@@ -182,10 +208,11 @@ func (s *LiveSession) Close() error {
 }
 
 func (s *LiveSession) SetDevMode(enabled bool) {
-	if s == nil || s.session == nil {
+	rt := s.Session()
+	if rt == nil {
 		return
 	}
-	s.session.SetDevMode(enabled)
+	rt.SetDevMode(enabled)
 }
 
 func (s *LiveSession) ClientAsset() string {
@@ -205,46 +232,61 @@ func (s *LiveSession) SetClientAsset(path string) {
 }
 
 func (s *LiveSession) Bus() *protocol.Bus {
-	if s == nil || s.session == nil {
+	rt := s.Session()
+	if rt == nil {
 		return nil
 	}
-	return s.session.Bus
+	return rt.Bus
 }
 
 func (s *LiveSession) ChannelManager() *runtime.ChannelManager {
-	if s == nil || s.session == nil {
+	rt := s.Session()
+	if rt == nil {
 		return nil
 	}
-	return s.session.ChannelManager()
+	return rt.ChannelManager()
 }
 
 func (s *LiveSession) UploadRegistry() *upload.Registry {
-	if s == nil || s.session == nil {
+	rt := s.Session()
+	if rt == nil {
 		return nil
 	}
-	return s.session.UploadRegistry
+	return rt.UploadRegistry
 }
 
 func (s *LiveSession) SetAutoFlush(fn func()) {
-	if s == nil || s.session == nil {
+	rt := s.Session()
+	if rt == nil {
 		return
 	}
-	s.session.SetAutoFlush(fn)
+	rt.SetAutoFlush(fn)
 }
 
 func (s *LiveSession) SetDOMTimeout(timeout time.Duration) {
-	if s == nil || s.session == nil {
+	rt := s.Session()
+	if rt == nil {
+		return
+	}
+	rt.SetDOMTimeout(timeout)
+}
+
+func (s *LiveSession) SetFrameBudget(window time.Duration, maxFPS int) {
+	rt := s.Session()
+	if rt == nil {
 		return
 	}
-	s.session.SetDOMTimeout(timeout)
+	rt.SetFlushWindow(window)
+	rt.SetMaxFPS(maxFPS)
 }
 
 func (s *LiveSession) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	if s == nil || s.session == nil {
+	rt := s.Session()
+	if rt == nil {
 		http.NotFound(w, r)
 		return
 	}
-	s.session.ServeHTTP(w, r)
+	rt.ServeHTTP(w, r)
 }
 
 func isClientTopic(topic protocol.Topic, event string) bool {
@@ -0,0 +1,296 @@
+package session
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/eleven-am/pondlive/internal/headers"
+)
+
+// SSETransport streams messages over Server-Sent Events. Each message is an
+// unnamed event whose id is its Message.Seq; unacknowledged messages are kept
+// so a reconnecting stream resumes after its Last-Event-ID.
+type SSETransport struct {
+	connID   string
+	nextSeq  uint64
+	encoding Encoding
+
+	mu           sync.Mutex
+	pending      []Message
+	wake         chan struct{}
+	stream       uint64
+	streaming    bool
+	closed       bool
+	requestInfo  *headers.RequestInfo
+	requestState *headers.RequestState
+}
+
+func NewSSETransport(connID string, h http.Header) *SSETransport {
+	info := headers.NewRequestInfoFromHeaders(h)
+	return &SSETransport{
+		connID:       connID,
+		wake:         make(chan struct{}),
+		requestInfo:  info,
+		requestState: headers.NewRequestState(info),
+	}
+}
+
+func (t *SSETransport) ConnID() string {
+	if t == nil {
+		return ""
+	}
+	return t.connID
+}
+
+func (t *SSETransport) UpdateRequestState(state *headers.RequestState) {
+	if t == nil {
+		return
+	}
+
+	state.SetIsLive(true)
+	state.ReplaceInfo(t.requestInfo)
+
+	t.mu.Lock()
+	t.requestState = state
+	t.mu.Unlock()
+
+	state.NotifyChange()
+}
+
+func (t *SSETransport) SetEncoding(enc Encoding) {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	t.encoding = enc
+	t.mu.Unlock()
+}
+
+func (t *SSETransport) RequestInfo() *headers.RequestInfo {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.requestInfo
+}
+
+func (t *SSETransport) RequestState() *headers.RequestState {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.requestState
+}
+
+func (t *SSETransport) Send(topic, event string, data any) error {
+	if t == nil {
+		return nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed {
+		return nil
+	}
+
+	t.pending = append(t.pending, Message{
+		Seq:   atomic.AddUint64(&t.nextSeq, 1),
+		Topic: topic,
+		Event: event,
+		Data:  data,
+	})
+	t.wakeLocked()
+	return nil
+}
+
+func (t *SSETransport) SendAck(sid string, eid uint64) uint64 {
+	if t == nil {
+		return 0
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed {
+		return 0
+	}
+
+	seq := atomic.AddUint64(&t.nextSeq, 1)
+	t.pending = append(t.pending, Message{
+		Seq:   seq,
+		Topic: "ack",
+		Event: "ack",
+		Data:  serverAck(sid, seq, eid),
+	})
+	t.wakeLocked()
+	return seq
+}
+
+func (t *SSETransport) wakeLocked() {
+	close(t.wake)
+	t.wake = make(chan struct{})
+}
+
+func (t *SSETransport) AckThrough(seq uint64) {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	i := 0
+	for i < len(t.pending) && t.pending[i].Seq <= seq {
+		i++
+	}
+	t.pending = append(t.pending[:0:0], t.pending[i:]...)
+	t.mu.Unlock()
+}
+
+func (t *SSETransport) Pending() int {
+	if t == nil {
+		return 0
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.pending)
+}
+
+func (t *SSETransport) LastSeq() uint64 {
+	if t == nil {
+		return 0
+	}
+	return atomic.LoadUint64(&t.nextSeq)
+}
+
+// Streaming reports whether a client stream is currently attached.
+func (t *SSETransport) Streaming() bool {
+	if t == nil {
+		return false
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.streaming
+}
+
+// Stream writes pending and future messages to w until the request ends, the
+// transport closes or a newer stream takes over. Messages up to the request's
+// Last-Event-ID are treated as acknowledged.
+func (t *SSETransport) Stream(w http.ResponseWriter, r *http.Request, heartbeat time.Duration) error {
+	if t == nil {
+		return errors.New("session: nil sse transport")
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return errors.New("session: response writer does not support flushing")
+	}
+
+	cursor, _ := strconv.ParseUint(strings.TrimSpace(r.Header.Get("Last-Event-ID")), 10, 64)
+	t.AckThrough(cursor)
+
+	t.mu.Lock()
+	t.stream++
+	stream := t.stream
+	t.streaming = true
+	t.wakeLocked()
+	t.mu.Unlock()
+
+	defer func() {
+		t.mu.Lock()
+		if t.stream == stream {
+			t.streaming = false
+		}
+		t.mu.Unlock()
+	}()
+
+	h := w.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-store")
+	h.Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	if _, err := fmt.Fprintf(w, "retry: 2000\nevent: conn\ndata: %q\n\n", t.connID); err != nil {
+		return err
+	}
+	flusher.Flush()
+
+	var ticker <-chan time.Time
+	if heartbeat > 0 {
+		tk := time.NewTicker(heartbeat)
+		defer tk.Stop()
+		ticker = tk.C
+	}
+
+	for {
+		t.mu.Lock()
+		if t.stream != stream {
+			t.mu.Unlock()
+			return nil
+		}
+		var batch []Message
+		for _, msg := range t.pending {
+			if msg.Seq > cursor {
+				batch = append(batch, msg)
+			}
+		}
+		wake := t.wake
+		enc := t.encoding
+		closed := t.closed
+		t.mu.Unlock()
+
+		for _, msg := range batch {
+			data, err := MarshalMessageAs(msg, enc)
+			if err != nil {
+				return err
+			}
+			if _, err := fmt.Fprintf(w, "id: %d\ndata: %s\n\n", msg.Seq, data); err != nil {
+				return err
+			}
+			cursor = msg.Seq
+		}
+		if len(batch) > 0 {
+			flusher.Flush()
+		}
+		if closed {
+			return nil
+		}
+
+		select {
+		case <-wake:
+		case <-r.Context().Done():
+			return nil
+		case <-ticker:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return err
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+func (t *SSETransport) IsLive() bool {
+	return true
+}
+
+func (t *SSETransport) Close() error {
+	if t == nil {
+		return nil
+	}
+
+	t.mu.Lock()
+	if !t.closed {
+		t.closed = true
+		t.wakeLocked()
+	}
+	t.mu.Unlock()
+
+	return nil
+}
@@ -0,0 +1,157 @@
+package session
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSSETransportStreamsPendingMessages(t *testing.T) {
+	transport := NewSSETransport("conn-1", nil)
+	_ = transport.Send("frame", "patch", []any{})
+	_ = transport.Send("router", "push", map[string]string{"path": "/next"})
+	_ = transport.Close()
+
+	rec := httptest.NewRecorder()
+	if err := transport.Stream(rec, httptest.NewRequest(http.MethodGet, "/live/sse", nil), 0); err != nil {
+		t.Fatalf("stream failed: %v", err)
+	}
+
+	if ct := rec.Header().Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("expected event-stream content type, got %q", ct)
+	}
+
+	body := rec.Body.String()
+	for _, want := range []string{
+		"event: conn\ndata: \"conn-1\"\n\n",
+		"id: 1\ndata: {\"seq\":1,\"topic\":\"frame\",\"event\":\"patch\",\"data\":[]}\n\n",
+		"id: 2\ndata: {\"seq\":2,\"topic\":\"router\",\"event\":\"push\",",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected stream to contain %q, got %q", want, body)
+		}
+	}
+}
+
+func TestSSETransportResumesAfterLastEventID(t *testing.T) {
+	transport := NewSSETransport("conn-1", nil)
+	transport.SetEncoding(EncodingCompact)
+	for i := 0; i < 3; i++ {
+		_ = transport.Send("dom", "call", nil)
+	}
+	_ = transport.Close()
+
+	req := httptest.NewRequest(http.MethodGet, "/live/sse", nil)
+	req.Header.Set("Last-Event-ID", "2")
+	rec := httptest.NewRecorder()
+	if err := transport.Stream(rec, req, 0); err != nil {
+		t.Fatalf("stream failed: %v", err)
+	}
+
+	body := rec.Body.String()
+	if strings.Contains(body, "id: 1\n") || strings.Contains(body, "id: 2\n") {
+		t.Errorf("expected acknowledged messages to be skipped, got %q", body)
+	}
+	if !strings.Contains(body, "id: 3\ndata: [3,\"dom\",\"call\",null]\n\n") {
+		t.Errorf("expected compact message 3, got %q", body)
+	}
+	if transport.Pending() != 1 {
+		t.Errorf("expected Last-Event-ID to ack through 2, pending=%d", transport.Pending())
+	}
+}
+
+func TestSSETransportAckThrough(t *testing.T) {
+	transport := NewSSETransport("conn-1", nil)
+	_ = transport.Send("frame", "patch", nil)
+	seq := transport.SendAck("sid", 0)
+	_ = transport.Send("frame", "patch", nil)
+
+	if seq != 2 || transport.LastSeq() != 3 {
+		t.Fatalf("expected ack seq 2 and last seq 3, got %d and %d", seq, transport.LastSeq())
+	}
+
+	transport.AckThrough(2)
+	if transport.Pending() != 1 {
+		t.Errorf("expected 1 pending message, got %d", transport.Pending())
+	}
+
+	_ = transport.Close()
+	if err := transport.Send("frame", "patch", nil); err != nil || transport.Pending() != 1 {
+		t.Errorf("expected send after close to be dropped, err=%v pending=%d", err, transport.Pending())
+	}
+}
+
+func TestSSETransportDeliversLiveMessages(t *testing.T) {
+	transport := NewSSETransport("conn-1", nil)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = transport.Stream(w, r, 0)
+	}))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	lines := make(chan string)
+	go func() {
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+		close(lines)
+	}()
+
+	next := func() string {
+		select {
+		case line := <-lines:
+			return line
+		case <-time.After(2 * time.Second):
+			t.Fatal("timed out waiting for stream")
+			return ""
+		}
+	}
+
+	for next() != "" {
+	}
+	if !transport.Streaming() {
+		t.Error("expected transport to report an attached stream")
+	}
+
+	_ = transport.Send("frame", "patch", nil)
+	if line := next(); line != "id: 1" {
+		t.Errorf("expected live message id, got %q", line)
+	}
+
+	_ = transport.Close()
+	for range lines {
+	}
+	deadline := time.Now().Add(time.Second)
+	for transport.Streaming() && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if transport.Streaming() {
+		t.Error("expected stream to end after close")
+	}
+}
+
+func TestSetTransportHandsRequestStateToSSE(t *testing.T) {
+	sess := NewLiveSession("sid", 1, dummyComponent, nil)
+	ssr := NewSSRTransport(httptest.NewRequest(http.MethodGet, "/page", nil))
+	sess.SetTransport(ssr)
+	state := ssr.RequestState()
+
+	live := NewSSETransport("conn-1", http.Header{"User-Agent": {"agent"}})
+	sess.SetTransport(live)
+
+	if live.RequestState() != state {
+		t.Fatal("expected SSE transport to adopt the SSR request state")
+	}
+	if !state.IsLive() {
+		t.Error("expected request state to be marked live")
+	}
+}
@@ -13,3 +13,9 @@ type Transport interface {
 
 	Close() error
 }
+
+// requestStateUpdater is implemented by live transports that take over the
+// request state captured by the transport they replace.
+type requestStateUpdater interface {
+	UpdateRequestState(state *headers.RequestState)
+}
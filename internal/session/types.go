@@ -12,6 +12,13 @@ type Config struct {
 	ClientAsset string
 
 	DOMTimeout time.Duration
+
+	Templates bool
+
+	FlushWindow time.Duration
+	MaxFPS      int
+
+	BasePath string
 }
 
 func DefaultConfig() Config {
@@ -7,6 +7,7 @@ import (
 	"sync/atomic"
 
 	"github.com/eleven-am/pondlive/internal/headers"
+	"github.com/eleven-am/pondlive/internal/protocol"
 )
 
 type Message struct {
@@ -21,9 +22,10 @@ type ChannelSender interface {
 }
 
 type WebSocketTransport struct {
-	sender  ChannelSender
-	userID  string
-	nextSeq uint64
+	sender   ChannelSender
+	userID   string
+	nextSeq  uint64
+	encoding Encoding
 
 	mu           sync.Mutex
 	pending      map[uint64]Message
@@ -58,6 +60,16 @@ func (t *WebSocketTransport) UpdateRequestState(state *headers.RequestState) {
 	state.NotifyChange()
 }
 
+func (t *WebSocketTransport) SetEncoding(enc Encoding) {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	t.encoding = enc
+	t.mu.Unlock()
+}
+
 func (t *WebSocketTransport) RequestInfo() *headers.RequestInfo {
 	if t == nil {
 		return nil
@@ -96,9 +108,14 @@ func (t *WebSocketTransport) Send(topic, event string, data any) error {
 	t.pending[seq] = msg
 	sender := t.sender
 	userID := t.userID
+	enc := t.encoding
 	t.mu.Unlock()
 
-	if err := sender.BroadcastTo(event, msg, userID); err != nil {
+	wire, err := EncodeMessage(msg, enc)
+	if err == nil {
+		err = sender.BroadcastTo(event, wire, userID)
+	}
+	if err != nil {
 		t.mu.Lock()
 		delete(t.pending, seq)
 		t.mu.Unlock()
@@ -164,11 +181,16 @@ func (t *WebSocketTransport) Resend() error {
 	}
 	sender := t.sender
 	userID := t.userID
+	enc := t.encoding
 	t.mu.Unlock()
 
 	var firstErr error
 	for _, msg := range msgs {
-		if err := sender.BroadcastTo(msg.Event, msg, userID); err != nil && firstErr == nil {
+		wire, err := EncodeMessage(msg, enc)
+		if err == nil {
+			err = sender.BroadcastTo(msg.Event, wire, userID)
+		}
+		if err != nil && firstErr == nil {
 			firstErr = err
 		}
 	}
@@ -194,7 +216,7 @@ func (t *WebSocketTransport) LastSeq() uint64 {
 	return atomic.LoadUint64(&t.nextSeq)
 }
 
-func (t *WebSocketTransport) SendAck(sid string) uint64 {
+func (t *WebSocketTransport) SendAck(sid string, eid uint64) uint64 {
 	if t == nil {
 		return 0
 	}
@@ -207,28 +229,21 @@ func (t *WebSocketTransport) SendAck(sid string) uint64 {
 
 	seq := atomic.AddUint64(&t.nextSeq, 1)
 
-	ack := struct {
-		T   string `json:"t"`
-		SID string `json:"sid"`
-		Seq uint64 `json:"seq"`
-	}{
-		T:   "ack",
-		SID: sid,
-		Seq: seq,
-	}
-
 	msg := Message{
 		Seq:   seq,
 		Topic: "ack",
 		Event: "ack",
-		Data:  ack,
+		Data:  serverAck(sid, seq, eid),
 	}
 
 	sender := t.sender
 	userID := t.userID
+	enc := t.encoding
 	t.mu.Unlock()
 
-	_ = sender.BroadcastTo("ack", msg, userID)
+	if wire, err := EncodeMessage(msg, enc); err == nil {
+		_ = sender.BroadcastTo("ack", wire, userID)
+	}
 
 	return seq
 }
@@ -258,3 +273,13 @@ func UnmarshalMessage(data []byte) (Message, error) {
 	err := json.Unmarshal(data, &msg)
 	return msg, err
 }
+
+// serverAck confirms delivery of a client message; eid is the event ID through
+// which the session has handled every client event.
+func serverAck(sid string, seq, eid uint64) protocol.ServerAck {
+	return protocol.ServerAck{
+		Event: protocol.Event{Type: protocol.AckTopic, SID: sid},
+		Seq:   seq,
+		EID:   eid,
+	}
+}
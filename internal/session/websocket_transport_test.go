@@ -310,7 +310,7 @@ func TestWebSocketTransportSendAck(t *testing.T) {
 	sender := &mockSender{}
 	transport := NewWebSocketTransport(sender, "user123", nil)
 
-	seq := transport.SendAck("session-1")
+	seq := transport.SendAck("session-1", 0)
 	if seq != 1 {
 		t.Errorf("expected seq 1, got %d", seq)
 	}
@@ -344,7 +344,7 @@ func TestWebSocketTransportSendAckSequenceAtomic(t *testing.T) {
 	transport := NewWebSocketTransport(sender, "user123", nil)
 
 	_ = transport.Send("topic", "event", nil)
-	seq := transport.SendAck("session-1")
+	seq := transport.SendAck("session-1", 0)
 	_ = transport.Send("topic", "event", nil)
 
 	if seq != 2 {
@@ -362,7 +362,7 @@ func TestWebSocketTransportSendAckClosed(t *testing.T) {
 
 	_ = transport.Close()
 
-	seq := transport.SendAck("session-1")
+	seq := transport.SendAck("session-1", 0)
 	if seq != 0 {
 		t.Errorf("expected seq 0 for closed transport, got %d", seq)
 	}
@@ -390,7 +390,7 @@ func TestWebSocketTransportSendAckFailureClearsPending(t *testing.T) {
 	sender := &mockSender{failNext: true}
 	transport := NewWebSocketTransport(sender, "user123", nil)
 
-	seq := transport.SendAck("sid")
+	seq := transport.SendAck("sid", 0)
 	if seq == 0 {
 		t.Fatalf("expected seq allocated")
 	}
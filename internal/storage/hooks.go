@@ -0,0 +1,127 @@
+package storage
+
+import (
+	"encoding/json"
+	"net/url"
+	"sync"
+
+	"github.com/eleven-am/pondlive/internal/headers"
+	"github.com/eleven-am/pondlive/internal/runtime"
+)
+
+type Option func(*options)
+
+type options struct {
+	cookie bool
+}
+
+func WithCookieMirror() Option {
+	return func(o *options) {
+		o.cookie = true
+	}
+}
+
+func UseLocalStorage[T any](ctx *runtime.Ctx, key string, initial T, opts ...Option) (T, func(T)) {
+	return useStorage(ctx, AreaLocal, key, initial, opts)
+}
+
+func UseSessionStorage[T any](ctx *runtime.Ctx, key string, initial T, opts ...Option) (T, func(T)) {
+	return useStorage(ctx, AreaSession, key, initial, opts)
+}
+
+func useStorage[T any](ctx *runtime.Ctx, area, key string, initial T, opts []Option) (T, func(T)) {
+	var cfg options
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&cfg)
+		}
+	}
+
+	state := providerCtx.UseContextValue(ctx)
+	requestState := headers.UseRequestState(ctx)
+
+	cookie := ""
+	start := initial
+	if cfg.cookie {
+		cookie = cookieName(area, key)
+		if requestState != nil {
+			if raw, ok := requestState.GetCookie(cookie); ok {
+				if decoded, err := url.PathUnescape(raw); err == nil {
+					start = decodeValue(&decoded, initial)
+				}
+			}
+		}
+	}
+
+	value, setValue := runtime.UseState(ctx, start)
+	bindingRef := runtime.UseRef(ctx, &binding{})
+	b := bindingRef.Current
+	w := b.bind(state, area, key, cookie, func(raw *string) {
+		setValue(decodeValue(raw, initial))
+	})
+
+	runtime.UseEffect(ctx, func() func() {
+		return state.watch(w)
+	}, state, w)
+
+	// The setter keeps its identity across renders and writes through to the
+	// provider itself, so a write made before the effect runs still persists.
+	setterRef := runtime.UseRef(ctx, (func(T))(nil))
+	if setterRef.Current == nil {
+		setterRef.Current = func(next T) {
+			setValue(next)
+
+			state, w := b.current()
+			if state == nil {
+				return
+			}
+
+			encoded, err := json.Marshal(next)
+			if err != nil {
+				return
+			}
+			raw := string(encoded)
+			state.set(w, &raw)
+		}
+	}
+
+	return value, setterRef.Current
+}
+
+// binding holds the provider and watcher a storage hook currently writes to.
+// The watcher is replaced rather than changed when its key moves, since the
+// provider may be calling the old one.
+type binding struct {
+	mu      sync.Mutex
+	state   *providerState
+	watcher *watcher
+}
+
+func (b *binding) bind(state *providerState, area, key, cookie string, fn func(*string)) *watcher {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state = state
+	if w := b.watcher; w == nil || w.area != area || w.key != key || w.cookie != cookie {
+		b.watcher = &watcher{area: area, key: key, cookie: cookie, fn: fn}
+	}
+	return b.watcher
+}
+
+func (b *binding) current() (*providerState, *watcher) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state, b.watcher
+}
+
+func decodeValue[T any](raw *string, fallback T) T {
+	if raw == nil {
+		return fallback
+	}
+
+	var value T
+	if err := json.Unmarshal([]byte(*raw), &value); err != nil {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,239 @@
+package storage
+
+import (
+	"net/url"
+	"sync"
+
+	"github.com/eleven-am/pondlive/internal/runtime"
+	"github.com/eleven-am/pondlive/internal/work"
+)
+
+const (
+	AreaLocal   = "local"
+	AreaSession = "session"
+)
+
+const clientScript = `function(element,transport){
+	var watched={};
+	function area(name){try{return name==='session'?window.sessionStorage:window.localStorage}catch(e){return null}}
+	function read(name,key){var s=area(name);if(!s)return null;try{return s.getItem(key)}catch(e){return null}}
+	function mirror(cookie,value){
+		if(!cookie)return;
+		document.cookie=value==null?cookie+'=;path=/;max-age=0;SameSite=Lax':cookie+'='+encodeURIComponent(value)+';path=/;max-age=31536000;SameSite=Lax';
+	}
+	transport.on('watch',function(list){
+		(list||[]).forEach(function(w){
+			watched[w.area+':'+w.key]=w;
+			var value=read(w.area,w.key);
+			mirror(w.cookie,value);
+			transport.send('value',{area:w.area,key:w.key,value:value});
+		});
+	});
+	transport.on('set',function(d){
+		var s=area(d.area);
+		if(s){try{if(d.value==null){s.removeItem(d.key)}else{s.setItem(d.key,d.value)}}catch(e){}}
+		mirror(d.cookie,d.value);
+	});
+	function onStorage(e){
+		if(!e.key)return;
+		var name=e.storageArea===window.sessionStorage?'session':'local';
+		var w=watched[name+':'+e.key];
+		if(!w)return;
+		mirror(w.cookie,e.newValue);
+		transport.send('value',{area:name,key:e.key,value:e.newValue});
+	}
+	window.addEventListener('storage',onStorage);
+	transport.send('ready',{});
+	return function(){window.removeEventListener('storage',onStorage)};
+}`
+
+type watcher struct {
+	area   string
+	key    string
+	cookie string
+	fn     func(raw *string)
+}
+
+type providerState struct {
+	script   runtime.ScriptHandle
+	mu       sync.Mutex
+	ready    bool
+	watchers map[string]map[*watcher]struct{}
+	pending  []map[string]any
+}
+
+var providerCtx = runtime.CreateContext[*providerState](nil)
+
+var Provider = runtime.Component(func(ctx *runtime.Ctx, children []work.Item) work.Node {
+	stateRef := runtime.UseRef(ctx, (*providerState)(nil))
+	if stateRef.Current == nil {
+		stateRef.Current = &providerState{watchers: make(map[string]map[*watcher]struct{})}
+	}
+	state := stateRef.Current
+
+	state.script = runtime.UseScript(ctx, clientScript)
+
+	runtime.UseEffect(ctx, func() func() {
+		state.script.On("ready", state.handleReady)
+		state.script.On("value", state.handleValue)
+		return nil
+	}, state)
+
+	providerCtx.UseProvider(ctx, state)
+
+	return &work.Fragment{Children: work.ItemsToNodes(children)}
+})
+
+var Render = runtime.Component(func(ctx *runtime.Ctx, _ []work.Item) work.Node {
+	state := providerCtx.UseContextValue(ctx)
+	if state == nil {
+		return nil
+	}
+
+	scriptNode := &work.Element{
+		Tag: "script",
+	}
+
+	state.script.AttachTo(scriptNode)
+	return scriptNode
+})
+
+func cookieName(area, key string) string {
+	return "pondlive_" + area + "_" + url.QueryEscape(key)
+}
+
+func watchKey(area, key string) string {
+	return area + ":" + key
+}
+
+func (w *watcher) payload() map[string]any {
+	return map[string]any{
+		"area":   w.area,
+		"key":    w.key,
+		"cookie": w.cookie,
+	}
+}
+
+func (p *providerState) watch(w *watcher) func() {
+	if p == nil || w == nil {
+		return nil
+	}
+
+	id := watchKey(w.area, w.key)
+
+	p.mu.Lock()
+	if p.watchers[id] == nil {
+		p.watchers[id] = make(map[*watcher]struct{})
+	}
+	p.watchers[id][w] = struct{}{}
+	ready := p.ready
+	p.mu.Unlock()
+
+	if ready {
+		p.script.Send("watch", []map[string]any{w.payload()})
+	}
+
+	return func() {
+		p.mu.Lock()
+		delete(p.watchers[id], w)
+		if len(p.watchers[id]) == 0 {
+			delete(p.watchers, id)
+		}
+		p.mu.Unlock()
+	}
+}
+
+func (p *providerState) set(source *watcher, raw *string) {
+	if p == nil || source == nil {
+		return
+	}
+
+	msg := source.payload()
+	if raw != nil {
+		msg["value"] = *raw
+	} else {
+		msg["value"] = nil
+	}
+
+	p.mu.Lock()
+	ready := p.ready
+	if !ready {
+		p.pending = append(p.pending, msg)
+	}
+	others := p.peersLocked(source)
+	p.mu.Unlock()
+
+	if ready {
+		p.script.Send("set", msg)
+	}
+
+	for _, w := range others {
+		w.fn(raw)
+	}
+}
+
+func (p *providerState) peersLocked(source *watcher) []*watcher {
+	var peers []*watcher
+	for w := range p.watchers[watchKey(source.area, source.key)] {
+		if w != source {
+			peers = append(peers, w)
+		}
+	}
+	return peers
+}
+
+func (p *providerState) handleReady(_ any) {
+	p.mu.Lock()
+	p.ready = true
+	pending := p.pending
+	p.pending = nil
+
+	seen := make(map[string]bool)
+	var list []map[string]any
+	for id, set := range p.watchers {
+		for w := range set {
+			if !seen[id] {
+				seen[id] = true
+				list = append(list, w.payload())
+			}
+		}
+	}
+	p.mu.Unlock()
+
+	for _, msg := range pending {
+		p.script.Send("set", msg)
+	}
+
+	if len(list) > 0 {
+		p.script.Send("watch", list)
+	}
+}
+
+func (p *providerState) handleValue(data any) {
+	msg, ok := data.(map[string]any)
+	if !ok {
+		return
+	}
+
+	area, _ := msg["area"].(string)
+	key, _ := msg["key"].(string)
+	if area == "" || key == "" {
+		return
+	}
+
+	var raw *string
+	if value, ok := msg["value"].(string); ok {
+		raw = &value
+	}
+
+	p.mu.Lock()
+	var targets []*watcher
+	for w := range p.watchers[watchKey(area, key)] {
+		targets = append(targets, w)
+	}
+	p.mu.Unlock()
+
+	for _, w := range targets {
+		w.fn(raw)
+	}
+}
@@ -0,0 +1,179 @@
+package storage
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/eleven-am/pondlive/internal/headers"
+	"github.com/eleven-am/pondlive/internal/protocol"
+	"github.com/eleven-am/pondlive/internal/runtime"
+	"github.com/eleven-am/pondlive/internal/work"
+)
+
+type prefs struct {
+	Collapsed bool   `json:"collapsed"`
+	Density   string `json:"density"`
+}
+
+func storageTestSession(requestState *headers.RequestState, child func(*runtime.Ctx, any, []work.Item) work.Node) (*runtime.Session, **providerState) {
+	var state *providerState
+	root := &runtime.Instance{
+		ID: "root",
+		Fn: func(ctx *runtime.Ctx, _ any, _ []work.Item) work.Node {
+			return headers.Provider(ctx, requestState,
+				Provider(ctx,
+					work.Component(func(ctx *runtime.Ctx, _ any, _ []work.Item) work.Node {
+						state = providerCtx.UseContextValue(ctx)
+						return nil
+					}),
+					work.Component(child),
+				),
+			)
+		},
+	}
+
+	sess := &runtime.Session{
+		Root:              root,
+		Components:        map[string]*runtime.Instance{"root": root},
+		Handlers:          make(map[string]work.Handler),
+		MountedComponents: make(map[*runtime.Instance]struct{}),
+		Bus:               protocol.NewBus(),
+	}
+	return sess, &state
+}
+
+func TestUseLocalStorageUsesInitialValueWithoutStoredData(t *testing.T) {
+	var value prefs
+	sess, _ := storageTestSession(headers.NewRequestState(nil), func(ctx *runtime.Ctx, _ any, _ []work.Item) work.Node {
+		value, _ = UseLocalStorage(ctx, "prefs", prefs{Density: "comfortable"})
+		return nil
+	})
+
+	if err := sess.Flush(); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+
+	if value.Density != "comfortable" || value.Collapsed {
+		t.Errorf("expected initial value, got %+v", value)
+	}
+}
+
+func TestUseLocalStorageReadsMirroredCookie(t *testing.T) {
+	header := http.Header{}
+	header.Set("Cookie", cookieName(AreaLocal, "prefs")+"=%7B%22collapsed%22%3Atrue%2C%22density%22%3A%22compact%22%7D")
+	requestState := headers.NewRequestState(headers.NewRequestInfoFromHeaders(header))
+
+	var value prefs
+	sess, _ := storageTestSession(requestState, func(ctx *runtime.Ctx, _ any, _ []work.Item) work.Node {
+		value, _ = UseLocalStorage(ctx, "prefs", prefs{Density: "comfortable"}, WithCookieMirror())
+		return nil
+	})
+
+	if err := sess.Flush(); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+
+	if !value.Collapsed || value.Density != "compact" {
+		t.Errorf("expected value from mirrored cookie, got %+v", value)
+	}
+}
+
+func TestUseSessionStorageUpdatesFromClientValues(t *testing.T) {
+	var value int
+	sess, state := storageTestSession(headers.NewRequestState(nil), func(ctx *runtime.Ctx, _ any, _ []work.Item) work.Node {
+		value, _ = UseSessionStorage(ctx, "count", 0)
+		return nil
+	})
+
+	if err := sess.Flush(); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+
+	(*state).handleValue(map[string]any{"area": AreaSession, "key": "count", "value": "7"})
+	if err := sess.Flush(); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+	if value != 7 {
+		t.Errorf("expected stored value 7, got %d", value)
+	}
+
+	(*state).handleValue(map[string]any{"area": AreaLocal, "key": "count", "value": "9"})
+	if err := sess.Flush(); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+	if value != 7 {
+		t.Errorf("expected local storage update to be ignored, got %d", value)
+	}
+
+	(*state).handleValue(map[string]any{"area": AreaSession, "key": "count", "value": nil})
+	if err := sess.Flush(); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+	if value != 0 {
+		t.Errorf("expected removed key to fall back to initial value, got %d", value)
+	}
+}
+
+func TestUseLocalStorageSetterSyncsPeersAndQueuesUntilReady(t *testing.T) {
+	var first, second string
+	var setFirst func(string)
+	sess, state := storageTestSession(headers.NewRequestState(nil), func(ctx *runtime.Ctx, _ any, _ []work.Item) work.Node {
+		first, setFirst = UseLocalStorage(ctx, "theme", "light")
+		second, _ = UseLocalStorage(ctx, "theme", "light")
+		return nil
+	})
+
+	if err := sess.Flush(); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+
+	setFirst("dark")
+	if err := sess.Flush(); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+
+	if first != "dark" || second != "dark" {
+		t.Errorf("expected both hooks to observe the write, got %q and %q", first, second)
+	}
+
+	(*state).mu.Lock()
+	pending := len((*state).pending)
+	(*state).mu.Unlock()
+	if pending != 1 {
+		t.Fatalf("expected write to be queued until the client is ready, got %d pending", pending)
+	}
+
+	(*state).handleReady(nil)
+
+	(*state).mu.Lock()
+	pending = len((*state).pending)
+	ready := (*state).ready
+	(*state).mu.Unlock()
+	if !ready || pending != 0 {
+		t.Errorf("expected pending writes to flush on ready, got ready=%v pending=%d", ready, pending)
+	}
+}
+
+func TestUseLocalStorageSetterPersistsBeforeEffect(t *testing.T) {
+	written := false
+	sess, state := storageTestSession(headers.NewRequestState(nil), func(ctx *runtime.Ctx, _ any, _ []work.Item) work.Node {
+		_, set := UseLocalStorage(ctx, "theme", "light")
+		if !written {
+			written = true
+			set("dark")
+		}
+		return nil
+	})
+
+	if err := sess.Flush(); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+
+	(*state).mu.Lock()
+	pending := (*state).pending
+	(*state).mu.Unlock()
+	if len(pending) != 1 || pending[0]["value"] != `"dark"` {
+		t.Fatalf("expected the write made during the first render to be kept, got %v", pending)
+	}
+
+}
@@ -15,6 +15,7 @@ type Config struct {
 	MaxSize      int64
 	AllowedTypes []string
 	Logger       *slog.Logger
+	BasePath     string
 }
 
 type LookupFunc func(token string) (UploadCallback, bool)
@@ -44,8 +45,12 @@ func NewHandler(cfg Config, lookup LookupFunc, onRemove func(token string)) (*Ha
 	composer := handler.NewStoreComposer()
 	store.UseIn(composer)
 
+	if cfg.BasePath == "" {
+		cfg.BasePath = "/tus"
+	}
+
 	tusConfig := handler.Config{
-		BasePath:                "/tus",
+		BasePath:                cfg.BasePath,
 		StoreComposer:           composer,
 		NotifyCompleteUploads:   true,
 		RespectForwardedHeaders: true,
@@ -15,6 +15,12 @@ func intPtr(i int) *int {
 	return &i
 }
 
+type diffState struct {
+	seq           int
+	templates     *Templates
+	htmlThreshold int
+}
+
 func Diff(prev, next view.Node) []Patch {
 	var flatPrev, flatNext view.Node
 	if prev != nil {
@@ -24,22 +30,20 @@ func Diff(prev, next view.Node) []Patch {
 		flatNext = Flatten(next)
 	}
 
-	seq := 0
 	patches := make([]Patch, 0)
-	diffNode(&patches, &seq, nil, flatPrev, flatNext)
+	diffNode(&patches, &diffState{htmlThreshold: DefaultHTMLInsertThreshold}, nil, flatPrev, flatNext)
 	return patches
 }
 
 func DiffRaw(prev, next view.Node) []Patch {
-	seq := 0
 	patches := make([]Patch, 0)
-	diffNode(&patches, &seq, nil, prev, next)
+	diffNode(&patches, &diffState{htmlThreshold: DefaultHTMLInsertThreshold}, nil, prev, next)
 	return patches
 }
 
-func emit(patches *[]Patch, seq *int, p Patch) {
-	p.Seq = *seq
-	*seq++
+func emit(patches *[]Patch, st *diffState, p Patch) {
+	p.Seq = st.seq
+	st.seq++
 	*patches = append(*patches, p)
 }
 
@@ -71,19 +75,19 @@ func nodeTypeOf(n view.Node) nodeType {
 	}
 }
 
-func diffNode(patches *[]Patch, seq *int, path []int, a, b view.Node) {
-	if a == nil && b == nil {
+func diffNode(patches *[]Patch, st *diffState, path []int, a, b view.Node) {
+	if a == b {
 		return
 	}
 	if a == nil || b == nil {
-		emit(patches, seq, Patch{Path: copyPath(path), Op: OpReplaceNode, Value: b})
+		emit(patches, st, Patch{Path: copyPath(path), Op: OpReplaceNode, Value: insertValue(patches, st, b)})
 		return
 	}
 
 	aType := nodeTypeOf(a)
 	bType := nodeTypeOf(b)
 	if aType != bType {
-		emit(patches, seq, Patch{Path: copyPath(path), Op: OpReplaceNode, Value: b})
+		emit(patches, st, Patch{Path: copyPath(path), Op: OpReplaceNode, Value: insertValue(patches, st, b)})
 		return
 	}
 
@@ -92,77 +96,80 @@ func diffNode(patches *[]Patch, seq *int, path []int, a, b view.Node) {
 		aElem := a.(*view.Element)
 		bElem := b.(*view.Element)
 		if aElem.Tag != bElem.Tag {
-			emit(patches, seq, Patch{Path: copyPath(path), Op: OpReplaceNode, Value: b})
+			emit(patches, st, Patch{Path: copyPath(path), Op: OpReplaceNode, Value: insertValue(patches, st, b)})
 			return
 		}
-		diffElement(patches, seq, path, aElem, bElem)
+		diffElement(patches, st, path, aElem, bElem)
 	case nodeText:
 		aText := a.(*view.Text)
 		bText := b.(*view.Text)
 		if aText.Text != bText.Text {
-			emit(patches, seq, Patch{Path: copyPath(path), Op: OpSetText, Value: bText.Text})
+			diffText(patches, st, path, aText.Text, bText.Text)
 		}
 	case nodeComment:
 		aComment := a.(*view.Comment)
 		bComment := b.(*view.Comment)
 		if aComment.Comment != bComment.Comment {
-			emit(patches, seq, Patch{Path: copyPath(path), Op: OpSetComment, Value: bComment.Comment})
+			emit(patches, st, Patch{Path: copyPath(path), Op: OpSetComment, Value: bComment.Comment})
 		}
 	case nodeFragment:
 		aFrag := a.(*view.Fragment)
 		bFrag := b.(*view.Fragment)
-		diffChildren(patches, seq, path, aFrag.Children, bFrag.Children)
+		diffChildren(patches, st, path, aFrag.Children, bFrag.Children)
 	}
 }
 
-func diffElement(patches *[]Patch, seq *int, path []int, a, b *view.Element) {
+func diffElement(patches *[]Patch, st *diffState, path []int, a, b *view.Element) {
+	if st.templates != nil && st.templates.diffInstance(patches, st, path, a, b) {
+		return
+	}
 
 	if a.UnsafeHTML != b.UnsafeHTML {
-		emit(patches, seq, Patch{Path: copyPath(path), Op: OpReplaceNode, Value: b})
+		emit(patches, st, Patch{Path: copyPath(path), Op: OpReplaceNode, Value: insertValue(patches, st, b)})
 		return
 	}
 
-	diffAttrs(patches, seq, path, a, b)
-	diffStyle(patches, seq, path, a, b)
+	diffAttrs(patches, st, path, a, b)
+	diffStyle(patches, st, path, a, b)
 
 	if a.Tag == "style" || b.Tag == "style" {
-		diffStylesheet(patches, seq, path, a, b)
+		diffStylesheet(patches, st, path, a, b)
 	}
 
 	if a.RefID != b.RefID {
 		if b.RefID == "" {
-			emit(patches, seq, Patch{Path: copyPath(path), Op: OpDelRef})
+			emit(patches, st, Patch{Path: copyPath(path), Op: OpDelRef})
 		} else {
-			emit(patches, seq, Patch{Path: copyPath(path), Op: OpSetRef, Value: b.RefID})
+			emit(patches, st, Patch{Path: copyPath(path), Op: OpSetRef, Value: b.RefID})
 		}
 	}
 
 	if !handlersEqual(a.Handlers, b.Handlers) {
-		emit(patches, seq, Patch{Path: copyPath(path), Op: OpSetHandlers, Value: b.Handlers})
+		emit(patches, st, Patch{Path: copyPath(path), Op: OpSetHandlers, Value: b.Handlers})
 	}
 
 	if !scriptEqual(a.Script, b.Script) {
 		if b.Script == nil {
-			emit(patches, seq, Patch{Path: copyPath(path), Op: OpDelScript})
+			emit(patches, st, Patch{Path: copyPath(path), Op: OpDelScript})
 		} else {
-			emit(patches, seq, Patch{Path: copyPath(path), Op: OpSetScript, Value: b.Script})
+			emit(patches, st, Patch{Path: copyPath(path), Op: OpSetScript, Value: b.Script})
 		}
 	}
 
 	if b.UnsafeHTML == "" {
-		diffChildren(patches, seq, path, a.Children, b.Children)
+		diffChildren(patches, st, path, a.Children, b.Children)
 	}
 }
 
-func diffChildren(patches *[]Patch, seq *int, parentPath []int, a, b []view.Node) {
+func diffChildren(patches *[]Patch, st *diffState, parentPath []int, a, b []view.Node) {
 	if hasKeys(a) || hasKeys(b) {
-		diffChildrenKeyed(patches, seq, parentPath, a, b)
+		diffChildrenKeyed(patches, st, parentPath, a, b)
 		return
 	}
-	diffChildrenIndexed(patches, seq, parentPath, a, b)
+	diffChildrenIndexed(patches, st, parentPath, a, b)
 }
 
-func diffChildrenIndexed(patches *[]Patch, seq *int, parentPath []int, a, b []view.Node) {
+func diffChildrenIndexed(patches *[]Patch, st *diffState, parentPath []int, a, b []view.Node) {
 	aLen := len(a)
 	bLen := len(b)
 
@@ -172,11 +179,11 @@ func diffChildrenIndexed(patches *[]Patch, seq *int, parentPath []int, a, b []vi
 
 	if aLen == 0 {
 		for i := 0; i < bLen; i++ {
-			emit(patches, seq, Patch{
+			emit(patches, st, Patch{
 				Path:  copyPath(parentPath),
 				Op:    OpAddChild,
 				Index: intPtr(i),
-				Value: b[i],
+				Value: insertValue(patches, st, b[i]),
 			})
 		}
 		return
@@ -184,7 +191,7 @@ func diffChildrenIndexed(patches *[]Patch, seq *int, parentPath []int, a, b []vi
 
 	if bLen == 0 {
 		for i := aLen - 1; i >= 0; i-- {
-			emit(patches, seq, Patch{
+			emit(patches, st, Patch{
 				Path:  copyPath(parentPath),
 				Op:    OpDelChild,
 				Index: intPtr(i),
@@ -214,14 +221,14 @@ func diffChildrenIndexed(patches *[]Patch, seq *int, parentPath []int, a, b []vi
 	}
 
 	if !hasAnySignatureMatch {
-		diffChildrenByPosition(patches, seq, parentPath, a, b)
+		diffChildrenByPosition(patches, st, parentPath, a, b)
 		return
 	}
 
 	head := 0
 	for head < aLen && head < bLen && signaturesMatchStrict(a[head], b[head]) {
 		childPath := append(copyPath(parentPath), head)
-		diffNode(patches, seq, childPath, a[head], b[head])
+		diffNode(patches, st, childPath, a[head], b[head])
 		head++
 	}
 
@@ -241,7 +248,7 @@ func diffChildrenIndexed(patches *[]Patch, seq *int, parentPath []int, a, b []vi
 		for i := bTail + 1; i < bLen; i++ {
 			aIdx := aTail + 1 + (i - bTail - 1)
 			childPath := append(copyPath(parentPath), i)
-			diffNode(patches, seq, childPath, a[aIdx], b[i])
+			diffNode(patches, st, childPath, a[aIdx], b[i])
 		}
 		return
 	}
@@ -279,7 +286,7 @@ func diffChildrenIndexed(patches *[]Patch, seq *int, parentPath []int, a, b []vi
 
 	for i := oldMiddleLen - 1; i >= 0; i-- {
 		if !matchedOld[i] {
-			emit(patches, seq, Patch{
+			emit(patches, st, Patch{
 				Path:  copyPath(parentPath),
 				Op:    OpDelChild,
 				Index: intPtr(head + i),
@@ -292,17 +299,17 @@ func diffChildrenIndexed(patches *[]Patch, seq *int, parentPath []int, a, b []vi
 		oldIdx := oldIndices[i]
 
 		if oldIdx < 0 {
-			emit(patches, seq, Patch{
+			emit(patches, st, Patch{
 				Path:  copyPath(parentPath),
 				Op:    OpAddChild,
 				Index: intPtr(newIdx),
-				Value: newMiddle[i],
+				Value: insertValue(patches, st, newMiddle[i]),
 			})
 			continue
 		}
 
 		if !lisSet[i] {
-			emit(patches, seq, Patch{
+			emit(patches, st, Patch{
 				Path:  copyPath(parentPath),
 				Op:    OpMoveChild,
 				Index: intPtr(newIdx),
@@ -314,17 +321,17 @@ func diffChildrenIndexed(patches *[]Patch, seq *int, parentPath []int, a, b []vi
 		}
 
 		childPath := append(copyPath(parentPath), newIdx)
-		diffNode(patches, seq, childPath, oldMiddle[oldIdx], newMiddle[i])
+		diffNode(patches, st, childPath, oldMiddle[oldIdx], newMiddle[i])
 	}
 
 	for i := bTail + 1; i < bLen; i++ {
 		aIdx := aTail + 1 + (i - bTail - 1)
 		childPath := append(copyPath(parentPath), i)
-		diffNode(patches, seq, childPath, a[aIdx], b[i])
+		diffNode(patches, st, childPath, a[aIdx], b[i])
 	}
 }
 
-func diffChildrenByPosition(patches *[]Patch, seq *int, parentPath []int, a, b []view.Node) {
+func diffChildrenByPosition(patches *[]Patch, st *diffState, parentPath []int, a, b []view.Node) {
 	minLen := len(a)
 	if len(b) < minLen {
 		minLen = len(b)
@@ -332,11 +339,11 @@ func diffChildrenByPosition(patches *[]Patch, seq *int, parentPath []int, a, b [
 
 	for i := 0; i < minLen; i++ {
 		childPath := append(copyPath(parentPath), i)
-		diffNode(patches, seq, childPath, a[i], b[i])
+		diffNode(patches, st, childPath, a[i], b[i])
 	}
 
 	for i := len(a) - 1; i >= minLen; i-- {
-		emit(patches, seq, Patch{
+		emit(patches, st, Patch{
 			Path:  copyPath(parentPath),
 			Op:    OpDelChild,
 			Index: intPtr(i),
@@ -344,20 +351,20 @@ func diffChildrenByPosition(patches *[]Patch, seq *int, parentPath []int, a, b [
 	}
 
 	for i := minLen; i < len(b); i++ {
-		emit(patches, seq, Patch{
+		emit(patches, st, Patch{
 			Path:  copyPath(parentPath),
 			Op:    OpAddChild,
 			Index: intPtr(i),
-			Value: b[i],
+			Value: insertValue(patches, st, b[i]),
 		})
 	}
 }
 
-func diffChildrenKeyed(patches *[]Patch, seq *int, parentPath []int, a, b []view.Node) {
-	diffChildrenWithKeys(patches, seq, parentPath, a, b, getKey)
+func diffChildrenKeyed(patches *[]Patch, st *diffState, parentPath []int, a, b []view.Node) {
+	diffChildrenWithKeys(patches, st, parentPath, a, b, getKey)
 }
 
-func diffChildrenWithKeys(patches *[]Patch, seq *int, parentPath []int, a, b []view.Node, keyFunc func(view.Node) string) {
+func diffChildrenWithKeys(patches *[]Patch, st *diffState, parentPath []int, a, b []view.Node, keyFunc func(view.Node) string) {
 	oldKeys := make(map[string]int)
 	newKeys := make(map[string]int)
 
@@ -412,7 +419,7 @@ func diffChildrenWithKeys(patches *[]Patch, seq *int, parentPath []int, a, b []v
 		if key := keyFunc(a[idx]); key != "" {
 			value = map[string]interface{}{"key": key}
 		}
-		emit(patches, seq, Patch{
+		emit(patches, st, Patch{
 			Path:  copyPath(parentPath),
 			Op:    OpDelChild,
 			Index: intPtr(idx),
@@ -447,13 +454,13 @@ func diffChildrenWithKeys(patches *[]Patch, seq *int, parentPath []int, a, b []v
 		if key == "" {
 			if newIdx < len(intermediate) && keyFunc(intermediate[newIdx]) == "" {
 				childPath := append(copyPath(parentPath), newIdx)
-				diffNode(patches, seq, childPath, intermediate[newIdx], newChild)
+				diffNode(patches, st, childPath, intermediate[newIdx], newChild)
 			} else {
-				emit(patches, seq, Patch{
+				emit(patches, st, Patch{
 					Path:  copyPath(parentPath),
 					Op:    OpAddChild,
 					Index: intPtr(newIdx),
-					Value: newChild,
+					Value: insertValue(patches, st, newChild),
 				})
 			}
 			continue
@@ -461,18 +468,18 @@ func diffChildrenWithKeys(patches *[]Patch, seq *int, parentPath []int, a, b []v
 
 		oldIdx, existedBefore := oldKeys[key]
 		if !existedBefore {
-			emit(patches, seq, Patch{
+			emit(patches, st, Patch{
 				Path:  copyPath(parentPath),
 				Op:    OpAddChild,
 				Index: intPtr(newIdx),
-				Value: newChild,
+				Value: insertValue(patches, st, newChild),
 			})
 			continue
 		}
 
 		intermediateIdx := intermediateKeys[key]
 		if intermediateIdx != newIdx {
-			emit(patches, seq, Patch{
+			emit(patches, st, Patch{
 				Path:  copyPath(parentPath),
 				Op:    OpMoveChild,
 				Index: intPtr(newIdx),
@@ -485,7 +492,7 @@ func diffChildrenWithKeys(patches *[]Patch, seq *int, parentPath []int, a, b []v
 		}
 
 		childPath := append(copyPath(parentPath), newIdx)
-		diffNode(patches, seq, childPath, a[oldIdx], newChild)
+		diffNode(patches, st, childPath, a[oldIdx], newChild)
 	}
 }
 
@@ -664,10 +671,10 @@ func intToStr(n int) string {
 	return digits
 }
 
-func diffAttrs(patches *[]Patch, seq *int, path []int, a, b *view.Element) {
+func diffAttrs(patches *[]Patch, st *diffState, path []int, a, b *view.Element) {
 	for k := range a.Attrs {
 		if _, ok := b.Attrs[k]; !ok {
-			emit(patches, seq, Patch{Path: copyPath(path), Op: OpDelAttr, Name: k})
+			emit(patches, st, Patch{Path: copyPath(path), Op: OpDelAttr, Name: k})
 		}
 	}
 
@@ -678,11 +685,11 @@ func diffAttrs(patches *[]Patch, seq *int, path []int, a, b *view.Element) {
 		}
 	}
 	if len(set) > 0 {
-		emit(patches, seq, Patch{Path: copyPath(path), Op: OpSetAttr, Value: set})
+		emit(patches, st, Patch{Path: copyPath(path), Op: OpSetAttr, Value: set})
 	}
 }
 
-func diffStyle(patches *[]Patch, seq *int, path []int, a, b *view.Element) {
+func diffStyle(patches *[]Patch, st *diffState, path []int, a, b *view.Element) {
 	if a.Style == nil && b.Style == nil {
 		return
 	}
@@ -698,7 +705,7 @@ func diffStyle(patches *[]Patch, seq *int, path []int, a, b *view.Element) {
 
 	for k := range aStyle {
 		if _, ok := bStyle[k]; !ok {
-			emit(patches, seq, Patch{Path: copyPath(path), Op: OpDelStyle, Name: k})
+			emit(patches, st, Patch{Path: copyPath(path), Op: OpDelStyle, Name: k})
 		}
 	}
 
@@ -709,11 +716,11 @@ func diffStyle(patches *[]Patch, seq *int, path []int, a, b *view.Element) {
 		}
 	}
 	if len(set) > 0 {
-		emit(patches, seq, Patch{Path: copyPath(path), Op: OpSetStyle, Value: set})
+		emit(patches, st, Patch{Path: copyPath(path), Op: OpSetStyle, Value: set})
 	}
 }
 
-func diffStylesheet(patches *[]Patch, seq *int, path []int, a, b *view.Element) {
+func diffStylesheet(patches *[]Patch, st *diffState, path []int, a, b *view.Element) {
 	if a.Stylesheet == nil && b.Stylesheet == nil {
 		return
 	}
@@ -738,7 +745,7 @@ func diffStylesheet(patches *[]Patch, seq *int, path []int, a, b *view.Element)
 		}
 		if len(oldProps) == 0 {
 			for prop, val := range newProps {
-				emit(patches, seq, Patch{
+				emit(patches, st, Patch{
 					Path:     copyPath(path),
 					Op:       OpSetStyleDecl,
 					Selector: sel,
@@ -750,7 +757,7 @@ func diffStylesheet(patches *[]Patch, seq *int, path []int, a, b *view.Element)
 		}
 		if len(newProps) == 0 {
 			for prop := range oldProps {
-				emit(patches, seq, Patch{
+				emit(patches, st, Patch{
 					Path:     copyPath(path),
 					Op:       OpDelStyleDecl,
 					Selector: sel,
@@ -762,7 +769,7 @@ func diffStylesheet(patches *[]Patch, seq *int, path []int, a, b *view.Element)
 
 		for prop := range oldProps {
 			if _, ok := newProps[prop]; !ok {
-				emit(patches, seq, Patch{
+				emit(patches, st, Patch{
 					Path:     copyPath(path),
 					Op:       OpDelStyleDecl,
 					Selector: sel,
@@ -773,7 +780,7 @@ func diffStylesheet(patches *[]Patch, seq *int, path []int, a, b *view.Element)
 
 		for prop, val := range newProps {
 			if oldProps[prop] != val {
-				emit(patches, seq, Patch{
+				emit(patches, st, Patch{
 					Path:     copyPath(path),
 					Op:       OpSetStyleDecl,
 					Selector: sel,
@@ -932,11 +939,16 @@ func Flatten(n view.Node) view.Node {
 		if len(node.Children) == 0 {
 			return node
 		}
+		changed := false
 		flattened := make([]view.Node, 0, len(node.Children))
 		for _, child := range node.Children {
-			if flat := Flatten(child); flat != nil {
+			flat := Flatten(child)
+			if flat != child {
+				changed = true
+			}
+			if flat != nil {
 				if frag, ok := flat.(*view.Fragment); ok {
-
+					changed = true
 					flattened = append(flattened, frag.Children...)
 				} else {
 					flattened = append(flattened, flat)
@@ -944,6 +956,10 @@ func Flatten(n view.Node) view.Node {
 			}
 		}
 
+		if !changed {
+			return node
+		}
+
 		return &view.Element{
 			Tag:        node.Tag,
 			Attrs:      node.Attrs,
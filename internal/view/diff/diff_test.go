@@ -1369,6 +1369,30 @@ func TestFlattenSingleElement(t *testing.T) {
 	}
 }
 
+func TestFlattenPreservesFragmentFreeSubtree(t *testing.T) {
+	inner := withChildren(elementNode("span"), textNode("a"))
+	tree := withChildren(elementNode("div"), inner, textNode("b"))
+
+	if result := Flatten(tree); result != view.Node(tree) {
+		t.Errorf("expected fragment-free element to be returned as-is")
+	}
+}
+
+func TestDiffSkipsIdenticalSubtree(t *testing.T) {
+	shared := withChildren(elementNode("ul"), withChildren(elementNode("li"), textNode("row")))
+	prev := withChildren(elementNode("div"), textNode("old"), shared)
+	next := withChildren(elementNode("div"), textNode("new"), shared)
+
+	patches := Diff(prev, next)
+
+	if len(patches) != 1 {
+		t.Fatalf("expected only the text patch, got %d: %+v", len(patches), patches)
+	}
+	if patches[0].Op != OpSetText {
+		t.Errorf("expected setText, got %s", patches[0].Op)
+	}
+}
+
 func TestFlattenWithNilNode(t *testing.T) {
 	result := Flatten(nil)
 	if result != nil {
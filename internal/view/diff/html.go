@@ -0,0 +1,135 @@
+package diff
+
+import (
+	"html"
+	"strings"
+
+	"github.com/eleven-am/pondlive/internal/view"
+)
+
+// DefaultHTMLInsertThreshold is how many nodes an inserted subtree needs before
+// it is sent as pre-rendered HTML.
+const DefaultHTMLInsertThreshold = 128
+
+type HTMLPayload struct {
+	HTML string  `json:"html"`
+	Key  string  `json:"key,omitempty"`
+	Meta []Patch `json:"meta,omitempty"`
+}
+
+var closesParagraph = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true, "details": true,
+	"div": true, "dl": true, "fieldset": true, "figcaption": true, "figure": true,
+	"footer": true, "form": true, "h1": true, "h2": true, "h3": true, "h4": true,
+	"h5": true, "h6": true, "header": true, "hgroup": true, "hr": true, "main": true,
+	"menu": true, "nav": true, "ol": true, "p": true, "pre": true, "section": true,
+	"table": true, "ul": true,
+}
+
+var noTextChildren = map[string]bool{
+	"table": true, "thead": true, "tbody": true, "tfoot": true, "tr": true, "colgroup": true,
+}
+
+type htmlContext struct {
+	inParagraph bool
+	inAnchor    bool
+	inForm      bool
+}
+
+func insertValue(patches *[]Patch, st *diffState, n view.Node) interface{} {
+	el, ok := n.(*view.Element)
+	if !ok {
+		return n
+	}
+	if st.templates != nil {
+		if payload := st.templates.instantiate(patches, st, el); payload != nil {
+			return payload
+		}
+	}
+	threshold := st.htmlThreshold
+	if threshold <= 0 {
+		return n
+	}
+	if countNodes(el, threshold) < threshold || !htmlSafe(el, htmlContext{}) {
+		return n
+	}
+
+	return &HTMLPayload{
+		HTML: view.RenderHTML(el),
+		Key:  el.Key,
+		Meta: ExtractMetadata(el),
+	}
+}
+
+func countNodes(n view.Node, limit int) int {
+	count := 1
+	var children []view.Node
+	switch node := n.(type) {
+	case *view.Element:
+		children = node.Children
+	case *view.Fragment:
+		children = node.Children
+	}
+	for _, child := range children {
+		if count >= limit {
+			break
+		}
+		count += countNodes(child, limit-count)
+	}
+	return count
+}
+
+// htmlSafe reports whether parsing RenderHTML output yields the same DOM shape,
+// so metadata paths and later patches still line up.
+func htmlSafe(el *view.Element, ctx htmlContext) bool {
+	switch el.Tag {
+	case "script", "template", "noscript":
+		return false
+	case "a":
+		if ctx.inAnchor {
+			return false
+		}
+		ctx.inAnchor = true
+	case "form":
+		if ctx.inForm {
+			return false
+		}
+		ctx.inForm = true
+	}
+	if ctx.inParagraph && closesParagraph[el.Tag] {
+		return false
+	}
+	if el.Tag == "p" {
+		ctx.inParagraph = true
+	}
+	if el.UnsafeHTML != "" {
+		return false
+	}
+
+	prevText := false
+	for i, child := range el.Children {
+		switch node := child.(type) {
+		case *view.Text:
+			if node.Text == "" || prevText || noTextChildren[el.Tag] {
+				return false
+			}
+			if i == 0 && (el.Tag == "pre" || el.Tag == "textarea" || el.Tag == "listing") && strings.HasPrefix(node.Text, "\n") {
+				return false
+			}
+			prevText = true
+			continue
+		case *view.Comment:
+			if html.EscapeString(node.Comment) != node.Comment {
+				return false
+			}
+		case *view.Element:
+			if !htmlSafe(node, ctx) {
+				return false
+			}
+		default:
+			return false
+		}
+		prevText = false
+	}
+	return true
+}
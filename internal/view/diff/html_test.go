@@ -0,0 +1,99 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/eleven-am/pondlive/internal/metadata"
+	"github.com/eleven-am/pondlive/internal/view"
+)
+
+func tableWithRows(rows int) *view.Element {
+	body := elementNode("tbody")
+	for i := 0; i < rows; i++ {
+		button := withChildren(elementNode("button"), textNode("edit"))
+		button.Handlers = []metadata.HandlerMeta{{Event: "click", Handler: fmt.Sprintf("h%d", i)}}
+		body.Children = append(body.Children, withKey(withChildren(elementNode("tr"),
+			withChildren(elementNode("td"), textNode(fmt.Sprintf("row %d", i))),
+			withChildren(elementNode("td"), button),
+		), fmt.Sprintf("r%d", i)))
+	}
+	return withChildren(elementNode("table"), body)
+}
+
+func TestLargeInsertUsesHTMLPayload(t *testing.T) {
+	prev := withChildren(elementNode("main"))
+	next := withChildren(elementNode("main"), tableWithRows(50))
+
+	patches := Diff(prev, next)
+	if len(patches) != 1 || patches[0].Op != OpAddChild {
+		t.Fatalf("expected a single addChild, got %#v", patches)
+	}
+	payload, ok := patches[0].Value.(*HTMLPayload)
+	if !ok {
+		t.Fatalf("expected HTML payload, got %T", patches[0].Value)
+	}
+	if !strings.HasPrefix(payload.HTML, "<table><tbody><tr data-key=\"r0\"><td>row 0</td>") {
+		t.Errorf("unexpected html prefix %q", payload.HTML[:60])
+	}
+	if len(payload.Meta) != 50 || payload.Meta[1].Op != OpSetHandlers {
+		t.Fatalf("expected one handler patch per row, got %d", len(payload.Meta))
+	}
+	if fmt.Sprint(payload.Meta[1].Path) != "[0 1 1 0]" {
+		t.Errorf("expected metadata path relative to the inserted root, got %v", payload.Meta[1].Path)
+	}
+}
+
+func TestSmallInsertKeepsViewNode(t *testing.T) {
+	prev := withChildren(elementNode("main"))
+	next := withChildren(elementNode("main"), tableWithRows(2))
+
+	patches := Diff(prev, next)
+	if _, ok := patches[0].Value.(*view.Element); !ok {
+		t.Fatalf("expected view node for a small insert, got %T", patches[0].Value)
+	}
+}
+
+func TestHTMLPayloadSkipsUnsafeTrees(t *testing.T) {
+	cases := map[string]func(*view.Element){
+		"adjacent text": func(el *view.Element) {
+			el.Children = append(el.Children, withChildren(elementNode("span"), textNode("a"), textNode("b")))
+		},
+		"block in paragraph": func(el *view.Element) {
+			el.Children = append(el.Children, withChildren(elementNode("p"), elementNode("div")))
+		},
+		"text in table row": func(el *view.Element) {
+			el.Children[0].(*view.Element).Children = append(el.Children[0].(*view.Element).Children, textNode(" "))
+		},
+		"script": func(el *view.Element) {
+			el.Children = append(el.Children, elementNode("script"))
+		},
+	}
+
+	for name, mutate := range cases {
+		table := tableWithRows(50)
+		mutate(table)
+		if _, ok := insertValue(nil, &diffState{htmlThreshold: DefaultHTMLInsertThreshold}, table).(*view.Element); !ok {
+			t.Errorf("%s: expected view node fallback", name)
+		}
+	}
+}
+
+func TestHTMLPayloadThresholdDisabled(t *testing.T) {
+	if _, ok := insertValue(nil, &diffState{}, tableWithRows(50)).(*view.Element); !ok {
+		t.Fatal("expected view node when the threshold is disabled")
+	}
+
+	for threshold, wantHTML := range map[int]bool{0: false, DefaultHTMLInsertThreshold: true} {
+		templates := NewTemplates()
+		templates.MinNodes = 1 << 20
+		templates.HTMLThreshold = threshold
+		prev := withChildren(elementNode("main"))
+		DiffTemplates(nil, prev, templates)
+		patches := DiffTemplates(prev, withChildren(elementNode("main"), tableWithRows(50)), templates)
+		if _, ok := patches[0].Value.(*HTMLPayload); ok != wantHTML {
+			t.Errorf("threshold %d: expected HTML payload %v, got %T", threshold, wantHTML, patches[0].Value)
+		}
+	}
+}
@@ -4,6 +4,8 @@ type OpKind string
 
 const (
 	OpSetText      OpKind = "setText"
+	OpAppendText   OpKind = "appendText"
+	OpSpliceText   OpKind = "spliceText"
 	OpSetComment   OpKind = "setComment"
 	OpSetAttr      OpKind = "setAttr"
 	OpDelAttr      OpKind = "delAttr"
@@ -20,6 +22,8 @@ const (
 	OpAddChild     OpKind = "addChild"
 	OpDelChild     OpKind = "delChild"
 	OpMoveChild    OpKind = "moveChild"
+	OpDefTemplate  OpKind = "defTemplate"
+	OpSetSlots     OpKind = "setSlots"
 )
 
 type Patch struct {
@@ -31,3 +35,9 @@ type Patch struct {
 	Selector string      `json:"selector,omitempty"`
 	Index    *int        `json:"index,omitempty"`
 }
+
+type TextSplice struct {
+	Offset int    `json:"offset"`
+	Delete int    `json:"delete"`
+	Insert string `json:"insert"`
+}
@@ -0,0 +1,287 @@
+package diff
+
+import (
+	"crypto/sha256"
+	"html"
+	"sort"
+	"strings"
+
+	"github.com/eleven-am/pondlive/internal/view"
+)
+
+type TemplateSlot struct {
+	Path []int  `json:"path"`
+	Attr string `json:"attr,omitempty"`
+}
+
+type TemplateDef struct {
+	ID      int            `json:"id"`
+	Statics []string       `json:"statics"`
+	Slots   []TemplateSlot `json:"slots"`
+}
+
+type TemplatePayload struct {
+	Template int      `json:"tpl"`
+	Slots    []string `json:"slots"`
+	Key      string   `json:"key,omitempty"`
+	Meta     []Patch  `json:"meta,omitempty"`
+}
+
+type SlotUpdate struct {
+	Template int            `json:"tpl"`
+	Slots    map[int]string `json:"slots"`
+}
+
+// Templates tracks the template definitions a single client has received and
+// which elements of the last diffed view were rendered from them.
+type Templates struct {
+	MinNodes int
+	// HTMLThreshold is the node count from which an insert that matches no
+	// template is sent as pre-rendered HTML. Zero or less disables it.
+	HTMLThreshold int
+	// MaxTemplates caps how many definitions are sent to one client. Once it
+	// is reached, elements of a new shape are inserted without a template.
+	// Zero or less removes the cap.
+	MaxTemplates int
+
+	ids       map[[sha256.Size]byte]int
+	source    view.Node
+	flat      view.Node
+	instances map[*view.Element]int
+	pending   map[*view.Element]int
+}
+
+type templateShape struct {
+	statics []string
+	slots   []TemplateSlot
+	values  []string
+	key     [sha256.Size]byte
+}
+
+// DefaultMaxTemplates bounds the definitions a session keeps for its client,
+// which has no way to forget one.
+const DefaultMaxTemplates = 512
+
+func NewTemplates() *Templates {
+	return &Templates{
+		MinNodes:      8,
+		HTMLThreshold: DefaultHTMLInsertThreshold,
+		MaxTemplates:  DefaultMaxTemplates,
+		ids:           make(map[[sha256.Size]byte]int),
+		instances:     make(map[*view.Element]int),
+	}
+}
+
+func DiffTemplates(prev, next view.Node, t *Templates) []Patch {
+	if t == nil {
+		return Diff(prev, next)
+	}
+
+	var flatPrev, flatNext view.Node
+	if prev != nil {
+		flatPrev = t.flat
+		if prev != t.source {
+			flatPrev = Flatten(prev)
+		}
+	}
+	if next != nil {
+		flatNext = Flatten(next)
+	}
+
+	t.pending = make(map[*view.Element]int)
+	patches := make([]Patch, 0)
+	diffNode(&patches, &diffState{templates: t, htmlThreshold: t.HTMLThreshold}, nil, flatPrev, flatNext)
+
+	t.source, t.flat = next, flatNext
+	t.instances, t.pending = t.pending, nil
+	return patches
+}
+
+func (t *Templates) instantiate(patches *[]Patch, st *diffState, el *view.Element) *TemplatePayload {
+	if countNodes(el, t.MinNodes) < t.MinNodes || !htmlSafe(el, htmlContext{}) {
+		return nil
+	}
+	shape, ok := buildTemplateShape(el)
+	if !ok {
+		return nil
+	}
+
+	id, defined := t.ids[shape.key]
+	if !defined {
+		if t.MaxTemplates > 0 && len(t.ids) >= t.MaxTemplates {
+			return nil
+		}
+		id = len(t.ids) + 1
+		t.ids[shape.key] = id
+		emit(patches, st, Patch{Op: OpDefTemplate, Value: TemplateDef{ID: id, Statics: shape.statics, Slots: shape.slots}})
+	}
+
+	t.pending[el] = id
+	return &TemplatePayload{
+		Template: id,
+		Slots:    shape.values,
+		Key:      el.Key,
+		Meta:     ExtractMetadata(el),
+	}
+}
+
+func (t *Templates) diffInstance(patches *[]Patch, st *diffState, path []int, a, b *view.Element) bool {
+	id, ok := t.instances[a]
+	if !ok {
+		return false
+	}
+	if !sameMetadata(a, b) {
+		return false
+	}
+
+	prevShape, ok := buildTemplateShape(a)
+	if !ok {
+		return false
+	}
+	nextShape, ok := buildTemplateShape(b)
+	if !ok || nextShape.key != prevShape.key {
+		return false
+	}
+
+	t.pending[b] = id
+	changed := make(map[int]string)
+	for i, value := range nextShape.values {
+		if prevShape.values[i] != value {
+			changed[i] = value
+		}
+	}
+	if len(changed) > 0 {
+		emit(patches, st, Patch{Path: copyPath(path), Op: OpSetSlots, Value: SlotUpdate{Template: id, Slots: changed}})
+	}
+	return true
+}
+
+func sameMetadata(a, b *view.Element) bool {
+	if a.Tag != b.Tag || a.RefID != b.RefID || !handlersEqual(a.Handlers, b.Handlers) || !scriptEqual(a.Script, b.Script) {
+		return false
+	}
+	if len(a.Children) != len(b.Children) {
+		return false
+	}
+	for i, child := range a.Children {
+		ae, aok := child.(*view.Element)
+		be, bok := b.Children[i].(*view.Element)
+		if aok != bok {
+			return false
+		}
+		if aok && !sameMetadata(ae, be) {
+			return false
+		}
+	}
+	return true
+}
+
+type shapeBuilder struct {
+	current strings.Builder
+	shape   templateShape
+}
+
+func buildTemplateShape(el *view.Element) (templateShape, bool) {
+	var b shapeBuilder
+	if !b.element(el, nil) {
+		return templateShape{}, false
+	}
+	b.shape.statics = append(b.shape.statics, b.current.String())
+
+	h := sha256.New()
+	for i, static := range b.shape.statics {
+		h.Write([]byte(static))
+		h.Write([]byte{0})
+		if i < len(b.shape.slots) {
+			h.Write([]byte(b.shape.slots[i].Attr))
+			h.Write([]byte{1})
+		}
+	}
+	h.Sum(b.shape.key[:0])
+	return b.shape, true
+}
+
+func (b *shapeBuilder) hole(path []int, attr, value string) {
+	b.shape.statics = append(b.shape.statics, b.current.String())
+	b.current.Reset()
+	b.shape.slots = append(b.shape.slots, TemplateSlot{Path: copyPath(path), Attr: attr})
+	b.shape.values = append(b.shape.values, value)
+}
+
+func (b *shapeBuilder) element(el *view.Element, path []int) bool {
+	if el.Tag == "html" || el.UnsafeHTML != "" || el.Stylesheet != nil {
+		return false
+	}
+
+	b.current.WriteByte('<')
+	b.current.WriteString(el.Tag)
+
+	keys := make([]string, 0, len(el.Attrs))
+	for k := range el.Attrs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		b.current.WriteByte(' ')
+		b.current.WriteString(k)
+		if values := el.Attrs[k]; len(values) > 0 {
+			b.current.WriteString(`="`)
+			b.hole(path, k, strings.Join(values, " "))
+			b.current.WriteByte('"')
+		}
+	}
+
+	if len(el.Style) > 0 {
+		b.current.WriteString(` style="`)
+		b.hole(path, "style", styleString(el.Style))
+		b.current.WriteByte('"')
+	}
+
+	if el.Key != "" {
+		b.current.WriteString(` data-key="`)
+		b.hole(path, "data-key", el.Key)
+		b.current.WriteByte('"')
+	}
+
+	b.current.WriteByte('>')
+	if view.IsVoidElement(el.Tag) {
+		return true
+	}
+
+	for i, child := range el.Children {
+		childPath := append(copyPath(path), i)
+		switch node := child.(type) {
+		case *view.Text:
+			b.hole(childPath, "", node.Text)
+		case *view.Comment:
+			b.current.WriteString("<!--")
+			b.current.WriteString(html.EscapeString(node.Comment))
+			b.current.WriteString("-->")
+		case *view.Element:
+			if !b.element(node, childPath) {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+
+	b.current.WriteString("</")
+	b.current.WriteString(el.Tag)
+	b.current.WriteByte('>')
+	return true
+}
+
+func styleString(style map[string]string) string {
+	keys := make([]string, 0, len(style))
+	for k := range style {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = k + ": " + style[k]
+	}
+	return strings.Join(parts, "; ")
+}
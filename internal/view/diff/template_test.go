@@ -0,0 +1,146 @@
+package diff
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"testing"
+
+	"github.com/eleven-am/pondlive/internal/view"
+)
+
+func templateRow(id int, label string) *view.Element {
+	return withKey(withChildren(withAttr(elementNode("li"), "class", "row"),
+		withChildren(elementNode("strong"), textNode(fmt.Sprintf("#%d", id))),
+		withChildren(elementNode("span"), textNode(label)),
+		withChildren(withStyle(elementNode("em"), "color", "red"), textNode("new")),
+		withChildren(elementNode("small"), textNode("x")),
+	), fmt.Sprintf("r%d", id))
+}
+
+func templateList(rows ...*view.Element) *view.Element {
+	list := elementNode("ul")
+	for _, row := range rows {
+		list.Children = append(list.Children, row)
+	}
+	return withChildren(elementNode("main"), list)
+}
+
+func TestTemplatesDefineOnceAndInsertSlots(t *testing.T) {
+	tpl := NewTemplates()
+	v0 := templateList()
+	v1 := templateList(templateRow(1, "one"))
+	v2 := templateList(templateRow(1, "one"), templateRow(2, "two"))
+
+	DiffTemplates(nil, v0, tpl)
+
+	patches := DiffTemplates(v0, v1, tpl)
+	if len(patches) != 2 || patches[0].Op != OpDefTemplate || patches[1].Op != OpAddChild {
+		t.Fatalf("expected defTemplate then addChild, got %#v", patches)
+	}
+	def := patches[0].Value.(TemplateDef)
+	payload := patches[1].Value.(*TemplatePayload)
+	if payload.Template != def.ID || payload.Key != "r1" {
+		t.Fatalf("unexpected payload %+v", payload)
+	}
+
+	patches = DiffTemplates(v1, v2, tpl)
+	if len(patches) != 1 || patches[0].Op != OpAddChild {
+		t.Fatalf("expected only addChild for a known template, got %#v", patches)
+	}
+	second := patches[0].Value.(*TemplatePayload)
+	if second.Template != def.ID || second.Slots[len(second.Slots)-4] != "two" {
+		t.Fatalf("expected slots for the second row, got %+v", second)
+	}
+}
+
+func TestTemplatesStopDefiningAtCap(t *testing.T) {
+	tpl := NewTemplates()
+	tpl.MaxTemplates = 1
+	other := templateRow(2, "two")
+	other.Tag = "div"
+
+	v0 := templateList()
+	v1 := templateList(templateRow(1, "one"))
+	v2 := templateList(templateRow(1, "one"), other)
+
+	DiffTemplates(nil, v0, tpl)
+	DiffTemplates(v0, v1, tpl)
+
+	patches := DiffTemplates(v1, v2, tpl)
+	if len(patches) != 1 || patches[0].Op != OpAddChild {
+		t.Fatalf("expected a plain addChild past the cap, got %#v", patches)
+	}
+	if _, ok := patches[0].Value.(*TemplatePayload); ok {
+		t.Error("expected the new shape to be inserted without a template")
+	}
+	if len(tpl.ids) != 1 {
+		t.Errorf("expected the cap to hold at 1 definition, got %d", len(tpl.ids))
+	}
+}
+
+func TestTemplateInstanceUpdatesSendChangedSlots(t *testing.T) {
+	tpl := NewTemplates()
+	v0 := templateList()
+	v1 := templateList(templateRow(1, "one"))
+	v2 := templateList(templateRow(1, "uno"))
+
+	DiffTemplates(nil, v0, tpl)
+	DiffTemplates(v0, v1, tpl)
+
+	patches := DiffTemplates(v1, v2, tpl)
+	if len(patches) != 1 || patches[0].Op != OpSetSlots {
+		t.Fatalf("expected a single setSlots, got %#v", patches)
+	}
+	update := patches[0].Value.(SlotUpdate)
+	if len(update.Slots) != 1 {
+		t.Fatalf("expected one changed slot, got %v", update.Slots)
+	}
+	for _, value := range update.Slots {
+		if value != "uno" {
+			t.Errorf("unexpected slot value %q", value)
+		}
+	}
+	if fmt.Sprint(patches[0].Path) != "[0 0]" {
+		t.Errorf("expected instance root path, got %v", patches[0].Path)
+	}
+
+	grown := templateRow(1, "uno")
+	grown.Children = append(grown.Children, elementNode("b"))
+	v3 := templateList(grown)
+	patches = DiffTemplates(v2, v3, tpl)
+	if len(patches) != 1 || patches[0].Op != OpAddChild {
+		t.Fatalf("expected structural change to fall back to a regular diff, got %#v", patches)
+	}
+}
+
+func TestTemplateShapeMatchesRenderHTML(t *testing.T) {
+	row := templateRow(3, `<"quoted" & escaped>`)
+	shape, ok := buildTemplateShape(row)
+	if !ok {
+		t.Fatal("expected row to be templatable")
+	}
+
+	var b strings.Builder
+	for i, static := range shape.statics {
+		b.WriteString(static)
+		if i < len(shape.values) {
+			b.WriteString(html.EscapeString(shape.values[i]))
+		}
+	}
+	if got, want := b.String(), view.RenderHTML(row); got != want {
+		t.Fatalf("template render mismatch:\n got %s\nwant %s", got, want)
+	}
+}
+
+func TestDiffTemplatesNilFallsBackToDiff(t *testing.T) {
+	prev := templateList()
+	next := templateList(templateRow(1, "one"))
+	patches := DiffTemplates(prev, next, nil)
+	if len(patches) != 1 || patches[0].Op != OpAddChild {
+		t.Fatalf("expected plain addChild, got %#v", patches)
+	}
+	if _, ok := patches[0].Value.(*view.Element); !ok {
+		t.Fatalf("expected view node payload, got %T", patches[0].Value)
+	}
+}
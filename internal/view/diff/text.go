@@ -0,0 +1,74 @@
+package diff
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	appendTextOverhead = 8
+	spliceTextOverhead = 40
+)
+
+func diffText(patches *[]Patch, st *diffState, path []int, a, b string) {
+	if a != "" && strings.HasPrefix(b, a) && len(b)-len(a)+appendTextOverhead < len(b) {
+		emit(patches, st, Patch{Path: copyPath(path), Op: OpAppendText, Value: b[len(a):]})
+		return
+	}
+
+	if splice, ok := textSplice(a, b); ok && len(splice.Insert)+spliceTextOverhead < len(b) {
+		emit(patches, st, Patch{Path: copyPath(path), Op: OpSpliceText, Value: splice})
+		return
+	}
+
+	emit(patches, st, Patch{Path: copyPath(path), Op: OpSetText, Value: b})
+}
+
+// Offsets are UTF-16 code units to match DOM CharacterData on the client.
+func textSplice(a, b string) (TextSplice, bool) {
+	if a == "" {
+		return TextSplice{}, false
+	}
+
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) {
+		ra, size := utf8.DecodeRuneInString(a[prefix:])
+		rb, sizeB := utf8.DecodeRuneInString(b[prefix:])
+		if ra != rb || size != sizeB {
+			break
+		}
+		prefix += size
+	}
+
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix {
+		ra, size := utf8.DecodeLastRuneInString(a[:len(a)-suffix])
+		rb, sizeB := utf8.DecodeLastRuneInString(b[:len(b)-suffix])
+		if ra != rb || size != sizeB || suffix+size > len(a)-prefix || suffix+size > len(b)-prefix {
+			break
+		}
+		suffix += size
+	}
+
+	if prefix == 0 && suffix == 0 {
+		return TextSplice{}, false
+	}
+
+	return TextSplice{
+		Offset: utf16Len(a[:prefix]),
+		Delete: utf16Len(a[prefix : len(a)-suffix]),
+		Insert: b[prefix : len(b)-suffix],
+	}, true
+}
+
+func utf16Len(s string) int {
+	n := 0
+	for _, r := range s {
+		if r >= 0x10000 {
+			n += 2
+		} else {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,66 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDiffTextAppend(t *testing.T) {
+	prev := strings.Repeat("token ", 20)
+	patches := Diff(textNode(prev), textNode(prev+"next"))
+
+	if len(patches) != 1 || patches[0].Op != OpAppendText || patches[0].Value.(string) != "next" {
+		t.Fatalf("expected appendText with the new suffix, got %#v", patches)
+	}
+}
+
+func TestDiffTextSplice(t *testing.T) {
+	prev := strings.Repeat("a", 50) + " draft " + strings.Repeat("b", 50)
+	next := strings.Repeat("a", 50) + " final " + strings.Repeat("b", 50)
+	patches := Diff(textNode(prev), textNode(next))
+
+	if len(patches) != 1 || patches[0].Op != OpSpliceText {
+		t.Fatalf("expected spliceText, got %#v", patches)
+	}
+	splice := patches[0].Value.(TextSplice)
+	if splice.Offset != 51 || splice.Delete != 5 || splice.Insert != "final" {
+		t.Fatalf("unexpected splice %+v", splice)
+	}
+	if got := applySplice(prev, splice); got != next {
+		t.Fatalf("splice produced %q", got)
+	}
+}
+
+func TestDiffTextSpliceUTF16Offsets(t *testing.T) {
+	prev := "😀é " + strings.Repeat("x", 60) + " old"
+	next := "😀é " + strings.Repeat("x", 60) + " new"
+	patches := Diff(textNode(prev), textNode(next))
+
+	if len(patches) != 1 || patches[0].Op != OpSpliceText {
+		t.Fatalf("expected spliceText, got %#v", patches)
+	}
+	splice := patches[0].Value.(TextSplice)
+	if splice.Offset != 2+1+1+60+1 || splice.Delete != 3 {
+		t.Fatalf("expected UTF-16 offsets, got %+v", splice)
+	}
+}
+
+func TestDiffTextShortChangesUseSetText(t *testing.T) {
+	cases := [][2]string{
+		{"tick 1", "tick 2"},
+		{"", "hello"},
+		{"ab", "abc"},
+		{strings.Repeat("a", 100), strings.Repeat("b", 100)},
+	}
+	for _, tc := range cases {
+		patches := Diff(textNode(tc[0]), textNode(tc[1]))
+		if len(patches) != 1 || patches[0].Op != OpSetText || patches[0].Value.(string) != tc[1] {
+			t.Errorf("%q -> %q: expected setText, got %#v", tc[0], tc[1], patches)
+		}
+	}
+}
+
+func applySplice(s string, splice TextSplice) string {
+	units := []rune(s)
+	return string(units[:splice.Offset]) + splice.Insert + string(units[splice.Offset+splice.Delete:])
+}
@@ -15,6 +15,10 @@ var voidElements = map[string]bool{
 	"track": true, "wbr": true,
 }
 
+func IsVoidElement(tag string) bool {
+	return voidElements[tag]
+}
+
 func RenderHTML(n Node) string {
 	if n == nil {
 		return ""
@@ -36,3 +36,7 @@ func NewFragment(items ...Item) *Fragment {
 	children, attrs := splitItems(items)
 	return &Fragment{Children: children, Attrs: attrs}
 }
+
+func NewStatic(key string, items ...Item) *StaticNode {
+	return &StaticNode{Key: key, Children: ItemsToNodes(items)}
+}
@@ -43,11 +43,15 @@ type Fragment struct {
 
 type ComponentNode struct {
 	Fn            any
+	FnID          uintptr
 	Props         any
 	InputChildren []Node
 	InputAttrs    []Item
 	Key           string
 	Name          string
+
+	Memo       bool
+	PropsEqual func(a, b any) bool
 }
 
 type PortalNode struct {
@@ -56,6 +60,11 @@ type PortalNode struct {
 
 type PortalTarget struct{}
 
+type StaticNode struct {
+	Key      string
+	Children []Node
+}
+
 func (e *Element) workNode()       {}
 func (t *Text) workNode()          {}
 func (c *Comment) workNode()       {}
@@ -63,6 +72,7 @@ func (f *Fragment) workNode()      {}
 func (c *ComponentNode) workNode() {}
 func (p *PortalNode) workNode()    {}
 func (p *PortalTarget) workNode()  {}
+func (s *StaticNode) workNode()    {}
 
 func (e *Element) ApplyTo(parent *Element) {
 	parent.Children = append(parent.Children, e)
@@ -94,3 +104,7 @@ func (p *PortalNode) ApplyTo(parent *Element) {
 func (p *PortalTarget) ApplyTo(parent *Element) {
 	parent.Children = append(parent.Children, p)
 }
+
+func (s *StaticNode) ApplyTo(parent *Element) {
+	parent.Children = append(parent.Children, s)
+}
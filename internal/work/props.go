@@ -208,11 +208,12 @@ func UnsafeHTML(html string) Item {
 
 func MergeEventOptions(a, b metadata.EventOptions) metadata.EventOptions {
 	merged := metadata.EventOptions{
-		Prevent: a.Prevent || b.Prevent,
-		Stop:    a.Stop || b.Stop,
-		Passive: a.Passive || b.Passive,
-		Once:    a.Once || b.Once,
-		Capture: a.Capture || b.Capture,
+		Prevent:   a.Prevent || b.Prevent,
+		Stop:      a.Stop || b.Stop,
+		Passive:   a.Passive || b.Passive,
+		Once:      a.Once || b.Once,
+		Capture:   a.Capture || b.Capture,
+		Immediate: a.Immediate || b.Immediate,
 	}
 
 	if a.Debounce > 0 && b.Debounce > 0 {
@@ -15,13 +15,26 @@ type App = server.App
 
 type UploadConfig = upload.Config
 
+type TransportMode = server.TransportMode
+
+const (
+	TransportAuto      = server.TransportAuto
+	TransportWebSocket = server.TransportWebSocket
+	TransportSSE       = server.TransportSSE
+)
+
 type appConfig struct {
-	clientAsset   string
-	sessionConfig *session.Config
-	idGenerator   func(*http.Request) (session.SessionID, error)
-	ctx           context.Context
-	pubsub        pond.PubSub
-	uploadConfig  *upload.Config
+	clientAsset    string
+	sessionConfig  *session.Config
+	idGenerator    func(*http.Request) (session.SessionID, error)
+	ctx            context.Context
+	pubsub         pond.PubSub
+	uploadConfig   *upload.Config
+	buildID        string
+	reloadGrace    time.Duration
+	reconnectGrace time.Duration
+	basePath       string
+	transport      TransportMode
 }
 
 type AppOption func(*appConfig)
@@ -44,6 +57,63 @@ func WithDOMTimeout(timeout time.Duration) AppOption {
 	}
 }
 
+func WithTemplates() AppOption {
+	return func(c *appConfig) {
+		if c.sessionConfig == nil {
+			c.sessionConfig = &session.Config{}
+		}
+		c.sessionConfig.Templates = true
+	}
+}
+
+func WithFlushWindow(window time.Duration) AppOption {
+	return func(c *appConfig) {
+		if c.sessionConfig == nil {
+			c.sessionConfig = &session.Config{}
+		}
+		c.sessionConfig.FlushWindow = window
+	}
+}
+
+func WithMaxFPS(fps int) AppOption {
+	return func(c *appConfig) {
+		if c.sessionConfig == nil {
+			c.sessionConfig = &session.Config{}
+		}
+		c.sessionConfig.MaxFPS = fps
+	}
+}
+
+func WithBuildID(id string) AppOption {
+	return func(c *appConfig) {
+		c.buildID = id
+	}
+}
+
+func WithReloadGrace(grace time.Duration) AppOption {
+	return func(c *appConfig) {
+		c.reloadGrace = grace
+	}
+}
+
+func WithReconnectGrace(grace time.Duration) AppOption {
+	return func(c *appConfig) {
+		c.reconnectGrace = grace
+	}
+}
+
+func WithBasePath(path string) AppOption {
+	return func(c *appConfig) {
+		c.basePath = path
+	}
+}
+
+func WithTransport(mode TransportMode) AppOption {
+	return func(c *appConfig) {
+		c.transport = mode
+	}
+}
+
 func WithIDGenerator(gen func(*http.Request) (session.SessionID, error)) AppOption {
 	return func(c *appConfig) {
 		c.idGenerator = gen
@@ -76,13 +146,18 @@ func NewApp(component func(*Ctx) Node, opts ...AppOption) (*App, error) {
 	}
 
 	serverCfg := server.Config{
-		Component:     component,
-		ClientAsset:   cfg.clientAsset,
-		SessionConfig: cfg.sessionConfig,
-		IDGenerator:   cfg.idGenerator,
-		Context:       cfg.ctx,
-		PubSub:        cfg.pubsub,
-		UploadConfig:  cfg.uploadConfig,
+		Component:      component,
+		ClientAsset:    cfg.clientAsset,
+		SessionConfig:  cfg.sessionConfig,
+		IDGenerator:    cfg.idGenerator,
+		Context:        cfg.ctx,
+		PubSub:         cfg.pubsub,
+		UploadConfig:   cfg.uploadConfig,
+		BuildID:        cfg.buildID,
+		ReloadGrace:    cfg.reloadGrace,
+		ReconnectGrace: cfg.reconnectGrace,
+		BasePath:       cfg.basePath,
+		Transport:      cfg.transport,
 	}
 
 	return server.New(serverCfg)
@@ -28,3 +28,7 @@ func Fragment(children ...Item) Node {
 func Portal(children ...Item) Node {
 	return portal.Portal(children...)
 }
+
+func Static(key string, children ...Item) Node {
+	return work.NewStatic(key, children...)
+}
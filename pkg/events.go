@@ -380,7 +380,7 @@ type FormEvent struct {
 }
 
 func (FormEvent) props() []string {
-	return []string{}
+	return []string{"form"}
 }
 
 func buildFormEvent(evt work.Event) FormEvent {
@@ -0,0 +1,633 @@
+package pkg
+
+import (
+	"context"
+	"reflect"
+	"sync"
+
+	"github.com/eleven-am/pondlive/internal/metadata"
+	"github.com/eleven-am/pondlive/internal/runtime"
+	"github.com/eleven-am/pondlive/internal/work"
+)
+
+type AsyncValidator[T any] func(ctx context.Context, values T) error
+
+type FormOptions[T any] struct {
+	ID       string
+	Initial  T
+	Validate func(values T) FieldErrors
+	Async    map[string]AsyncValidator[T]
+	OnSubmit func(values T) error
+}
+
+type FormField struct {
+	Name       string
+	ID         string
+	Value      string
+	Error      string
+	Touched    bool
+	Dirty      bool
+	Validating bool
+
+	checkbox bool
+	setValue func(string)
+	touch    func()
+}
+
+type FormState[T any] struct {
+	state *formState[T]
+}
+
+type formFieldState struct {
+	touched    bool
+	dirty      bool
+	validating bool
+	cancel     context.CancelFunc
+	generation int
+}
+
+type formState[T any] struct {
+	mu          sync.Mutex
+	opts        FormOptions[T]
+	id          string
+	specs       []formFieldSpec
+	initial     map[string]string
+	values      map[string]string
+	fields      map[string]*formFieldState
+	errors      FieldErrors
+	asyncErrors FieldErrors
+	submitError error
+	submitting  bool
+	submitCount int
+	bindings    map[string]*ValueActions
+	refresh     func()
+}
+
+type formItems []work.Item
+
+func (items formItems) ApplyTo(el *work.Element) {
+	for _, item := range items {
+		if item != nil {
+			item.ApplyTo(el)
+		}
+	}
+}
+
+func UseFormState[T any](ctx *Ctx, opts FormOptions[T]) *FormState[T] {
+	refresh := runtime.UseRerender(ctx)
+	ref := runtime.UseRef(ctx, (*formState[T])(nil))
+
+	if ref.Current == nil {
+		state := &formState[T]{
+			id:          opts.ID,
+			specs:       formFieldSpecs(reflect.TypeOf(opts.Initial)),
+			initial:     make(map[string]string),
+			fields:      make(map[string]*formFieldState),
+			errors:      FieldErrors{},
+			asyncErrors: FieldErrors{},
+			bindings:    make(map[string]*ValueActions),
+		}
+		if state.id == "" {
+			state.id = ctx.ComponentID()
+		}
+
+		initial := reflect.ValueOf(opts.Initial)
+		for initial.Kind() == reflect.Ptr && !initial.IsNil() {
+			initial = initial.Elem()
+		}
+		for _, spec := range state.specs {
+			raw := ""
+			if initial.Kind() == reflect.Struct {
+				raw = encodeFormValue(initial.FieldByIndex(spec.index))
+			}
+			state.initial[spec.name] = raw
+			state.fields[spec.name] = &formFieldState{}
+		}
+		state.values = cloneStringMap(state.initial)
+		state.opts = opts
+		state.validateLocked()
+		ref.Current = state
+	}
+
+	state := ref.Current
+	state.mu.Lock()
+	state.opts = opts
+	state.refresh = refresh
+	state.mu.Unlock()
+
+	runtime.UseEffect(ctx, func() func() {
+		return func() {
+			state.mu.Lock()
+			defer state.mu.Unlock()
+			for _, field := range state.fields {
+				if field.cancel != nil {
+					field.cancel()
+				}
+			}
+		}
+	}, state)
+
+	return &FormState[T]{state: state}
+}
+
+func (f *FormState[T]) Values() T {
+	f.state.mu.Lock()
+	defer f.state.mu.Unlock()
+	values, _ := f.state.decodeLocked()
+	return values
+}
+
+func (f *FormState[T]) Field(name string) FormField {
+	s := f.state
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	field := FormField{
+		Name:  name,
+		ID:    s.id + "-" + name,
+		Value: s.values[name],
+		setValue: func(value string) {
+			s.input(name, value)
+		},
+		touch: func() {
+			s.touch(name)
+		},
+	}
+
+	if spec, ok := s.spec(name); ok {
+		field.checkbox = spec.kind == reflect.Bool
+	}
+
+	if fs := s.fields[name]; fs != nil {
+		field.Touched = fs.touched
+		field.Dirty = fs.dirty
+		field.Validating = fs.validating
+		if fs.touched || s.submitCount > 0 {
+			field.Error = s.errorLocked(name)
+		}
+	}
+
+	return field
+}
+
+func (f *FormState[T]) Errors() FieldErrors {
+	f.state.mu.Lock()
+	defer f.state.mu.Unlock()
+	return f.state.allErrorsLocked()
+}
+
+func (f *FormState[T]) Valid() bool {
+	f.state.mu.Lock()
+	defer f.state.mu.Unlock()
+	return len(f.state.allErrorsLocked()) == 0
+}
+
+func (f *FormState[T]) Dirty() bool {
+	f.state.mu.Lock()
+	defer f.state.mu.Unlock()
+	for _, fs := range f.state.fields {
+		if fs.dirty {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *FormState[T]) Submitting() bool {
+	f.state.mu.Lock()
+	defer f.state.mu.Unlock()
+	return f.state.submitting
+}
+
+func (f *FormState[T]) SubmitCount() int {
+	f.state.mu.Lock()
+	defer f.state.mu.Unlock()
+	return f.state.submitCount
+}
+
+func (f *FormState[T]) SubmitError() error {
+	f.state.mu.Lock()
+	defer f.state.mu.Unlock()
+	return f.state.submitError
+}
+
+func (f *FormState[T]) SetValue(name, value string) {
+	f.state.setValue(name, value)
+}
+
+func (f *FormState[T]) SetError(name, msg string) {
+	s := f.state
+	s.mu.Lock()
+	if msg == "" {
+		delete(s.asyncErrors, name)
+	} else {
+		s.asyncErrors[name] = msg
+	}
+	s.mu.Unlock()
+	s.rerender()
+}
+
+func (f *FormState[T]) Reset() {
+	s := f.state
+	s.mu.Lock()
+	for name, fs := range s.fields {
+		if fs.cancel != nil {
+			fs.cancel()
+		}
+		s.fields[name] = &formFieldState{generation: fs.generation + 1}
+	}
+	s.values = cloneStringMap(s.initial)
+	s.asyncErrors = FieldErrors{}
+	s.submitError = nil
+	s.submitCount = 0
+	s.validateLocked()
+	bindings := s.bindingsLocked()
+	values := cloneStringMap(s.values)
+	s.mu.Unlock()
+
+	for name, actions := range bindings {
+		s.syncBinding(name, actions, values[name])
+	}
+	s.rerender()
+}
+
+func (f *FormState[T]) Bind(name string, actions *ValueActions) {
+	if actions == nil {
+		return
+	}
+	f.state.mu.Lock()
+	f.state.bindings[name] = actions
+	f.state.mu.Unlock()
+}
+
+func (f *FormState[T]) Submit(values map[string]string) error {
+	return f.state.submit(values)
+}
+
+func (f *FormState[T]) Props() Item {
+	return formItems{
+		work.Attr("novalidate", ""),
+		work.OnWith("submit", metadata.EventOptions{Props: FormEvent{}.props(), Prevent: true}, func(evt work.Event) work.Updates {
+			if run, ok := f.state.beginSubmit(evt.Form); ok {
+				go run()
+			}
+			return nil
+		}),
+	}
+}
+
+func (f FormField) Invalid() bool {
+	return f.Error != ""
+}
+
+func (f FormField) ErrorID() string {
+	return f.ID + "-error"
+}
+
+func (f FormField) Props() Item {
+	items := formItems{
+		work.Attr("id", f.ID),
+		work.Attr("name", f.Name),
+	}
+
+	if f.checkbox {
+		items = append(items,
+			work.Attr("type", "checkbox"),
+			work.Attr("value", "true"),
+			work.If(f.Value != "", work.Checked()),
+			work.OnWith("change", metadata.EventOptions{Props: []string{"target.checked"}}, func(evt work.Event) work.Updates {
+				if payloadBool(evt.Payload, "target.checked") {
+					f.setValue("true")
+				} else {
+					f.setValue("")
+				}
+				return nil
+			}),
+		)
+	} else {
+		items = append(items,
+			work.Attr("value", f.Value),
+			work.OnWith("input", metadata.EventOptions{Props: ChangeEvent{}.props()}, func(evt work.Event) work.Updates {
+				f.setValue(evt.Value)
+				return nil
+			}),
+		)
+	}
+
+	items = append(items, work.On("blur", func(work.Event) work.Updates {
+		f.touch()
+		return nil
+	}))
+
+	if f.Invalid() {
+		items = append(items,
+			work.Aria("invalid", "true"),
+			work.Aria("describedby", f.ErrorID()),
+		)
+	}
+
+	return items
+}
+
+func (s *formState[T]) spec(name string) (formFieldSpec, bool) {
+	for _, spec := range s.specs {
+		if spec.name == name {
+			return spec, true
+		}
+	}
+	return formFieldSpec{}, false
+}
+
+// rerender may run on a validator or submit goroutine, so it only marks the
+// component dirty; everything the form renders is read under s.mu.
+func (s *formState[T]) rerender() {
+	s.mu.Lock()
+	refresh := s.refresh
+	s.mu.Unlock()
+
+	if refresh != nil {
+		refresh()
+	}
+}
+
+func (s *formState[T]) input(name, value string) {
+	s.mu.Lock()
+	s.setRawLocked(name, value)
+	s.mu.Unlock()
+
+	s.runAsync(name)
+	s.rerender()
+}
+
+func (s *formState[T]) setValue(name, value string) {
+	s.mu.Lock()
+	s.setRawLocked(name, value)
+	actions := s.bindings[name]
+	s.mu.Unlock()
+
+	s.syncBinding(name, actions, value)
+	s.runAsync(name)
+	s.rerender()
+}
+
+func (s *formState[T]) touch(name string) {
+	s.mu.Lock()
+	fs := s.fields[name]
+	if fs == nil || fs.touched {
+		s.mu.Unlock()
+		return
+	}
+	fs.touched = true
+	s.mu.Unlock()
+
+	s.rerender()
+}
+
+func (s *formState[T]) setRawLocked(name, value string) {
+	s.values[name] = value
+
+	fs := s.fields[name]
+	if fs == nil {
+		fs = &formFieldState{}
+		s.fields[name] = fs
+	}
+	fs.dirty = value != s.initial[name]
+
+	delete(s.asyncErrors, name)
+	s.validateLocked()
+}
+
+func (s *formState[T]) syncBinding(name string, actions *ValueActions, value string) {
+	if actions == nil {
+		return
+	}
+	if spec, ok := s.spec(name); ok && spec.kind == reflect.Bool {
+		actions.SetChecked(value != "")
+		return
+	}
+	actions.SetValue(value)
+}
+
+func (s *formState[T]) bindingsLocked() map[string]*ValueActions {
+	bindings := make(map[string]*ValueActions, len(s.bindings))
+	for name, actions := range s.bindings {
+		bindings[name] = actions
+	}
+	return bindings
+}
+
+func (s *formState[T]) decodeLocked() (T, FieldErrors) {
+	var values T
+	errs := FieldErrors{}
+
+	target := reflect.ValueOf(&values).Elem()
+	if target.Kind() == reflect.Ptr {
+		target.Set(reflect.New(target.Type().Elem()))
+		target = target.Elem()
+	}
+
+	for _, spec := range s.specs {
+		if err := decodeFormValue(target.FieldByIndex(spec.index), s.values[spec.name]); err != nil {
+			errs[spec.name] = err.Error()
+		}
+	}
+	return values, errs
+}
+
+func (s *formState[T]) validateLocked() {
+	values, errs := s.decodeLocked()
+
+	for _, spec := range s.specs {
+		if _, failed := errs[spec.name]; failed {
+			continue
+		}
+		for _, rule := range spec.rules {
+			if msg := checkFormRule(spec, rule, s.values[spec.name]); msg != "" {
+				errs[spec.name] = msg
+				break
+			}
+		}
+	}
+
+	if s.opts.Validate != nil {
+		for name, msg := range s.opts.Validate(values) {
+			if _, failed := errs[name]; !failed && msg != "" {
+				errs[name] = msg
+			}
+		}
+	}
+
+	s.errors = errs
+}
+
+func (s *formState[T]) errorLocked(name string) string {
+	if msg := s.errors[name]; msg != "" {
+		return msg
+	}
+	return s.asyncErrors[name]
+}
+
+func (s *formState[T]) allErrorsLocked() FieldErrors {
+	errs := FieldErrors{}
+	for name, msg := range s.asyncErrors {
+		errs[name] = msg
+	}
+	for name, msg := range s.errors {
+		errs[name] = msg
+	}
+	return errs
+}
+
+func (s *formState[T]) runAsync(name string) {
+	s.mu.Lock()
+	validator := s.opts.Async[name]
+	fs := s.fields[name]
+	if validator == nil || fs == nil {
+		s.mu.Unlock()
+		return
+	}
+
+	if fs.cancel != nil {
+		fs.cancel()
+		fs.cancel = nil
+	}
+	fs.generation++
+
+	if s.errors[name] != "" {
+		fs.validating = false
+		s.mu.Unlock()
+		return
+	}
+
+	values, _ := s.decodeLocked()
+	ctx, cancel := context.WithCancel(context.Background())
+	fs.cancel = cancel
+	fs.validating = true
+	generation := fs.generation
+	s.mu.Unlock()
+
+	go func() {
+		err := validator(ctx, values)
+
+		s.mu.Lock()
+		if fs.generation != generation || ctx.Err() != nil {
+			s.mu.Unlock()
+			return
+		}
+		fs.validating = false
+		fs.cancel = nil
+		if err != nil {
+			s.asyncErrors[name] = err.Error()
+		} else {
+			delete(s.asyncErrors, name)
+		}
+		s.mu.Unlock()
+
+		cancel()
+		s.rerender()
+	}()
+}
+
+func (s *formState[T]) submit(form map[string]string) error {
+	run, ok := s.beginSubmit(form)
+	if !ok {
+		return nil
+	}
+	return run()
+}
+
+// beginSubmit marks the form as submitting and returns the validation and
+// OnSubmit work still to run. The submit handler runs that work on its own
+// goroutine so the submitting state reaches the client while it is pending.
+func (s *formState[T]) beginSubmit(form map[string]string) (func() error, bool) {
+	s.mu.Lock()
+	if s.submitting {
+		s.mu.Unlock()
+		return nil, false
+	}
+
+	if form != nil {
+		for _, spec := range s.specs {
+			value, ok := form[spec.name]
+			if !ok && spec.kind != reflect.Bool {
+				continue
+			}
+			s.values[spec.name] = value
+			s.fields[spec.name].dirty = value != s.initial[spec.name]
+		}
+	}
+
+	for _, fs := range s.fields {
+		fs.touched = true
+		if fs.cancel != nil {
+			fs.cancel()
+			fs.cancel = nil
+		}
+		fs.generation++
+		fs.validating = false
+	}
+
+	s.submitCount++
+	s.submitting = true
+	s.submitError = nil
+	s.asyncErrors = FieldErrors{}
+	s.validateLocked()
+
+	values, _ := s.decodeLocked()
+	hasErrors := len(s.errors) > 0
+	opts := s.opts
+	s.mu.Unlock()
+	s.rerender()
+
+	return func() error {
+		return s.finishSubmit(opts, values, hasErrors)
+	}, true
+}
+
+func (s *formState[T]) finishSubmit(opts FormOptions[T], values T, hasErrors bool) error {
+	asyncErrs := FieldErrors{}
+	if !hasErrors {
+		for name, validator := range opts.Async {
+			if validator == nil {
+				continue
+			}
+			if err := validator(context.Background(), values); err != nil {
+				asyncErrs[name] = err.Error()
+			}
+		}
+	}
+
+	var submitErr error
+	if !hasErrors && len(asyncErrs) == 0 && opts.OnSubmit != nil {
+		submitErr = opts.OnSubmit(values)
+	}
+
+	s.mu.Lock()
+	s.submitting = false
+	for name, msg := range asyncErrs {
+		s.asyncErrors[name] = msg
+	}
+	if fieldErrs, ok := submitErr.(FieldErrors); ok {
+		for name, msg := range fieldErrs {
+			s.asyncErrors[name] = msg
+		}
+	} else if submitErr != nil {
+		s.submitError = submitErr
+	}
+	errs := s.allErrorsLocked()
+	s.mu.Unlock()
+	s.rerender()
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return submitErr
+}
+
+func cloneStringMap(m map[string]string) map[string]string {
+	out := make(map[string]string, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
@@ -0,0 +1,231 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/eleven-am/pondlive/internal/runtime"
+	"github.com/eleven-am/pondlive/internal/work"
+)
+
+type signupForm struct {
+	Email string `form:"email" validate:"required,email"`
+	Name  string `form:"name" validate:"min=2"`
+	Age   int    `form:"age" validate:"min=18"`
+	Terms bool   `form:"terms" validate:"required"`
+}
+
+func formTestSession(render func(ctx *Ctx)) *runtime.Session {
+	root := &runtime.Instance{
+		ID: "root",
+		Fn: func(ctx *runtime.Ctx, _ any, _ []work.Item) work.Node {
+			render(ctx)
+			return nil
+		},
+	}
+	return &runtime.Session{
+		Root:              root,
+		Components:        map[string]*runtime.Instance{"root": root},
+		Handlers:          make(map[string]work.Handler),
+		MountedComponents: make(map[*runtime.Instance]struct{}),
+	}
+}
+
+func TestFormStateDecodesInitialValues(t *testing.T) {
+	var form *FormState[signupForm]
+	sess := formTestSession(func(ctx *Ctx) {
+		form = UseFormState(ctx, FormOptions[signupForm]{
+			Initial: signupForm{Email: "a@example.com", Age: 30},
+		})
+	})
+	if err := sess.Flush(); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+
+	if got := form.Field("age").Value; got != "30" {
+		t.Errorf("expected age field value 30, got %q", got)
+	}
+	if got := form.Values(); got.Email != "a@example.com" || got.Age != 30 {
+		t.Errorf("unexpected decoded values %+v", got)
+	}
+	if form.Field("terms").Error != "" {
+		t.Error("expected untouched field to hide its error")
+	}
+	if form.Valid() {
+		t.Error("expected form to be invalid until terms are accepted")
+	}
+}
+
+func TestFormStateFieldPropsReflectValidation(t *testing.T) {
+	var form *FormState[signupForm]
+	sess := formTestSession(func(ctx *Ctx) {
+		form = UseFormState(ctx, FormOptions[signupForm]{})
+	})
+	if err := sess.Flush(); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+
+	el := &work.Element{Tag: "input"}
+	form.Field("email").Props().ApplyTo(el)
+	if el.Handlers["input"].Fn == nil || el.Handlers["blur"].Fn == nil {
+		t.Fatal("expected input and blur handlers on field props")
+	}
+
+	el.Handlers["input"].Fn(work.Event{Value: "not-an-email"})
+	el.Handlers["blur"].Fn(work.Event{})
+	if err := sess.Flush(); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+
+	field := form.Field("email")
+	if !field.Touched || !field.Dirty {
+		t.Errorf("expected field to be touched and dirty, got %+v", field)
+	}
+	if field.Error != "Must be a valid email address" {
+		t.Errorf("unexpected error %q", field.Error)
+	}
+
+	el = &work.Element{Tag: "input"}
+	field.Props().ApplyTo(el)
+	if got := el.Attrs["aria-invalid"]; len(got) != 1 || got[0] != "true" {
+		t.Errorf("expected aria-invalid, got %v", got)
+	}
+	if got := el.Attrs["aria-describedby"]; len(got) != 1 || got[0] != field.ErrorID() {
+		t.Errorf("expected aria-describedby %q, got %v", field.ErrorID(), got)
+	}
+	if got := el.Attrs["value"]; len(got) != 1 || got[0] != "not-an-email" {
+		t.Errorf("expected value attr to track input, got %v", got)
+	}
+}
+
+func TestFormStateSubmitDecodesAndReportsServerErrors(t *testing.T) {
+	var submitted []signupForm
+	var form *FormState[signupForm]
+	sess := formTestSession(func(ctx *Ctx) {
+		form = UseFormState(ctx, FormOptions[signupForm]{
+			OnSubmit: func(values signupForm) error {
+				submitted = append(submitted, values)
+				if values.Email == "taken@example.com" {
+					return FieldErrors{"email": "Already registered"}
+				}
+				return nil
+			},
+		})
+	})
+	if err := sess.Flush(); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+
+	err := form.Submit(map[string]string{"email": "x", "age": "abc"})
+	if err == nil {
+		t.Fatal("expected invalid submission to fail")
+	}
+	if len(submitted) != 0 {
+		t.Fatal("expected OnSubmit not to run for invalid values")
+	}
+	errs := form.Errors()
+	if errs["age"] != "Must be a whole number" || errs["terms"] != "This field is required" {
+		t.Errorf("unexpected errors %v", errs)
+	}
+
+	err = form.Submit(map[string]string{"email": "taken@example.com", "name": "Ada", "age": "36", "terms": "true"})
+	if len(submitted) != 1 || submitted[0].Age != 36 || !submitted[0].Terms {
+		t.Fatalf("expected decoded submission, got %+v", submitted)
+	}
+	if err == nil || form.Errors()["email"] != "Already registered" {
+		t.Errorf("expected server field error, got %v", form.Errors())
+	}
+	if form.SubmitCount() != 2 {
+		t.Errorf("expected submit count 2, got %d", form.SubmitCount())
+	}
+}
+
+func TestFormStateAsyncValidator(t *testing.T) {
+	done := make(chan struct{}, 1)
+	var form *FormState[signupForm]
+	sess := formTestSession(func(ctx *Ctx) {
+		form = UseFormState(ctx, FormOptions[signupForm]{
+			Async: map[string]AsyncValidator[signupForm]{
+				"email": func(_ context.Context, values signupForm) error {
+					defer func() { done <- struct{}{} }()
+					if values.Email == "taken@example.com" {
+						return errors.New("Already registered")
+					}
+					return nil
+				},
+			},
+		})
+	})
+	if err := sess.Flush(); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+
+	f := form
+	f.SetValue("email", "taken@example.com")
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("async validator did not run")
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for f.Errors()["email"] == "" && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got := f.Errors()["email"]; got != "Already registered" {
+		t.Errorf("expected async error, got %q", got)
+	}
+	if f.Field("email").Validating {
+		t.Error("expected validation to be finished")
+	}
+}
+
+func TestFormStateSubmitHandlerRunsInBackground(t *testing.T) {
+	release := make(chan struct{})
+	submitted := make(chan signupForm, 1)
+	var form *FormState[signupForm]
+	sess := formTestSession(func(ctx *Ctx) {
+		form = UseFormState(ctx, FormOptions[signupForm]{
+			OnSubmit: func(values signupForm) error {
+				<-release
+				submitted <- values
+				return nil
+			},
+		})
+	})
+	if err := sess.Flush(); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+
+	f := form
+	el := &work.Element{Tag: "form"}
+	f.Props().ApplyTo(el)
+	el.Handlers["submit"].Fn(work.Event{Form: map[string]string{
+		"email": "ada@example.com", "name": "Ada", "age": "36", "terms": "true",
+	}})
+
+	if !f.Submitting() {
+		t.Fatal("expected the form to be submitting while OnSubmit runs")
+	}
+	close(release)
+
+	select {
+	case values := <-submitted:
+		if values.Email != "ada@example.com" {
+			t.Errorf("unexpected submission %+v", values)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("OnSubmit did not run")
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for f.Submitting() && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if f.Submitting() {
+		t.Error("expected submitting to clear once OnSubmit returned")
+	}
+}
@@ -0,0 +1,260 @@
+package pkg
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+type FieldErrors map[string]string
+
+func (e FieldErrors) Error() string {
+	parts := make([]string, 0, len(e))
+	for name, msg := range e {
+		parts = append(parts, name+": "+msg)
+	}
+	return strings.Join(parts, "; ")
+}
+
+type formRule struct {
+	name  string
+	param string
+}
+
+type formFieldSpec struct {
+	name  string
+	index []int
+	kind  reflect.Kind
+	rules []formRule
+}
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+var textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+func formFieldSpecs(t reflect.Type) []formFieldSpec {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		panic("pkg: UseFormState requires a struct type")
+	}
+
+	var specs []formFieldSpec
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name := field.Tag.Get("form")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		spec := formFieldSpec{
+			name:  name,
+			index: field.Index,
+			kind:  field.Type.Kind(),
+		}
+
+		if tag := field.Tag.Get("validate"); tag != "" {
+			for _, part := range strings.Split(tag, ",") {
+				part = strings.TrimSpace(part)
+				if part == "" {
+					continue
+				}
+				rule := formRule{name: part}
+				if eq := strings.IndexByte(part, '='); eq >= 0 {
+					rule.name = part[:eq]
+					rule.param = part[eq+1:]
+				}
+				spec.rules = append(spec.rules, rule)
+			}
+		}
+
+		specs = append(specs, spec)
+	}
+	return specs
+}
+
+func encodeFormValue(v reflect.Value) string {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+
+	if v.Type().Implements(textMarshalerType) {
+		text, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return ""
+		}
+		return string(text)
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		if v.Bool() {
+			return "true"
+		}
+		return ""
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64)
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+func decodeFormValue(v reflect.Value, raw string) error {
+	if v.Kind() == reflect.Ptr {
+		if raw == "" {
+			v.Set(reflect.Zero(v.Type()))
+			return nil
+		}
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+
+	if v.CanAddr() && v.Addr().Type().Implements(textUnmarshalerType) {
+		if raw == "" {
+			v.Set(reflect.Zero(v.Type()))
+			return nil
+		}
+		if err := v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(raw)); err != nil {
+			return errors.New("Invalid value")
+		}
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Bool:
+		switch strings.ToLower(raw) {
+		case "", "false", "off", "0":
+			v.SetBool(false)
+		default:
+			v.SetBool(true)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if raw == "" {
+			v.SetInt(0)
+			return nil
+		}
+		n, err := strconv.ParseInt(strings.TrimSpace(raw), 10, v.Type().Bits())
+		if err != nil {
+			return errors.New("Must be a whole number")
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if raw == "" {
+			v.SetUint(0)
+			return nil
+		}
+		n, err := strconv.ParseUint(strings.TrimSpace(raw), 10, v.Type().Bits())
+		if err != nil {
+			return errors.New("Must be a positive whole number")
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		if raw == "" {
+			v.SetFloat(0)
+			return nil
+		}
+		n, err := strconv.ParseFloat(strings.TrimSpace(raw), v.Type().Bits())
+		if err != nil {
+			return errors.New("Must be a number")
+		}
+		v.SetFloat(n)
+	default:
+		return errors.New("Unsupported field type")
+	}
+	return nil
+}
+
+func checkFormRule(spec formFieldSpec, rule formRule, raw string) string {
+	switch rule.name {
+	case "required":
+		if strings.TrimSpace(raw) == "" {
+			return "This field is required"
+		}
+		return ""
+	}
+
+	if raw == "" {
+		return ""
+	}
+
+	numeric := isNumericKind(spec.kind)
+
+	switch rule.name {
+	case "email":
+		addr, err := mail.ParseAddress(raw)
+		if err != nil || addr.Address != raw {
+			return "Must be a valid email address"
+		}
+	case "url":
+		u, err := url.ParseRequestURI(raw)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return "Must be a valid URL"
+		}
+	case "min":
+		limit, _ := strconv.ParseFloat(rule.param, 64)
+		if numeric {
+			if n, err := strconv.ParseFloat(raw, 64); err == nil && n < limit {
+				return "Must be at least " + rule.param
+			}
+		} else if float64(utf8.RuneCountInString(raw)) < limit {
+			return "Must be at least " + rule.param + " characters"
+		}
+	case "max":
+		limit, _ := strconv.ParseFloat(rule.param, 64)
+		if numeric {
+			if n, err := strconv.ParseFloat(raw, 64); err == nil && n > limit {
+				return "Must be at most " + rule.param
+			}
+		} else if float64(utf8.RuneCountInString(raw)) > limit {
+			return "Must be at most " + rule.param + " characters"
+		}
+	case "len":
+		limit, _ := strconv.Atoi(rule.param)
+		if utf8.RuneCountInString(raw) != limit {
+			return "Must be exactly " + rule.param + " characters"
+		}
+	case "oneof":
+		for _, option := range strings.Fields(rule.param) {
+			if raw == option {
+				return ""
+			}
+		}
+		return "Must be one of " + strings.Join(strings.Fields(rule.param), ", ")
+	}
+	return ""
+}
+
+func isNumericKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/eleven-am/pondlive/internal/metatags"
 	"github.com/eleven-am/pondlive/internal/protocol"
 	"github.com/eleven-am/pondlive/internal/runtime"
+	"github.com/eleven-am/pondlive/internal/storage"
 	"github.com/eleven-am/pondlive/internal/styles"
 	"github.com/eleven-am/pondlive/internal/upload"
 )
@@ -44,10 +45,12 @@ type (
 	PresenceInput[T any]      = runtime.PresenceInput[T]
 	PresenceResult[T any]     = runtime.PresenceResult[T]
 	PresenceItem[T any]       = runtime.PresenceItem[T]
+	KeepAliveProps            = runtime.KeepAliveProps
 	Meta                      = metatags.Meta
 	CookieOptions             = headers.CookieOptions
 	Document                  = document.Document
 	EventOptions              = metadata.EventOptions
+	StorageOption             = storage.Option
 )
 
 const (
@@ -147,6 +150,22 @@ func UseCookie(ctx *Ctx, name string) (string, func(value string, opts *CookieOp
 	return headers.UseCookie(ctx, name)
 }
 
+func UseStatus(ctx *Ctx, code int) {
+	headers.UseStatus(ctx, code)
+}
+
+func UseLocalStorage[T any](ctx *Ctx, key string, initial T, opts ...StorageOption) (T, func(T)) {
+	return storage.UseLocalStorage(ctx, key, initial, opts...)
+}
+
+func UseSessionStorage[T any](ctx *Ctx, key string, initial T, opts ...StorageOption) (T, func(T)) {
+	return storage.UseSessionStorage(ctx, key, initial, opts...)
+}
+
+func WithCookieMirror() StorageOption {
+	return storage.WithCookieMirror()
+}
+
 func UseMetaTags(ctx *Ctx, meta *Meta) {
 	metatags.UseMetaTags(ctx, meta)
 }
@@ -192,6 +211,14 @@ func UseHydrated(ctx *Ctx, fn func() func(), deps ...any) {
 	}, allDeps...)
 }
 
+func UseActivated(ctx *Ctx, fn func()) {
+	runtime.UseActivated(ctx, fn)
+}
+
+func UseDeactivated(ctx *Ctx, fn func()) {
+	runtime.UseDeactivated(ctx, fn)
+}
+
 func UsePresence[T any](ctx *Ctx, in PresenceInput[T]) PresenceResult[T] {
 	return runtime.UsePresence(ctx, in)
 }
@@ -1,6 +1,7 @@
 package pkg
 
 import (
+	"net/http"
 	"net/url"
 
 	"github.com/eleven-am/pondlive/internal/router"
@@ -10,6 +11,7 @@ import (
 var (
 	ErrParamNotFound      = router.ErrParamNotFound
 	ErrQueryParamNotFound = router.ErrQueryParamNotFound
+	ErrInvalidParam       = router.ErrInvalidParam
 )
 
 type (
@@ -22,8 +24,41 @@ type (
 	RedirectProps   = router.RedirectProps
 	Router          = router.Router
 	NavigationEvent = router.NavigationEvent
+	GuardResult     = router.GuardResult
+	Blocker         = router.Blocker
+	ScrollBehavior  = router.ScrollBehavior
+	RouteMatch      = router.RouteMatch
+	SitemapOptions  = router.SitemapOptions
 )
 
+const (
+	ScrollDefault  = router.ScrollDefault
+	ScrollAuto     = router.ScrollAuto
+	ScrollPreserve = router.ScrollPreserve
+	FocusNone      = router.FocusNone
+)
+
+type (
+	RouteDef[T any]          = router.RouteDef[T]
+	QueryStateOptions[T any] = router.QueryStateOptions[T]
+)
+
+func DefineRoute[T any](pattern string) *RouteDef[T] {
+	return router.DefineRoute[T](pattern)
+}
+
+func Allow() GuardResult {
+	return router.Allow()
+}
+
+func GuardRedirect(to string, status int) GuardResult {
+	return router.GuardRedirect(to, status)
+}
+
+func GuardFallback(fallback func(*Ctx) Node) GuardResult {
+	return router.GuardFallback(fallback)
+}
+
 func Navigate(ctx *Ctx, href string) {
 	router.Navigate(ctx, href)
 }
@@ -72,6 +107,38 @@ func UseSearchParams(ctx *Ctx) url.Values {
 	return router.UseSearchParams(ctx)
 }
 
+func UseBlocker(ctx *Ctx, when bool, onBlock func(NavigationEvent) bool) *Blocker {
+	return router.UseBlocker(ctx, when, onBlock)
+}
+
+func UseQueryState[T any](ctx *Ctx, opts QueryStateOptions[T]) (T, func(T)) {
+	return router.UseQueryState(ctx, opts)
+}
+
+func UseLoaderData[T any](ctx *Ctx) T {
+	return router.UseLoaderData[T](ctx)
+}
+
+func UseLoaderError(ctx *Ctx) error {
+	return router.UseLoaderError(ctx)
+}
+
+func UseNavigationPending(ctx *Ctx) bool {
+	return router.UseNavigationPending(ctx)
+}
+
+func UseMatches(ctx *Ctx) []RouteMatch {
+	return router.UseMatches(ctx)
+}
+
+func SitemapHandler(opts SitemapOptions) http.Handler {
+	return router.SitemapHandler(opts)
+}
+
+func RobotsHandler(opts SitemapOptions) http.Handler {
+	return router.RobotsHandler(opts)
+}
+
 func UseMatched(ctx *Ctx) bool {
 	return router.UseMatched(ctx)
 }